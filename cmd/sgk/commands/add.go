@@ -3,6 +3,7 @@ package commands
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
@@ -20,22 +21,44 @@ func AddCmd(addModule AddModuleFunc) *cobra.Command {
 			
 			database, _ := cmd.Flags().GetString("database")
 			routePrefix, _ := cmd.Flags().GetString("route-prefix")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			oauthProviders, _ := cmd.Flags().GetStringSlice("oauth-providers")
+			emailSender, _ := cmd.Flags().GetString("email-sender")
+			
+			if err := validateModuleOptions(oauthProviders, emailSender); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
 			
 			options := map[string]interface{}{
 				"database":     database,
 				"route_prefix": routePrefix,
+				"dry_run":      dryRun,
+			}
+			switch moduleName {
+			case "auth":
+				options["oauth_providers"] = strings.Join(oauthProviders, ",")
+			case "email":
+				options["email_sender"] = emailSender
 			}
 			
 			if err := addModule(moduleName, options); err != nil {
 				fmt.Fprintf(os.Stderr, "Error adding module: %v\n", err)
 				os.Exit(1)
 			}
+			if dryRun {
+				return
+			}
 			fmt.Printf("✅ Module '%s' added successfully!\n", moduleName)
+			fmt.Println("📦 Run 'go mod tidy' to fetch its dependencies")
 		},
 	}
 
 	cmd.Flags().String("database", "postgres", "Database type (postgres, mysql, sqlite)")
 	cmd.Flags().String("route-prefix", "", "Route prefix for the module")
+	cmd.Flags().StringSlice("oauth-providers", []string{}, "OAuth providers to enable for the auth module (google, github, oidc)")
+	cmd.Flags().String("email-sender", "", "Email sender for the email module (smtp, mock)")
+	cmd.Flags().Bool("dry-run", false, "Print the planned file operations without writing anything")
 
 	return cmd
 }
\ No newline at end of file
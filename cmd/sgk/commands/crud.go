@@ -8,10 +8,10 @@ import (
 	"github.com/spf13/cobra"
 )
 
-type CrudGeneratorFunc func(moduleName string) error
+type CrudGeneratorFunc func(moduleName string, dryRun bool) error
 
 func CrudCmd(generateCrud CrudGeneratorFunc) *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "crud [module-name]",
 		Short: "Generate a complete CRUD module with model, repository, service, and controller",
 		Long: `Generate a complete CRUD module
@@ -34,14 +34,23 @@ Example: sgk crud product`,
 				}
 			}
 			
-			if err := generateCrud(moduleName); err != nil {
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			
+			if err := generateCrud(moduleName, dryRun); err != nil {
 				fmt.Fprintf(os.Stderr, "Error generating CRUD module: %v\n", err)
 				os.Exit(1)
 			}
+			if dryRun {
+				return
+			}
 			
 			fmt.Printf("✅ CRUD module '%s' generated successfully!\n", moduleName)
 			fmt.Printf("📁 Files created in internal/%s/\n", moduleName)
 			fmt.Printf("🔄 Module registered in main.go\n")
 		},
 	}
+
+	cmd.Flags().Bool("dry-run", false, "Print the planned file operations without writing anything")
+
+	return cmd
 }
\ No newline at end of file
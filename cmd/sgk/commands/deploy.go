@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+type DeployInitFunc func(force, dryRun bool) error
+
+func DeployCmd(initDeploy DeployInitFunc) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "deploy",
+		Short: "Manage deployment files for your project",
+	}
+
+	initCmd := &cobra.Command{
+		Use:   "init",
+		Short: "Generate Dockerfile, production compose file and Kubernetes manifests",
+		Long: `Generates a multi-stage Dockerfile, docker-compose.prod.yml and Kubernetes
+manifests under deploy/k8s based on the modules installed in sgk.json.
+
+Redis is included when the auth module is installed, and liveness/readiness
+probes point at the health module's endpoints when it is installed.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			force, _ := cmd.Flags().GetBool("force")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+			if err := initDeploy(force, dryRun); err != nil {
+				fmt.Fprintf(os.Stderr, "Error generating deployment files: %v\n", err)
+				os.Exit(1)
+			}
+			if dryRun {
+				return
+			}
+			fmt.Println("✅ Deployment files generated successfully!")
+		},
+	}
+
+	initCmd.Flags().Bool("force", false, "Overwrite existing deployment files")
+	initCmd.Flags().Bool("dry-run", false, "Print the planned file operations without writing anything")
+
+	cmd.AddCommand(initCmd)
+
+	return cmd
+}
@@ -0,0 +1,64 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+type EnvSyncFunc func(dryRun bool) error
+
+type EnvCheckFunc func(path string, production bool) error
+
+func EnvCmd(syncEnv EnvSyncFunc, checkEnv EnvCheckFunc) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "env",
+		Short: "Sync and validate environment configuration",
+	}
+
+	syncCmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Regenerate .env.example from the installed modules",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+			if err := syncEnv(dryRun); err != nil {
+				fmt.Fprintf(os.Stderr, "Error syncing .env.example: %v\n", err)
+				os.Exit(1)
+			}
+			if dryRun {
+				return
+			}
+			fmt.Println("✅ .env.example is up to date!")
+		},
+	}
+	syncCmd.Flags().Bool("dry-run", false, "Print the planned file operations without writing anything")
+
+	checkCmd := &cobra.Command{
+		Use:   "check",
+		Short: "Validate a .env file against the installed modules",
+		Long: `Checks a .env file against the env schema of the installed modules and
+reports missing required variables, unknown variables, and secrets still set
+to their example default when running in production (ENV=production or
+--production).`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			path, _ := cmd.Flags().GetString("file")
+			production, _ := cmd.Flags().GetBool("production")
+
+			if err := checkEnv(path, production); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+	checkCmd.Flags().String("file", ".env", "Path of the env file to check")
+	checkCmd.Flags().Bool("production", false, "Treat the file as production configuration")
+
+	cmd.AddCommand(syncCmd)
+	cmd.AddCommand(checkCmd)
+
+	return cmd
+}
@@ -7,33 +7,110 @@ import (
 	"github.com/spf13/cobra"
 )
 
-type NewProjectFunc func(projectName string, modules []string, goModule, database string) error
+type NewProjectOptions struct {
+	ProjectName    string
+	Modules        []string
+	GoModule       string
+	Database       string
+	RoutePrefixes  map[string]string
+	OAuthProviders []string
+	EmailSender    string
+	DryRun         bool
+}
 
-func NewCmd(createProject NewProjectFunc) *cobra.Command {
+type NewProjectFunc func(opts NewProjectOptions) error
+
+type ModuleChoice struct {
+	Name         string
+	Description  string
+	RoutePrefix  string
+	Dependencies []string
+}
+
+type ModuleCatalogFunc func() []ModuleChoice
+
+func NewCmd(createProject NewProjectFunc, catalog ModuleCatalogFunc) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "new [project-name]",
 		Short: "Create a new SaaS project with selected modules",
-		Long:  "Creates a complete SaaS project with go.mod, main.go, and selected modules",
-		Args:  cobra.ExactArgs(1),
+		Long: `Creates a complete SaaS project with go.mod, main.go, and selected modules.
+
+Run without flags from a terminal to start an interactive wizard.`,
+		Args: cobra.MaximumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			projectName := args[0]
-			
-			modules, _ := cmd.Flags().GetStringSlice("modules")
-			goModule, _ := cmd.Flags().GetString("go-module")
-			database, _ := cmd.Flags().GetString("database")
-			
-			if err := createProject(projectName, modules, goModule, database); err != nil {
+			var opts NewProjectOptions
+			if len(args) > 0 {
+				opts.ProjectName = args[0]
+			}
+
+			if cmd.Flags().NFlag() == 0 && isTerminal(os.Stdin) {
+				wizardOpts, err := runNewProjectWizard(os.Stdin, os.Stdout, catalog(), opts.ProjectName)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					os.Exit(1)
+				}
+				opts = wizardOpts
+			} else {
+				if opts.ProjectName == "" {
+					fmt.Fprintf(os.Stderr, "Error: project name is required when not running interactively\n")
+					os.Exit(1)
+				}
+
+				opts.Modules, _ = cmd.Flags().GetStringSlice("modules")
+				opts.GoModule, _ = cmd.Flags().GetString("go-module")
+				opts.Database, _ = cmd.Flags().GetString("database")
+				opts.RoutePrefixes, _ = cmd.Flags().GetStringToString("route-prefix")
+				opts.OAuthProviders, _ = cmd.Flags().GetStringSlice("oauth-providers")
+				opts.EmailSender, _ = cmd.Flags().GetString("email-sender")
+				opts.DryRun, _ = cmd.Flags().GetBool("dry-run")
+
+				if err := validateModuleOptions(opts.OAuthProviders, opts.EmailSender); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					os.Exit(1)
+				}
+			}
+
+			if err := createProject(opts); err != nil {
 				fmt.Fprintf(os.Stderr, "Error creating project: %v\n", err)
 				os.Exit(1)
 			}
-			fmt.Printf("✅ Project '%s' created successfully!\n", projectName)
-			fmt.Printf("📂 cd %s && go mod tidy && go run main.go\n", projectName)
+			if opts.DryRun {
+				return
+			}
+			fmt.Printf("✅ Project '%s' created successfully!\n", opts.ProjectName)
+			fmt.Printf("📂 cd %s && go mod tidy && go run main.go\n", opts.ProjectName)
 		},
 	}
 
 	cmd.Flags().StringSlice("modules", []string{}, "Modules to include (auth,subscription,team,etc)")
 	cmd.Flags().String("go-module", "", "Go module path (defaults to project name)")
 	cmd.Flags().String("database", "postgres", "Database type (postgres, mysql, sqlite)")
+	cmd.Flags().StringToString("route-prefix", map[string]string{}, "Route prefix per module (e.g. auth=/api/v1/auth)")
+	cmd.Flags().StringSlice("oauth-providers", []string{}, "OAuth providers to enable for the auth module (google, github, oidc)")
+	cmd.Flags().String("email-sender", "", "Email sender for the email module (smtp, mock)")
+	cmd.Flags().Bool("dry-run", false, "Print the planned file operations without writing anything")
 
 	return cmd
-}
\ No newline at end of file
+}
+
+// validateModuleOptions rejects flag values the wizard would not offer,
+// since they end up in the generated wiring.
+func validateModuleOptions(oauthProviders []string, emailSender string) error {
+	for _, provider := range oauthProviders {
+		if !containsString(wizardOAuthProviders, provider) {
+			return fmt.Errorf("unknown OAuth provider '%s'", provider)
+		}
+	}
+	if emailSender != "" && !containsString(wizardEmailSenders, emailSender) {
+		return fmt.Errorf("unknown email sender '%s'", emailSender)
+	}
+	return nil
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,272 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	wizardDatabases      = []string{"postgres", "mysql", "sqlite"}
+	wizardOAuthProviders = []string{"google", "github", "oidc"}
+	wizardEmailSenders   = []string{"mock", "smtp"}
+)
+
+type wizard struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// runNewProjectWizard asks for everything `sgk new` needs and returns the
+// resulting options. The equivalent non-interactive command is printed
+// before returning so the run can be reproduced in scripts.
+func runNewProjectWizard(in io.Reader, out io.Writer, catalog []ModuleChoice, projectName string) (NewProjectOptions, error) {
+	w := &wizard{in: bufio.NewReader(in), out: out}
+
+	sort.Slice(catalog, func(i, j int) bool {
+		return catalog[i].Name < catalog[j].Name
+	})
+	byName := make(map[string]ModuleChoice, len(catalog))
+	for _, module := range catalog {
+		byName[module.Name] = module
+	}
+
+	fmt.Fprintln(out, "🧙 Create a new SaaS Go Kit project")
+	fmt.Fprintln(out)
+
+	opts := NewProjectOptions{
+		ProjectName:   projectName,
+		RoutePrefixes: make(map[string]string),
+	}
+
+	var err error
+	for opts.ProjectName == "" {
+		if opts.ProjectName, err = w.ask("Project name", ""); err != nil {
+			return opts, err
+		}
+	}
+
+	if opts.GoModule, err = w.ask("Go module path", opts.ProjectName); err != nil {
+		return opts, err
+	}
+
+	if opts.Database, err = w.choose("Database", wizardDatabases, "postgres"); err != nil {
+		return opts, err
+	}
+
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "📦 Available modules:")
+	for i, module := range catalog {
+		fmt.Fprintf(out, "  %d) %-10s %s\n", i+1, module.Name, module.Description)
+	}
+
+	selected, err := w.selectModules(catalog)
+	if err != nil {
+		return opts, err
+	}
+	opts.Modules = resolveModuleDependencies(out, selected, byName)
+
+	if len(opts.Modules) > 0 {
+		fmt.Fprintln(out)
+	}
+	for _, name := range opts.Modules {
+		defaultPrefix := byName[name].RoutePrefix
+		prefix, err := w.ask(fmt.Sprintf("Route prefix for %s", name), defaultPrefix)
+		if err != nil {
+			return opts, err
+		}
+		if prefix != defaultPrefix {
+			opts.RoutePrefixes[name] = prefix
+		}
+	}
+
+	if containsString(opts.Modules, "auth") {
+		answer, err := w.ask(fmt.Sprintf("OAuth providers to enable (%s, comma-separated, blank for none)", strings.Join(wizardOAuthProviders, ", ")), "")
+		if err != nil {
+			return opts, err
+		}
+		for _, provider := range splitList(answer) {
+			if !containsString(wizardOAuthProviders, provider) {
+				return opts, fmt.Errorf("unknown OAuth provider '%s'", provider)
+			}
+			opts.OAuthProviders = append(opts.OAuthProviders, provider)
+		}
+	}
+
+	if containsString(opts.Modules, "email") {
+		if opts.EmailSender, err = w.choose("Email sender", wizardEmailSenders, "mock"); err != nil {
+			return opts, err
+		}
+	}
+
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "🔁 Equivalent command:")
+	fmt.Fprintf(out, "  %s\n", equivalentNewCommand(opts))
+	fmt.Fprintln(out)
+
+	return opts, nil
+}
+
+// equivalentNewCommand renders opts as a non-interactive `sgk new` call.
+func equivalentNewCommand(opts NewProjectOptions) string {
+	parts := []string{"sgk", "new", opts.ProjectName}
+
+	if opts.GoModule != "" && opts.GoModule != opts.ProjectName {
+		parts = append(parts, "--go-module", opts.GoModule)
+	}
+	if opts.Database != "" {
+		parts = append(parts, "--database", opts.Database)
+	}
+	if len(opts.Modules) > 0 {
+		parts = append(parts, "--modules", strings.Join(opts.Modules, ","))
+	}
+
+	prefixModules := make([]string, 0, len(opts.RoutePrefixes))
+	for name := range opts.RoutePrefixes {
+		prefixModules = append(prefixModules, name)
+	}
+	sort.Strings(prefixModules)
+	for _, name := range prefixModules {
+		parts = append(parts, "--route-prefix", fmt.Sprintf("%s=%s", name, opts.RoutePrefixes[name]))
+	}
+
+	if len(opts.OAuthProviders) > 0 {
+		parts = append(parts, "--oauth-providers", strings.Join(opts.OAuthProviders, ","))
+	}
+	if opts.EmailSender != "" {
+		parts = append(parts, "--email-sender", opts.EmailSender)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func (w *wizard) ask(question, defaultValue string) (string, error) {
+	if defaultValue != "" {
+		fmt.Fprintf(w.out, "? %s [%s]: ", question, defaultValue)
+	} else {
+		fmt.Fprintf(w.out, "? %s: ", question)
+	}
+
+	line, err := w.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("wizard aborted: %w", err)
+	}
+
+	answer := strings.TrimSpace(line)
+	if answer == "" {
+		return defaultValue, nil
+	}
+	return answer, nil
+}
+
+func (w *wizard) choose(question string, choices []string, defaultValue string) (string, error) {
+	for {
+		answer, err := w.ask(fmt.Sprintf("%s (%s)", question, strings.Join(choices, "/")), defaultValue)
+		if err != nil {
+			return "", err
+		}
+		if containsString(choices, answer) {
+			return answer, nil
+		}
+		fmt.Fprintf(w.out, "  Please choose one of: %s\n", strings.Join(choices, ", "))
+	}
+}
+
+func (w *wizard) selectModules(catalog []ModuleChoice) ([]string, error) {
+	for {
+		answer, err := w.ask("Modules to include (names or numbers, comma-separated)", "")
+		if err != nil {
+			return nil, err
+		}
+
+		var selected []string
+		valid := true
+		for _, item := range splitList(answer) {
+			name := item
+			if n, err := strconv.Atoi(item); err == nil {
+				if n < 1 || n > len(catalog) {
+					valid = false
+					break
+				}
+				name = catalog[n-1].Name
+			}
+
+			found := false
+			for _, module := range catalog {
+				if module.Name == name {
+					found = true
+					break
+				}
+			}
+			if !found {
+				valid = false
+				break
+			}
+
+			if !containsString(selected, name) {
+				selected = append(selected, name)
+			}
+		}
+
+		if valid {
+			return selected, nil
+		}
+		fmt.Fprintln(w.out, "  Unknown module, please pick from the list above.")
+	}
+}
+
+// resolveModuleDependencies returns selected plus every internal dependency,
+// ordered so that dependencies come before the modules that need them.
+func resolveModuleDependencies(out io.Writer, selected []string, byName map[string]ModuleChoice) []string {
+	var ordered []string
+	visited := make(map[string]bool)
+
+	var visit func(name, requiredBy string)
+	visit = func(name, requiredBy string) {
+		if visited[name] {
+			return
+		}
+		visited[name] = true
+
+		if requiredBy != "" && !containsString(selected, name) {
+			fmt.Fprintf(out, "  ➕ %s requires %s, selecting it too\n", requiredBy, name)
+		}
+
+		for _, dep := range byName[name].Dependencies {
+			if dep == "core" {
+				continue
+			}
+			visit(dep, name)
+		}
+
+		ordered = append(ordered, name)
+	}
+
+	for _, name := range selected {
+		visit(name, "")
+	}
+
+	return ordered
+}
+
+func splitList(value string) []string {
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if trimmed := strings.TrimSpace(item); trimmed != "" {
+			result = append(result, trimmed)
+		}
+	}
+	return result
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
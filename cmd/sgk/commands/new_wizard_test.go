@@ -0,0 +1,147 @@
+package commands
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testCatalog() []ModuleChoice {
+	return []ModuleChoice{
+		{Name: "health", RoutePrefix: "/health"},
+		{Name: "email", RoutePrefix: "/api/v1/email", Dependencies: []string{"core"}},
+		{Name: "auth", RoutePrefix: "/api/v1/auth", Dependencies: []string{"core", "email"}},
+	}
+}
+
+func runWizard(t *testing.T, projectName string, answers ...string) (NewProjectOptions, string) {
+	t.Helper()
+
+	var out bytes.Buffer
+	in := strings.NewReader(strings.Join(answers, "\n") + "\n")
+	opts, err := runNewProjectWizard(in, &out, testCatalog(), projectName)
+	if err != nil {
+		t.Fatalf("runNewProjectWizard: %v\n%s", err, out.String())
+	}
+	return opts, out.String()
+}
+
+func TestWizardDefaults(t *testing.T) {
+	opts, out := runWizard(t, "",
+		"shop", // project name
+		"",     // go module
+		"",     // database
+		"health",
+		"", // health route prefix
+	)
+
+	want := NewProjectOptions{
+		ProjectName:   "shop",
+		GoModule:      "shop",
+		Database:      "postgres",
+		Modules:       []string{"health"},
+		RoutePrefixes: map[string]string{},
+	}
+	if !reflect.DeepEqual(opts, want) {
+		t.Fatalf("opts = %+v, want %+v", opts, want)
+	}
+
+	if !strings.Contains(out, "sgk new shop --database postgres --modules health\n") {
+		t.Fatalf("output does not show the equivalent command:\n%s", out)
+	}
+}
+
+func TestWizardModuleOptions(t *testing.T) {
+	opts, out := runWizard(t, "shop",
+		"github.com/acme/shop",
+		"sqlite",
+		"1", // auth, which pulls in email
+		"",  // email route prefix
+		"/auth",
+		"google, github",
+		"smtp",
+	)
+
+	want := NewProjectOptions{
+		ProjectName:    "shop",
+		GoModule:       "github.com/acme/shop",
+		Database:       "sqlite",
+		Modules:        []string{"email", "auth"},
+		RoutePrefixes:  map[string]string{"auth": "/auth"},
+		OAuthProviders: []string{"google", "github"},
+		EmailSender:    "smtp",
+	}
+	if !reflect.DeepEqual(opts, want) {
+		t.Fatalf("opts = %+v, want %+v", opts, want)
+	}
+
+	if !strings.Contains(out, "auth requires email") {
+		t.Fatalf("output does not mention the added dependency:\n%s", out)
+	}
+	command := "sgk new shop --go-module github.com/acme/shop --database sqlite --modules email,auth " +
+		"--route-prefix auth=/auth --oauth-providers google,github --email-sender smtp\n"
+	if !strings.Contains(out, command) {
+		t.Fatalf("output does not show the equivalent command:\n%s", out)
+	}
+}
+
+func TestWizardEmailSenderDefault(t *testing.T) {
+	opts, _ := runWizard(t, "shop", "", "", "email", "", "")
+
+	if opts.EmailSender != "mock" {
+		t.Fatalf("email sender = %q, want mock", opts.EmailSender)
+	}
+}
+
+func TestWizardRepromptsInvalidAnswers(t *testing.T) {
+	opts, out := runWizard(t, "shop",
+		"",
+		"oracle",
+		"mysql",
+		"9",
+		"billing",
+		"3",
+		"",
+	)
+
+	if opts.Database != "mysql" {
+		t.Fatalf("database = %q, want mysql", opts.Database)
+	}
+	if !reflect.DeepEqual(opts.Modules, []string{"health"}) {
+		t.Fatalf("modules = %v, want [health]", opts.Modules)
+	}
+	if strings.Count(out, "Please choose one of") != 1 {
+		t.Fatalf("database was not asked again once:\n%s", out)
+	}
+	if strings.Count(out, "Unknown module") != 2 {
+		t.Fatalf("modules were not asked again twice:\n%s", out)
+	}
+}
+
+func TestWizardRejectsUnknownOAuthProvider(t *testing.T) {
+	in := strings.NewReader("\n\nauth\n\n\nfacebook\n")
+	_, err := runNewProjectWizard(in, &bytes.Buffer{}, testCatalog(), "shop")
+	if err == nil || !strings.Contains(err.Error(), "facebook") {
+		t.Fatalf("error = %v, want unknown OAuth provider", err)
+	}
+}
+
+func TestWizardAbortsOnEOF(t *testing.T) {
+	in := strings.NewReader("shop\n")
+	if _, err := runNewProjectWizard(in, &bytes.Buffer{}, testCatalog(), ""); err == nil {
+		t.Fatal("wizard finished without all answers")
+	}
+}
+
+func TestValidateModuleOptions(t *testing.T) {
+	if err := validateModuleOptions([]string{"google", "oidc"}, "smtp"); err != nil {
+		t.Fatalf("validateModuleOptions: %v", err)
+	}
+	if err := validateModuleOptions([]string{"facebook"}, ""); err == nil {
+		t.Fatal("unknown OAuth provider was accepted")
+	}
+	if err := validateModuleOptions(nil, "sendgrid"); err == nil {
+		t.Fatal("unknown email sender was accepted")
+	}
+}
@@ -0,0 +1,33 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+type ListRoutesFunc func(jsonOutput bool) error
+
+func RoutesCmd(listRoutes ListRoutesFunc) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "routes",
+		Short: "List every HTTP endpoint registered by the project",
+		Long: `Statically analyses the RegisterRoutes functions of installed modules and
+CRUD resources and lists method, path, handler and middleware, marking which
+endpoints require authentication and which permissions they need.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			jsonOutput, _ := cmd.Flags().GetBool("json")
+
+			if err := listRoutes(jsonOutput); err != nil {
+				fmt.Fprintf(os.Stderr, "Error listing routes: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().Bool("json", false, "Output routes as JSON")
+
+	return cmd
+}
@@ -8,9 +8,10 @@ import (
 
 	"github.com/karurosux/saas-go-kit/cmd/sgk/internal/embed"
 	"github.com/karurosux/saas-go-kit/cmd/sgk/internal/project"
+	"github.com/karurosux/saas-go-kit/cmd/sgk/internal/staging"
 )
 
-func GenerateCRUDModule(moduleName string) error {
+func GenerateCRUDModule(moduleName string, dryRun bool) error {
 	config, err := project.LoadProjectConfig()
 	if err != nil {
 		return fmt.Errorf("failed to load project config: %w", err)
@@ -34,17 +35,21 @@ func GenerateCRUDModule(moduleName string) error {
 			Database: config.Project.Database,
 		},
 		Module: struct {
-			Name string
+			Name        string
+			RoutePrefix string
 		}{
 			Name: moduleName,
 		},
 	}
 
-	if err := copyCRUDTemplate(moduleName, templateData); err != nil {
+	tx := staging.New(".", dryRun)
+	defer tx.Rollback()
+
+	if err := copyCRUDTemplate(tx, moduleName, templateData); err != nil {
 		return fmt.Errorf("failed to copy CRUD template: %w", err)
 	}
 
-	if err := updateMainGoWithCRUDModule(config.Project.GoModule, moduleName); err != nil {
+	if err := updateMainGoWithCRUDModule(tx, config.Project.GoModule, moduleName); err != nil {
 		return fmt.Errorf("failed to update main.go: %w", err)
 	}
 
@@ -53,24 +58,29 @@ func GenerateCRUDModule(moduleName string) error {
 		InstalledAt: time.Now(),
 	}
 
-	if err := project.SaveProjectConfig(config); err != nil {
+	if err := project.StageProjectConfig(tx, config); err != nil {
 		return fmt.Errorf("failed to save project config: %w", err)
 	}
 
-	return nil
+	if dryRun {
+		tx.PrintPlan(os.Stdout)
+		return nil
+	}
+
+	return tx.Commit()
 }
 
-func copyCRUDTemplate(moduleName string, data embed.TemplateData) error {
-	return embed.CopyCRUDModuleFromEmbed(moduleName, embed.CRUDTemplateData{
+func copyCRUDTemplate(tx *staging.Transaction, moduleName string, data embed.TemplateData) error {
+	return embed.CopyCRUDModuleFromEmbed(tx, moduleName, embed.CRUDTemplateData{
 		Project: data.Project,
 		ModuleName: moduleName,
 		ModuleNameCap: strings.Title(moduleName),
 	})
 }
 
-func updateMainGoWithCRUDModule(goModule, moduleName string) error {
+func updateMainGoWithCRUDModule(tx *staging.Transaction, goModule, moduleName string) error {
 	mainPath := "main.go"
-	content, err := os.ReadFile(mainPath)
+	content, err := tx.ReadFile(mainPath)
 	if err != nil {
 		return fmt.Errorf("failed to read main.go: %w", err)
 	}
@@ -100,15 +110,26 @@ func updateMainGoWithCRUDModule(goModule, moduleName string) error {
 		}
 	}
 	
-	if lastModuleRegistrationLine > 0 {
-		registrationLine := fmt.Sprintf("\tif err := %s.RegisterModule(container); err != nil {", moduleName)
-		logLine := fmt.Sprintf("\t\tlog.Fatalf(core.ErrMsgModuleRegistration, \"%s\", err)", moduleName)
-		closeLine := "\t}"
-		
-		newLines := []string{registrationLine, logLine, closeLine}
-		lines = append(lines[:lastModuleRegistrationLine+1], append(newLines, lines[lastModuleRegistrationLine+1:]...)...)
+	if lastModuleRegistrationLine < 0 {
+		for i, line := range lines {
+			if strings.Contains(line, "// Register modules") {
+				lastModuleRegistrationLine = i
+				break
+			}
+		}
+	}
+	
+	if lastModuleRegistrationLine < 0 {
+		return fmt.Errorf("could not find '// Register modules' marker in main.go")
 	}
+	
+	registrationLine := fmt.Sprintf("\tif err := %s.RegisterModule(container); err != nil {", moduleName)
+	logLine := fmt.Sprintf("\t\tlog.Fatalf(core.ErrMsgModuleRegistration, \"%s\", err)", moduleName)
+	closeLine := "\t}"
+	
+	newLines := []string{registrationLine, logLine, closeLine}
+	lines = append(lines[:lastModuleRegistrationLine+1], append(newLines, lines[lastModuleRegistrationLine+1:]...)...)
 
 	mainContent = strings.Join(lines, "\n")
-	return os.WriteFile(mainPath, []byte(mainContent), 0644)
+	return tx.WriteFile(mainPath, []byte(mainContent), 0644)
 }
\ No newline at end of file
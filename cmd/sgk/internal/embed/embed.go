@@ -1,13 +1,15 @@
 package embed
 
 import (
+	"bytes"
 	"embed"
 	"fmt"
 	"io/fs"
-	"os"
 	"path/filepath"
 	"strings"
 	"text/template"
+
+	"github.com/karurosux/saas-go-kit/cmd/sgk/internal/staging"
 )
 
 //go:embed templates
@@ -20,8 +22,12 @@ type TemplateData struct {
 		Database string
 	}
 	Module struct {
-		Name string
+		Name        string
+		RoutePrefix string
 	}
+	// Options are the generation options recorded for the module in
+	// sgk.json, such as the OAuth providers chosen for auth.
+	Options map[string]string
 }
 
 type CRUDTemplateData struct {
@@ -34,11 +40,8 @@ type CRUDTemplateData struct {
 	ModuleNameCap string
 }
 
-func CopyModuleFromEmbed(moduleName string, data TemplateData) error {
+func CopyModuleFromEmbed(tx *staging.Transaction, moduleName string, data TemplateData) error {
 	moduleDir := filepath.Join("internal", moduleName)
-	if err := os.MkdirAll(moduleDir, 0755); err != nil {
-		return err
-	}
 
 	templatePath := fmt.Sprintf("templates/%s", moduleName)
 
@@ -54,10 +57,6 @@ func CopyModuleFromEmbed(moduleName string, data TemplateData) error {
 		relPath := strings.TrimPrefix(path, templatePath+"/")
 		destPath := filepath.Join(moduleDir, relPath)
 
-		if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
-			return err
-		}
-
 		content, err := templatesFS.ReadFile(path)
 		if err != nil {
 			return err
@@ -71,15 +70,12 @@ func CopyModuleFromEmbed(moduleName string, data TemplateData) error {
 				return fmt.Errorf("failed to parse template %s: %w", path, err)
 			}
 
-			destFile, err := os.Create(destPath)
-			if err != nil {
-				return err
-			}
-			defer destFile.Close()
-
-			if err := tmpl.Execute(destFile, data); err != nil {
+			var rendered bytes.Buffer
+			if err := tmpl.Execute(&rendered, data); err != nil {
 				return fmt.Errorf("failed to execute template %s: %w", path, err)
 			}
+
+			return tx.WriteFile(destPath, rendered.Bytes(), 0644)
 		} else {
 			if strings.HasSuffix(path, ".go") {
 				contentStr := string(content)
@@ -87,11 +83,12 @@ func CopyModuleFromEmbed(moduleName string, data TemplateData) error {
 				contentStr = strings.ReplaceAll(contentStr, "{{.Project.Name}}", data.Project.Name)
 				contentStr = strings.ReplaceAll(contentStr, "{{.Project.Database}}", data.Project.Database)
 				contentStr = strings.ReplaceAll(contentStr, "{{.Module.Name}}", data.Module.Name)
+				contentStr = strings.ReplaceAll(contentStr, "{{.Module.RoutePrefix}}", data.Module.RoutePrefix)
 				content = []byte(contentStr)
 				content = fixImportPaths(content, data.Project.GoModule)
 			}
 
-			if err := os.WriteFile(destPath, content, 0644); err != nil {
+			if err := tx.WriteFile(destPath, content, 0644); err != nil {
 				return err
 			}
 		}
@@ -102,11 +99,8 @@ func CopyModuleFromEmbed(moduleName string, data TemplateData) error {
 	return err
 }
 
-func CopyCRUDModuleFromEmbed(moduleName string, data CRUDTemplateData) error {
+func CopyCRUDModuleFromEmbed(tx *staging.Transaction, moduleName string, data CRUDTemplateData) error {
 	moduleDir := filepath.Join("internal", moduleName)
-	if err := os.MkdirAll(moduleDir, 0755); err != nil {
-		return err
-	}
 
 	templatePath := "templates/crud"
 
@@ -139,10 +133,6 @@ func CopyCRUDModuleFromEmbed(moduleName string, data CRUDTemplateData) error {
 
 		destPath := filepath.Join(moduleDir, relPath)
 
-		if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
-			return err
-		}
-
 		content, err := templatesFS.ReadFile(path)
 		if err != nil {
 			return err
@@ -158,11 +148,7 @@ func CopyCRUDModuleFromEmbed(moduleName string, data CRUDTemplateData) error {
 			content = []byte(contentStr)
 		}
 
-		if err := os.WriteFile(destPath, content, 0644); err != nil {
-			return err
-		}
-
-		return nil
+		return tx.WriteFile(destPath, content, 0644)
 	})
 
 	return err
@@ -205,11 +191,8 @@ func fixImportPaths(content []byte, goModule string) []byte {
 	return []byte(strings.Join(result, "\n"))
 }
 
-func CopyCoreFromEmbed() error {
+func CopyCoreFromEmbed(tx *staging.Transaction) error {
 	destDir := filepath.Join("internal", "core")
-	if err := os.MkdirAll(destDir, 0755); err != nil {
-		return err
-	}
 
 	corePath := "templates/core"
 	entries, err := templatesFS.ReadDir(corePath)
@@ -228,7 +211,7 @@ func CopyCoreFromEmbed() error {
 		}
 
 		destPath := filepath.Join(destDir, entry.Name())
-		if err := os.WriteFile(destPath, content, 0644); err != nil {
+		if err := tx.WriteFile(destPath, content, 0644); err != nil {
 			return err
 		}
 	}
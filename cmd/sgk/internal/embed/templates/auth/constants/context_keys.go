@@ -8,5 +8,9 @@ const (
 	ContextKeySession = "session"
 
 	ContextKeyIsAuthenticated = "is_authenticated"
+
+	ContextKeyImpersonatorID = "impersonator_id"
+
+	ContextKeyAPIKey = "api_key"
 )
 
@@ -0,0 +1,13 @@
+package authconstants
+
+// Cookie mode keeps the session in HttpOnly cookies. The CSRF cookie is
+// readable by scripts, which echo it in HeaderCSRFToken on unsafe requests.
+const (
+	CookieAccessToken = "access_token"
+
+	CookieRefreshToken = "refresh_token"
+
+	CookieCSRFToken = "csrf_token"
+
+	HeaderCSRFToken = "X-CSRF-Token"
+)
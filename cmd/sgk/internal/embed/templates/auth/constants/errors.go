@@ -10,10 +10,51 @@ const (
 	ErrTokenExpired            = "token expired"
 	ErrTokenAlreadyUsed        = "token already used"
 	ErrSessionExpired          = "session expired"
+	ErrSessionNotFound         = "session not found"
 	ErrInvalidPassword         = "invalid password"
 	ErrPasswordTooWeak         = "password too weak"
 	ErrInvalidEmail            = "invalid email format"
 	ErrInvalidPhone            = "invalid phone format"
 	ErrVerificationRequired    = "verification required"
 	ErrUnauthorized            = "unauthorized"
+	ErrInvalidMFACode          = "invalid mfa code"
+	ErrMFAAlreadyEnabled       = "mfa already enabled"
+	ErrMFANotEnabled           = "mfa not enabled"
+	ErrMFASetupNotStarted      = "mfa setup not started"
+	ErrMFARequiredForRole      = "mfa is required for your role"
+	ErrInvalidOAuthState       = "invalid or expired oauth state"
+	ErrIdentityNotFound        = "identity not found"
+	ErrIdentityAlreadyLinked   = "identity is linked to another account"
+	ErrOAuthAccountExists      = "an account with this email already exists, sign in and link the provider"
+	ErrLastSignInMethod        = "cannot remove the only sign-in method"
+	ErrDeviceMismatch          = "link must be opened on the device that requested it"
+	ErrInvalidPasskey          = "invalid passkey response"
+	ErrPasskeyChallenge        = "invalid or expired passkey challenge"
+	ErrPasskeyNotFound         = "passkey not found"
+	ErrPasskeyExists           = "passkey already registered"
+	ErrPasskeyCloned           = "passkey sign counter did not increase, the authenticator may be cloned"
+	ErrAccountLocked           = "account temporarily locked after too many failed logins"
+	ErrTooManyLoginAttempts    = "too many login attempts, try again later"
+	ErrPhoneRequired           = "no phone number associated with account"
+	ErrPhoneCannotBeRemoved    = "a verified phone number is required"
+	ErrPhoneInUse              = "phone already in use"
+	ErrPhoneAlreadyVerified    = "phone already verified"
+	ErrCodeRecentlySent        = "a verification code was sent recently, try again later"
+	ErrTooManyCodeAttempts     = "too many incorrect codes, request a new one"
+	ErrTooManyMFAAttempts      = "too many incorrect codes, sign in again"
+	ErrEmailInUse              = "email already in use"
+	ErrEmailUnchanged          = "new email matches the current one"
+	ErrImpersonationForbidden  = "not allowed while impersonating a user"
+	ErrCannotImpersonate       = "this account cannot be impersonated"
+	ErrNotImpersonating        = "session is not an impersonation session"
+	ErrPermissionDenied        = "insufficient permissions"
+	ErrAPIKeyNotFound          = "api key not found"
+	ErrInvalidAPIKey           = "invalid api key"
+	ErrAPIKeyExpired           = "api key expired"
+	ErrAPIKeyIPNotAllowed      = "api key not allowed from this address"
+	ErrAPIKeyForbidden         = "not allowed with an api key"
+	ErrTooManyAPIKeys          = "api key limit reached"
+	ErrInvalidAPIKeyScope      = "api key scope exceeds your permissions"
+	ErrInvalidIPAllowlist      = "invalid ip address or cidr in allowlist"
+	ErrInvalidCSRFToken        = "missing or invalid csrf token"
 )
\ No newline at end of file
@@ -0,0 +1,6 @@
+package authconstants
+
+// PermissionImpersonate allows starting impersonation sessions. Accounts
+// holding it cannot be impersonated themselves, so staff cannot borrow each
+// other's privileges.
+const PermissionImpersonate = "users:impersonate"
@@ -1,29 +1,56 @@
 package authcontroller
 
 import (
+	"crypto/subtle"
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 	
+	authconstants "{{.Project.GoModule}}/internal/auth/constants"
 	authinterface "{{.Project.GoModule}}/internal/auth/interface"
 	authmiddleware "{{.Project.GoModule}}/internal/auth/middleware"
 	authmodel "{{.Project.GoModule}}/internal/auth/model"
 	"{{.Project.GoModule}}/internal/core"
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
 
 type AuthController struct {
-	service authinterface.AuthService
+	service           authinterface.AuthService
+	cookies           authinterface.CookieSettings
+	refreshCookiePath string
 }
 
-func NewAuthController(service authinterface.AuthService) *AuthController {
+func NewAuthController(service authinterface.AuthService, cookies authinterface.CookieSettings) *AuthController {
 	return &AuthController{
-		service: service,
+		service:           service,
+		cookies:           cookies,
+		refreshCookiePath: "/",
 	}
 }
 
+// passwordPolicyErrorResponse adds the individual policy violations to the
+// regular error body so clients can show them next to the password field.
+type passwordPolicyErrorResponse struct {
+	core.ErrorResponse
+	Violations []authinterface.PasswordViolation `json:"violations"`
+}
+
 func (ac *AuthController) handleError(c echo.Context, err error) error {
+	var policyErr *authinterface.PasswordPolicyError
+	if errors.As(err, &policyErr) {
+		return c.JSON(http.StatusBadRequest, passwordPolicyErrorResponse{
+			ErrorResponse: core.ErrorResponse{
+				Success: false,
+				Error:   policyErr.Error(),
+				Code:    core.ErrCodeValidation,
+			},
+			Violations: policyErr.Violations,
+		})
+	}
+	
 	var appErr *core.AppError
 	if errors.As(err, &appErr) {
 		switch appErr.Code {
@@ -37,6 +64,12 @@ func (ac *AuthController) handleError(c echo.Context, err error) error {
 			return core.BadRequest(c, err)
 		case core.ErrCodeConflict:
 			return core.Error(c, http.StatusConflict, err)
+		case core.ErrCodeAccountLocked:
+			setRetryAfter(c, err)
+			return core.Error(c, http.StatusLocked, err, appErr.Code)
+		case core.ErrCodeTooManyRequests:
+			setRetryAfter(c, err)
+			return core.Error(c, http.StatusTooManyRequests, err, appErr.Code)
 		default:
 			return core.InternalServerError(c, err)
 		}
@@ -44,30 +77,115 @@ func (ac *AuthController) handleError(c echo.Context, err error) error {
 	return core.InternalServerError(c, err)
 }
 
+func setRetryAfter(c echo.Context, err error) {
+	var blocked *authinterface.LoginBlockedError
+	if errors.As(err, &blocked) {
+		seconds := int((blocked.RetryAfter + time.Second - 1) / time.Second)
+		c.Response().Header().Set("Retry-After", strconv.Itoa(seconds))
+	}
+}
+
 func (ac *AuthController) RegisterRoutes(e *echo.Echo, basePath string, authMiddleware *authmiddleware.AuthMiddleware) {
+	e.GET("/.well-known/jwks.json", ac.JWKS)
+	
 	group := e.Group(basePath)
+	group.Use(authMiddleware.CaptureClientInfo())
+	
+	// The refresh cookie is only sent to the refresh endpoint
+	ac.refreshCookiePath = basePath + "/refresh"
 	
 	group.POST("/register", ac.Register)
 	group.POST("/login", ac.Login)
-	group.POST("/refresh", ac.RefreshToken)
+	group.POST("/refresh", ac.RefreshToken, authMiddleware.RequireCSRF())
 	group.POST("/forgot-password", ac.ForgotPassword)
 	group.POST("/reset-password", ac.ResetPassword)
 	group.POST("/verify-email", ac.VerifyEmail)
+	group.POST("/magic-link", ac.RequestMagicLink)
+	group.POST("/magic-link/verify", ac.VerifyMagicLink)
+	group.POST("/unlock", ac.UnlockAccount)
+	group.POST("/email/confirm", ac.ConfirmEmailChange)
+	group.POST("/email/revert", ac.RevertEmailChange)
+	group.POST("/restore-account", ac.RestoreAccount)
+	group.POST("/passkeys/login/begin", ac.BeginPasskeyLogin)
+	group.POST("/passkeys/login/finish", ac.FinishPasskeyLogin)
 	
 	// OAuth routes
 	group.GET("/providers", ac.GetProviders)
 	group.GET("/oauth/:provider", ac.OAuthLogin)
 	group.GET("/oauth/:provider/callback", ac.OAuthCallback)
 	
+	// MFA routes accept either an authenticated user or a login challenge token
+	group.POST("/mfa/setup", ac.SetupMFA, authMiddleware.OptionalAuth(), authMiddleware.BlockImpersonation(), authMiddleware.BlockAPIKeys())
+	group.POST("/mfa/verify", ac.VerifyMFA, authMiddleware.OptionalAuth(), authMiddleware.BlockImpersonation(), authMiddleware.BlockAPIKeys())
+	
+	// Phone verification accepts either an authenticated user or a login
+	// challenge token
+	group.POST("/verify-phone", ac.VerifyPhone, authMiddleware.OptionalAuth())
+	group.POST("/verify-phone/phone", ac.SetChallengePhone)
+	
 	protected := group.Group("")
 	protected.Use(authMiddleware.RequireAuth())
 	
 	protected.POST("/logout", ac.Logout)
+	protected.POST("/logout-all", ac.LogoutAll, authMiddleware.BlockImpersonation(), authMiddleware.BlockAPIKeys())
+	protected.GET("/sessions", ac.ListSessions)
+	protected.DELETE("/sessions/:id", ac.RevokeSession, authMiddleware.BlockAPIKeys())
 	protected.GET("/me", ac.GetCurrentUser)
-	protected.PUT("/me", ac.UpdateProfile)
-	protected.POST("/change-password", ac.ChangePassword)
+	protected.PUT("/me", ac.UpdateProfile, authMiddleware.BlockImpersonation())
+	protected.DELETE("/me", ac.DeleteAccount, authMiddleware.BlockImpersonation(), authMiddleware.BlockAPIKeys())
+	protected.GET("/me/activity", ac.ListLoginHistory)
+	protected.GET("/me/export", ac.ExportAccountData, authMiddleware.BlockImpersonation(), authMiddleware.BlockAPIKeys())
+	protected.POST("/change-password", ac.ChangePassword, authMiddleware.BlockImpersonation(), authMiddleware.BlockAPIKeys())
+	protected.POST("/email/change", ac.RequestEmailChange, authMiddleware.BlockImpersonation(), authMiddleware.BlockAPIKeys())
 	protected.POST("/resend-verification", ac.ResendVerification)
-	protected.POST("/verify-phone", ac.VerifyPhone)
+	protected.POST("/mfa/disable", ac.DisableMFA, authMiddleware.BlockImpersonation(), authMiddleware.BlockAPIKeys())
+	protected.GET("/identities", ac.ListIdentities)
+	protected.POST("/identities/:provider/link", ac.LinkIdentity, authMiddleware.BlockImpersonation(), authMiddleware.BlockAPIKeys())
+	protected.DELETE("/identities/:id", ac.UnlinkIdentity, authMiddleware.BlockImpersonation(), authMiddleware.BlockAPIKeys())
+	protected.POST("/passkeys/register/begin", ac.BeginPasskeyRegistration, authMiddleware.BlockImpersonation(), authMiddleware.BlockAPIKeys())
+	protected.POST("/passkeys/register/finish", ac.FinishPasskeyRegistration, authMiddleware.BlockImpersonation(), authMiddleware.BlockAPIKeys())
+	protected.GET("/passkeys", ac.ListPasskeys)
+	protected.DELETE("/passkeys/:id", ac.DeletePasskey, authMiddleware.BlockImpersonation(), authMiddleware.BlockAPIKeys())
+	
+	// Impersonation lets support staff act as a customer. The session it
+	// returns is scoped to the target user and cannot change their security
+	// settings.
+	protected.POST("/impersonate/stop", ac.StopImpersonation)
+	protected.POST("/impersonate/:id", ac.StartImpersonation, authMiddleware.RequirePermission("users:impersonate"), authMiddleware.BlockAPIKeys())
+	
+	// API keys authenticate scripts and services with the owner's identity,
+	// optionally limited to a set of scopes. Keys cannot manage keys.
+	protected.GET("/api-keys", ac.ListAPIKeys)
+	protected.POST("/api-keys", ac.CreateAPIKey, authMiddleware.BlockImpersonation(), authMiddleware.BlockAPIKeys())
+	protected.DELETE("/api-keys/:id", ac.RevokeAPIKey, authMiddleware.BlockImpersonation(), authMiddleware.BlockAPIKeys())
+}
+
+func (ac *AuthController) respondLogin(c echo.Context, result *authinterface.LoginResult) error {
+	if result.LinkedIdentity != nil {
+		return core.Success(c, toIdentityResponse(result.LinkedIdentity))
+	}
+	
+	if result.Challenge != nil {
+		return core.Success(c, result.Challenge)
+	}
+	
+	if result.PhoneChallenge != nil {
+		return core.Success(c, result.PhoneChallenge)
+	}
+	
+	session, err := ac.sessionResponse(c, result.Session)
+	if err != nil {
+		return core.InternalServerError(c, fmt.Errorf("Failed to create session"))
+	}
+	
+	if len(result.RecoveryCodes) > 0 {
+		return core.Success(c, map[string]interface{}{
+			"session":        session,
+			"recovery_codes": result.RecoveryCodes,
+		})
+	}
+	
+	return core.Success(c, session)
 }
 
 func (ac *AuthController) Register(c echo.Context) error {
@@ -98,16 +216,18 @@ func (ac *AuthController) Login(c echo.Context) error {
 		return core.BadRequest(c, err)
 	}
 	
-	session, err := ac.service.Login(c.Request().Context(), &req)
+	result, err := ac.service.Login(c.Request().Context(), &req)
 	if err != nil {
 		return ac.handleError(c, err)
 	}
 	
-	return core.Success(c, session)
+	return ac.respondLogin(c, result)
 }
 
+// RefreshTokenRequest carries the refresh token, which may be omitted in
+// cookie mode where it is read from the refresh cookie.
 type RefreshTokenRequest struct {
-	RefreshToken string `json:"refresh_token" validate:"required"`
+	RefreshToken string `json:"refresh_token"`
 }
 
 func (ac *AuthController) RefreshToken(c echo.Context) error {
@@ -116,44 +236,141 @@ func (ac *AuthController) RefreshToken(c echo.Context) error {
 		return core.BadRequest(c, fmt.Errorf("Invalid request body"))
 	}
 	
-	if err := c.Validate(req); err != nil {
-		return core.BadRequest(c, err)
+	refreshToken := req.RefreshToken
+	if refreshToken == "" && ac.cookies.Enabled {
+		if cookie, err := c.Cookie(authconstants.CookieRefreshToken); err == nil {
+			refreshToken = cookie.Value
+		}
+	}
+	if refreshToken == "" {
+		return core.BadRequest(c, fmt.Errorf("Refresh token is required"))
 	}
 	
-	session, err := ac.service.RefreshSession(c.Request().Context(), req.RefreshToken)
+	session, err := ac.service.RefreshSession(c.Request().Context(), refreshToken)
 	if err != nil {
+		ac.clearSessionCookies(c)
 		return ac.handleError(c, err)
 	}
 	
-	return core.Success(c, session)
+	response, err := ac.sessionResponse(c, session)
+	if err != nil {
+		return core.InternalServerError(c, fmt.Errorf("Failed to refresh session"))
+	}
+	
+	return core.Success(c, response)
 }
 
 func (ac *AuthController) Logout(c echo.Context) error {
-	userID, err := authmiddleware.GetUserIDFromContext(c)
-	if err != nil {
+	session := authmiddleware.GetSessionFromContext(c)
+	if session == nil {
 		return core.Unauthorized(c, fmt.Errorf("Not authenticated"))
 	}
 	
-	if err := ac.service.Logout(c.Request().Context(), userID); err != nil {
+	if err := ac.service.Logout(c.Request().Context(), session.GetToken()); err != nil {
 		return core.InternalServerError(c, fmt.Errorf("Failed to logout"))
 	}
 	
+	ac.clearSessionCookies(c)
+	
 	return core.Success(c, map[string]string{
 		"message": "Logged out successfully",
 	})
 }
 
+func (ac *AuthController) LogoutAll(c echo.Context) error {
+	userID, err := authmiddleware.GetUserIDFromContext(c)
+	if err != nil {
+		return core.Unauthorized(c, fmt.Errorf("Not authenticated"))
+	}
+	
+	if err := ac.service.LogoutAll(c.Request().Context(), userID); err != nil {
+		return ac.handleError(c, err)
+	}
+	
+	ac.clearSessionCookies(c)
+	
+	return core.Success(c, map[string]string{
+		"message": "Logged out from all devices",
+	})
+}
+
+type SessionResponse struct {
+	ID         uuid.UUID `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	Current    bool      `json:"current"`
+}
+
+func (ac *AuthController) ListSessions(c echo.Context) error {
+	userID, err := authmiddleware.GetUserIDFromContext(c)
+	if err != nil {
+		return core.Unauthorized(c, fmt.Errorf("Not authenticated"))
+	}
+	
+	sessions, err := ac.service.ListSessions(c.Request().Context(), userID)
+	if err != nil {
+		return ac.handleError(c, err)
+	}
+	
+	var currentID uuid.UUID
+	if current := authmiddleware.GetSessionFromContext(c); current != nil {
+		currentID = current.GetID()
+	}
+	
+	response := make([]SessionResponse, len(sessions))
+	for i, session := range sessions {
+		response[i] = SessionResponse{
+			ID:         session.GetID(),
+			UserAgent:  session.GetUserAgent(),
+			IPAddress:  session.GetIPAddress(),
+			CreatedAt:  session.GetCreatedAt(),
+			LastSeenAt: session.GetLastSeenAt(),
+			Current:    session.GetID() == currentID,
+		}
+	}
+	
+	return core.Success(c, response)
+}
+
+func (ac *AuthController) RevokeSession(c echo.Context) error {
+	userID, err := authmiddleware.GetUserIDFromContext(c)
+	if err != nil {
+		return core.Unauthorized(c, fmt.Errorf("Not authenticated"))
+	}
+	
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return core.BadRequest(c, fmt.Errorf("Invalid session ID"))
+	}
+	
+	if err := ac.service.RevokeSession(c.Request().Context(), userID, sessionID); err != nil {
+		return ac.handleError(c, err)
+	}
+	
+	return core.Success(c, map[string]string{
+		"message": "Session revoked",
+	})
+}
+
 func (ac *AuthController) GetCurrentUser(c echo.Context) error {
-	account := authmiddleware.GetAccountFromContext(c)
-	if account == nil {
+	userID, err := authmiddleware.GetUserIDFromContext(c)
+	if err != nil {
 		return core.Unauthorized(c, fmt.Errorf("Not authenticated"))
 	}
 	
+	// Load the account rather than using the context one, which only holds
+	// token claims when stateless validation is enabled.
+	account, err := ac.service.GetAccount(c.Request().Context(), userID)
+	if err != nil {
+		return ac.handleError(c, err)
+	}
+	
 	return core.Success(c, account)
 }
 
 type UpdateProfileRequest struct {
-	Email *string `json:"email,omitempty" validate:"omitempty,email"`
 	Phone *string `json:"phone,omitempty" validate:"omitempty,e164"`
 }
 
@@ -173,7 +390,6 @@ func (ac *AuthController) UpdateProfile(c echo.Context) error {
 	}
 	
 	updates := authinterface.AccountUpdates{
-		Email: req.Email,
 		Phone: req.Phone,
 	}
 	
@@ -185,18 +401,89 @@ func (ac *AuthController) UpdateProfile(c echo.Context) error {
 	return core.Success(c, account)
 }
 
-type ChangePasswordRequest struct {
-	OldPassword string `json:"old_password" validate:"required"`
-	NewPassword string `json:"new_password" validate:"required,min=8"`
+// DeleteAccountRequest carries the password confirming the deletion. It may
+// be empty for accounts that have no password.
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
 }
 
-func (ac *AuthController) ChangePassword(c echo.Context) error {
+func (ac *AuthController) DeleteAccount(c echo.Context) error {
 	userID, err := authmiddleware.GetUserIDFromContext(c)
 	if err != nil {
 		return core.Unauthorized(c, fmt.Errorf("Not authenticated"))
 	}
 	
-	var req ChangePasswordRequest
+	var req DeleteAccountRequest
+	if err := c.Bind(&req); err != nil {
+		return core.BadRequest(c, fmt.Errorf("Invalid request body"))
+	}
+	
+	purgeAt, err := ac.service.DeleteAccount(c.Request().Context(), userID, req.Password)
+	if err != nil {
+		return ac.handleError(c, err)
+	}
+	
+	ac.clearSessionCookies(c)
+	
+	return core.Success(c, map[string]interface{}{
+		"message":  "Account scheduled for deletion, all sessions were signed out",
+		"purge_at": purgeAt,
+	})
+}
+
+// ListLoginHistory returns the current user's recent sign-in attempts,
+// newest first. The optional limit query parameter caps the number returned.
+func (ac *AuthController) ListLoginHistory(c echo.Context) error {
+	userID, err := authmiddleware.GetUserIDFromContext(c)
+	if err != nil {
+		return core.Unauthorized(c, fmt.Errorf("Not authenticated"))
+	}
+	
+	limit := 0
+	if value := c.QueryParam("limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil {
+			return core.BadRequest(c, fmt.Errorf("Invalid limit"))
+		}
+	}
+	
+	entries, err := ac.service.ListLoginHistory(c.Request().Context(), userID, limit)
+	if err != nil {
+		return ac.handleError(c, err)
+	}
+	
+	return core.Success(c, entries)
+}
+
+// ExportAccountData downloads everything stored about the current user as
+// a JSON file.
+func (ac *AuthController) ExportAccountData(c echo.Context) error {
+	userID, err := authmiddleware.GetUserIDFromContext(c)
+	if err != nil {
+		return core.Unauthorized(c, fmt.Errorf("Not authenticated"))
+	}
+	
+	export, err := ac.service.ExportAccountData(c.Request().Context(), userID)
+	if err != nil {
+		return ac.handleError(c, err)
+	}
+	
+	filename := fmt.Sprintf("account-data-%s.json", export.ExportedAt.Format("20060102-150405"))
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	return c.JSONPretty(http.StatusOK, export, "  ")
+}
+
+type RequestEmailChangeRequest struct {
+	Password string `json:"password" validate:"required"`
+	NewEmail string `json:"new_email" validate:"required,email"`
+}
+
+func (ac *AuthController) RequestEmailChange(c echo.Context) error {
+	userID, err := authmiddleware.GetUserIDFromContext(c)
+	if err != nil {
+		return core.Unauthorized(c, fmt.Errorf("Not authenticated"))
+	}
+	
+	var req RequestEmailChangeRequest
 	if err := c.Bind(&req); err != nil {
 		return core.BadRequest(c, fmt.Errorf("Invalid request body"))
 	}
@@ -205,21 +492,21 @@ func (ac *AuthController) ChangePassword(c echo.Context) error {
 		return core.BadRequest(c, err)
 	}
 	
-	if err := ac.service.ChangePassword(c.Request().Context(), userID, req.OldPassword, req.NewPassword); err != nil {
+	if err := ac.service.RequestEmailChange(c.Request().Context(), userID, req.Password, req.NewEmail); err != nil {
 		return ac.handleError(c, err)
 	}
 	
 	return core.Success(c, map[string]string{
-		"message": "Password changed successfully",
+		"message": "Confirmation sent to the new email address",
 	})
 }
 
-type ForgotPasswordRequest struct {
-	Email string `json:"email" validate:"required,email"`
+type EmailChangeTokenRequest struct {
+	Token string `json:"token" validate:"required"`
 }
 
-func (ac *AuthController) ForgotPassword(c echo.Context) error {
-	var req ForgotPasswordRequest
+func (ac *AuthController) ConfirmEmailChange(c echo.Context) error {
+	var req EmailChangeTokenRequest
 	if err := c.Bind(&req); err != nil {
 		return core.BadRequest(c, fmt.Errorf("Invalid request body"))
 	}
@@ -228,21 +515,17 @@ func (ac *AuthController) ForgotPassword(c echo.Context) error {
 		return core.BadRequest(c, err)
 	}
 	
-	if err := ac.service.SendPasswordReset(c.Request().Context(), req.Email); err != nil {
+	if err := ac.service.ConfirmEmailChange(c.Request().Context(), req.Token); err != nil {
+		return ac.handleError(c, err)
 	}
 	
 	return core.Success(c, map[string]string{
-		"message": "If the email exists, a password reset link has been sent",
+		"message": "Email changed successfully",
 	})
 }
 
-type ResetPasswordRequest struct {
-	Token       string `json:"token" validate:"required"`
-	NewPassword string `json:"new_password" validate:"required,min=8"`
-}
-
-func (ac *AuthController) ResetPassword(c echo.Context) error {
-	var req ResetPasswordRequest
+func (ac *AuthController) RevertEmailChange(c echo.Context) error {
+	var req EmailChangeTokenRequest
 	if err := c.Bind(&req); err != nil {
 		return core.BadRequest(c, fmt.Errorf("Invalid request body"))
 	}
@@ -251,21 +534,21 @@ func (ac *AuthController) ResetPassword(c echo.Context) error {
 		return core.BadRequest(c, err)
 	}
 	
-	if err := ac.service.ResetPassword(c.Request().Context(), req.Token, req.NewPassword); err != nil {
+	if err := ac.service.RevertEmailChange(c.Request().Context(), req.Token); err != nil {
 		return ac.handleError(c, err)
 	}
 	
 	return core.Success(c, map[string]string{
-		"message": "Password reset successfully",
+		"message": "Email change reverted, all sessions were signed out",
 	})
 }
 
-type VerifyEmailRequest struct {
+type RestoreAccountRequest struct {
 	Token string `json:"token" validate:"required"`
 }
 
-func (ac *AuthController) VerifyEmail(c echo.Context) error {
-	var req VerifyEmailRequest
+func (ac *AuthController) RestoreAccount(c echo.Context) error {
+	var req RestoreAccountRequest
 	if err := c.Bind(&req); err != nil {
 		return core.BadRequest(c, fmt.Errorf("Invalid request body"))
 	}
@@ -274,26 +557,27 @@ func (ac *AuthController) VerifyEmail(c echo.Context) error {
 		return core.BadRequest(c, err)
 	}
 	
-	if err := ac.service.VerifyEmail(c.Request().Context(), req.Token); err != nil {
+	if err := ac.service.RestoreAccount(c.Request().Context(), req.Token); err != nil {
 		return ac.handleError(c, err)
 	}
 	
 	return core.Success(c, map[string]string{
-		"message": "Email verified successfully",
+		"message": "Account restored, you can sign in again",
 	})
 }
 
-type ResendVerificationRequest struct {
-	Type string `json:"type" validate:"required,oneof=email phone"`
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required"`
 }
 
-func (ac *AuthController) ResendVerification(c echo.Context) error {
+func (ac *AuthController) ChangePassword(c echo.Context) error {
 	userID, err := authmiddleware.GetUserIDFromContext(c)
 	if err != nil {
 		return core.Unauthorized(c, fmt.Errorf("Not authenticated"))
 	}
 	
-	var req ResendVerificationRequest
+	var req ChangePasswordRequest
 	if err := c.Bind(&req); err != nil {
 		return core.BadRequest(c, fmt.Errorf("Invalid request body"))
 	}
@@ -302,33 +586,21 @@ func (ac *AuthController) ResendVerification(c echo.Context) error {
 		return core.BadRequest(c, err)
 	}
 	
-	switch req.Type {
-	case "email":
-		if err := ac.service.SendEmailVerification(c.Request().Context(), userID); err != nil {
-			return ac.handleError(c, err)
-		}
-	case "phone":
-		if err := ac.service.SendPhoneVerification(c.Request().Context(), userID); err != nil {
-			return ac.handleError(c, err)
-		}
+	if err := ac.service.ChangePassword(c.Request().Context(), userID, req.OldPassword, req.NewPassword); err != nil {
+		return ac.handleError(c, err)
 	}
 	
 	return core.Success(c, map[string]string{
-		"message": "Verification sent successfully",
+		"message": "Password changed successfully",
 	})
 }
 
-type VerifyPhoneRequest struct {
-	Code string `json:"code" validate:"required,len=6"`
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
 }
 
-func (ac *AuthController) VerifyPhone(c echo.Context) error {
-	userID, err := authmiddleware.GetUserIDFromContext(c)
-	if err != nil {
-		return core.Unauthorized(c, fmt.Errorf("Not authenticated"))
-	}
-	
-	var req VerifyPhoneRequest
+func (ac *AuthController) ForgotPassword(c echo.Context) error {
+	var req ForgotPasswordRequest
 	if err := c.Bind(&req); err != nil {
 		return core.BadRequest(c, fmt.Errorf("Invalid request body"))
 	}
@@ -337,65 +609,750 @@ func (ac *AuthController) VerifyPhone(c echo.Context) error {
 		return core.BadRequest(c, err)
 	}
 	
-	if err := ac.service.VerifyPhone(c.Request().Context(), userID, req.Code); err != nil {
-		return ac.handleError(c, err)
+	if err := ac.service.SendPasswordReset(c.Request().Context(), req.Email); err != nil {
 	}
 	
 	return core.Success(c, map[string]string{
-		"message": "Phone verified successfully",
+		"message": "If the email exists, a password reset link has been sent",
 	})
 }
 
-func (ac *AuthController) GetProviders(c echo.Context) error {
-	providers := ac.service.GetAvailableProviders(c.Request().Context())
-	
-	return core.Success(c, map[string]interface{}{
-		"providers": providers,
-	})
+// magicLinkDeviceCookie holds the device binding secret for magic links
+// when binding is enabled.
+const magicLinkDeviceCookie = "magic_link_device"
+
+type MagicLinkRequest struct {
+	Email string `json:"email" validate:"required,email"`
 }
 
-func (ac *AuthController) OAuthLogin(c echo.Context) error {
-	provider := c.Param("provider")
-	state := c.QueryParam("state")
+func (ac *AuthController) RequestMagicLink(c echo.Context) error {
+	var req MagicLinkRequest
+	if err := c.Bind(&req); err != nil {
+		return core.BadRequest(c, fmt.Errorf("Invalid request body"))
+	}
 	
-	if state == "" {
-		state = fmt.Sprintf("%d", time.Now().Unix())
+	if err := c.Validate(req); err != nil {
+		return core.BadRequest(c, err)
 	}
 	
-	authURL, err := ac.service.GetOAuthURL(c.Request().Context(), provider, state)
+	deviceBinding, err := ac.service.SendMagicLink(c.Request().Context(), req.Email)
 	if err != nil {
 		return ac.handleError(c, err)
 	}
 	
-	return c.Redirect(http.StatusTemporaryRedirect, authURL)
+	response := map[string]string{
+		"message": "If the email exists, a login link has been sent",
+	}
+	
+	// Browsers keep the secret in an HttpOnly cookie; clients without a
+	// cookie jar send device_binding back when verifying.
+	if deviceBinding != "" {
+		c.SetCookie(&http.Cookie{
+			Name:     magicLinkDeviceCookie,
+			Value:    deviceBinding,
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   c.Scheme() == "https",
+			SameSite: http.SameSiteLaxMode,
+		})
+		response["device_binding"] = deviceBinding
+	}
+	
+	return core.Success(c, response)
 }
 
-func (ac *AuthController) OAuthCallback(c echo.Context) error {
-	provider := c.Param("provider")
-	code := c.QueryParam("code")
-	state := c.QueryParam("state")
-	errorParam := c.QueryParam("error")
+type VerifyMagicLinkRequest struct {
+	Token         string `json:"token" validate:"required"`
+	DeviceBinding string `json:"device_binding"`
+}
+
+func (ac *AuthController) VerifyMagicLink(c echo.Context) error {
+	var req VerifyMagicLinkRequest
+	if err := c.Bind(&req); err != nil {
+		return core.BadRequest(c, fmt.Errorf("Invalid request body"))
+	}
 	
-	if errorParam != "" {
-		errorDesc := c.QueryParam("error_description")
-		return core.BadRequest(c, fmt.Errorf("OAuth error: %s - %s", errorParam, errorDesc))
+	if err := c.Validate(req); err != nil {
+		return core.BadRequest(c, err)
 	}
 	
-	if code == "" {
-		return core.BadRequest(c, fmt.Errorf("Authorization code is required"))
+	if req.DeviceBinding == "" {
+		if cookie, err := c.Cookie(magicLinkDeviceCookie); err == nil {
+			req.DeviceBinding = cookie.Value
+		}
 	}
 	
-	session, err := ac.service.HandleOAuthCallback(c.Request().Context(), provider, code, state)
+	result, err := ac.service.VerifyMagicLink(c.Request().Context(), req.Token, req.DeviceBinding)
 	if err != nil {
 		return ac.handleError(c, err)
 	}
 	
-	sessionData := map[string]interface{}{
-		"access_token":  session.GetToken(),
-		"refresh_token": session.GetRefreshToken(),
-		"expires_at":    session.GetExpiresAt(),
-		"token_type":    "Bearer",
+	return ac.respondLogin(c, result)
+}
+
+type UnlockAccountRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+func (ac *AuthController) UnlockAccount(c echo.Context) error {
+	var req UnlockAccountRequest
+	if err := c.Bind(&req); err != nil {
+		return core.BadRequest(c, fmt.Errorf("Invalid request body"))
+	}
+	
+	if err := c.Validate(req); err != nil {
+		return core.BadRequest(c, err)
+	}
+	
+	if err := ac.service.UnlockAccount(c.Request().Context(), req.Token); err != nil {
+		return ac.handleError(c, err)
 	}
 	
-	return core.Success(c, sessionData)
+	return core.Success(c, map[string]string{
+		"message": "Account unlocked successfully",
+	})
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required"`
+}
+
+func (ac *AuthController) ResetPassword(c echo.Context) error {
+	var req ResetPasswordRequest
+	if err := c.Bind(&req); err != nil {
+		return core.BadRequest(c, fmt.Errorf("Invalid request body"))
+	}
+	
+	if err := c.Validate(req); err != nil {
+		return core.BadRequest(c, err)
+	}
+	
+	if err := ac.service.ResetPassword(c.Request().Context(), req.Token, req.NewPassword); err != nil {
+		return ac.handleError(c, err)
+	}
+	
+	return core.Success(c, map[string]string{
+		"message": "Password reset successfully",
+	})
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+func (ac *AuthController) VerifyEmail(c echo.Context) error {
+	var req VerifyEmailRequest
+	if err := c.Bind(&req); err != nil {
+		return core.BadRequest(c, fmt.Errorf("Invalid request body"))
+	}
+	
+	if err := c.Validate(req); err != nil {
+		return core.BadRequest(c, err)
+	}
+	
+	if err := ac.service.VerifyEmail(c.Request().Context(), req.Token); err != nil {
+		return ac.handleError(c, err)
+	}
+	
+	return core.Success(c, map[string]string{
+		"message": "Email verified successfully",
+	})
+}
+
+type ResendVerificationRequest struct {
+	Type string `json:"type" validate:"required,oneof=email phone"`
+}
+
+func (ac *AuthController) ResendVerification(c echo.Context) error {
+	userID, err := authmiddleware.GetUserIDFromContext(c)
+	if err != nil {
+		return core.Unauthorized(c, fmt.Errorf("Not authenticated"))
+	}
+	
+	var req ResendVerificationRequest
+	if err := c.Bind(&req); err != nil {
+		return core.BadRequest(c, fmt.Errorf("Invalid request body"))
+	}
+	
+	if err := c.Validate(req); err != nil {
+		return core.BadRequest(c, err)
+	}
+	
+	switch req.Type {
+	case "email":
+		if err := ac.service.SendEmailVerification(c.Request().Context(), userID); err != nil {
+			return ac.handleError(c, err)
+		}
+	case "phone":
+		if err := ac.service.SendPhoneVerification(c.Request().Context(), userID); err != nil {
+			return ac.handleError(c, err)
+		}
+	}
+	
+	return core.Success(c, map[string]string{
+		"message": "Verification sent successfully",
+	})
+}
+
+// VerifyPhoneRequest carries PhoneToken instead of an access token when the
+// phone is verified to finish a login.
+type VerifyPhoneRequest struct {
+	Code       string `json:"code" validate:"required,len=6"`
+	PhoneToken string `json:"phone_token"`
+}
+
+func (ac *AuthController) VerifyPhone(c echo.Context) error {
+	var req VerifyPhoneRequest
+	if err := c.Bind(&req); err != nil {
+		return core.BadRequest(c, fmt.Errorf("Invalid request body"))
+	}
+	
+	if err := c.Validate(req); err != nil {
+		return core.BadRequest(c, err)
+	}
+	
+	if req.PhoneToken != "" {
+		result, err := ac.service.VerifyPhoneChallenge(c.Request().Context(), req.PhoneToken, req.Code)
+		if err != nil {
+			return ac.handleError(c, err)
+		}
+		return ac.respondLogin(c, result)
+	}
+	
+	userID, err := authmiddleware.GetUserIDFromContext(c)
+	if err != nil {
+		return core.Unauthorized(c, fmt.Errorf("Not authenticated"))
+	}
+	
+	if err := ac.service.VerifyPhone(c.Request().Context(), userID, req.Code); err != nil {
+		return ac.handleError(c, err)
+	}
+	
+	return core.Success(c, map[string]string{
+		"message": "Phone verified successfully",
+	})
+}
+
+// SetChallengePhoneRequest adds a phone during login, for accounts whose
+// phone challenge came back with phone_required.
+type SetChallengePhoneRequest struct {
+	PhoneToken string `json:"phone_token" validate:"required"`
+	Phone      string `json:"phone" validate:"required,e164"`
+}
+
+func (ac *AuthController) SetChallengePhone(c echo.Context) error {
+	var req SetChallengePhoneRequest
+	if err := c.Bind(&req); err != nil {
+		return core.BadRequest(c, fmt.Errorf("Invalid request body"))
+	}
+	
+	if err := c.Validate(req); err != nil {
+		return core.BadRequest(c, err)
+	}
+	
+	if err := ac.service.SetChallengePhone(c.Request().Context(), req.PhoneToken, req.Phone); err != nil {
+		return ac.handleError(c, err)
+	}
+	
+	return core.Success(c, map[string]string{
+		"message": "Verification code sent",
+	})
+}
+
+func (ac *AuthController) GetProviders(c echo.Context) error {
+	providers := ac.service.GetAvailableProviders(c.Request().Context())
+	
+	return core.Success(c, map[string]interface{}{
+		"providers": providers,
+	})
+}
+
+// oauthStateCookie holds the state of the OAuth flow the browser started.
+// The callback must carry the same state, so a victim cannot be sent through
+// a callback the attacker started to sign in to, or link, their account.
+const oauthStateCookie = "oauth_state"
+
+func (ac *AuthController) setOAuthStateCookie(c echo.Context, state string) {
+	c.SetCookie(&http.Cookie{
+		Name:     oauthStateCookie,
+		Value:    state,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   c.Scheme() == "https",
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func (ac *AuthController) OAuthLogin(c echo.Context) error {
+	provider := c.Param("provider")
+	
+	authURL, state, err := ac.service.GetOAuthURL(c.Request().Context(), provider)
+	if err != nil {
+		return ac.handleError(c, err)
+	}
+	
+	ac.setOAuthStateCookie(c, state)
+	return c.Redirect(http.StatusTemporaryRedirect, authURL)
+}
+
+func (ac *AuthController) OAuthCallback(c echo.Context) error {
+	provider := c.Param("provider")
+	code := c.QueryParam("code")
+	state := c.QueryParam("state")
+	errorParam := c.QueryParam("error")
+	
+	if errorParam != "" {
+		errorDesc := c.QueryParam("error_description")
+		return core.BadRequest(c, fmt.Errorf("OAuth error: %s - %s", errorParam, errorDesc))
+	}
+	
+	if code == "" {
+		return core.BadRequest(c, fmt.Errorf("Authorization code is required"))
+	}
+	
+	cookie, err := c.Cookie(oauthStateCookie)
+	if err != nil || state == "" || subtle.ConstantTimeCompare([]byte(cookie.Value), []byte(state)) != 1 {
+		return ac.handleError(c, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrInvalidOAuthState))
+	}
+	c.SetCookie(&http.Cookie{
+		Name:     oauthStateCookie,
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   c.Scheme() == "https",
+		SameSite: http.SameSiteLaxMode,
+	})
+	
+	result, err := ac.service.HandleOAuthCallback(c.Request().Context(), provider, code, state)
+	if err != nil {
+		return ac.handleError(c, err)
+	}
+	
+	return ac.respondLogin(c, result)
+}
+
+type SetupMFARequest struct {
+	MFAToken string `json:"mfa_token"`
+}
+
+func (ac *AuthController) SetupMFA(c echo.Context) error {
+	var req SetupMFARequest
+	if err := c.Bind(&req); err != nil {
+		return core.BadRequest(c, fmt.Errorf("Invalid request body"))
+	}
+	
+	var setup *authinterface.MFASetup
+	var err error
+	if userID, authErr := authmiddleware.GetUserIDFromContext(c); authErr == nil {
+		setup, err = ac.service.SetupMFA(c.Request().Context(), userID)
+	} else if req.MFAToken != "" {
+		setup, err = ac.service.SetupMFAForChallenge(c.Request().Context(), req.MFAToken)
+	} else {
+		return core.Unauthorized(c, fmt.Errorf("Not authenticated"))
+	}
+	if err != nil {
+		return ac.handleError(c, err)
+	}
+	
+	return core.Success(c, setup)
+}
+
+type VerifyMFARequest struct {
+	MFAToken string `json:"mfa_token"`
+	Code     string `json:"code" validate:"required"`
+}
+
+// VerifyMFA completes a login when mfa_token is given, otherwise it confirms
+// the authenticated user's pending setup and returns their recovery codes.
+func (ac *AuthController) VerifyMFA(c echo.Context) error {
+	var req VerifyMFARequest
+	if err := c.Bind(&req); err != nil {
+		return core.BadRequest(c, fmt.Errorf("Invalid request body"))
+	}
+	
+	if err := c.Validate(req); err != nil {
+		return core.BadRequest(c, err)
+	}
+	
+	if req.MFAToken != "" {
+		result, err := ac.service.VerifyMFA(c.Request().Context(), req.MFAToken, req.Code)
+		if err != nil {
+			return ac.handleError(c, err)
+		}
+		return ac.respondLogin(c, result)
+	}
+	
+	userID, err := authmiddleware.GetUserIDFromContext(c)
+	if err != nil {
+		return core.Unauthorized(c, fmt.Errorf("Not authenticated"))
+	}
+	
+	recoveryCodes, err := ac.service.ConfirmMFA(c.Request().Context(), userID, req.Code)
+	if err != nil {
+		return ac.handleError(c, err)
+	}
+	
+	return core.Success(c, map[string]interface{}{
+		"message":        "Two-factor authentication enabled",
+		"recovery_codes": recoveryCodes,
+	})
+}
+
+type DisableMFARequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+func (ac *AuthController) DisableMFA(c echo.Context) error {
+	userID, err := authmiddleware.GetUserIDFromContext(c)
+	if err != nil {
+		return core.Unauthorized(c, fmt.Errorf("Not authenticated"))
+	}
+	
+	var req DisableMFARequest
+	if err := c.Bind(&req); err != nil {
+		return core.BadRequest(c, fmt.Errorf("Invalid request body"))
+	}
+	
+	if err := c.Validate(req); err != nil {
+		return core.BadRequest(c, err)
+	}
+	
+	if err := ac.service.DisableMFA(c.Request().Context(), userID, req.Code); err != nil {
+		return ac.handleError(c, err)
+	}
+	
+	return core.Success(c, map[string]string{
+		"message": "Two-factor authentication disabled",
+	})
+}
+
+func (ac *AuthController) JWKS(c echo.Context) error {
+	c.Response().Header().Set("Cache-Control", "public, max-age=300")
+	return c.JSON(http.StatusOK, ac.service.GetJWKS(c.Request().Context()))
+}
+
+type IdentityResponse struct {
+	ID             uuid.UUID `json:"id"`
+	Provider       string    `json:"provider"`
+	ProviderUserID string    `json:"provider_user_id"`
+	Email          string    `json:"email,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func toIdentityResponse(identity authinterface.Identity) IdentityResponse {
+	return IdentityResponse{
+		ID:             identity.GetID(),
+		Provider:       identity.GetProvider(),
+		ProviderUserID: identity.GetProviderUserID(),
+		Email:          identity.GetEmail(),
+		CreatedAt:      identity.GetCreatedAt(),
+	}
+}
+
+func (ac *AuthController) ListIdentities(c echo.Context) error {
+	userID, err := authmiddleware.GetUserIDFromContext(c)
+	if err != nil {
+		return core.Unauthorized(c, fmt.Errorf("Not authenticated"))
+	}
+	
+	identities, err := ac.service.ListIdentities(c.Request().Context(), userID)
+	if err != nil {
+		return ac.handleError(c, err)
+	}
+	
+	response := make([]IdentityResponse, len(identities))
+	for i, identity := range identities {
+		response[i] = toIdentityResponse(identity)
+	}
+	
+	return core.Success(c, response)
+}
+
+// LinkIdentity returns the provider URL instead of redirecting, since the
+// browser has to navigate there without the API's Authorization header.
+// Cross-origin clients must send the request with credentials so the
+// browser keeps the state cookie the callback checks.
+func (ac *AuthController) LinkIdentity(c echo.Context) error {
+	userID, err := authmiddleware.GetUserIDFromContext(c)
+	if err != nil {
+		return core.Unauthorized(c, fmt.Errorf("Not authenticated"))
+	}
+	
+	authURL, state, err := ac.service.GetOAuthLinkURL(c.Request().Context(), userID, c.Param("provider"))
+	if err != nil {
+		return ac.handleError(c, err)
+	}
+	
+	ac.setOAuthStateCookie(c, state)
+	return core.Success(c, map[string]string{
+		"auth_url": authURL,
+	})
+}
+
+func (ac *AuthController) UnlinkIdentity(c echo.Context) error {
+	userID, err := authmiddleware.GetUserIDFromContext(c)
+	if err != nil {
+		return core.Unauthorized(c, fmt.Errorf("Not authenticated"))
+	}
+	
+	identityID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return core.BadRequest(c, fmt.Errorf("Invalid identity ID"))
+	}
+	
+	if err := ac.service.UnlinkIdentity(c.Request().Context(), userID, identityID); err != nil {
+		return ac.handleError(c, err)
+	}
+	
+	return core.Success(c, map[string]string{
+		"message": "Identity unlinked",
+	})
+}
+
+type PasskeyResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Transports []string   `json:"transports"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+func toPasskeyResponse(passkey authinterface.WebAuthnCredential) PasskeyResponse {
+	return PasskeyResponse{
+		ID:         passkey.GetID(),
+		Name:       passkey.GetName(),
+		Transports: passkey.GetTransports(),
+		CreatedAt:  passkey.GetCreatedAt(),
+		LastUsedAt: passkey.GetLastUsedAt(),
+	}
+}
+
+func (ac *AuthController) BeginPasskeyRegistration(c echo.Context) error {
+	userID, err := authmiddleware.GetUserIDFromContext(c)
+	if err != nil {
+		return core.Unauthorized(c, fmt.Errorf("Not authenticated"))
+	}
+	
+	options, err := ac.service.BeginPasskeyRegistration(c.Request().Context(), userID)
+	if err != nil {
+		return ac.handleError(c, err)
+	}
+	
+	return core.Success(c, options)
+}
+
+type FinishPasskeyRegistrationRequest struct {
+	Name       string                                   `json:"name"`
+	Credential authinterface.WebAuthnCredentialResponse `json:"credential"`
+}
+
+func (ac *AuthController) FinishPasskeyRegistration(c echo.Context) error {
+	userID, err := authmiddleware.GetUserIDFromContext(c)
+	if err != nil {
+		return core.Unauthorized(c, fmt.Errorf("Not authenticated"))
+	}
+	
+	var req FinishPasskeyRegistrationRequest
+	if err := c.Bind(&req); err != nil {
+		return core.BadRequest(c, fmt.Errorf("Invalid request body"))
+	}
+	
+	passkey, err := ac.service.FinishPasskeyRegistration(c.Request().Context(), userID, req.Name, &req.Credential)
+	if err != nil {
+		return ac.handleError(c, err)
+	}
+	
+	return core.Success(c, toPasskeyResponse(passkey))
+}
+
+type BeginPasskeyLoginRequest struct {
+	Email string `json:"email" validate:"omitempty,email"`
+}
+
+func (ac *AuthController) BeginPasskeyLogin(c echo.Context) error {
+	var req BeginPasskeyLoginRequest
+	if err := c.Bind(&req); err != nil {
+		return core.BadRequest(c, fmt.Errorf("Invalid request body"))
+	}
+	
+	if err := c.Validate(req); err != nil {
+		return core.BadRequest(c, err)
+	}
+	
+	options, err := ac.service.BeginPasskeyLogin(c.Request().Context(), req.Email)
+	if err != nil {
+		return ac.handleError(c, err)
+	}
+	
+	return core.Success(c, options)
+}
+
+func (ac *AuthController) FinishPasskeyLogin(c echo.Context) error {
+	var credential authinterface.WebAuthnCredentialResponse
+	if err := c.Bind(&credential); err != nil {
+		return core.BadRequest(c, fmt.Errorf("Invalid request body"))
+	}
+	
+	result, err := ac.service.FinishPasskeyLogin(c.Request().Context(), &credential)
+	if err != nil {
+		return ac.handleError(c, err)
+	}
+	
+	return ac.respondLogin(c, result)
+}
+
+func (ac *AuthController) ListPasskeys(c echo.Context) error {
+	userID, err := authmiddleware.GetUserIDFromContext(c)
+	if err != nil {
+		return core.Unauthorized(c, fmt.Errorf("Not authenticated"))
+	}
+	
+	passkeys, err := ac.service.ListPasskeys(c.Request().Context(), userID)
+	if err != nil {
+		return ac.handleError(c, err)
+	}
+	
+	response := make([]PasskeyResponse, len(passkeys))
+	for i, passkey := range passkeys {
+		response[i] = toPasskeyResponse(passkey)
+	}
+	
+	return core.Success(c, response)
+}
+
+func (ac *AuthController) DeletePasskey(c echo.Context) error {
+	userID, err := authmiddleware.GetUserIDFromContext(c)
+	if err != nil {
+		return core.Unauthorized(c, fmt.Errorf("Not authenticated"))
+	}
+	
+	passkeyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return core.BadRequest(c, fmt.Errorf("Invalid passkey ID"))
+	}
+	
+	if err := ac.service.DeletePasskey(c.Request().Context(), userID, passkeyID); err != nil {
+		return ac.handleError(c, err)
+	}
+	
+	return core.Success(c, map[string]string{
+		"message": "Passkey deleted",
+	})
+}
+
+type StartImpersonationRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+func (ac *AuthController) StartImpersonation(c echo.Context) error {
+	impersonatorID, err := authmiddleware.GetUserIDFromContext(c)
+	if err != nil {
+		return core.Unauthorized(c, fmt.Errorf("Not authenticated"))
+	}
+	
+	targetID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return core.BadRequest(c, fmt.Errorf("Invalid user ID"))
+	}
+	
+	var req StartImpersonationRequest
+	if err := c.Bind(&req); err != nil {
+		return core.BadRequest(c, fmt.Errorf("Invalid request body"))
+	}
+	
+	if err := c.Validate(req); err != nil {
+		return core.BadRequest(c, err)
+	}
+	
+	session, err := ac.service.StartImpersonation(c.Request().Context(), impersonatorID, targetID, req.Reason)
+	if err != nil {
+		return ac.handleError(c, err)
+	}
+	
+	return core.Success(c, session)
+}
+
+func (ac *AuthController) StopImpersonation(c echo.Context) error {
+	session := authmiddleware.GetSessionFromContext(c)
+	if session == nil {
+		return core.Unauthorized(c, fmt.Errorf("Not authenticated"))
+	}
+	
+	if err := ac.service.StopImpersonation(c.Request().Context(), session.GetToken()); err != nil {
+		return ac.handleError(c, err)
+	}
+	
+	return core.Success(c, map[string]string{
+		"message": "Impersonation ended",
+	})
+}
+
+type CreateAPIKeyRequest struct {
+	Name       string     `json:"name" validate:"required,max=100"`
+	Scopes     []string   `json:"scopes" validate:"max=50,dive,required,max=100"`
+	AllowedIPs []string   `json:"allowed_ips" validate:"max=50"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+}
+
+func (ac *AuthController) CreateAPIKey(c echo.Context) error {
+	userID, err := authmiddleware.GetUserIDFromContext(c)
+	if err != nil {
+		return core.Unauthorized(c, fmt.Errorf("Not authenticated"))
+	}
+	
+	var req CreateAPIKeyRequest
+	if err := c.Bind(&req); err != nil {
+		return core.BadRequest(c, fmt.Errorf("Invalid request body"))
+	}
+	
+	if err := c.Validate(req); err != nil {
+		return core.BadRequest(c, err)
+	}
+	
+	created, err := ac.service.CreateAPIKey(c.Request().Context(), userID, authinterface.CreateAPIKeyOptions{
+		Name:       req.Name,
+		Scopes:     req.Scopes,
+		AllowedIPs: req.AllowedIPs,
+		ExpiresAt:  req.ExpiresAt,
+	})
+	if err != nil {
+		return ac.handleError(c, err)
+	}
+	
+	return core.Created(c, created, "Store this key now, it will not be shown again")
+}
+
+func (ac *AuthController) ListAPIKeys(c echo.Context) error {
+	userID, err := authmiddleware.GetUserIDFromContext(c)
+	if err != nil {
+		return core.Unauthorized(c, fmt.Errorf("Not authenticated"))
+	}
+	
+	keys, err := ac.service.ListAPIKeys(c.Request().Context(), userID)
+	if err != nil {
+		return ac.handleError(c, err)
+	}
+	
+	return core.Success(c, keys)
+}
+
+func (ac *AuthController) RevokeAPIKey(c echo.Context) error {
+	userID, err := authmiddleware.GetUserIDFromContext(c)
+	if err != nil {
+		return core.Unauthorized(c, fmt.Errorf("Not authenticated"))
+	}
+	
+	keyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return core.BadRequest(c, fmt.Errorf("Invalid API key ID"))
+	}
+	
+	if err := ac.service.RevokeAPIKey(c.Request().Context(), userID, keyID); err != nil {
+		return ac.handleError(c, err)
+	}
+	
+	return core.Success(c, map[string]string{
+		"message": "API key revoked",
+	})
 }
\ No newline at end of file
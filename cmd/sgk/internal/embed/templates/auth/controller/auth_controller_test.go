@@ -0,0 +1,324 @@
+package authcontroller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	authconstants "{{.Project.GoModule}}/internal/auth/constants"
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	authmiddleware "{{.Project.GoModule}}/internal/auth/middleware"
+	authmodel "{{.Project.GoModule}}/internal/auth/model"
+	"{{.Project.GoModule}}/internal/core"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// fakeAuthService embeds the interface and implements only what the tests
+// reach; anything else panics on the nil embedded value.
+type fakeAuthService struct {
+	authinterface.AuthService
+	oauthResult    *authinterface.LoginResult
+	oauthCallbacks int
+}
+
+func (s *fakeAuthService) GetOAuthURL(ctx context.Context, provider string) (string, string, error) {
+	return "https://provider.example.com/authorize?state=login-state", "login-state", nil
+}
+
+func (s *fakeAuthService) GetOAuthLinkURL(ctx context.Context, accountID uuid.UUID, provider string) (string, string, error) {
+	return "https://provider.example.com/authorize?state=link-state", "link-state", nil
+}
+
+func (s *fakeAuthService) HandleOAuthCallback(ctx context.Context, provider, code, state string) (*authinterface.LoginResult, error) {
+	s.oauthCallbacks++
+	return s.oauthResult, nil
+}
+
+// serve runs handler for req and returns the recorded response.
+func serve(t *testing.T, handler echo.HandlerFunc, req *http.Request, params map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	e := echo.New()
+	e.Validator = core.NewValidator()
+
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	for name, value := range params {
+		c.SetParamNames(append(c.ParamNames(), name)...)
+		c.SetParamValues(append(c.ParamValues(), value)...)
+	}
+
+	if err := handler(c); err != nil {
+		e.HTTPErrorHandler(err, c)
+	}
+	return rec
+}
+
+func decodeData(t *testing.T, rec *httptest.ResponseRecorder) map[string]any {
+	t.Helper()
+
+	var body struct {
+		Success bool           `json:"success"`
+		Data    map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response %q: %v", rec.Body.String(), err)
+	}
+	if !body.Success {
+		t.Fatalf("response = %s, want success", rec.Body.String())
+	}
+	return body.Data
+}
+
+// oauthCallbackRequest comes back with the state cookie the flow set.
+func oauthCallbackRequest() *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/oauth/github/callback?code=code&state=state", nil)
+	req.AddCookie(&http.Cookie{Name: oauthStateCookie, Value: "state"})
+	return req
+}
+
+func findCookie(rec *httptest.ResponseRecorder, name string) *http.Cookie {
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.Name == name {
+			return cookie
+		}
+	}
+	return nil
+}
+
+func TestOAuthStartSetsStateCookie(t *testing.T) {
+	ac := NewAuthController(&fakeAuthService{}, authinterface.CookieSettings{})
+
+	t.Run("login", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/oauth/github", nil)
+		rec := serve(t, ac.OAuthLogin, req, map[string]string{"provider": "github"})
+		if rec.Code != http.StatusTemporaryRedirect {
+			t.Fatalf("status = %d, want a redirect", rec.Code)
+		}
+
+		cookie := findCookie(rec, oauthStateCookie)
+		if cookie == nil || cookie.Value != "login-state" || !cookie.HttpOnly || cookie.SameSite != http.SameSiteLaxMode {
+			t.Fatalf("state cookie = %+v, want the state in an HttpOnly SameSite=Lax cookie", cookie)
+		}
+	})
+
+	t.Run("link", func(t *testing.T) {
+		e := echo.New()
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodPost, "/api/v1/auth/identities/github/link", nil), rec)
+		c.SetParamNames("provider")
+		c.SetParamValues("github")
+		c.Set(authconstants.ContextKeyUserID, uuid.New())
+
+		if err := ac.LinkIdentity(c); err != nil {
+			t.Fatalf("LinkIdentity: %v", err)
+		}
+		if cookie := findCookie(rec, oauthStateCookie); cookie == nil || cookie.Value != "link-state" {
+			t.Fatalf("state cookie = %+v, want the link state", cookie)
+		}
+	})
+}
+
+func TestOAuthCallbackRequiresStateCookie(t *testing.T) {
+	tests := []struct {
+		name   string
+		cookie *http.Cookie
+	}{
+		{"missing", nil},
+		{"other flow", &http.Cookie{Name: oauthStateCookie, Value: "other-state"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &fakeAuthService{oauthResult: &authinterface.LoginResult{}}
+			ac := NewAuthController(service, authinterface.CookieSettings{})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/oauth/github/callback?code=code&state=state", nil)
+			if tt.cookie != nil {
+				req.AddCookie(tt.cookie)
+			}
+
+			rec := serve(t, ac.OAuthCallback, req, map[string]string{"provider": "github"})
+			if rec.Code != http.StatusUnauthorized {
+				t.Fatalf("status = %d, want 401", rec.Code)
+			}
+			if service.oauthCallbacks != 0 {
+				t.Fatal("the code was redeemed without the browser's state")
+			}
+		})
+	}
+}
+
+func TestOAuthCallbackLinkedIdentity(t *testing.T) {
+	identity := &authmodel.Identity{ID: uuid.New(), Provider: "github", ProviderUserID: "42"}
+	service := &fakeAuthService{oauthResult: &authinterface.LoginResult{LinkedIdentity: identity}}
+	ac := NewAuthController(service, authinterface.CookieSettings{Enabled: true})
+
+	rec := serve(t, ac.OAuthCallback, oauthCallbackRequest(), map[string]string{"provider": "github"})
+	data := decodeData(t, rec)
+	if data["id"] != identity.ID.String() || data["provider"] != "github" {
+		t.Fatalf("data = %v, want the linked identity", data)
+	}
+	if cookie := findCookie(rec, authconstants.CookieAccessToken); cookie != nil {
+		t.Fatalf("linking set a session cookie %+v", cookie)
+	}
+	if cookie := findCookie(rec, oauthStateCookie); cookie == nil || cookie.MaxAge >= 0 {
+		t.Fatalf("state cookie = %+v, want it cleared", cookie)
+	}
+}
+
+func TestOAuthCallbackPhoneChallenge(t *testing.T) {
+	challenge := &authinterface.PhoneChallenge{
+		PhoneVerificationRequired: true,
+		Token:                     "phone-token",
+		ExpiresAt:                 time.Now().Add(10 * time.Minute),
+	}
+
+	for _, cookies := range []bool{false, true} {
+		name := "bearer"
+		if cookies {
+			name = "cookies"
+		}
+
+		t.Run(name, func(t *testing.T) {
+			service := &fakeAuthService{oauthResult: &authinterface.LoginResult{PhoneChallenge: challenge}}
+			ac := NewAuthController(service, authinterface.CookieSettings{Enabled: cookies})
+
+			rec := serve(t, ac.OAuthCallback, oauthCallbackRequest(), map[string]string{"provider": "github"})
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, body %s", rec.Code, rec.Body.String())
+			}
+
+			data := decodeData(t, rec)
+			if data["phone_token"] != "phone-token" || data["phone_verification_required"] != true {
+				t.Fatalf("data = %v, want the phone challenge", data)
+			}
+			if findCookie(rec, authconstants.CookieAccessToken) != nil || findCookie(rec, authconstants.CookieRefreshToken) != nil {
+				t.Fatalf("cookies = %v, want no session before the phone is verified", rec.Result().Cookies())
+			}
+		})
+	}
+}
+
+func TestOAuthCallbackSession(t *testing.T) {
+	session := &authmodel.Session{
+		ID:               uuid.New(),
+		UserID:           uuid.New(),
+		Token:            "access-token",
+		RefreshToken:     "refresh-token",
+		ExpiresAt:        time.Now().Add(time.Hour),
+		RefreshExpiresAt: time.Now().Add(24 * time.Hour),
+	}
+	service := &fakeAuthService{oauthResult: &authinterface.LoginResult{Session: session}}
+
+	t.Run("bearer", func(t *testing.T) {
+		ac := NewAuthController(service, authinterface.CookieSettings{})
+
+		rec := serve(t, ac.OAuthCallback, oauthCallbackRequest(), map[string]string{"provider": "github"})
+		data := decodeData(t, rec)
+		if data["token"] != "access-token" || data["refresh_token"] != "refresh-token" {
+			t.Fatalf("data = %v, want the session tokens", data)
+		}
+	})
+
+	t.Run("cookies", func(t *testing.T) {
+		ac := NewAuthController(service, authinterface.CookieSettings{Enabled: true})
+
+		rec := serve(t, ac.OAuthCallback, oauthCallbackRequest(), map[string]string{"provider": "github"})
+		data := decodeData(t, rec)
+		if _, ok := data["token"]; ok {
+			t.Fatalf("data = %v, want no tokens in cookie mode", data)
+		}
+
+		cookies := make(map[string]string)
+		for _, cookie := range rec.Result().Cookies() {
+			cookies[cookie.Name] = cookie.Value
+		}
+		if cookies[authconstants.CookieAccessToken] != "access-token" || cookies[authconstants.CookieRefreshToken] != "refresh-token" {
+			t.Fatalf("cookies = %v, want the session tokens", cookies)
+		}
+	})
+}
+
+// impersonationService authenticates every bearer token as staff acting as
+// a customer.
+type impersonationService struct {
+	fakeAuthService
+	customer *authmodel.Account
+	session  *authmodel.Session
+}
+
+func newImpersonationService() *impersonationService {
+	customer := &authmodel.Account{ID: uuid.New(), Email: "customer@example.com"}
+	staffID := uuid.New()
+	return &impersonationService{
+		customer: customer,
+		session: &authmodel.Session{
+			ID:             uuid.New(),
+			UserID:         customer.ID,
+			ImpersonatorID: &staffID,
+			ExpiresAt:      time.Now().Add(time.Hour),
+		},
+	}
+}
+
+func (s *impersonationService) AuthenticateToken(ctx context.Context, token string) (authinterface.Account, authinterface.Session, error) {
+	return s.customer, s.session, nil
+}
+
+func (s *impersonationService) ListLoginHistory(ctx context.Context, accountID uuid.UUID, limit int) ([]authinterface.LoginHistoryEntry, error) {
+	return nil, nil
+}
+
+func TestImpersonationBlockedRoutes(t *testing.T) {
+	service := newImpersonationService()
+	ac := NewAuthController(service, authinterface.CookieSettings{})
+
+	e := echo.New()
+	e.Validator = core.NewValidator()
+	ac.RegisterRoutes(e, "/api/v1/auth", authmiddleware.NewAuthMiddleware(service))
+
+	request := func(method, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/v1/auth"+path, strings.NewReader(`{}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer impersonation-token")
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	id := uuid.New().String()
+	blocked := []struct{ method, path string }{
+		{http.MethodPut, "/me"},
+		{http.MethodDelete, "/me"},
+		{http.MethodGet, "/me/export"},
+		{http.MethodPost, "/change-password"},
+		{http.MethodPost, "/email/change"},
+		{http.MethodPost, "/logout-all"},
+		{http.MethodPost, "/mfa/disable"},
+		{http.MethodPost, "/identities/github/link"},
+		{http.MethodDelete, "/identities/" + id},
+		{http.MethodPost, "/passkeys/register/begin"},
+		{http.MethodDelete, "/passkeys/" + id},
+		{http.MethodPost, "/api-keys"},
+		{http.MethodDelete, "/api-keys/" + id},
+		{http.MethodPost, "/impersonate/" + id},
+	}
+	for _, route := range blocked {
+		t.Run(route.method+" "+route.path, func(t *testing.T) {
+			rec := request(route.method, route.path)
+			if rec.Code != http.StatusForbidden {
+				t.Fatalf("status = %d, body %s, want 403 while impersonating", rec.Code, rec.Body.String())
+			}
+		})
+	}
+
+	if rec := request(http.MethodGet, "/me/activity"); rec.Code != http.StatusOK {
+		t.Fatalf("GET /me/activity status = %d, want reads to stay open while impersonating", rec.Code)
+	}
+}
@@ -0,0 +1,81 @@
+package authcontroller
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"time"
+
+	authconstants "{{.Project.GoModule}}/internal/auth/constants"
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// cookieSessionResponse replaces the session in responses in cookie mode so
+// the tokens never reach scripts. CSRFToken is the value to send in the
+// CSRF header, for clients that cannot read the cookie.
+type cookieSessionResponse struct {
+	ID               uuid.UUID `json:"id"`
+	UserID           uuid.UUID `json:"user_id"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+	CSRFToken        string    `json:"csrf_token"`
+}
+
+// sessionResponse returns what to send back for a new or refreshed session.
+// In cookie mode it sets the session cookies, with a fresh CSRF token, and
+// leaves the tokens out of the body.
+func (ac *AuthController) sessionResponse(c echo.Context, session authinterface.Session) (any, error) {
+	if !ac.cookies.Enabled {
+		return session, nil
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	csrfToken := base64.RawURLEncoding.EncodeToString(buf)
+
+	c.SetCookie(ac.newCookie(authconstants.CookieAccessToken, session.GetToken(), "/", session.GetExpiresAt(), true))
+	c.SetCookie(ac.newCookie(authconstants.CookieRefreshToken, session.GetRefreshToken(), ac.refreshCookiePath, session.GetRefreshExpiresAt(), true))
+	c.SetCookie(ac.newCookie(authconstants.CookieCSRFToken, csrfToken, "/", session.GetRefreshExpiresAt(), false))
+
+	return cookieSessionResponse{
+		ID:               session.GetID(),
+		UserID:           session.GetUserID(),
+		ExpiresAt:        session.GetExpiresAt(),
+		RefreshExpiresAt: session.GetRefreshExpiresAt(),
+		CSRFToken:        csrfToken,
+	}, nil
+}
+
+// clearSessionCookies expires the session cookies. It does nothing outside
+// cookie mode.
+func (ac *AuthController) clearSessionCookies(c echo.Context) {
+	if !ac.cookies.Enabled {
+		return
+	}
+
+	expired := time.Unix(0, 0)
+	c.SetCookie(ac.newCookie(authconstants.CookieAccessToken, "", "/", expired, true))
+	c.SetCookie(ac.newCookie(authconstants.CookieRefreshToken, "", ac.refreshCookiePath, expired, true))
+	c.SetCookie(ac.newCookie(authconstants.CookieCSRFToken, "", "/", expired, false))
+}
+
+func (ac *AuthController) newCookie(name, value, path string, expires time.Time, httpOnly bool) *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   ac.cookies.Domain,
+		Expires:  expires,
+		Secure:   ac.cookies.Secure,
+		HttpOnly: httpOnly,
+		SameSite: ac.cookies.SameSite,
+	}
+	if value == "" {
+		cookie.MaxAge = -1
+	}
+	return cookie
+}
@@ -0,0 +1,49 @@
+package authinterface
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKey is a long-lived credential for scripts and services. Only a hash of
+// the secret is stored; the prefix identifies the key when it is presented.
+// An empty scope list grants the owner's full permissions and an empty
+// allowlist accepts any address.
+type APIKey interface {
+	GetID() uuid.UUID
+	GetAccountID() uuid.UUID
+	GetName() string
+	GetPrefix() string
+	GetSecretHash() string
+	GetScopes() []string
+	GetAllowedIPs() []string
+	GetExpiresAt() *time.Time
+	GetLastUsedAt() *time.Time
+	GetCreatedAt() time.Time
+	IsExpired() bool
+}
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key APIKey) error
+	GetByPrefix(ctx context.Context, prefix string) (APIKey, error)
+	ListByAccount(ctx context.Context, accountID uuid.UUID) ([]APIKey, error)
+	UpdateLastUsed(ctx context.Context, id uuid.UUID, usedAt time.Time) error
+	Delete(ctx context.Context, accountID, id uuid.UUID) error
+	DeleteByAccount(ctx context.Context, accountID uuid.UUID) error
+}
+
+type CreateAPIKeyOptions struct {
+	Name       string
+	Scopes     []string
+	AllowedIPs []string
+	ExpiresAt  *time.Time
+}
+
+// CreatedAPIKey is returned once when a key is created. Key is the only copy
+// of the full secret.
+type CreatedAPIKey struct {
+	APIKey APIKey `json:"api_key"`
+	Key    string `json:"key"`
+}
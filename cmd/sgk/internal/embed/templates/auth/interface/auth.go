@@ -2,9 +2,13 @@ package authinterface
 
 import (
 	"context"
+	"errors"
+	"net/http"
 	"time"
 	
 	"github.com/google/uuid"
+
+	"{{.Project.GoModule}}/internal/core"
 )
 
 type Account interface {
@@ -14,11 +18,19 @@ type Account interface {
 	GetPasswordHash() string
 	GetEmailVerified() bool
 	GetPhoneVerified() bool
+	GetMFAEnabled() bool
+	GetMFASecret() string
+	GetMFALastUsedStep() int64
 	GetCreatedAt() time.Time
 	GetUpdatedAt() time.Time
 	SetPasswordHash(hash string)
+	SetEmail(email string)
 	SetEmailVerified(verified bool)
+	SetPhone(phone string)
 	SetPhoneVerified(verified bool)
+	SetMFASecret(secret string)
+	SetMFAEnabled(enabled bool)
+	SetMFALastUsedStep(step int64)
 }
 
 type Token interface {
@@ -28,6 +40,8 @@ type Token interface {
 	GetType() TokenType
 	GetUsed() bool
 	GetExpiresAt() time.Time
+	GetDeviceBinding() string
+	GetEmail() string
 	GetCreatedAt() time.Time
 	GetUpdatedAt() time.Time
 	SetUsed(used bool)
@@ -41,18 +55,79 @@ const (
 	TokenTypePhoneVerification TokenType = "phone_verification"
 	TokenTypePasswordReset     TokenType = "password_reset"
 	TokenTypeRefresh           TokenType = "refresh"
+	TokenTypeMFAChallenge      TokenType = "mfa_challenge"
+	TokenTypeMFAEnrollment     TokenType = "mfa_enrollment"
+	TokenTypeMagicLink         TokenType = "magic_link"
+	TokenTypeAccountUnlock     TokenType = "account_unlock"
+	TokenTypePhoneChallenge    TokenType = "phone_challenge"
+	TokenTypeEmailChange       TokenType = "email_change"
+	TokenTypeEmailRevert       TokenType = "email_revert"
+	TokenTypeAccountRestore    TokenType = "account_restore"
 )
 
 type Session interface {
+	GetID() uuid.UUID
 	GetUserID() uuid.UUID
 	GetToken() string
 	GetRefreshToken() string
 	GetExpiresAt() time.Time
 	GetRefreshExpiresAt() time.Time
+	GetUserAgent() string
+	GetIPAddress() string
+	GetCreatedAt() time.Time
+	GetLastSeenAt() time.Time
+	GetImpersonatorID() uuid.UUID
 	IsExpired() bool
 	IsRefreshExpired() bool
 }
 
+// MFAChallenge is returned by Login instead of a Session when a second
+// factor is needed. EnrollmentRequired is set when the account has no MFA yet
+// but one of its roles requires it; the token then allows setting it up.
+type MFAChallenge struct {
+	MFARequired        bool      `json:"mfa_required"`
+	EnrollmentRequired bool      `json:"enrollment_required"`
+	Token              string    `json:"mfa_token"`
+	ExpiresAt          time.Time `json:"expires_at"`
+}
+
+// PhoneChallenge is returned by Login instead of a Session when the account
+// must verify its phone number first. A code was texted to the phone;
+// VerifyPhoneChallenge with the token and that code continues the login.
+// PhoneRequired means the account has no phone yet and nothing was sent:
+// SetChallengePhone with the token adds one and texts the code.
+type PhoneChallenge struct {
+	PhoneVerificationRequired bool      `json:"phone_verification_required"`
+	PhoneRequired             bool      `json:"phone_required"`
+	Token                     string    `json:"phone_token"`
+	ExpiresAt                 time.Time `json:"expires_at"`
+}
+
+// LoginResult holds either an issued Session or a pending MFA or phone
+// challenge. RecoveryCodes is only set when MFA was enrolled while logging
+// in. LinkedIdentity is set instead of all of them when an OAuth callback
+// completed linking a provider to an already signed-in account.
+type LoginResult struct {
+	Session        Session
+	Challenge      *MFAChallenge
+	PhoneChallenge *PhoneChallenge
+	RecoveryCodes  []string
+	LinkedIdentity Identity
+}
+
+// AccountDataExport is the archive returned by a data export. Data holds
+// each installed module's export, keyed by module name.
+type AccountDataExport struct {
+	AccountID  uuid.UUID      `json:"account_id"`
+	ExportedAt time.Time      `json:"exported_at"`
+	Data       map[string]any `json:"data"`
+}
+
+type MFASetup struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
 type LoginRequest interface {
 	GetStrategy() string
 	GetCredentials() map[string]any
@@ -68,15 +143,59 @@ type RegisterRequest interface {
 
 type AuthService interface {
 	Register(ctx context.Context, req RegisterRequest) (Account, error)
-	Login(ctx context.Context, req LoginRequest) (Session, error)
+	Login(ctx context.Context, req LoginRequest) (*LoginResult, error)
 	RefreshSession(ctx context.Context, refreshToken string) (Session, error)
-	Logout(ctx context.Context, userID uuid.UUID) error
+	Logout(ctx context.Context, token string) error
+	LogoutAll(ctx context.Context, userID uuid.UUID) error
+	ListSessions(ctx context.Context, userID uuid.UUID) ([]Session, error)
+	RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error
 	
 	SendEmailVerification(ctx context.Context, accountID uuid.UUID) error
 	VerifyEmail(ctx context.Context, token string) error
 	
 	SendPhoneVerification(ctx context.Context, accountID uuid.UUID) error
 	VerifyPhone(ctx context.Context, accountID uuid.UUID, code string) error
+	VerifyPhoneChallenge(ctx context.Context, phoneToken, code string) (*LoginResult, error)
+	SetChallengePhone(ctx context.Context, phoneToken, phone string) error
+	
+	SetupMFA(ctx context.Context, accountID uuid.UUID) (*MFASetup, error)
+	SetupMFAForChallenge(ctx context.Context, mfaToken string) (*MFASetup, error)
+	ConfirmMFA(ctx context.Context, accountID uuid.UUID, code string) ([]string, error)
+	VerifyMFA(ctx context.Context, mfaToken, code string) (*LoginResult, error)
+	DisableMFA(ctx context.Context, accountID uuid.UUID, code string) error
+	
+	SendMagicLink(ctx context.Context, email string) (string, error)
+	VerifyMagicLink(ctx context.Context, token, deviceBinding string) (*LoginResult, error)
+	
+	BeginPasskeyRegistration(ctx context.Context, accountID uuid.UUID) (*WebAuthnCreationOptions, error)
+	FinishPasskeyRegistration(ctx context.Context, accountID uuid.UUID, name string, credential *WebAuthnCredentialResponse) (WebAuthnCredential, error)
+	BeginPasskeyLogin(ctx context.Context, email string) (*WebAuthnRequestOptions, error)
+	FinishPasskeyLogin(ctx context.Context, credential *WebAuthnCredentialResponse) (*LoginResult, error)
+	ListPasskeys(ctx context.Context, accountID uuid.UUID) ([]WebAuthnCredential, error)
+	DeletePasskey(ctx context.Context, accountID, passkeyID uuid.UUID) error
+	
+	UnlockAccount(ctx context.Context, token string) error
+	
+	StartImpersonation(ctx context.Context, impersonatorID, targetID uuid.UUID, reason string) (Session, error)
+	StopImpersonation(ctx context.Context, token string) error
+	
+	ListLoginHistory(ctx context.Context, accountID uuid.UUID, limit int) ([]LoginHistoryEntry, error)
+	
+	CreateAPIKey(ctx context.Context, accountID uuid.UUID, opts CreateAPIKeyOptions) (*CreatedAPIKey, error)
+	ListAPIKeys(ctx context.Context, accountID uuid.UUID) ([]APIKey, error)
+	RevokeAPIKey(ctx context.Context, accountID, keyID uuid.UUID) error
+	AuthenticateAPIKey(ctx context.Context, rawKey, ipAddress string) (Account, APIKey, error)
+	
+	RequestEmailChange(ctx context.Context, accountID uuid.UUID, password, newEmail string) error
+	ConfirmEmailChange(ctx context.Context, token string) error
+	RevertEmailChange(ctx context.Context, token string) error
+	
+	DeleteAccount(ctx context.Context, accountID uuid.UUID, password string) (time.Time, error)
+	RestoreAccount(ctx context.Context, token string) error
+	PurgeDeletedAccounts(ctx context.Context) error
+	ExportAccountData(ctx context.Context, accountID uuid.UUID) (*AccountDataExport, error)
+	ExportUserData(ctx context.Context, subject core.DataSubject) (any, error)
+	DeleteUserData(ctx context.Context, subject core.DataSubject) error
 	
 	SendPasswordReset(ctx context.Context, email string) error
 	ResetPassword(ctx context.Context, token, newPassword string) error
@@ -87,11 +206,18 @@ type AuthService interface {
 	UpdateAccount(ctx context.Context, accountID uuid.UUID, updates AccountUpdates) (Account, error)
 	
 	ValidateSession(ctx context.Context, token string) (Account, error)
+	AuthenticateToken(ctx context.Context, token string) (Account, Session, error)
+	GetJWKS(ctx context.Context) JSONWebKeySet
 	
-	// OAuth methods
-	GetOAuthURL(ctx context.Context, provider string, state string) (string, error)
-	HandleOAuthCallback(ctx context.Context, provider string, code string, state string) (Session, error)
+	// OAuth methods. The URL methods also return the state, which the
+	// caller ties to the browser so the callback can check it came back to
+	// the browser that started the flow.
+	GetOAuthURL(ctx context.Context, provider string) (authURL, state string, err error)
+	GetOAuthLinkURL(ctx context.Context, accountID uuid.UUID, provider string) (authURL, state string, err error)
+	HandleOAuthCallback(ctx context.Context, provider string, code string, state string) (*LoginResult, error)
 	GetAvailableProviders(ctx context.Context) []string
+	ListIdentities(ctx context.Context, accountID uuid.UUID) ([]Identity, error)
+	UnlinkIdentity(ctx context.Context, accountID, identityID uuid.UUID) error
 }
 
 type AccountRepository interface {
@@ -104,6 +230,9 @@ type AccountRepository interface {
 	Exists(ctx context.Context, id uuid.UUID) (bool, error)
 	ExistsByEmail(ctx context.Context, email string) (bool, error)
 	ExistsByPhone(ctx context.Context, phone string) (bool, error)
+	ListDeletedBefore(ctx context.Context, before time.Time) ([]Account, error)
+	Restore(ctx context.Context, id uuid.UUID) error
+	Purge(ctx context.Context, id uuid.UUID) error
 }
 
 type TokenRepository interface {
@@ -114,20 +243,92 @@ type TokenRepository interface {
 	Delete(ctx context.Context, id uuid.UUID) error
 	DeleteExpired(ctx context.Context) error
 	MarkAsUsed(ctx context.Context, id uuid.UUID) error
+	ListByAccount(ctx context.Context, accountID uuid.UUID) ([]Token, error)
+	DeleteByAccount(ctx context.Context, accountID uuid.UUID) error
+}
+
+type RecoveryCodeRepository interface {
+	Replace(ctx context.Context, accountID uuid.UUID, codeHashes []string) error
+	Consume(ctx context.Context, accountID uuid.UUID, codeHash string) (bool, error)
+	DeleteByAccount(ctx context.Context, accountID uuid.UUID) error
 }
 
+// ErrRefreshTokenReused is returned by SessionStore.Rotate when the refresh
+// token was already exchanged.
+var ErrRefreshTokenReused = errors.New("refresh token already used")
+
+// SessionStore keeps every session of a user, one per device. Delete removes
+// all of a user's sessions; DeleteByID and DeleteByToken remove a single one.
+// A session keeps its ID across refreshes, so the ID also names the family of
+// refresh tokens issued to that device.
 type SessionStore interface {
 	Store(ctx context.Context, session Session) error
 	Get(ctx context.Context, token string) (Session, error)
+	GetByID(ctx context.Context, sessionID uuid.UUID) (Session, error)
 	GetByRefreshToken(ctx context.Context, refreshToken string) (Session, error)
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]Session, error)
+	Rotate(ctx context.Context, refreshToken string, next Session) error
+	GetFamilyByRotatedToken(ctx context.Context, refreshToken string) (uuid.UUID, error)
+	Touch(ctx context.Context, sessionID uuid.UUID, seenAt time.Time) error
 	Delete(ctx context.Context, userID uuid.UUID) error
+	DeleteByID(ctx context.Context, sessionID uuid.UUID) error
 	DeleteByToken(ctx context.Context, token string) error
 	DeleteExpired(ctx context.Context) error
 }
 
+// Backends selectable with AUTH_SESSION_STORE. It decides where sessions,
+// revocations, login attempts and pending OAuth and WebAuthn ceremonies are
+// kept.
+const (
+	SessionStoreRedis  = "redis"
+	SessionStoreGORM   = "gorm"
+	SessionStoreMemory = "memory"
+)
+
+// ExpiringStore is implemented by stores that keep expired entries until
+// DeleteExpired removes them. Redis expires keys on its own.
+type ExpiringStore interface {
+	DeleteExpired(ctx context.Context) error
+}
+
+// RevocationList remembers sessions that ended before their access tokens
+// expired, so stateless validation can reject those tokens.
+type RevocationList interface {
+	Revoke(ctx context.Context, sessionID uuid.UUID, until time.Time) error
+	IsRevoked(ctx context.Context, sessionID uuid.UUID) (bool, error)
+}
+
+// PasswordHasher hashes and verifies passwords. NeedsRehash reports hashes
+// made with an outdated algorithm or parameters, which the service replaces
+// after the next successful login.
 type PasswordHasher interface {
 	Hash(password string) (string, error)
 	Verify(password, hash string) error
+	NeedsRehash(hash string) bool
+}
+
+const (
+	PasswordHashArgon2id = "argon2id"
+	PasswordHashBcrypt   = "bcrypt"
+)
+
+// Argon2Params tunes Argon2id password hashing. Memory is in KiB.
+type Argon2Params struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// CookieSettings configures cookie mode, where sessions are kept in HttpOnly
+// cookies instead of being handed to scripts, and unsafe requests must echo
+// the CSRF cookie in a header. SameSite None requires Secure.
+type CookieSettings struct {
+	Enabled  bool
+	Domain   string
+	SameSite http.SameSite
+	Secure   bool
 }
 
 type TokenGenerator interface {
@@ -135,15 +336,30 @@ type TokenGenerator interface {
 	GenerateSecureToken() string
 }
 
+// TOTPProvider implements RFC 6238 time-based one-time passwords. Validate
+// returns the matched time step so callers can reject replayed codes.
+type TOTPProvider interface {
+	GenerateSecret() (string, error)
+	ProvisioningURI(secret, accountName string) string
+	Validate(secret, code string, at time.Time) (int64, bool)
+}
+
 type EmailSender interface {
 	SendVerificationEmail(email, token string) error
 	SendPasswordResetEmail(email, token string) error
 	SendWelcomeEmail(email string) error
+	SendMagicLinkEmail(email, token string) error
+	SendAccountUnlockEmail(email, token string) error
+	SendEmailChangeConfirmation(newEmail, token string) error
+	SendEmailChangedNotification(oldEmail, newEmail, revertToken string) error
+	SendAccountDeletionScheduled(email, restoreToken string, purgeAt time.Time) error
+	SendNewDeviceLogin(email, userAgent, ipAddress string, loggedInAt time.Time) error
 }
 
 
+// AccountUpdates changes profile fields. The email address is changed with
+// RequestEmailChange instead, which confirms the new address first.
 type AccountUpdates struct {
-	Email         *string
 	Phone         *string
 	EmailVerified *bool
 	PhoneVerified *bool
@@ -151,11 +367,44 @@ type AccountUpdates struct {
 
 type AuthConfig interface {
 	GetJWTSecret() string
+	GetJWTIssuer() string
+	GetJWTAudience() []string
+	IsRoleClaimsEnabled() bool
+	IsStatelessValidation() bool
 	GetJWTExpiration() time.Duration
 	GetRefreshTokenExpiration() time.Duration
 	GetVerificationTokenExpiration() time.Duration
 	GetPasswordResetTokenExpiration() time.Duration
+	GetEmailChangeRevertExpiration() time.Duration
+	GetAccountDeletionGracePeriod() time.Duration
+	GetImpersonationDuration() time.Duration
+	GetCookieSettings() CookieSettings
+	IsNewDeviceAlertEnabled() bool
+	GetSessionStore() string
+	GetPasswordHashAlgorithm() string
 	GetBcryptCost() int
+	GetArgon2Params() Argon2Params
+	GetPasswordRules() PasswordRules
 	IsEmailVerificationRequired() bool
 	IsPhoneVerificationRequired() bool
+	GetPhoneVerificationExpiration() time.Duration
+	GetPhoneVerificationMaxAttempts() int
+	GetPhoneVerificationResendInterval() time.Duration
+	GetPhoneVerificationsPerHour() int
+	GetMFAChallengeExpiration() time.Duration
+	GetMFAMaxAttempts() int
+	GetMFARequiredRoles() []string
+	GetOAuthStateExpiration() time.Duration
+	GetMagicLinkExpiration() time.Duration
+	GetMagicLinkRequestsPerHour() int
+	IsMagicLinkDeviceBindingEnabled() bool
+	GetWebAuthnRPID() string
+	GetWebAuthnRPName() string
+	GetWebAuthnOrigins() []string
+	GetWebAuthnTimeout() time.Duration
+	GetMaxFailedLogins() int
+	GetMaxFailedLoginsPerIP() int
+	GetFailedLoginWindow() time.Duration
+	GetLockoutDuration() time.Duration
+	GetLoginDelayBase() time.Duration
 }
\ No newline at end of file
@@ -0,0 +1,63 @@
+package authinterface
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AuthEventType string
+
+const (
+	AuthEventRegistered               AuthEventType = "registered"
+	AuthEventLoggedIn                 AuthEventType = "logged_in"
+	AuthEventLoginFailed              AuthEventType = "login_failed"
+	AuthEventLoggedOut                AuthEventType = "logged_out"
+	AuthEventPasswordChanged          AuthEventType = "password_changed"
+	AuthEventPasswordReset            AuthEventType = "password_reset"
+	AuthEventEmailVerified            AuthEventType = "email_verified"
+	AuthEventOAuthLinked              AuthEventType = "oauth_linked"
+	AuthEventRefreshTokenReused       AuthEventType = "refresh_token_reused"
+	AuthEventPasskeyCounterRegression AuthEventType = "passkey_counter_regression"
+	AuthEventAccountLocked            AuthEventType = "account_locked"
+	AuthEventAccountDeletionScheduled AuthEventType = "account_deletion_scheduled"
+	AuthEventAccountRestored          AuthEventType = "account_restored"
+	AuthEventAccountPurged            AuthEventType = "account_purged"
+	AuthEventImpersonationStarted     AuthEventType = "impersonation_started"
+	AuthEventImpersonationStopped     AuthEventType = "impersonation_stopped"
+	AuthEventAPIKeyCreated            AuthEventType = "api_key_created"
+	AuthEventAPIKeyRevoked            AuthEventType = "api_key_revoked"
+)
+
+// AuthEvent describes a security relevant event raised by the auth service.
+type AuthEvent struct {
+	Type       AuthEventType
+	AccountID  uuid.UUID
+	SessionID  uuid.UUID
+	IPAddress  string
+	UserAgent  string
+	OccurredAt time.Time
+	Metadata   map[string]any
+}
+
+// AuthEventHook receives auth events. Implementations must not block; the
+// service calls them inline on the request path.
+type AuthEventHook interface {
+	HandleAuthEvent(ctx context.Context, event AuthEvent)
+}
+
+// AuthEventHookFunc adapts a function to AuthEventHook.
+type AuthEventHookFunc func(ctx context.Context, event AuthEvent)
+
+func (f AuthEventHookFunc) HandleAuthEvent(ctx context.Context, event AuthEvent) {
+	f(ctx, event)
+}
+
+// AuthEventDispatcher is the AuthEventHook the service reports to. It passes
+// each event on to the hooks subscribed to its type, or to every event when
+// no types are given, so other modules can react to auth events.
+type AuthEventDispatcher interface {
+	AuthEventHook
+	Subscribe(hook AuthEventHook, types ...AuthEventType)
+}
@@ -0,0 +1,46 @@
+package authinterface
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Identity links an account to a user at an external provider. Accounts are
+// found by (provider, provider user id) rather than by email, so a provider
+// that lets users pick any address cannot be used to take over an account.
+type Identity interface {
+	GetID() uuid.UUID
+	GetAccountID() uuid.UUID
+	GetProvider() string
+	GetProviderUserID() string
+	GetEmail() string
+	GetCreatedAt() time.Time
+}
+
+type IdentityRepository interface {
+	Create(ctx context.Context, identity Identity) error
+	GetByProvider(ctx context.Context, provider, providerUserID string) (Identity, error)
+	ListByAccount(ctx context.Context, accountID uuid.UUID) ([]Identity, error)
+	Delete(ctx context.Context, accountID, identityID uuid.UUID) error
+}
+
+// OAuthState is what the server remembers between redirecting to a provider
+// and handling its callback. AccountID is set when a logged-in user is
+// linking a provider rather than signing in.
+type OAuthState struct {
+	State        string    `json:"state"`
+	Provider     string    `json:"provider"`
+	Nonce        string    `json:"nonce"`
+	CodeVerifier string    `json:"code_verifier"`
+	AccountID    uuid.UUID `json:"account_id"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// OAuthStateStore keeps pending authorization requests. Consume returns and
+// deletes the state in one step so a callback cannot be replayed.
+type OAuthStateStore interface {
+	Save(ctx context.Context, state *OAuthState, expiration time.Duration) error
+	Consume(ctx context.Context, state string) (*OAuthState, error)
+}
@@ -0,0 +1,35 @@
+package authinterface
+
+// SigningKey is a JWT key. For HMAC keys Private and Public hold the same
+// shared secret; for asymmetric keys Public is the matching public key.
+type SigningKey struct {
+	ID        string
+	Algorithm string
+	Private   any
+	Public    any
+}
+
+// KeyProvider supplies the key used to sign new tokens and every key tokens
+// may still be verified with, so keys can be rotated without logging users
+// out. PublicKeys lists the asymmetric keys published in the JWKS.
+type KeyProvider interface {
+	SigningKey() *SigningKey
+	VerificationKey(kid string) (*SigningKey, error)
+	PublicKeys() []*SigningKey
+}
+
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
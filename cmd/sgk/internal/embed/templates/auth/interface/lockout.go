@@ -0,0 +1,38 @@
+package authinterface
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// LoginAttempts is the failed login state of one key (an email or an IP).
+type LoginAttempts struct {
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   time.Time
+}
+
+func (a *LoginAttempts) IsLocked(now time.Time) bool {
+	return now.Before(a.LockedUntil)
+}
+
+// LoginAttemptTracker counts failed logins. Failures are forgotten once no
+// new failure was recorded for window; Lock also clears the count so a
+// fresh allowance starts when the lock ends.
+type LoginAttemptTracker interface {
+	Get(ctx context.Context, key string) (*LoginAttempts, error)
+	RecordFailure(ctx context.Context, key string, window time.Duration) (*LoginAttempts, error)
+	Lock(ctx context.Context, key string, until time.Time) error
+	Reset(ctx context.Context, key string) error
+}
+
+// LoginBlockedError is the cause attached to lockout and throttling errors
+// so handlers can tell clients when to retry.
+type LoginBlockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginBlockedError) Error() string {
+	return fmt.Sprintf("retry after %s", e.RetryAfter.Round(time.Second))
+}
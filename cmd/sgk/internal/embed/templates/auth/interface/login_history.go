@@ -0,0 +1,35 @@
+package authinterface
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LoginHistoryEntry is one sign-in attempt on an account, or the start or
+// end of an impersonation of it. Device is the user agent without version
+// numbers, so browser updates are not reported as new devices.
+type LoginHistoryEntry interface {
+	GetID() uuid.UUID
+	GetAccountID() uuid.UUID
+	GetSessionID() *uuid.UUID
+	GetSuccess() bool
+	GetMethod() string
+	GetFailureReason() string
+	GetIPAddress() string
+	GetUserAgent() string
+	GetDevice() string
+	GetNewDevice() bool
+	GetImpersonatorID() *uuid.UUID
+	GetReason() string
+	GetCreatedAt() time.Time
+}
+
+type LoginHistoryRepository interface {
+	Create(ctx context.Context, entry LoginHistoryEntry) error
+	ListByAccount(ctx context.Context, accountID uuid.UUID, limit int) ([]LoginHistoryEntry, error)
+	HasLoggedIn(ctx context.Context, accountID uuid.UUID) (bool, error)
+	HasLoggedInFrom(ctx context.Context, accountID uuid.UUID, device string) (bool, error)
+	DeleteByAccount(ctx context.Context, accountID uuid.UUID) error
+}
@@ -0,0 +1,83 @@
+package authinterface
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// PasswordRules configures the default password policy. HistorySize is the
+// number of previous passwords that may not be reused; zero disables the
+// check.
+type PasswordRules struct {
+	MinLength          int
+	MaxLength          int
+	RequireUppercase   bool
+	RequireLowercase   bool
+	RequireDigit       bool
+	RequireSymbol      bool
+	DisallowUserInputs bool
+	HistorySize        int
+}
+
+// PasswordCandidate is a password about to be set. AccountID and
+// CurrentHash are empty on registration. UserInputs are values tied to the
+// user, such as the email or name, that the password must not contain.
+type PasswordCandidate struct {
+	Password    string
+	AccountID   uuid.UUID
+	CurrentHash string
+	UserInputs  []string
+}
+
+// PasswordPolicy decides whether a password may be set. Validate returns a
+// *PasswordPolicyError listing every rule the password breaks. Remember is
+// called with the hash once a password was stored so reuse can be checked,
+// and Forget drops what was remembered when the account is erased.
+type PasswordPolicy interface {
+	Validate(ctx context.Context, candidate PasswordCandidate) error
+	Remember(ctx context.Context, accountID uuid.UUID, passwordHash string) error
+	Forget(ctx context.Context, accountID uuid.UUID) error
+}
+
+// BreachedPasswordChecker reports passwords known from data breaches.
+type BreachedPasswordChecker interface {
+	IsBreached(password string) bool
+}
+
+type PasswordHistoryRepository interface {
+	Add(ctx context.Context, accountID uuid.UUID, passwordHash string) error
+	ListRecent(ctx context.Context, accountID uuid.UUID, limit int) ([]string, error)
+	Prune(ctx context.Context, accountID uuid.UUID, keep int) error
+	DeleteByAccount(ctx context.Context, accountID uuid.UUID) error
+}
+
+const (
+	PasswordViolationTooShort         = "too_short"
+	PasswordViolationTooLong          = "too_long"
+	PasswordViolationMissingUppercase = "missing_uppercase"
+	PasswordViolationMissingLowercase = "missing_lowercase"
+	PasswordViolationMissingDigit     = "missing_digit"
+	PasswordViolationMissingSymbol    = "missing_symbol"
+	PasswordViolationUserInput        = "contains_user_input"
+	PasswordViolationReused           = "reused"
+	PasswordViolationBreached         = "breached"
+)
+
+type PasswordViolation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type PasswordPolicyError struct {
+	Violations []PasswordViolation `json:"violations"`
+}
+
+func (e *PasswordPolicyError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		messages[i] = violation.Message
+	}
+	return strings.Join(messages, "; ")
+}
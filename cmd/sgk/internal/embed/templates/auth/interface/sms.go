@@ -0,0 +1,5 @@
+package authinterface
+
+type SMSSender interface {
+	SendVerificationSMS(phone, code string) error
+}
@@ -15,24 +15,44 @@ type AuthStrategy interface {
 
 type OAuthStrategy interface {
 	AuthStrategy
-	GetAuthURL(state string) string
-	ExchangeCode(ctx context.Context, code string) (*OAuthTokens, error)
+	GetAuthURL(request OAuthRequest) string
+	ExchangeCode(ctx context.Context, exchange OAuthExchange) (*OAuthTokens, error)
 	GetUserInfo(ctx context.Context, tokens *OAuthTokens) (*OAuthUserInfo, error)
 }
 
 type AuthStrategyType string
 
 const (
-	StrategyTypeLocal AuthStrategyType = "local"
-	StrategyTypeOAuth AuthStrategyType = "oauth"
-	StrategyTypeSAML  AuthStrategyType = "saml"
+	StrategyTypeLocal        AuthStrategyType = "local"
+	StrategyTypeOAuth        AuthStrategyType = "oauth"
+	StrategyTypeSAML         AuthStrategyType = "saml"
+	StrategyTypePasswordless AuthStrategyType = "passwordless"
 )
 
+// OAuthRequest carries the values bound to one authorization request.
+// CodeChallenge is the S256 PKCE challenge for the stored code verifier.
+type OAuthRequest struct {
+	State         string
+	Nonce         string
+	CodeChallenge string
+}
+
+// OAuthExchange is what the callback needs to redeem an authorization code.
+type OAuthExchange struct {
+	Code         string
+	CodeVerifier string
+	Nonce        string
+}
+
+// OAuthTokens are the provider's tokens. Nonce is the value the ID token
+// must carry, copied from the exchange.
 type OAuthTokens struct {
 	AccessToken  string
 	RefreshToken string
 	ExpiresIn    int
 	TokenType    string
+	IDToken      string
+	Nonce        string
 }
 
 type OAuthUserInfo struct {
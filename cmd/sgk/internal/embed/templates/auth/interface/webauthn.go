@@ -0,0 +1,122 @@
+package authinterface
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebAuthnCredential is a passkey registered to an account. PublicKey holds
+// the COSE encoded key exactly as the authenticator returned it.
+type WebAuthnCredential interface {
+	GetID() uuid.UUID
+	GetAccountID() uuid.UUID
+	GetCredentialID() string
+	GetPublicKey() []byte
+	GetSignCount() uint32
+	GetTransports() []string
+	GetName() string
+	GetCreatedAt() time.Time
+	GetLastUsedAt() *time.Time
+}
+
+type WebAuthnCredentialRepository interface {
+	Create(ctx context.Context, credential WebAuthnCredential) error
+	GetByCredentialID(ctx context.Context, credentialID string) (WebAuthnCredential, error)
+	ListByAccount(ctx context.Context, accountID uuid.UUID) ([]WebAuthnCredential, error)
+	UpdateSignCount(ctx context.Context, id uuid.UUID, signCount uint32, usedAt time.Time) error
+	Delete(ctx context.Context, accountID, id uuid.UUID) error
+}
+
+type WebAuthnCeremony string
+
+const (
+	WebAuthnCeremonyRegistration WebAuthnCeremony = "registration"
+	WebAuthnCeremonyLogin        WebAuthnCeremony = "login"
+)
+
+// WebAuthnSession is a pending ceremony, keyed by its challenge. AccountID
+// is the registering account, or for logins the account that was named up
+// front (uuid.Nil for discoverable credentials).
+type WebAuthnSession struct {
+	Challenge string           `json:"challenge"`
+	Ceremony  WebAuthnCeremony `json:"ceremony"`
+	AccountID uuid.UUID        `json:"account_id"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// WebAuthnSessionStore keeps pending ceremonies. Consume deletes the session
+// so each challenge can be answered once.
+type WebAuthnSessionStore interface {
+	Save(ctx context.Context, session *WebAuthnSession, expiration time.Duration) error
+	Consume(ctx context.Context, challenge string) (*WebAuthnSession, error)
+}
+
+// The types below mirror the WebAuthn JSON serialisation used by
+// navigator.credentials.create/get and PublicKeyCredential.toJSON();
+// binary fields are base64url encoded.
+
+type WebAuthnRelyingParty struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type WebAuthnUser struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+type WebAuthnCredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int64  `json:"alg"`
+}
+
+type WebAuthnCredentialDescriptor struct {
+	Type       string   `json:"type"`
+	ID         string   `json:"id"`
+	Transports []string `json:"transports,omitempty"`
+}
+
+type WebAuthnAuthenticatorSelection struct {
+	ResidentKey      string `json:"residentKey"`
+	UserVerification string `json:"userVerification"`
+}
+
+type WebAuthnCreationOptions struct {
+	Challenge              string                         `json:"challenge"`
+	RP                     WebAuthnRelyingParty           `json:"rp"`
+	User                   WebAuthnUser                   `json:"user"`
+	PubKeyCredParams       []WebAuthnCredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int64                          `json:"timeout"`
+	ExcludeCredentials     []WebAuthnCredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection WebAuthnAuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                         `json:"attestation"`
+}
+
+type WebAuthnRequestOptions struct {
+	Challenge        string                         `json:"challenge"`
+	Timeout          int64                          `json:"timeout"`
+	RPID             string                         `json:"rpId"`
+	AllowCredentials []WebAuthnCredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                         `json:"userVerification"`
+}
+
+type WebAuthnAuthenticatorResponse struct {
+	ClientDataJSON    string   `json:"clientDataJSON"`
+	AttestationObject string   `json:"attestationObject,omitempty"`
+	Transports        []string `json:"transports,omitempty"`
+	AuthenticatorData string   `json:"authenticatorData,omitempty"`
+	Signature         string   `json:"signature,omitempty"`
+	UserHandle        string   `json:"userHandle,omitempty"`
+}
+
+// WebAuthnCredentialResponse is the PublicKeyCredential returned by the
+// browser at the end of either ceremony.
+type WebAuthnCredentialResponse struct {
+	ID       string                        `json:"id"`
+	RawID    string                        `json:"rawId"`
+	Type     string                        `json:"type"`
+	Response WebAuthnAuthenticatorResponse `json:"response"`
+}
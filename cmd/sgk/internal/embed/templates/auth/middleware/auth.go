@@ -7,9 +7,17 @@ import (
 	authconstants "{{.Project.GoModule}}/internal/auth/constants"
 	authinterface "{{.Project.GoModule}}/internal/auth/interface"
 	"{{.Project.GoModule}}/internal/core"
+	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 )
 
+// API keys are accepted from the X-API-Key header or as an "ApiKey"
+// authorization, alongside the configured session token.
+const (
+	apiKeyHeader = "X-API-Key"
+	apiKeyScheme = "ApiKey "
+)
+
 type AuthMiddleware struct {
 	authService authinterface.AuthService
 	config      MiddlewareConfig
@@ -20,6 +28,8 @@ type MiddlewareConfig struct {
 	TokenLookup  string // "header:Authorization" or "cookie:token"
 	TokenPrefix  string // "Bearer " for header
 	ErrorHandler func(c echo.Context, err error) error
+	RoleLookup   core.RoleLookup // checks RequirePermission, denies all when nil
+	CookieMode   bool            // reads the access cookie first and checks CSRF tokens
 }
 
 func DefaultConfig() MiddlewareConfig {
@@ -49,6 +59,8 @@ func NewAuthMiddleware(authService authinterface.AuthService, config ...Middlewa
 		if config[0].ErrorHandler != nil {
 			cfg.ErrorHandler = config[0].ErrorHandler
 		}
+		cfg.RoleLookup = config[0].RoleLookup
+		cfg.CookieMode = config[0].CookieMode
 	}
 	
 	return &AuthMiddleware{
@@ -64,19 +76,32 @@ func (m *AuthMiddleware) RequireAuth() echo.MiddlewareFunc {
 				return next(c)
 			}
 			
-			token, err := m.extractToken(c)
+			if key := extractAPIKey(c); key != "" {
+				account, apiKey, err := m.authService.AuthenticateAPIKey(c.Request().Context(), key, c.RealIP())
+				if err != nil {
+					return m.config.ErrorHandler(c, err)
+				}
+				
+				setAPIKeyContext(c, account, apiKey)
+				
+				return next(c)
+			}
+			
+			token, fromCookie, err := m.sessionToken(c)
 			if err != nil {
 				return m.config.ErrorHandler(c, err)
 			}
 			
-			account, err := m.authService.ValidateSession(c.Request().Context(), token)
+			if fromCookie && !ValidCSRFToken(c) {
+				return core.Forbidden(c, fmt.Errorf(authconstants.ErrInvalidCSRFToken))
+			}
+			
+			account, session, err := m.authService.AuthenticateToken(c.Request().Context(), token)
 			if err != nil {
 				return m.config.ErrorHandler(c, err)
 			}
 			
-			c.Set(authconstants.ContextKeyUserID, account.GetID())
-			c.Set(authconstants.ContextKeyAccount, account)
-			c.Set(authconstants.ContextKeyIsAuthenticated, true)
+			setAuthContext(c, account, session)
 			
 			return next(c)
 		}
@@ -90,27 +115,124 @@ func (m *AuthMiddleware) OptionalAuth() echo.MiddlewareFunc {
 				return next(c)
 			}
 			
-			token, err := m.extractToken(c)
-			if err != nil || token == "" {
+			if key := extractAPIKey(c); key != "" {
+				account, apiKey, err := m.authService.AuthenticateAPIKey(c.Request().Context(), key, c.RealIP())
+				if err != nil {
+					c.Set(authconstants.ContextKeyIsAuthenticated, false)
+					return next(c)
+				}
+				
+				setAPIKeyContext(c, account, apiKey)
+				
+				return next(c)
+			}
+			
+			token, fromCookie, err := m.sessionToken(c)
+			if err != nil || token == "" || (fromCookie && !ValidCSRFToken(c)) {
 				c.Set(authconstants.ContextKeyIsAuthenticated, false)
 				return next(c)
 			}
 			
-			account, err := m.authService.ValidateSession(c.Request().Context(), token)
+			account, session, err := m.authService.AuthenticateToken(c.Request().Context(), token)
 			if err != nil {
 				c.Set(authconstants.ContextKeyIsAuthenticated, false)
 				return next(c)
 			}
 			
-			c.Set(authconstants.ContextKeyUserID, account.GetID())
-			c.Set(authconstants.ContextKeyAccount, account)
-			c.Set(authconstants.ContextKeyIsAuthenticated, true)
+			setAuthContext(c, account, session)
+			
+			return next(c)
+		}
+	}
+}
+
+// RequirePermission lets the request through when the authenticated user
+// holds permission and, for API keys, the key's scopes grant it.
+// Impersonation sessions are always rejected, so staff permissions are never
+// exercised on a customer's behalf. It must run after RequireAuth.
+func (m *AuthMiddleware) RequirePermission(permission string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			userID, err := GetUserIDFromContext(c)
+			if err != nil {
+				return m.config.ErrorHandler(c, err)
+			}
+			
+			if IsImpersonating(c) || m.config.RoleLookup == nil || !core.RequestAllows(c, permission) {
+				return core.Forbidden(c, fmt.Errorf(authconstants.ErrPermissionDenied))
+			}
+			
+			allowed, err := m.config.RoleLookup.UserHasPermission(c.Request().Context(), userID, permission)
+			if err != nil {
+				return core.InternalServerError(c, fmt.Errorf("failed to check permissions"))
+			}
+			if !allowed {
+				return core.Forbidden(c, fmt.Errorf(authconstants.ErrPermissionDenied))
+			}
 			
 			return next(c)
 		}
 	}
 }
 
+// BlockImpersonation rejects requests made with an impersonation session.
+// It guards account security settings such as the password and MFA, which
+// only the account owner may change.
+func (m *AuthMiddleware) BlockImpersonation() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if IsImpersonating(c) {
+				return core.Forbidden(c, fmt.Errorf(authconstants.ErrImpersonationForbidden))
+			}
+			return next(c)
+		}
+	}
+}
+
+// BlockAPIKeys rejects requests authenticated with an API key. It guards
+// routes that manage credentials, so a leaked key cannot be used to mint
+// new keys or take over the account.
+func (m *AuthMiddleware) BlockAPIKeys() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if GetAPIKeyFromContext(c) != nil {
+				return core.Forbidden(c, fmt.Errorf(authconstants.ErrAPIKeyForbidden))
+			}
+			return next(c)
+		}
+	}
+}
+
+// CaptureClientInfo records the caller's user agent and IP address in the
+// request context so new sessions can be attributed to a device.
+func (m *AuthMiddleware) CaptureClientInfo() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := authinterface.WithClientInfo(c.Request().Context(), authinterface.ClientInfo{
+				UserAgent: c.Request().UserAgent(),
+				IPAddress: c.RealIP(),
+			})
+			c.SetRequest(c.Request().WithContext(ctx))
+			
+			return next(c)
+		}
+	}
+}
+
+// sessionToken reads the access token from its cookie in cookie mode and
+// otherwise, or for clients that send a header instead, from the configured
+// lookup. It reports whether the token came from the cookie.
+func (m *AuthMiddleware) sessionToken(c echo.Context) (string, bool, error) {
+	if m.config.CookieMode {
+		if cookie, err := c.Cookie(authconstants.CookieAccessToken); err == nil && cookie.Value != "" {
+			return cookie.Value, true, nil
+		}
+	}
+	
+	token, err := m.extractToken(c)
+	return token, false, err
+}
+
 func (m *AuthMiddleware) extractToken(c echo.Context) (string, error) {
 	parts := strings.Split(m.config.TokenLookup, ":")
 	if len(parts) != 2 {
@@ -143,4 +265,39 @@ func (m *AuthMiddleware) extractToken(c echo.Context) (string, error) {
 	default:
 		return "", echo.NewHTTPError(500, "unsupported token lookup method")
 	}
+}
+
+func setAuthContext(c echo.Context, account authinterface.Account, session authinterface.Session) {
+	c.Set(authconstants.ContextKeyUserID, account.GetID())
+	c.Set(authconstants.ContextKeyAccount, account)
+	c.Set(authconstants.ContextKeySession, session)
+	c.Set(authconstants.ContextKeyIsAuthenticated, true)
+	if impersonatorID := session.GetImpersonatorID(); impersonatorID != uuid.Nil {
+		c.Set(authconstants.ContextKeyImpersonatorID, impersonatorID)
+	}
+}
+
+// extractAPIKey returns the API key the request carries, if any.
+func extractAPIKey(c echo.Context) string {
+	if key := c.Request().Header.Get(apiKeyHeader); key != "" {
+		return strings.TrimSpace(key)
+	}
+	
+	header := c.Request().Header.Get(echo.HeaderAuthorization)
+	if len(header) > len(apiKeyScheme) && strings.EqualFold(header[:len(apiKeyScheme)], apiKeyScheme) {
+		return strings.TrimSpace(header[len(apiKeyScheme):])
+	}
+	return ""
+}
+
+// setAPIKeyContext fills the same keys as a session login, without a
+// session, and limits the request to the key's scopes when it has any.
+func setAPIKeyContext(c echo.Context, account authinterface.Account, apiKey authinterface.APIKey) {
+	c.Set(authconstants.ContextKeyUserID, account.GetID())
+	c.Set(authconstants.ContextKeyAccount, account)
+	c.Set(authconstants.ContextKeyAPIKey, apiKey)
+	c.Set(authconstants.ContextKeyIsAuthenticated, true)
+	if scopes := apiKey.GetScopes(); len(scopes) > 0 {
+		c.Set(core.ContextKeyScopes, scopes)
+	}
 }
\ No newline at end of file
@@ -0,0 +1,54 @@
+package authmiddleware
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+
+	authconstants "{{.Project.GoModule}}/internal/auth/constants"
+	"{{.Project.GoModule}}/internal/core"
+	"github.com/labstack/echo/v4"
+)
+
+// RequireCSRF checks the CSRF token of unsafe requests that carry a session
+// cookie. RequireAuth already checks requests authenticated by the access
+// cookie; this covers routes that read the refresh cookie. It does nothing
+// outside cookie mode.
+func (m *AuthMiddleware) RequireCSRF() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if m.config.CookieMode && hasSessionCookie(c) && !ValidCSRFToken(c) {
+				return core.Forbidden(c, fmt.Errorf(authconstants.ErrInvalidCSRFToken))
+			}
+			return next(c)
+		}
+	}
+}
+
+// ValidCSRFToken reports whether the request passes the double-submit check:
+// safe methods always do, others must send the CSRF cookie's value in the
+// CSRF header. A cross-site page can make the browser send the cookie but
+// cannot read it to set the header.
+func ValidCSRFToken(c echo.Context) bool {
+	switch c.Request().Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+
+	cookie, err := c.Cookie(authconstants.CookieCSRFToken)
+	if err != nil || cookie.Value == "" {
+		return false
+	}
+
+	header := c.Request().Header.Get(authconstants.HeaderCSRFToken)
+	return subtle.ConstantTimeCompare([]byte(header), []byte(cookie.Value)) == 1
+}
+
+func hasSessionCookie(c echo.Context) bool {
+	for _, name := range []string{authconstants.CookieAccessToken, authconstants.CookieRefreshToken} {
+		if cookie, err := c.Cookie(name); err == nil && cookie.Value != "" {
+			return true
+		}
+	}
+	return false
+}
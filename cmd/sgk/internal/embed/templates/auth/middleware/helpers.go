@@ -41,6 +41,29 @@ func GetSessionFromContext(c echo.Context) authinterface.Session {
 	return nil
 }
 
+// GetImpersonatorIDFromContext returns the staff account acting as the
+// authenticated user, if the request uses an impersonation session.
+func GetImpersonatorIDFromContext(c echo.Context) (uuid.UUID, bool) {
+	if impersonatorID, ok := c.Get(authconstants.ContextKeyImpersonatorID).(uuid.UUID); ok {
+		return impersonatorID, true
+	}
+	return uuid.Nil, false
+}
+
+func IsImpersonating(c echo.Context) bool {
+	_, ok := GetImpersonatorIDFromContext(c)
+	return ok
+}
+
+// GetAPIKeyFromContext returns the API key the request was authenticated
+// with, or nil for session requests.
+func GetAPIKeyFromContext(c echo.Context) authinterface.APIKey {
+	if apiKey, ok := c.Get(authconstants.ContextKeyAPIKey).(authinterface.APIKey); ok {
+		return apiKey
+	}
+	return nil
+}
+
 func IsAuthenticated(c echo.Context) bool {
 	if isAuth := c.Get(authconstants.ContextKeyIsAuthenticated); isAuth != nil {
 		if value, ok := isAuth.(bool); ok {
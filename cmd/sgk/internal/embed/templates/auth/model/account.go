@@ -4,17 +4,22 @@ import (
 	"time"
 	
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type Account struct {
-	ID            uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	Email         string    `json:"email" gorm:"uniqueIndex;not null"`
-	Phone         string    `json:"phone,omitempty" gorm:"uniqueIndex"`
-	PasswordHash  string    `json:"-" gorm:"not null"`
-	EmailVerified bool      `json:"email_verified" gorm:"default:false"`
-	PhoneVerified bool      `json:"phone_verified" gorm:"default:false"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID              uuid.UUID      `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Email           string         `json:"email" gorm:"uniqueIndex;not null"`
+	Phone           string         `json:"phone,omitempty" gorm:"uniqueIndex"`
+	PasswordHash    string         `json:"-" gorm:"not null"`
+	EmailVerified   bool           `json:"email_verified" gorm:"default:false"`
+	PhoneVerified   bool           `json:"phone_verified" gorm:"default:false"`
+	MFAEnabled      bool           `json:"mfa_enabled" gorm:"default:false"`
+	MFASecret       string         `json:"-"`
+	MFALastUsedStep int64          `json:"-" gorm:"default:0"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
 func (a *Account) GetID() uuid.UUID {
@@ -41,6 +46,18 @@ func (a *Account) GetPhoneVerified() bool {
 	return a.PhoneVerified
 }
 
+func (a *Account) GetMFAEnabled() bool {
+	return a.MFAEnabled
+}
+
+func (a *Account) GetMFASecret() string {
+	return a.MFASecret
+}
+
+func (a *Account) GetMFALastUsedStep() int64 {
+	return a.MFALastUsedStep
+}
+
 func (a *Account) GetCreatedAt() time.Time {
 	return a.CreatedAt
 }
@@ -57,6 +74,26 @@ func (a *Account) SetEmailVerified(verified bool) {
 	a.EmailVerified = verified
 }
 
+func (a *Account) SetEmail(email string) {
+	a.Email = email
+}
+
+func (a *Account) SetPhone(phone string) {
+	a.Phone = phone
+}
+
 func (a *Account) SetPhoneVerified(verified bool) {
 	a.PhoneVerified = verified
+}
+
+func (a *Account) SetMFASecret(secret string) {
+	a.MFASecret = secret
+}
+
+func (a *Account) SetMFAEnabled(enabled bool) {
+	a.MFAEnabled = enabled
+}
+
+func (a *Account) SetMFALastUsedStep(step int64) {
+	a.MFALastUsedStep = step
 }
\ No newline at end of file
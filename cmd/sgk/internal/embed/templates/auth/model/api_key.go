@@ -0,0 +1,65 @@
+package authmodel
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type APIKey struct {
+	ID         uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	AccountID  uuid.UUID  `json:"account_id" gorm:"type:uuid;not null;index"`
+	Name       string     `json:"name" gorm:"not null"`
+	Prefix     string     `json:"prefix" gorm:"uniqueIndex;not null"`
+	SecretHash string     `json:"-" gorm:"not null"`
+	Scopes     []string   `json:"scopes" gorm:"serializer:json"`
+	AllowedIPs []string   `json:"allowed_ips" gorm:"serializer:json"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+func (k *APIKey) GetID() uuid.UUID {
+	return k.ID
+}
+
+func (k *APIKey) GetAccountID() uuid.UUID {
+	return k.AccountID
+}
+
+func (k *APIKey) GetName() string {
+	return k.Name
+}
+
+func (k *APIKey) GetPrefix() string {
+	return k.Prefix
+}
+
+func (k *APIKey) GetSecretHash() string {
+	return k.SecretHash
+}
+
+func (k *APIKey) GetScopes() []string {
+	return k.Scopes
+}
+
+func (k *APIKey) GetAllowedIPs() []string {
+	return k.AllowedIPs
+}
+
+func (k *APIKey) GetExpiresAt() *time.Time {
+	return k.ExpiresAt
+}
+
+func (k *APIKey) GetLastUsedAt() *time.Time {
+	return k.LastUsedAt
+}
+
+func (k *APIKey) GetCreatedAt() time.Time {
+	return k.CreatedAt
+}
+
+func (k *APIKey) IsExpired() bool {
+	return k.ExpiresAt != nil && time.Now().After(*k.ExpiresAt)
+}
@@ -0,0 +1,35 @@
+package authmodel
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// The models below hold short-lived auth state when it is kept in the
+// database instead of Redis. Rows are ignored once ExpiresAt has passed and
+// removed by the stores' DeleteExpired.
+
+// LoginAttempt is the failed login count of one throttling key.
+type LoginAttempt struct {
+	Key           string `gorm:"primary_key"`
+	Failures      int    `gorm:"not null;default:0"`
+	LastFailureAt time.Time
+	LockedUntil   time.Time
+	ExpiresAt     time.Time `gorm:"not null;index"`
+}
+
+// RevokedSession is a session whose access tokens must be rejected until
+// they expire.
+type RevokedSession struct {
+	SessionID uuid.UUID `gorm:"type:uuid;primary_key"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
+
+// PendingState is an OAuth authorization request or WebAuthn ceremony
+// waiting for its callback, stored as JSON.
+type PendingState struct {
+	Key       string    `gorm:"primary_key"`
+	Data      string    `gorm:"type:text;not null"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
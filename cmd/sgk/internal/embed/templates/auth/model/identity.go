@@ -0,0 +1,41 @@
+package authmodel
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Identity struct {
+	ID             uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	AccountID      uuid.UUID `json:"account_id" gorm:"type:uuid;not null;index"`
+	Provider       string    `json:"provider" gorm:"not null;uniqueIndex:idx_identity_provider_user"`
+	ProviderUserID string    `json:"provider_user_id" gorm:"not null;uniqueIndex:idx_identity_provider_user"`
+	Email          string    `json:"email"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func (i *Identity) GetID() uuid.UUID {
+	return i.ID
+}
+
+func (i *Identity) GetAccountID() uuid.UUID {
+	return i.AccountID
+}
+
+func (i *Identity) GetProvider() string {
+	return i.Provider
+}
+
+func (i *Identity) GetProviderUserID() string {
+	return i.ProviderUserID
+}
+
+func (i *Identity) GetEmail() string {
+	return i.Email
+}
+
+func (i *Identity) GetCreatedAt() time.Time {
+	return i.CreatedAt
+}
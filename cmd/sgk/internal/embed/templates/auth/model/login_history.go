@@ -0,0 +1,77 @@
+package authmodel
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type LoginHistory struct {
+	ID            uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	AccountID     uuid.UUID  `json:"account_id" gorm:"type:uuid;not null;index:idx_login_history_account_device"`
+	SessionID     *uuid.UUID `json:"session_id,omitempty" gorm:"type:uuid"`
+	Success       bool       `json:"success"`
+	Method        string     `json:"method,omitempty"`
+	FailureReason string     `json:"failure_reason,omitempty"`
+	IPAddress     string     `json:"ip_address,omitempty"`
+	UserAgent     string     `json:"user_agent,omitempty"`
+	Device        string     `json:"-" gorm:"index:idx_login_history_account_device"`
+	NewDevice     bool       `json:"new_device"`
+	// ImpersonatorID and Reason are set on the entries that record staff
+	// starting and stopping an impersonation of the account.
+	ImpersonatorID *uuid.UUID `json:"impersonator_id,omitempty" gorm:"type:uuid;index"`
+	Reason         string     `json:"reason,omitempty"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"index"`
+}
+
+func (h *LoginHistory) GetID() uuid.UUID {
+	return h.ID
+}
+
+func (h *LoginHistory) GetAccountID() uuid.UUID {
+	return h.AccountID
+}
+
+func (h *LoginHistory) GetSessionID() *uuid.UUID {
+	return h.SessionID
+}
+
+func (h *LoginHistory) GetSuccess() bool {
+	return h.Success
+}
+
+func (h *LoginHistory) GetMethod() string {
+	return h.Method
+}
+
+func (h *LoginHistory) GetFailureReason() string {
+	return h.FailureReason
+}
+
+func (h *LoginHistory) GetIPAddress() string {
+	return h.IPAddress
+}
+
+func (h *LoginHistory) GetUserAgent() string {
+	return h.UserAgent
+}
+
+func (h *LoginHistory) GetDevice() string {
+	return h.Device
+}
+
+func (h *LoginHistory) GetNewDevice() bool {
+	return h.NewDevice
+}
+
+func (h *LoginHistory) GetImpersonatorID() *uuid.UUID {
+	return h.ImpersonatorID
+}
+
+func (h *LoginHistory) GetReason() string {
+	return h.Reason
+}
+
+func (h *LoginHistory) GetCreatedAt() time.Time {
+	return h.CreatedAt
+}
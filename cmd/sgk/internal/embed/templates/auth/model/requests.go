@@ -49,13 +49,22 @@ func (r *LoginRequest) Validate() error {
 		if !ok || password == "" {
 			return fmt.Errorf(authconstants.ErrInvalidPassword)
 		}
-	case "google", "github", "facebook":
+	case "magic_link":
+		token, ok := creds["token"].(string)
+		if !ok || token == "" {
+			return fmt.Errorf(authconstants.ErrInvalidToken)
+		}
+	case "webauthn":
+		if _, ok := creds["credential"].(map[string]any); !ok {
+			return fmt.Errorf("passkey credential is required")
+		}
+	default:
+		// OAuth and OIDC providers; unknown names are rejected by the
+		// strategy registry.
 		code, ok := creds["code"].(string)
 		if !ok || code == "" {
 			return fmt.Errorf("authorization code is required")
 		}
-	default:
-		return fmt.Errorf("unsupported authentication strategy: %s", strategy)
 	}
 	
 	return nil
@@ -64,7 +73,7 @@ func (r *LoginRequest) Validate() error {
 type RegisterRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Phone    string `json:"phone,omitempty" validate:"omitempty,e164"`
-	Password string `json:"password" validate:"required,min=8"`
+	Password string `json:"password" validate:"required"`
 }
 
 func (r *RegisterRequest) GetEmail() string {
@@ -89,8 +98,9 @@ func (r *RegisterRequest) Validate() error {
 	if r.GetPhone() != "" && !phoneRegex.MatchString(r.GetPhone()) {
 		return fmt.Errorf(authconstants.ErrInvalidPhone)
 	}
-	if len(r.GetPassword()) < 8 {
-		return fmt.Errorf(authconstants.ErrPasswordTooWeak)
+	// Strength rules are enforced by the configured PasswordPolicy.
+	if r.GetPassword() == "" {
+		return fmt.Errorf(authconstants.ErrInvalidPassword)
 	}
 	return nil
 }
\ No newline at end of file
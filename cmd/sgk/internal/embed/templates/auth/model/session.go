@@ -7,11 +7,29 @@ import (
 )
 
 type Session struct {
-	UserID             uuid.UUID `json:"user_id"`
-	Token              string    `json:"token"`
-	RefreshToken       string    `json:"refresh_token"`
-	ExpiresAt          time.Time `json:"expires_at"`
-	RefreshExpiresAt   time.Time `json:"refresh_expires_at"`
+	ID                 uuid.UUID  `json:"id" gorm:"type:uuid;primary_key"`
+	UserID             uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	Token              string     `json:"token" gorm:"uniqueIndex;not null"`
+	RefreshToken       string     `json:"refresh_token" gorm:"index"`
+	ExpiresAt          time.Time  `json:"expires_at" gorm:"not null"`
+	RefreshExpiresAt   time.Time  `json:"refresh_expires_at" gorm:"not null;index"`
+	UserAgent          string     `json:"user_agent,omitempty"`
+	IPAddress          string     `json:"ip_address,omitempty"`
+	CreatedAt          time.Time  `json:"created_at"`
+	LastSeenAt         time.Time  `json:"last_seen_at"`
+	ImpersonatorID     *uuid.UUID `json:"impersonator_id,omitempty" gorm:"type:uuid"`
+}
+
+// RotatedRefreshToken remembers a refresh token that was exchanged, so a
+// later attempt to use it again can be traced to its session family.
+type RotatedRefreshToken struct {
+	Token     string    `gorm:"primary_key"`
+	SessionID uuid.UUID `gorm:"type:uuid;not null"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
+
+func (s *Session) GetID() uuid.UUID {
+	return s.ID
 }
 
 func (s *Session) GetUserID() uuid.UUID {
@@ -34,6 +52,31 @@ func (s *Session) GetRefreshExpiresAt() time.Time {
 	return s.RefreshExpiresAt
 }
 
+func (s *Session) GetUserAgent() string {
+	return s.UserAgent
+}
+
+func (s *Session) GetIPAddress() string {
+	return s.IPAddress
+}
+
+func (s *Session) GetCreatedAt() time.Time {
+	return s.CreatedAt
+}
+
+func (s *Session) GetLastSeenAt() time.Time {
+	return s.LastSeenAt
+}
+
+// GetImpersonatorID returns the staff account acting as the user, or
+// uuid.Nil for a session the user started themselves.
+func (s *Session) GetImpersonatorID() uuid.UUID {
+	if s.ImpersonatorID == nil {
+		return uuid.Nil
+	}
+	return *s.ImpersonatorID
+}
+
 func (s *Session) IsExpired() bool {
 	return time.Now().After(s.ExpiresAt)
 }
@@ -8,14 +8,20 @@ import (
 )
 
 type Token struct {
-	ID        uuid.UUID                `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	AccountID uuid.UUID                `json:"account_id" gorm:"type:uuid;not null;index"`
-	Token     string                   `json:"token" gorm:"uniqueIndex;not null"`
-	Type      authinterface.TokenType  `json:"type" gorm:"not null;index"`
-	Used      bool                     `json:"used" gorm:"default:false;index"`
-	ExpiresAt time.Time                `json:"expires_at" gorm:"not null;index"`
-	CreatedAt time.Time                `json:"created_at"`
-	UpdatedAt time.Time                `json:"updated_at"`
+	ID        uuid.UUID               `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	AccountID uuid.UUID               `json:"account_id" gorm:"type:uuid;not null;index"`
+	Token     string                  `json:"token" gorm:"uniqueIndex;not null"`
+	Type      authinterface.TokenType `json:"type" gorm:"not null;index"`
+	Used      bool                    `json:"used" gorm:"default:false;index"`
+	ExpiresAt time.Time               `json:"expires_at" gorm:"not null;index"`
+	// DeviceBinding is the hash of a secret held by the device that asked
+	// for the token, for flows that must be completed on that device.
+	DeviceBinding string `json:"-"`
+	// Email is the address an email change token switches to, or the
+	// address a revert token restores.
+	Email     string    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 func (t *Token) GetID() uuid.UUID {
@@ -42,6 +48,14 @@ func (t *Token) GetExpiresAt() time.Time {
 	return t.ExpiresAt
 }
 
+func (t *Token) GetDeviceBinding() string {
+	return t.DeviceBinding
+}
+
+func (t *Token) GetEmail() string {
+	return t.Email
+}
+
 func (t *Token) GetCreatedAt() time.Time {
 	return t.CreatedAt
 }
@@ -0,0 +1,56 @@
+package authmodel
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WebAuthnCredential struct {
+	ID           uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	AccountID    uuid.UUID  `json:"account_id" gorm:"type:uuid;not null;index"`
+	CredentialID string     `json:"credential_id" gorm:"uniqueIndex;not null"`
+	PublicKey    []byte     `json:"-" gorm:"not null"`
+	SignCount    uint32     `json:"sign_count" gorm:"default:0"`
+	Transports   []string   `json:"transports" gorm:"serializer:json"`
+	Name         string     `json:"name"`
+	LastUsedAt   *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+func (c *WebAuthnCredential) GetID() uuid.UUID {
+	return c.ID
+}
+
+func (c *WebAuthnCredential) GetAccountID() uuid.UUID {
+	return c.AccountID
+}
+
+func (c *WebAuthnCredential) GetCredentialID() string {
+	return c.CredentialID
+}
+
+func (c *WebAuthnCredential) GetPublicKey() []byte {
+	return c.PublicKey
+}
+
+func (c *WebAuthnCredential) GetSignCount() uint32 {
+	return c.SignCount
+}
+
+func (c *WebAuthnCredential) GetTransports() []string {
+	return c.Transports
+}
+
+func (c *WebAuthnCredential) GetName() string {
+	return c.Name
+}
+
+func (c *WebAuthnCredential) GetCreatedAt() time.Time {
+	return c.CreatedAt
+}
+
+func (c *WebAuthnCredential) GetLastUsedAt() *time.Time {
+	return c.LastUsedAt
+}
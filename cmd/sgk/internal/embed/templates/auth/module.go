@@ -1,8 +1,14 @@
 package auth
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/redis/go-redis/v9"
@@ -13,14 +19,22 @@ import (
 	authinterface "{{.Project.GoModule}}/internal/auth/interface"
 	authmiddleware "{{.Project.GoModule}}/internal/auth/middleware"
 	authgorm "{{.Project.GoModule}}/internal/auth/repository/gorm"
+	authmemory "{{.Project.GoModule}}/internal/auth/repository/memory"
 	authredis "{{.Project.GoModule}}/internal/auth/repository/redis"
 	authservice "{{.Project.GoModule}}/internal/auth/service"
 	"{{.Project.GoModule}}/internal/core"
 	emailinterface "{{.Project.GoModule}}/internal/email/interface"
 )
 
+// ProvideRedisClient fails unless AUTH_SESSION_STORE selects Redis, so
+// optional consumers such as the health checks skip it.
 func ProvideRedisClient(i *do.Injector) (*redis.Client, error) {
 	config := do.MustInvoke[*core.Config](i)
+	authConfig := do.MustInvoke[authinterface.AuthConfig](i)
+
+	if authConfig.GetSessionStore() != authinterface.SessionStoreRedis {
+		return nil, fmt.Errorf("redis is not used with AUTH_SESSION_STORE=%s", authConfig.GetSessionStore())
+	}
 
 	opts, err := redis.ParseURL(config.RedisURL)
 	if err != nil {
@@ -46,19 +60,226 @@ func ProvideTokenRepository(i *do.Injector) (authinterface.TokenRepository, erro
 	return authgorm.NewTokenRepository(db), nil
 }
 
+func ProvideRecoveryCodeRepository(i *do.Injector) (authinterface.RecoveryCodeRepository, error) {
+	db := do.MustInvoke[*gorm.DB](i)
+	return authgorm.NewRecoveryCodeRepository(db), nil
+}
+
+func ProvideIdentityRepository(i *do.Injector) (authinterface.IdentityRepository, error) {
+	db := do.MustInvoke[*gorm.DB](i)
+	return authgorm.NewIdentityRepository(db), nil
+}
+
+func ProvidePasswordHistoryRepository(i *do.Injector) (authinterface.PasswordHistoryRepository, error) {
+	db := do.MustInvoke[*gorm.DB](i)
+	return authgorm.NewPasswordHistoryRepository(db), nil
+}
+
+func ProvideOAuthStateStore(i *do.Injector) (authinterface.OAuthStateStore, error) {
+	switch backend := sessionStoreBackend(i); backend {
+	case authinterface.SessionStoreRedis:
+		return authredis.NewOAuthStateStore(do.MustInvoke[*redis.Client](i), "oauth_state"), nil
+	case authinterface.SessionStoreGORM:
+		return authgorm.NewOAuthStateStore(do.MustInvoke[*storeDB](i).db, "oauth_state"), nil
+	case authinterface.SessionStoreMemory:
+		return authmemory.NewOAuthStateStore(), nil
+	default:
+		return nil, unsupportedSessionStore(backend)
+	}
+}
+
+func ProvideWebAuthnCredentialRepository(i *do.Injector) (authinterface.WebAuthnCredentialRepository, error) {
+	db := do.MustInvoke[*gorm.DB](i)
+	return authgorm.NewWebAuthnCredentialRepository(db), nil
+}
+
+func ProvideAPIKeyRepository(i *do.Injector) (authinterface.APIKeyRepository, error) {
+	db := do.MustInvoke[*gorm.DB](i)
+	return authgorm.NewAPIKeyRepository(db), nil
+}
+
+func ProvideLoginHistoryRepository(i *do.Injector) (authinterface.LoginHistoryRepository, error) {
+	db := do.MustInvoke[*gorm.DB](i)
+	return authgorm.NewLoginHistoryRepository(db), nil
+}
+
+func ProvideWebAuthnSessionStore(i *do.Injector) (authinterface.WebAuthnSessionStore, error) {
+	switch backend := sessionStoreBackend(i); backend {
+	case authinterface.SessionStoreRedis:
+		return authredis.NewWebAuthnSessionStore(do.MustInvoke[*redis.Client](i), "webauthn"), nil
+	case authinterface.SessionStoreGORM:
+		return authgorm.NewWebAuthnSessionStore(do.MustInvoke[*storeDB](i).db, "webauthn"), nil
+	case authinterface.SessionStoreMemory:
+		return authmemory.NewWebAuthnSessionStore(), nil
+	default:
+		return nil, unsupportedSessionStore(backend)
+	}
+}
+
+func ProvideLoginAttemptTracker(i *do.Injector) (authinterface.LoginAttemptTracker, error) {
+	switch backend := sessionStoreBackend(i); backend {
+	case authinterface.SessionStoreRedis:
+		return authredis.NewLoginAttemptTracker(do.MustInvoke[*redis.Client](i), "login_attempts"), nil
+	case authinterface.SessionStoreGORM:
+		return authgorm.NewLoginAttemptTracker(do.MustInvoke[*storeDB](i).db), nil
+	case authinterface.SessionStoreMemory:
+		return authmemory.NewLoginAttemptTracker(), nil
+	default:
+		return nil, unsupportedSessionStore(backend)
+	}
+}
+
 func ProvideSessionStore(i *do.Injector) (authinterface.SessionStore, error) {
-	redisClient := do.MustInvoke[*redis.Client](i)
-	return authredis.NewSessionStore(redisClient, "session"), nil
+	switch backend := sessionStoreBackend(i); backend {
+	case authinterface.SessionStoreRedis:
+		return authredis.NewSessionStore(do.MustInvoke[*redis.Client](i), "session"), nil
+	case authinterface.SessionStoreGORM:
+		return authgorm.NewSessionStore(do.MustInvoke[*storeDB](i).db), nil
+	case authinterface.SessionStoreMemory:
+		return authmemory.NewSessionStore(), nil
+	default:
+		return nil, unsupportedSessionStore(backend)
+	}
+}
+
+func ProvideRevocationList(i *do.Injector) (authinterface.RevocationList, error) {
+	switch backend := sessionStoreBackend(i); backend {
+	case authinterface.SessionStoreRedis:
+		return authredis.NewRevocationList(do.MustInvoke[*redis.Client](i), "revoked"), nil
+	case authinterface.SessionStoreGORM:
+		return authgorm.NewRevocationList(do.MustInvoke[*storeDB](i).db), nil
+	case authinterface.SessionStoreMemory:
+		return authmemory.NewRevocationList(), nil
+	default:
+		return nil, unsupportedSessionStore(backend)
+	}
+}
+
+// storeDB is the database used by the gorm session store backend, with its
+// tables migrated.
+type storeDB struct {
+	db *gorm.DB
 }
 
+func provideStoreDB(i *do.Injector) (*storeDB, error) {
+	db := do.MustInvoke[*gorm.DB](i)
+	if err := authgorm.AutoMigrateStores(db); err != nil {
+		return nil, fmt.Errorf("failed to run session store migrations: %w", err)
+	}
+	return &storeDB{db: db}, nil
+}
+
+// ProvideStoreCleanup sweeps expired entries from the gorm and memory
+// backends, which unlike Redis do not drop them on their own. Tokens live in
+// the database whatever the session store is.
+func ProvideStoreCleanup(i *do.Injector) (*authservice.StoreCleanup, error) {
+	stores := []authinterface.ExpiringStore{do.MustInvoke[authinterface.TokenRepository](i)}
+
+	interval := time.Minute
+	switch sessionStoreBackend(i) {
+	case authinterface.SessionStoreRedis:
+		return authservice.StartStoreCleanup(10*time.Minute, stores...), nil
+	case authinterface.SessionStoreGORM:
+		interval = 10 * time.Minute
+	}
+
+	for _, store := range []any{
+		do.MustInvoke[authinterface.SessionStore](i),
+		do.MustInvoke[authinterface.RevocationList](i),
+		do.MustInvoke[authinterface.LoginAttemptTracker](i),
+		do.MustInvoke[authinterface.OAuthStateStore](i),
+		do.MustInvoke[authinterface.WebAuthnSessionStore](i),
+	} {
+		if expiring, ok := store.(authinterface.ExpiringStore); ok {
+			stores = append(stores, expiring)
+		}
+	}
+
+	return authservice.StartStoreCleanup(interval, stores...), nil
+}
+
+func sessionStoreBackend(i *do.Injector) string {
+	return do.MustInvoke[authinterface.AuthConfig](i).GetSessionStore()
+}
+
+func unsupportedSessionStore(backend string) error {
+	return fmt.Errorf("unsupported AUTH_SESSION_STORE %q", backend)
+}
+
+// ProvidePasswordHasher hashes new passwords with the configured algorithm
+// and still verifies hashes made with the other one, so existing accounts
+// move over as they sign in.
 func ProvidePasswordHasher(i *do.Injector) (authinterface.PasswordHasher, error) {
-	return authservice.NewBcryptPasswordHasher(12), nil
+	authConfig := do.MustInvoke[authinterface.AuthConfig](i)
+
+	bcryptHasher := authservice.NewBcryptPasswordHasher(authConfig.GetBcryptCost())
+	argon2Hasher := authservice.NewArgon2idPasswordHasher(authConfig.GetArgon2Params())
+
+	switch authConfig.GetPasswordHashAlgorithm() {
+	case authinterface.PasswordHashArgon2id:
+		return authservice.NewMultiPasswordHasher(argon2Hasher, bcryptHasher), nil
+	case authinterface.PasswordHashBcrypt:
+		return authservice.NewMultiPasswordHasher(bcryptHasher, argon2Hasher), nil
+	default:
+		return nil, fmt.Errorf("unsupported password hash algorithm %q", authConfig.GetPasswordHashAlgorithm())
+	}
+}
+
+// ProvidePasswordPolicy enforces the configured password rules and, when
+// PASSWORD_BREACHED_LIST_FILE is set, rejects passwords found in that list.
+func ProvidePasswordPolicy(i *do.Injector) (authinterface.PasswordPolicy, error) {
+	authConfig := do.MustInvoke[authinterface.AuthConfig](i)
+	passwordHasher := do.MustInvoke[authinterface.PasswordHasher](i)
+	historyRepo := do.MustInvoke[authinterface.PasswordHistoryRepository](i)
+
+	var breached authinterface.BreachedPasswordChecker
+	if file := os.Getenv("PASSWORD_BREACHED_LIST_FILE"); file != "" {
+		checker, err := authservice.LoadBreachedPasswords(file)
+		if err != nil {
+			return nil, err
+		}
+		breached = checker
+	}
+
+	return authservice.NewDefaultPasswordPolicy(authConfig.GetPasswordRules(), passwordHasher, historyRepo, breached), nil
 }
 
 func ProvideTokenGenerator(i *do.Injector) (authinterface.TokenGenerator, error) {
 	return authservice.NewTokenGenerator(), nil
 }
 
+func ProvideTOTPProvider(i *do.Injector) (authinterface.TOTPProvider, error) {
+	issuer := os.Getenv("MFA_ISSUER")
+	if issuer == "" {
+		issuer = "{{.Project.Name}}"
+	}
+	return authservice.NewTOTPProvider(issuer), nil
+}
+
+// ProvideAuthEventDispatcher logs every auth event and keeps the login
+// history. Other modules subscribe to it to react to auth events.
+func ProvideAuthEventDispatcher(i *do.Injector) (authinterface.AuthEventDispatcher, error) {
+	authConfig := do.MustInvoke[authinterface.AuthConfig](i)
+
+	dispatcher := authservice.NewEventDispatcher()
+	dispatcher.Subscribe(authservice.NewLogEventHook())
+	dispatcher.Subscribe(
+		authservice.NewLoginHistoryRecorder(
+			do.MustInvoke[authinterface.LoginHistoryRepository](i),
+			do.MustInvoke[authinterface.AccountRepository](i),
+			do.MustInvoke[authinterface.EmailSender](i),
+			authConfig.IsNewDeviceAlertEnabled(),
+		),
+		authinterface.AuthEventLoggedIn,
+		authinterface.AuthEventLoginFailed,
+	)
+	return dispatcher, nil
+}
+
+func ProvideAuthEventHook(i *do.Injector) (authinterface.AuthEventHook, error) {
+	return do.MustInvoke[authinterface.AuthEventDispatcher](i), nil
+}
+
 func ProvideEmailSender(i *do.Injector) (authinterface.EmailSender, error) {
 	baseURL := os.Getenv("BASE_URL")
 	if baseURL == "" {
@@ -73,31 +294,261 @@ func ProvideEmailSender(i *do.Injector) (authinterface.EmailSender, error) {
 	return authservice.NewEmailSenderAdapter(emailService, baseURL), nil
 }
 
+// ProvideSMSSender posts to SMS_PROVIDER_URL when it is set and prints
+// messages to the console otherwise.
+func ProvideSMSSender(i *do.Injector) (authinterface.SMSSender, error) {
+	providerURL := os.Getenv("SMS_PROVIDER_URL")
+	if providerURL == "" {
+		return authservice.NewMockSMSSender(), nil
+	}
+
+	return authservice.NewHTTPSMSSender(authservice.HTTPSMSConfig{
+		URL:    providerURL,
+		APIKey: os.Getenv("SMS_API_KEY"),
+		From:   os.Getenv("SMS_FROM"),
+	}), nil
+}
+
 func ProvideAuthConfig(i *do.Injector) (authinterface.AuthConfig, error) {
-	return authservice.NewDefaultAuthConfig(), nil
+	config := do.MustInvoke[*core.Config](i)
+
+	authConfig := authservice.NewDefaultAuthConfig()
+	authConfig.SetJWTSecret(config.JWTSecret)
+
+	issuer := os.Getenv("JWT_ISSUER")
+	if issuer == "" {
+		issuer = "{{.Project.Name}}"
+	}
+	authConfig.SetJWTIssuer(issuer)
+
+	if audience := os.Getenv("JWT_AUDIENCE"); audience != "" {
+		var audiences []string
+		for _, aud := range strings.Split(audience, ",") {
+			if aud = strings.TrimSpace(aud); aud != "" {
+				audiences = append(audiences, aud)
+			}
+		}
+		authConfig.SetJWTAudience(audiences)
+	}
+
+	if value := os.Getenv("JWT_ROLE_CLAIMS"); value != "" {
+		authConfig.SetRoleClaimsEnabled(value == "true")
+	}
+	if algorithm := os.Getenv("PASSWORD_HASH_ALGORITHM"); algorithm != "" {
+		authConfig.SetPasswordHashAlgorithm(algorithm)
+	}
+	if value := os.Getenv("BCRYPT_COST"); value != "" {
+		if cost, err := strconv.Atoi(value); err == nil {
+			authConfig.SetBcryptCost(cost)
+		} else {
+			fmt.Printf("Invalid BCRYPT_COST %q: %v\n", value, err)
+		}
+	}
+	rules := authConfig.GetPasswordRules()
+	if value := os.Getenv("PASSWORD_MIN_LENGTH"); value != "" {
+		if length, err := strconv.Atoi(value); err == nil {
+			rules.MinLength = length
+		} else {
+			fmt.Printf("Invalid PASSWORD_MIN_LENGTH %q: %v\n", value, err)
+		}
+	}
+	if value := os.Getenv("PASSWORD_HISTORY"); value != "" {
+		if size, err := strconv.Atoi(value); err == nil {
+			rules.HistorySize = size
+		} else {
+			fmt.Printf("Invalid PASSWORD_HISTORY %q: %v\n", value, err)
+		}
+	}
+	for _, class := range strings.Split(os.Getenv("PASSWORD_REQUIRED_CLASSES"), ",") {
+		switch strings.TrimSpace(class) {
+		case "":
+		case "upper":
+			rules.RequireUppercase = true
+		case "lower":
+			rules.RequireLowercase = true
+		case "digit":
+			rules.RequireDigit = true
+		case "symbol":
+			rules.RequireSymbol = true
+		default:
+			fmt.Printf("Unknown password character class %q\n", class)
+		}
+	}
+	authConfig.SetPasswordRules(rules)
+
+	authConfig.SetPhoneVerificationRequired(os.Getenv("PHONE_VERIFICATION_REQUIRED") == "true")
+	authConfig.SetStatelessValidation(os.Getenv("AUTH_STATELESS_VALIDATION") == "true")
+	authConfig.SetMagicLinkDeviceBinding(os.Getenv("MAGIC_LINK_DEVICE_BINDING") == "true")
+	if value := os.Getenv("AUTH_NEW_DEVICE_ALERTS"); value != "" {
+		authConfig.SetNewDeviceAlertEnabled(value == "true")
+	}
+	if store := os.Getenv("AUTH_SESSION_STORE"); store != "" {
+		authConfig.SetSessionStore(store)
+	}
+
+	// Passkeys are bound to the frontend origin; the relying party id
+	// defaults to its host.
+	origins := []string{"http://localhost:8080"}
+	if baseURL := os.Getenv("BASE_URL"); baseURL != "" {
+		origins = []string{strings.TrimSuffix(baseURL, "/")}
+	}
+	if value := os.Getenv("WEBAUTHN_ORIGINS"); value != "" {
+		origins = nil
+		for _, origin := range strings.Split(value, ",") {
+			if origin = strings.TrimSuffix(strings.TrimSpace(origin), "/"); origin != "" {
+				origins = append(origins, origin)
+			}
+		}
+	}
+	rpID := os.Getenv("WEBAUTHN_RP_ID")
+	if rpID == "" {
+		rpID = "localhost"
+		if parsed, err := url.Parse(origins[0]); err == nil && parsed.Hostname() != "" {
+			rpID = parsed.Hostname()
+		}
+	}
+	rpName := os.Getenv("WEBAUTHN_RP_NAME")
+	if rpName == "" {
+		rpName = "{{.Project.Name}}"
+	}
+	authConfig.SetWebAuthnRelyingParty(rpID, rpName, origins)
+
+	maxFailed := authConfig.GetMaxFailedLogins()
+	if value := os.Getenv("AUTH_MAX_FAILED_LOGINS"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			maxFailed = parsed
+		} else {
+			fmt.Printf("Invalid AUTH_MAX_FAILED_LOGINS %q: %v\n", value, err)
+		}
+	}
+	maxFailedPerIP := authConfig.GetMaxFailedLoginsPerIP()
+	if value := os.Getenv("AUTH_MAX_FAILED_LOGINS_PER_IP"); value != "" {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			maxFailedPerIP = parsed
+		} else {
+			fmt.Printf("Invalid AUTH_MAX_FAILED_LOGINS_PER_IP %q: %v\n", value, err)
+		}
+	}
+	authConfig.SetMaxFailedLogins(maxFailed, maxFailedPerIP)
+	if value := os.Getenv("AUTH_LOCKOUT_DURATION"); value != "" {
+		if duration, err := time.ParseDuration(value); err == nil {
+			authConfig.SetLockoutDuration(duration)
+		} else {
+			fmt.Printf("Invalid AUTH_LOCKOUT_DURATION %q: %v\n", value, err)
+		}
+	}
+
+	if value := os.Getenv("AUTH_IMPERSONATION_DURATION"); value != "" {
+		if duration, err := time.ParseDuration(value); err == nil {
+			authConfig.SetImpersonationDuration(duration)
+		} else {
+			fmt.Printf("Invalid AUTH_IMPERSONATION_DURATION %q: %v\n", value, err)
+		}
+	}
+
+	cookies := authConfig.GetCookieSettings()
+	if value := os.Getenv("AUTH_COOKIE_MODE"); value != "" {
+		cookies.Enabled = value == "true"
+	}
+	if value := os.Getenv("AUTH_COOKIE_DOMAIN"); value != "" {
+		cookies.Domain = value
+	}
+	if value := os.Getenv("AUTH_COOKIE_SECURE"); value != "" {
+		cookies.Secure = value != "false"
+	}
+	switch value := strings.ToLower(os.Getenv("AUTH_COOKIE_SAMESITE")); value {
+	case "":
+	case "lax":
+		cookies.SameSite = http.SameSiteLaxMode
+	case "strict":
+		cookies.SameSite = http.SameSiteStrictMode
+	case "none":
+		cookies.SameSite = http.SameSiteNoneMode
+		cookies.Secure = true
+	default:
+		fmt.Printf("Invalid AUTH_COOKIE_SAMESITE %q, expected lax, strict or none\n", value)
+	}
+	authConfig.SetCookieSettings(cookies)
+
+	if value := os.Getenv("ACCOUNT_DELETION_GRACE_PERIOD"); value != "" {
+		if duration, err := time.ParseDuration(value); err == nil {
+			authConfig.SetAccountDeletionGracePeriod(duration)
+		} else {
+			fmt.Printf("Invalid ACCOUNT_DELETION_GRACE_PERIOD %q: %v\n", value, err)
+		}
+	}
+
+	if roles := os.Getenv("MFA_REQUIRED_ROLES"); roles != "" {
+		var required []string
+		for _, role := range strings.Split(roles, ",") {
+			if role = strings.TrimSpace(role); role != "" {
+				required = append(required, role)
+			}
+		}
+		authConfig.SetMFARequiredRoles(required)
+	}
+
+	return authConfig, nil
+}
+
+// ProvideKeyProvider signs tokens with JWT_SIGNING_KEY_FILE (RSA or Ed25519
+// PEM) when set, and with HS256 and JWT_SECRET otherwise.
+func ProvideKeyProvider(i *do.Injector) (authinterface.KeyProvider, error) {
+	signingKeyFile := os.Getenv("JWT_SIGNING_KEY_FILE")
+	if signingKeyFile == "" {
+		authConfig := do.MustInvoke[authinterface.AuthConfig](i)
+		return authservice.NewHMACKeyProvider(authConfig.GetJWTSecret()), nil
+	}
+
+	var verificationKeyFiles []string
+	for _, file := range strings.Split(os.Getenv("JWT_VERIFICATION_KEY_FILES"), ",") {
+		if file = strings.TrimSpace(file); file != "" {
+			verificationKeyFiles = append(verificationKeyFiles, file)
+		}
+	}
+
+	return authservice.NewPEMKeyProvider(signingKeyFile, verificationKeyFiles)
 }
 
 func ProvideStrategyRegistry(i *do.Injector) (authinterface.StrategyRegistry, error) {
 	registry := authservice.NewStrategyRegistry()
-	
+
 	// Register email/password strategy
 	accountRepo := do.MustInvoke[authinterface.AccountRepository](i)
 	passwordHasher := do.MustInvoke[authinterface.PasswordHasher](i)
+	identityRepo := do.MustInvoke[authinterface.IdentityRepository](i)
 	emailPasswordStrategy := authservice.NewEmailPasswordStrategy(accountRepo, passwordHasher)
 	if err := registry.Register(emailPasswordStrategy); err != nil {
 		return nil, err
 	}
-	
-	// Register OAuth strategies if configured
+
+	tokenRepo := do.MustInvoke[authinterface.TokenRepository](i)
+	if err := registry.Register(authservice.NewMagicLinkStrategy(accountRepo, tokenRepo)); err != nil {
+		return nil, err
+	}
+
+	webAuthnStrategy := authservice.NewWebAuthnStrategy(
+		accountRepo,
+		do.MustInvoke[authinterface.WebAuthnCredentialRepository](i),
+		do.MustInvoke[authinterface.WebAuthnSessionStore](i),
+		do.MustInvoke[authinterface.AuthConfig](i),
+		do.MustInvoke[authinterface.AuthEventHook](i),
+	)
+	if err := registry.Register(webAuthnStrategy); err != nil {
+		return nil, err
+	}
+
+	// Register the OAuth strategies chosen at generation time, if configured
 	googleClientID := os.Getenv("GOOGLE_OAUTH_CLIENT_ID")
 	googleClientSecret := os.Getenv("GOOGLE_OAUTH_CLIENT_SECRET")
-	if googleClientID != "" && googleClientSecret != "" {
+	if oauthProviderEnabled("google") && googleClientID != "" && googleClientSecret != "" {
 		googleRedirectURI := os.Getenv("GOOGLE_OAUTH_REDIRECT_URI")
 		if googleRedirectURI == "" {
-			googleRedirectURI = "http://localhost:8080/api/v1/auth/oauth/google/callback"
+			googleRedirectURI = "http://localhost:8080{{.Module.RoutePrefix}}/oauth/google/callback"
 		}
 		googleStrategy := authservice.NewGoogleOAuthStrategy(
 			accountRepo,
+			identityRepo,
 			googleClientID,
 			googleClientSecret,
 			googleRedirectURI,
@@ -106,51 +557,153 @@ func ProvideStrategyRegistry(i *do.Injector) (authinterface.StrategyRegistry, er
 			return nil, err
 		}
 	}
-	
+
+	githubClientID := os.Getenv("GITHUB_OAUTH_CLIENT_ID")
+	githubClientSecret := os.Getenv("GITHUB_OAUTH_CLIENT_SECRET")
+	if oauthProviderEnabled("github") && githubClientID != "" && githubClientSecret != "" {
+		githubRedirectURI := os.Getenv("GITHUB_OAUTH_REDIRECT_URI")
+		if githubRedirectURI == "" {
+			githubRedirectURI = "http://localhost:8080{{.Module.RoutePrefix}}/oauth/github/callback"
+		}
+		githubStrategy := authservice.NewGitHubOAuthStrategy(accountRepo, identityRepo, authservice.GitHubOAuthConfig{
+			ClientID:     githubClientID,
+			ClientSecret: githubClientSecret,
+			RedirectURI:  githubRedirectURI,
+			Enabled:      true,
+		})
+		if err := registry.Register(githubStrategy); err != nil {
+			return nil, err
+		}
+	}
+
+	oidcIssuerURL := os.Getenv("OIDC_ISSUER_URL")
+	oidcClientID := os.Getenv("OIDC_CLIENT_ID")
+	if oauthProviderEnabled("oidc") && oidcIssuerURL != "" && oidcClientID != "" {
+		oidcName := os.Getenv("OIDC_PROVIDER_NAME")
+		if oidcName == "" {
+			oidcName = "oidc"
+		}
+		oidcRedirectURI := os.Getenv("OIDC_REDIRECT_URI")
+		if oidcRedirectURI == "" {
+			oidcRedirectURI = "http://localhost:8080{{.Module.RoutePrefix}}/oauth/" + oidcName + "/callback"
+		}
+		var oidcScopes []string
+		if scopes := os.Getenv("OIDC_SCOPES"); scopes != "" {
+			oidcScopes = strings.Fields(strings.ReplaceAll(scopes, ",", " "))
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		oidcStrategy, err := authservice.NewOIDCStrategy(ctx, accountRepo, identityRepo, authservice.OIDCConfig{
+			Name:         oidcName,
+			IssuerURL:    oidcIssuerURL,
+			ClientID:     oidcClientID,
+			ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+			RedirectURI:  oidcRedirectURI,
+			Scopes:       oidcScopes,
+		})
+		if err != nil {
+			// A provider outage should not keep the app from starting.
+			fmt.Printf("Failed to configure OIDC provider %s: %v\n", oidcName, err)
+		} else if err := registry.Register(oidcStrategy); err != nil {
+			return nil, err
+		}
+	}
+
 	return registry, nil
 }
 
+func oauthProviderEnabled(name string) bool {
+	for _, provider := range strings.Split(oauthProviders, ",") {
+		if strings.TrimSpace(provider) == name {
+			return true
+		}
+	}
+	return false
+}
+
 func ProvideAuthService(i *do.Injector) (authinterface.AuthService, error) {
-	accountRepo := do.MustInvoke[authinterface.AccountRepository](i)
-	tokenRepo := do.MustInvoke[authinterface.TokenRepository](i)
-	sessionStore := do.MustInvoke[authinterface.SessionStore](i)
-	passwordHasher := do.MustInvoke[authinterface.PasswordHasher](i)
-	tokenGenerator := do.MustInvoke[authinterface.TokenGenerator](i)
-	emailSender := do.MustInvoke[authinterface.EmailSender](i)
-	authConfig := do.MustInvoke[authinterface.AuthConfig](i)
-	strategyRegistry := do.MustInvoke[authinterface.StrategyRegistry](i)
+	service := authservice.NewAuthService(
+		do.MustInvoke[authinterface.AccountRepository](i),
+		do.MustInvoke[authinterface.TokenRepository](i),
+		do.MustInvoke[authinterface.SessionStore](i),
+		do.MustInvoke[authinterface.PasswordHasher](i),
+		do.MustInvoke[authinterface.TokenGenerator](i),
+		do.MustInvoke[authinterface.AuthConfig](i),
+		authservice.AuthServiceDeps{
+			EmailSender:      do.MustInvoke[authinterface.EmailSender](i),
+			SMSSender:        do.MustInvoke[authinterface.SMSSender](i),
+			StrategyRegistry: do.MustInvoke[authinterface.StrategyRegistry](i),
+			RecoveryCodeRepo: do.MustInvoke[authinterface.RecoveryCodeRepository](i),
+			TOTPProvider:     do.MustInvoke[authinterface.TOTPProvider](i),
+			RoleLookup:       core.OptionalRoleLookup(i),
+			EventHook:        do.MustInvoke[authinterface.AuthEventHook](i),
+			KeyProvider:      do.MustInvoke[authinterface.KeyProvider](i),
+			RevocationList:   do.MustInvoke[authinterface.RevocationList](i),
+			IdentityRepo:     do.MustInvoke[authinterface.IdentityRepository](i),
+			OAuthStateStore:  do.MustInvoke[authinterface.OAuthStateStore](i),
+			CredentialRepo:   do.MustInvoke[authinterface.WebAuthnCredentialRepository](i),
+			WebAuthnSessions: do.MustInvoke[authinterface.WebAuthnSessionStore](i),
+			LoginAttempts:    do.MustInvoke[authinterface.LoginAttemptTracker](i),
+			PasswordPolicy:   do.MustInvoke[authinterface.PasswordPolicy](i),
+			APIKeyRepo:       do.MustInvoke[authinterface.APIKeyRepository](i),
+			LoginHistoryRepo: do.MustInvoke[authinterface.LoginHistoryRepository](i),
+			UserData:         do.MustInvoke[*core.UserDataRegistry](i),
+		},
+	)
 
-	return authservice.NewAuthService(
-		accountRepo,
-		tokenRepo,
-		sessionStore,
-		passwordHasher,
-		tokenGenerator,
-		emailSender,
-		authConfig,
-		strategyRegistry,
-	), nil
+	// Accounts deleted with a grace period are purged once it has passed.
+	service.StartAccountPurger(context.Background(), time.Hour)
+
+	// Expired auth state is swept until the container shuts down.
+	do.MustInvoke[*authservice.StoreCleanup](i)
+
+	return service, nil
 }
 
 func ProvideAuthMiddleware(i *do.Injector) (*authmiddleware.AuthMiddleware, error) {
 	authService := do.MustInvoke[authinterface.AuthService](i)
-	return authmiddleware.NewAuthMiddleware(authService), nil
+	authConfig := do.MustInvoke[authinterface.AuthConfig](i)
+	return authmiddleware.NewAuthMiddleware(authService, authmiddleware.MiddlewareConfig{
+		RoleLookup: core.OptionalRoleLookup(i),
+		CookieMode: authConfig.GetCookieSettings().Enabled,
+	}), nil
 }
 
 func ProvideAuthController(i *do.Injector) (*authcontroller.AuthController, error) {
 	authService := do.MustInvoke[authinterface.AuthService](i)
-	return authcontroller.NewAuthController(authService), nil
+	authConfig := do.MustInvoke[authinterface.AuthConfig](i)
+	return authcontroller.NewAuthController(authService, authConfig.GetCookieSettings()), nil
 }
 
 func RegisterModule(container *core.Container) error {
 	do.Provide(container, ProvideRedisClient)
 	do.Provide(container, ProvideAccountRepository)
 	do.Provide(container, ProvideTokenRepository)
+	do.Provide(container, ProvideRecoveryCodeRepository)
+	do.Provide(container, ProvideIdentityRepository)
+	do.Provide(container, ProvidePasswordHistoryRepository)
+	do.Provide(container, ProvideOAuthStateStore)
+	do.Provide(container, ProvideWebAuthnCredentialRepository)
+	do.Provide(container, ProvideWebAuthnSessionStore)
+	do.Provide(container, ProvideAPIKeyRepository)
+	do.Provide(container, ProvideLoginHistoryRepository)
+	do.Provide(container, ProvideLoginAttemptTracker)
 	do.Provide(container, ProvideSessionStore)
+	do.Provide(container, ProvideRevocationList)
+	do.Provide(container, provideStoreDB)
+	do.Provide(container, ProvideStoreCleanup)
 	do.Provide(container, ProvidePasswordHasher)
+	do.Provide(container, ProvidePasswordPolicy)
 	do.Provide(container, ProvideTokenGenerator)
+	do.Provide(container, ProvideTOTPProvider)
+	do.Provide(container, ProvideAuthEventDispatcher)
+	do.Provide(container, ProvideAuthEventHook)
 	do.Provide(container, ProvideEmailSender)
+	do.Provide(container, ProvideSMSSender)
 	do.Provide(container, ProvideAuthConfig)
+	do.Provide(container, ProvideKeyProvider)
 	do.Provide(container, ProvideStrategyRegistry)
 	do.Provide(container, ProvideAuthService)
 	do.Provide(container, ProvideAuthMiddleware)
@@ -160,7 +713,9 @@ func RegisterModule(container *core.Container) error {
 	authController := do.MustInvoke[*authcontroller.AuthController](container)
 	authMiddleware := do.MustInvoke[*authmiddleware.AuthMiddleware](container)
 
-	authController.RegisterRoutes(e, "/api/v1/auth", authMiddleware)
+	authController.RegisterRoutes(e, "{{.Module.RoutePrefix}}", authMiddleware)
+
+	core.RegisterUserData(container, "auth", do.MustInvoke[authinterface.AuthService](container))
 
 	return nil
 }
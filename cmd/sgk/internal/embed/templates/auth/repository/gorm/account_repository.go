@@ -3,6 +3,7 @@ package gorm
 import (
 	"context"
 	"errors"
+	"time"
 	
 	authinterface "{{.Project.GoModule}}/internal/auth/interface"
 	authmodel "{{.Project.GoModule}}/internal/auth/model"
@@ -79,14 +80,55 @@ func (r *AccountRepository) Exists(ctx context.Context, id uuid.UUID) (bool, err
 	return count > 0, err
 }
 
+// ExistsByEmail also counts accounts pending deletion, whose address stays
+// reserved until they are purged.
 func (r *AccountRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
 	var count int64
-	err := r.db.WithContext(ctx).Model(&authmodel.Account{}).Where("email = ?", email).Count(&count).Error
+	err := r.db.WithContext(ctx).Unscoped().Model(&authmodel.Account{}).Where("email = ?", email).Count(&count).Error
 	return count > 0, err
 }
 
 func (r *AccountRepository) ExistsByPhone(ctx context.Context, phone string) (bool, error) {
 	var count int64
-	err := r.db.WithContext(ctx).Model(&authmodel.Account{}).Where("phone = ? AND phone != ''", phone).Count(&count).Error
+	err := r.db.WithContext(ctx).Unscoped().Model(&authmodel.Account{}).Where("phone = ? AND phone != ''", phone).Count(&count).Error
 	return count > 0, err
+}
+
+// ListDeletedBefore returns the soft deleted accounts whose deletion was
+// requested before the given time.
+func (r *AccountRepository) ListDeletedBefore(ctx context.Context, before time.Time) ([]authinterface.Account, error) {
+	var accounts []authmodel.Account
+	err := r.db.WithContext(ctx).
+		Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Find(&accounts).Error
+	if err != nil {
+		return nil, err
+	}
+	
+	result := make([]authinterface.Account, len(accounts))
+	for i := range accounts {
+		result[i] = &accounts[i]
+	}
+	return result, nil
+}
+
+func (r *AccountRepository) Restore(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Unscoped().
+		Model(&authmodel.Account{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("account not found")
+	}
+	return nil
+}
+
+// Purge permanently removes an account, whether or not it was soft deleted.
+func (r *AccountRepository) Purge(ctx context.Context, id uuid.UUID) error {
+	return r.db.WithContext(ctx).Unscoped().Delete(&authmodel.Account{}, "id = ?", id).Error
 }
\ No newline at end of file
@@ -0,0 +1,74 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	authmodel "{{.Project.GoModule}}/internal/auth/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) authinterface.APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+func (r *APIKeyRepository) Create(ctx context.Context, key authinterface.APIKey) error {
+	return r.db.WithContext(ctx).Create(key).Error
+}
+
+func (r *APIKeyRepository) GetByPrefix(ctx context.Context, prefix string) (authinterface.APIKey, error) {
+	var key authmodel.APIKey
+	err := r.db.WithContext(ctx).First(&key, "prefix = ?", prefix).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("api key not found")
+		}
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *APIKeyRepository) ListByAccount(ctx context.Context, accountID uuid.UUID) ([]authinterface.APIKey, error) {
+	var keys []authmodel.APIKey
+	err := r.db.WithContext(ctx).Where("account_id = ?", accountID).Order("created_at").Find(&keys).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]authinterface.APIKey, len(keys))
+	for i := range keys {
+		result[i] = &keys[i]
+	}
+	return result, nil
+}
+
+func (r *APIKeyRepository) UpdateLastUsed(ctx context.Context, id uuid.UUID, usedAt time.Time) error {
+	return r.db.WithContext(ctx).
+		Model(&authmodel.APIKey{}).
+		Where("id = ?", id).
+		Update("last_used_at", usedAt).Error
+}
+
+func (r *APIKeyRepository) Delete(ctx context.Context, accountID, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Delete(&authmodel.APIKey{}, "id = ? AND account_id = ?", id, accountID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("api key not found")
+	}
+	return nil
+}
+
+func (r *APIKeyRepository) DeleteByAccount(ctx context.Context, accountID uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Where("account_id = ?", accountID).
+		Delete(&authmodel.APIKey{}).Error
+}
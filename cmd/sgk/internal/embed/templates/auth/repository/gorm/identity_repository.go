@@ -0,0 +1,60 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	authmodel "{{.Project.GoModule}}/internal/auth/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type IdentityRepository struct {
+	db *gorm.DB
+}
+
+func NewIdentityRepository(db *gorm.DB) authinterface.IdentityRepository {
+	return &IdentityRepository{db: db}
+}
+
+func (r *IdentityRepository) Create(ctx context.Context, identity authinterface.Identity) error {
+	return r.db.WithContext(ctx).Create(identity).Error
+}
+
+func (r *IdentityRepository) GetByProvider(ctx context.Context, provider, providerUserID string) (authinterface.Identity, error) {
+	var identity authmodel.Identity
+	err := r.db.WithContext(ctx).First(&identity, "provider = ? AND provider_user_id = ?", provider, providerUserID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("identity not found")
+		}
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *IdentityRepository) ListByAccount(ctx context.Context, accountID uuid.UUID) ([]authinterface.Identity, error) {
+	var identities []authmodel.Identity
+	err := r.db.WithContext(ctx).Where("account_id = ?", accountID).Order("created_at").Find(&identities).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]authinterface.Identity, len(identities))
+	for i := range identities {
+		result[i] = &identities[i]
+	}
+	return result, nil
+}
+
+func (r *IdentityRepository) Delete(ctx context.Context, accountID, identityID uuid.UUID) error {
+	result := r.db.WithContext(ctx).Delete(&authmodel.Identity{}, "id = ? AND account_id = ?", identityID, accountID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("identity not found")
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	authmodel "{{.Project.GoModule}}/internal/auth/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LoginAttemptTracker keeps each key's state in the login_attempts table
+// until the failure window or the lock ends, whichever is later. Failures
+// are counted with a single upsert so concurrent attempts are not lost.
+type LoginAttemptTracker struct {
+	db *gorm.DB
+}
+
+func NewLoginAttemptTracker(db *gorm.DB) authinterface.LoginAttemptTracker {
+	return &LoginAttemptTracker{db: db}
+}
+
+func (t *LoginAttemptTracker) Get(ctx context.Context, key string) (*authinterface.LoginAttempts, error) {
+	var attempt authmodel.LoginAttempt
+	err := t.db.WithContext(ctx).First(&attempt, "key = ? AND expires_at > ?", key, time.Now()).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &authinterface.LoginAttempts{}, nil
+		}
+		return nil, err
+	}
+
+	return &authinterface.LoginAttempts{
+		Failures:      attempt.Failures,
+		LastFailureAt: attempt.LastFailureAt,
+		LockedUntil:   attempt.LockedUntil,
+	}, nil
+}
+
+func (t *LoginAttemptTracker) RecordFailure(ctx context.Context, key string, window time.Duration) (*authinterface.LoginAttempts, error) {
+	now := time.Now()
+	expiresAt := now.Add(window)
+
+	// An expired row starts over as if it did not exist
+	attempt := &authmodel.LoginAttempt{
+		Key:           key,
+		Failures:      1,
+		LastFailureAt: now,
+		ExpiresAt:     expiresAt,
+	}
+	err := t.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "key"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"failures":        gorm.Expr("CASE WHEN login_attempts.expires_at > ? THEN login_attempts.failures + 1 ELSE 1 END", now),
+			"locked_until":    gorm.Expr("CASE WHEN login_attempts.expires_at > ? THEN login_attempts.locked_until ELSE ? END", now, time.Time{}),
+			"expires_at":      gorm.Expr("CASE WHEN login_attempts.expires_at > ? AND login_attempts.locked_until > ? THEN login_attempts.locked_until ELSE ? END", now, expiresAt, expiresAt),
+			"last_failure_at": now,
+		}),
+	}).Create(attempt).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to record login failure: %w", err)
+	}
+
+	return t.Get(ctx, key)
+}
+
+func (t *LoginAttemptTracker) Lock(ctx context.Context, key string, until time.Time) error {
+	attempt := &authmodel.LoginAttempt{
+		Key:         key,
+		LockedUntil: until,
+		ExpiresAt:   until,
+	}
+	return t.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"failures", "locked_until", "expires_at"}),
+	}).Create(attempt).Error
+}
+
+func (t *LoginAttemptTracker) Reset(ctx context.Context, key string) error {
+	return t.db.WithContext(ctx).Delete(&authmodel.LoginAttempt{}, "key = ?", key).Error
+}
+
+func (t *LoginAttemptTracker) DeleteExpired(ctx context.Context) error {
+	return t.db.WithContext(ctx).
+		Where("expires_at <= ?", time.Now()).
+		Delete(&authmodel.LoginAttempt{}).Error
+}
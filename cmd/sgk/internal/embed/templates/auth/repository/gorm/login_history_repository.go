@@ -0,0 +1,67 @@
+package gorm
+
+import (
+	"context"
+
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	authmodel "{{.Project.GoModule}}/internal/auth/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type LoginHistoryRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginHistoryRepository(db *gorm.DB) authinterface.LoginHistoryRepository {
+	return &LoginHistoryRepository{db: db}
+}
+
+func (r *LoginHistoryRepository) Create(ctx context.Context, entry authinterface.LoginHistoryEntry) error {
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+// ListByAccount returns the most recent entries first.
+func (r *LoginHistoryRepository) ListByAccount(ctx context.Context, accountID uuid.UUID, limit int) ([]authinterface.LoginHistoryEntry, error) {
+	var entries []authmodel.LoginHistory
+	err := r.db.WithContext(ctx).
+		Where("account_id = ?", accountID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]authinterface.LoginHistoryEntry, len(entries))
+	for i := range entries {
+		result[i] = &entries[i]
+	}
+	return result, nil
+}
+
+// HasLoggedIn and HasLoggedInFrom skip impersonation entries, so staff
+// devices do not count as the owner's.
+func (r *LoginHistoryRepository) HasLoggedIn(ctx context.Context, accountID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&authmodel.LoginHistory{}).
+		Where("account_id = ? AND success = ? AND impersonator_id IS NULL", accountID, true).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *LoginHistoryRepository) HasLoggedInFrom(ctx context.Context, accountID uuid.UUID, device string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&authmodel.LoginHistory{}).
+		Where("account_id = ? AND device = ? AND success = ? AND impersonator_id IS NULL", accountID, device, true).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *LoginHistoryRepository) DeleteByAccount(ctx context.Context, accountID uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Where("account_id = ?", accountID).
+		Delete(&authmodel.LoginHistory{}).Error
+}
@@ -9,5 +9,23 @@ func AutoMigrate(db *gorm.DB) error {
 	return db.AutoMigrate(
 		&authmodel.Account{},
 		&authmodel.Token{},
+		&authmodel.RecoveryCode{},
+		&authmodel.Identity{},
+		&authmodel.WebAuthnCredential{},
+		&authmodel.PasswordHistory{},
+		&authmodel.APIKey{},
+		&authmodel.LoginHistory{},
+	)
+}
+
+// AutoMigrateStores creates the tables for sessions and other short-lived
+// auth state, which are only needed when it is kept in the database.
+func AutoMigrateStores(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&authmodel.Session{},
+		&authmodel.RotatedRefreshToken{},
+		&authmodel.LoginAttempt{},
+		&authmodel.RevokedSession{},
+		&authmodel.PendingState{},
 	)
 }
\ No newline at end of file
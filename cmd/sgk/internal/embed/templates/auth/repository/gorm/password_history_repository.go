@@ -0,0 +1,58 @@
+package gorm
+
+import (
+	"context"
+
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	authmodel "{{.Project.GoModule}}/internal/auth/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PasswordHistoryRepository struct {
+	db *gorm.DB
+}
+
+func NewPasswordHistoryRepository(db *gorm.DB) authinterface.PasswordHistoryRepository {
+	return &PasswordHistoryRepository{db: db}
+}
+
+func (r *PasswordHistoryRepository) Add(ctx context.Context, accountID uuid.UUID, passwordHash string) error {
+	entry := &authmodel.PasswordHistory{
+		ID:           uuid.New(),
+		AccountID:    accountID,
+		PasswordHash: passwordHash,
+	}
+	return r.db.WithContext(ctx).Create(entry).Error
+}
+
+func (r *PasswordHistoryRepository) ListRecent(ctx context.Context, accountID uuid.UUID, limit int) ([]string, error) {
+	var hashes []string
+	err := r.db.WithContext(ctx).
+		Model(&authmodel.PasswordHistory{}).
+		Where("account_id = ?", accountID).
+		Order("created_at DESC").
+		Limit(limit).
+		Pluck("password_hash", &hashes).Error
+	return hashes, err
+}
+
+// Prune deletes all but the keep most recent entries of an account.
+func (r *PasswordHistoryRepository) Prune(ctx context.Context, accountID uuid.UUID, keep int) error {
+	recent := r.db.
+		Model(&authmodel.PasswordHistory{}).
+		Select("id").
+		Where("account_id = ?", accountID).
+		Order("created_at DESC").
+		Limit(keep)
+
+	return r.db.WithContext(ctx).
+		Where("account_id = ? AND id NOT IN (?)", accountID, recent).
+		Delete(&authmodel.PasswordHistory{}).Error
+}
+
+func (r *PasswordHistoryRepository) DeleteByAccount(ctx context.Context, accountID uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Where("account_id = ?", accountID).
+		Delete(&authmodel.PasswordHistory{}).Error
+}
@@ -0,0 +1,129 @@
+package gorm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	authmodel "{{.Project.GoModule}}/internal/auth/model"
+	"gorm.io/gorm"
+)
+
+var errPendingStateNotFound = errors.New("pending state not found")
+
+// pendingStates keeps values under <prefix>:<key> in the pending_states table
+// until they are consumed or expire.
+type pendingStates struct {
+	db     *gorm.DB
+	prefix string
+}
+
+func (p *pendingStates) save(ctx context.Context, key string, value any, expiration time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return p.db.WithContext(ctx).Save(&authmodel.PendingState{
+		Key:       p.key(key),
+		Data:      string(data),
+		ExpiresAt: time.Now().Add(expiration),
+	}).Error
+}
+
+// consume loads and deletes the value in one transaction. The delete is
+// conditional, so of two concurrent callers only one gets the value.
+func (p *pendingStates) consume(ctx context.Context, key string, dest any) error {
+	return p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var state authmodel.PendingState
+		err := tx.First(&state, "key = ? AND expires_at > ?", p.key(key), time.Now()).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errPendingStateNotFound
+			}
+			return err
+		}
+
+		result := tx.Delete(&authmodel.PendingState{}, "key = ?", state.Key)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errPendingStateNotFound
+		}
+
+		return json.Unmarshal([]byte(state.Data), dest)
+	})
+}
+
+func (p *pendingStates) deleteExpired(ctx context.Context) error {
+	return p.db.WithContext(ctx).
+		Where("key LIKE ? AND expires_at <= ?", p.key("%"), time.Now()).
+		Delete(&authmodel.PendingState{}).Error
+}
+
+func (p *pendingStates) key(key string) string {
+	return fmt.Sprintf("%s:%s", p.prefix, key)
+}
+
+type OAuthStateStore struct {
+	states *pendingStates
+}
+
+func NewOAuthStateStore(db *gorm.DB, prefix string) authinterface.OAuthStateStore {
+	if prefix == "" {
+		prefix = "oauth_state"
+	}
+	return &OAuthStateStore{states: &pendingStates{db: db, prefix: prefix}}
+}
+
+func (s *OAuthStateStore) Save(ctx context.Context, state *authinterface.OAuthState, expiration time.Duration) error {
+	return s.states.save(ctx, state.State, state, expiration)
+}
+
+func (s *OAuthStateStore) Consume(ctx context.Context, state string) (*authinterface.OAuthState, error) {
+	var stored authinterface.OAuthState
+	if err := s.states.consume(ctx, state, &stored); err != nil {
+		if errors.Is(err, errPendingStateNotFound) {
+			return nil, errors.New("oauth state not found")
+		}
+		return nil, err
+	}
+	return &stored, nil
+}
+
+func (s *OAuthStateStore) DeleteExpired(ctx context.Context) error {
+	return s.states.deleteExpired(ctx)
+}
+
+type WebAuthnSessionStore struct {
+	sessions *pendingStates
+}
+
+func NewWebAuthnSessionStore(db *gorm.DB, prefix string) authinterface.WebAuthnSessionStore {
+	if prefix == "" {
+		prefix = "webauthn"
+	}
+	return &WebAuthnSessionStore{sessions: &pendingStates{db: db, prefix: prefix}}
+}
+
+func (s *WebAuthnSessionStore) Save(ctx context.Context, session *authinterface.WebAuthnSession, expiration time.Duration) error {
+	return s.sessions.save(ctx, session.Challenge, session, expiration)
+}
+
+func (s *WebAuthnSessionStore) Consume(ctx context.Context, challenge string) (*authinterface.WebAuthnSession, error) {
+	var session authinterface.WebAuthnSession
+	if err := s.sessions.consume(ctx, challenge, &session); err != nil {
+		if errors.Is(err, errPendingStateNotFound) {
+			return nil, errors.New("webauthn session not found")
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (s *WebAuthnSessionStore) DeleteExpired(ctx context.Context) error {
+	return s.sessions.deleteExpired(ctx)
+}
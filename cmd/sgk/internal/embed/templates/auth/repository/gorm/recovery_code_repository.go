@@ -0,0 +1,57 @@
+package gorm
+
+import (
+	"context"
+	"time"
+
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	authmodel "{{.Project.GoModule}}/internal/auth/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RecoveryCodeRepository struct {
+	db *gorm.DB
+}
+
+func NewRecoveryCodeRepository(db *gorm.DB) authinterface.RecoveryCodeRepository {
+	return &RecoveryCodeRepository{db: db}
+}
+
+func (r *RecoveryCodeRepository) Replace(ctx context.Context, accountID uuid.UUID, codeHashes []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&authmodel.RecoveryCode{}, "account_id = ?", accountID).Error; err != nil {
+			return err
+		}
+
+		codes := make([]authmodel.RecoveryCode, len(codeHashes))
+		for i, hash := range codeHashes {
+			codes[i] = authmodel.RecoveryCode{
+				ID:        uuid.New(),
+				AccountID: accountID,
+				CodeHash:  hash,
+			}
+		}
+		if len(codes) == 0 {
+			return nil
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// Consume marks an unused code as used in a single statement so the same
+// code cannot be redeemed twice by concurrent requests.
+func (r *RecoveryCodeRepository) Consume(ctx context.Context, accountID uuid.UUID, codeHash string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&authmodel.RecoveryCode{}).
+		Where("account_id = ? AND code_hash = ? AND used_at IS NULL", accountID, codeHash).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *RecoveryCodeRepository) DeleteByAccount(ctx context.Context, accountID uuid.UUID) error {
+	return r.db.WithContext(ctx).Delete(&authmodel.RecoveryCode{}, "account_id = ?", accountID).Error
+}
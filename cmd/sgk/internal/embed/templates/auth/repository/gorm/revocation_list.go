@@ -0,0 +1,46 @@
+package gorm
+
+import (
+	"context"
+	"time"
+
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	authmodel "{{.Project.GoModule}}/internal/auth/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RevocationList keeps revoked session ids until the last access token
+// issued for them expires.
+type RevocationList struct {
+	db *gorm.DB
+}
+
+func NewRevocationList(db *gorm.DB) authinterface.RevocationList {
+	return &RevocationList{db: db}
+}
+
+func (l *RevocationList) Revoke(ctx context.Context, sessionID uuid.UUID, until time.Time) error {
+	if !until.After(time.Now()) {
+		return nil
+	}
+	return l.db.WithContext(ctx).Save(&authmodel.RevokedSession{SessionID: sessionID, ExpiresAt: until}).Error
+}
+
+func (l *RevocationList) IsRevoked(ctx context.Context, sessionID uuid.UUID) (bool, error) {
+	var count int64
+	err := l.db.WithContext(ctx).
+		Model(&authmodel.RevokedSession{}).
+		Where("session_id = ? AND expires_at > ?", sessionID, time.Now()).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (l *RevocationList) DeleteExpired(ctx context.Context) error {
+	return l.db.WithContext(ctx).
+		Where("expires_at <= ?", time.Now()).
+		Delete(&authmodel.RevokedSession{}).Error
+}
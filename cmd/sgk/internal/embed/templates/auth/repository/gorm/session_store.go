@@ -0,0 +1,189 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	authmodel "{{.Project.GoModule}}/internal/auth/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var errSessionNotFound = errors.New("session not found")
+
+// SessionStore keeps sessions in the sessions table. A session stays until
+// both its access and refresh tokens have expired; each token is only
+// honoured until its own expiry.
+type SessionStore struct {
+	db *gorm.DB
+}
+
+func NewSessionStore(db *gorm.DB) authinterface.SessionStore {
+	return &SessionStore{db: db}
+}
+
+func (s *SessionStore) Store(ctx context.Context, session authinterface.Session) error {
+	if !session.GetExpiresAt().After(time.Now()) {
+		return errors.New("session already expired")
+	}
+	return s.db.WithContext(ctx).Save(session).Error
+}
+
+func (s *SessionStore) Get(ctx context.Context, token string) (authinterface.Session, error) {
+	return s.first(ctx, "token = ? AND expires_at > ?", token, time.Now())
+}
+
+func (s *SessionStore) GetByID(ctx context.Context, sessionID uuid.UUID) (authinterface.Session, error) {
+	now := time.Now()
+	return s.first(ctx, "id = ? AND (expires_at > ? OR refresh_expires_at > ?)", sessionID, now, now)
+}
+
+func (s *SessionStore) GetByRefreshToken(ctx context.Context, refreshToken string) (authinterface.Session, error) {
+	if refreshToken == "" {
+		return nil, errSessionNotFound
+	}
+	now := time.Now()
+	return s.first(ctx, "refresh_token = ? AND (expires_at > ? OR refresh_expires_at > ?)", refreshToken, now, now)
+}
+
+func (s *SessionStore) ListByUser(ctx context.Context, userID uuid.UUID) ([]authinterface.Session, error) {
+	var sessions []authmodel.Session
+	now := time.Now()
+	err := s.db.WithContext(ctx).
+		Where("user_id = ? AND (expires_at > ? OR refresh_expires_at > ?)", userID, now, now).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]authinterface.Session, len(sessions))
+	for i := range sessions {
+		result[i] = &sessions[i]
+	}
+	return result, nil
+}
+
+// Rotate replaces the session holding refreshToken with next. The old row is
+// deleted conditionally, so only one of several concurrent refreshes
+// succeeds, and the old token is remembered to detect later reuse.
+func (s *SessionStore) Rotate(ctx context.Context, refreshToken string, next authinterface.Session) error {
+	if refreshToken == "" {
+		return authinterface.ErrRefreshTokenReused
+	}
+	if !next.GetExpiresAt().After(time.Now()) {
+		return errors.New("session already expired")
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var previous authmodel.Session
+		now := time.Now()
+		err := tx.First(&previous, "refresh_token = ? AND (expires_at > ? OR refresh_expires_at > ?)", refreshToken, now, now).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return authinterface.ErrRefreshTokenReused
+			}
+			return err
+		}
+
+		result := tx.Delete(&authmodel.Session{}, "id = ? AND refresh_token = ?", previous.ID, refreshToken)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return authinterface.ErrRefreshTokenReused
+		}
+
+		rotated := &authmodel.RotatedRefreshToken{
+			Token:     refreshToken,
+			SessionID: previous.ID,
+			ExpiresAt: previous.RefreshExpiresAt,
+		}
+		if err := tx.Save(rotated).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(next).Error
+	})
+}
+
+func (s *SessionStore) GetFamilyByRotatedToken(ctx context.Context, refreshToken string) (uuid.UUID, error) {
+	var rotated authmodel.RotatedRefreshToken
+	err := s.db.WithContext(ctx).First(&rotated, "token = ? AND expires_at > ?", refreshToken, time.Now()).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return uuid.Nil, errSessionNotFound
+		}
+		return uuid.Nil, err
+	}
+	return rotated.SessionID, nil
+}
+
+func (s *SessionStore) Touch(ctx context.Context, sessionID uuid.UUID, seenAt time.Time) error {
+	now := time.Now()
+	result := s.db.WithContext(ctx).
+		Model(&authmodel.Session{}).
+		Where("id = ? AND (expires_at > ? OR refresh_expires_at > ?)", sessionID, now, now).
+		Update("last_seen_at", seenAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errSessionNotFound
+	}
+	return nil
+}
+
+func (s *SessionStore) Delete(ctx context.Context, userID uuid.UUID) error {
+	return s.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Delete(&authmodel.Session{}).Error
+}
+
+func (s *SessionStore) DeleteByID(ctx context.Context, sessionID uuid.UUID) error {
+	result := s.db.WithContext(ctx).Delete(&authmodel.Session{}, "id = ?", sessionID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errSessionNotFound
+	}
+	return nil
+}
+
+func (s *SessionStore) DeleteByToken(ctx context.Context, token string) error {
+	session, err := s.Get(ctx, token)
+	if err != nil {
+		return err
+	}
+	return s.DeleteByID(ctx, session.GetID())
+}
+
+// DeleteExpired removes sessions whose access and refresh tokens have both
+// expired, along with rotated tokens past their expiry.
+func (s *SessionStore) DeleteExpired(ctx context.Context) error {
+	now := time.Now()
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("expires_at <= ? AND refresh_expires_at <= ?", now, now).
+			Delete(&authmodel.Session{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("expires_at <= ?", now).
+			Delete(&authmodel.RotatedRefreshToken{}).Error
+	})
+}
+
+func (s *SessionStore) first(ctx context.Context, query string, args ...any) (authinterface.Session, error) {
+	var session authmodel.Session
+	err := s.db.WithContext(ctx).Where(query, args...).First(&session).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errSessionNotFound
+		}
+		return nil, err
+	}
+	return &session, nil
+}
@@ -0,0 +1,73 @@
+package gorm
+
+import (
+	"testing"
+
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	"{{.Project.GoModule}}/internal/auth/repository/sessionstoretest"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB opens a private in-memory SQLite database. A single connection
+// keeps it alive for the test and serialises concurrent transactions the way
+// row locks would.
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	return db
+}
+
+// newMigratedDB returns a test database with the store tables.
+func newMigratedDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db := newTestDB(t)
+	if err := AutoMigrateStores(db); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return db
+}
+
+func TestSessionStore(t *testing.T) {
+	sessionstoretest.Run(t, func(t *testing.T) authinterface.SessionStore {
+		return NewSessionStore(newMigratedDB(t))
+	})
+}
+
+func TestLoginAttemptTracker(t *testing.T) {
+	sessionstoretest.RunLoginAttemptTracker(t, func(t *testing.T) authinterface.LoginAttemptTracker {
+		return NewLoginAttemptTracker(newMigratedDB(t))
+	})
+}
+
+func TestRevocationList(t *testing.T) {
+	sessionstoretest.RunRevocationList(t, func(t *testing.T) authinterface.RevocationList {
+		return NewRevocationList(newMigratedDB(t))
+	})
+}
+
+func TestOAuthStateStore(t *testing.T) {
+	sessionstoretest.RunOAuthStateStore(t, func(t *testing.T) authinterface.OAuthStateStore {
+		return NewOAuthStateStore(newMigratedDB(t), "oauth_state")
+	})
+}
+
+func TestWebAuthnSessionStore(t *testing.T) {
+	sessionstoretest.RunWebAuthnSessionStore(t, func(t *testing.T) authinterface.WebAuthnSessionStore {
+		return NewWebAuthnSessionStore(newMigratedDB(t), "webauthn")
+	})
+}
@@ -68,22 +68,49 @@ func (r *TokenRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+// DeleteExpired keeps tokens for an hour after they expire, because the
+// per-hour limits on phone codes and magic links count them.
 func (r *TokenRepository) DeleteExpired(ctx context.Context) error {
 	return r.db.WithContext(ctx).
-		Where("expires_at < ?", time.Now()).
+		Where("expires_at < ?", time.Now().Add(-time.Hour)).
 		Delete(&authmodel.Token{}).Error
 }
 
+// MarkAsUsed only flips unused tokens, so when two requests race to redeem
+// the same token exactly one of them succeeds.
 func (r *TokenRepository) MarkAsUsed(ctx context.Context, id uuid.UUID) error {
 	result := r.db.WithContext(ctx).
 		Model(&authmodel.Token{}).
-		Where("id = ?", id).
+		Where("id = ? AND used = ?", id, false).
 		Update("used", true)
 	if result.Error != nil {
 		return result.Error
 	}
 	if result.RowsAffected == 0 {
-		return errors.New("token not found")
+		return errors.New("token not found or already used")
 	}
 	return nil
+}
+
+func (r *TokenRepository) ListByAccount(ctx context.Context, accountID uuid.UUID) ([]authinterface.Token, error) {
+	var tokens []authmodel.Token
+	err := r.db.WithContext(ctx).
+		Where("account_id = ?", accountID).
+		Order("created_at DESC").
+		Find(&tokens).Error
+	if err != nil {
+		return nil, err
+	}
+	
+	result := make([]authinterface.Token, len(tokens))
+	for i := range tokens {
+		result[i] = &tokens[i]
+	}
+	return result, nil
+}
+
+func (r *TokenRepository) DeleteByAccount(ctx context.Context, accountID uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Where("account_id = ?", accountID).
+		Delete(&authmodel.Token{}).Error
 }
\ No newline at end of file
@@ -0,0 +1,56 @@
+package gorm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	authmodel "{{.Project.GoModule}}/internal/auth/model"
+)
+
+func TestTokenRepositoryDeleteExpired(t *testing.T) {
+	// The model's uuid default is Postgres only, so the table is made here.
+	db := newTestDB(t)
+	err := db.Exec(`CREATE TABLE tokens (
+		id TEXT PRIMARY KEY, account_id TEXT NOT NULL, token TEXT NOT NULL UNIQUE,
+		type TEXT NOT NULL, used NUMERIC DEFAULT false, expires_at DATETIME NOT NULL,
+		device_binding TEXT, email TEXT, created_at DATETIME, updated_at DATETIME
+	)`).Error
+	if err != nil {
+		t.Fatalf("failed to create tokens table: %v", err)
+	}
+	repo := NewTokenRepository(db)
+	ctx := context.Background()
+	accountID := uuid.New()
+
+	expiries := map[string]time.Time{
+		"valid":        time.Now().Add(time.Minute),
+		"just expired": time.Now().Add(-time.Minute),
+		"long expired": time.Now().Add(-2 * time.Hour),
+	}
+	for value, expiresAt := range expiries {
+		token := &authmodel.Token{
+			ID:        uuid.New(),
+			AccountID: accountID,
+			Token:     value,
+			Type:      authinterface.TokenTypePhoneVerification,
+			ExpiresAt: expiresAt,
+		}
+		if err := repo.Create(ctx, token); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	if err := repo.DeleteExpired(ctx); err != nil {
+		t.Fatalf("DeleteExpired: %v", err)
+	}
+
+	for value, want := range map[string]bool{"valid": true, "just expired": true, "long expired": false} {
+		_, err := repo.GetByToken(ctx, value)
+		if got := err == nil; got != want {
+			t.Errorf("token %q kept = %v, want %v", value, got, want)
+		}
+	}
+}
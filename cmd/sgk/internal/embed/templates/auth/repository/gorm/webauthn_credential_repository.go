@@ -0,0 +1,82 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	authmodel "{{.Project.GoModule}}/internal/auth/model"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type WebAuthnCredentialRepository struct {
+	db *gorm.DB
+}
+
+func NewWebAuthnCredentialRepository(db *gorm.DB) authinterface.WebAuthnCredentialRepository {
+	return &WebAuthnCredentialRepository{db: db}
+}
+
+func (r *WebAuthnCredentialRepository) Create(ctx context.Context, credential authinterface.WebAuthnCredential) error {
+	return r.db.WithContext(ctx).Create(credential).Error
+}
+
+func (r *WebAuthnCredentialRepository) GetByCredentialID(ctx context.Context, credentialID string) (authinterface.WebAuthnCredential, error) {
+	var credential authmodel.WebAuthnCredential
+	err := r.db.WithContext(ctx).First(&credential, "credential_id = ?", credentialID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("credential not found")
+		}
+		return nil, err
+	}
+	return &credential, nil
+}
+
+func (r *WebAuthnCredentialRepository) ListByAccount(ctx context.Context, accountID uuid.UUID) ([]authinterface.WebAuthnCredential, error) {
+	var credentials []authmodel.WebAuthnCredential
+	err := r.db.WithContext(ctx).Where("account_id = ?", accountID).Order("created_at").Find(&credentials).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]authinterface.WebAuthnCredential, len(credentials))
+	for i := range credentials {
+		result[i] = &credentials[i]
+	}
+	return result, nil
+}
+
+// UpdateSignCount only moves the counter forward, so two concurrent logins
+// with the same counter value cannot both be accepted.
+func (r *WebAuthnCredentialRepository) UpdateSignCount(ctx context.Context, id uuid.UUID, signCount uint32, usedAt time.Time) error {
+	query := r.db.WithContext(ctx).Model(&authmodel.WebAuthnCredential{}).Where("id = ?", id)
+	if signCount > 0 {
+		query = query.Where("sign_count < ?", signCount)
+	}
+
+	result := query.Updates(map[string]any{
+		"sign_count":   signCount,
+		"last_used_at": usedAt,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("sign count did not increase")
+	}
+	return nil
+}
+
+func (r *WebAuthnCredentialRepository) Delete(ctx context.Context, accountID, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).Delete(&authmodel.WebAuthnCredential{}, "id = ? AND account_id = ?", id, accountID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("credential not found")
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+)
+
+type loginAttempts struct {
+	attempts  authinterface.LoginAttempts
+	expiresAt time.Time
+}
+
+// LoginAttemptTracker keeps each key's state until the failure window or the
+// lock ends, whichever is later.
+type LoginAttemptTracker struct {
+	mu      sync.Mutex
+	entries map[string]loginAttempts
+}
+
+func NewLoginAttemptTracker() authinterface.LoginAttemptTracker {
+	return &LoginAttemptTracker{entries: make(map[string]loginAttempts)}
+}
+
+func (t *LoginAttemptTracker) Get(ctx context.Context, key string) (*authinterface.LoginAttempts, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	attempts := t.live(key).attempts
+	return &attempts, nil
+}
+
+func (t *LoginAttemptTracker) RecordFailure(ctx context.Context, key string, window time.Duration) (*authinterface.LoginAttempts, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	entry := t.live(key)
+	entry.attempts.Failures++
+	entry.attempts.LastFailureAt = now
+	entry.expiresAt = now.Add(window)
+	if entry.attempts.LockedUntil.After(entry.expiresAt) {
+		entry.expiresAt = entry.attempts.LockedUntil
+	}
+	t.entries[key] = entry
+
+	attempts := entry.attempts
+	return &attempts, nil
+}
+
+func (t *LoginAttemptTracker) Lock(ctx context.Context, key string, until time.Time) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entry := t.live(key)
+	entry.attempts.Failures = 0
+	entry.attempts.LockedUntil = until
+	entry.expiresAt = until
+	t.entries[key] = entry
+	return nil
+}
+
+func (t *LoginAttemptTracker) Reset(ctx context.Context, key string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.entries, key)
+	return nil
+}
+
+func (t *LoginAttemptTracker) DeleteExpired(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	for key, entry := range t.entries {
+		if !entry.expiresAt.After(now) {
+			delete(t.entries, key)
+		}
+	}
+	return nil
+}
+
+// live returns the key's state, or a fresh one once it has expired.
+func (t *LoginAttemptTracker) live(key string) loginAttempts {
+	entry, ok := t.entries[key]
+	if !ok || !entry.expiresAt.After(time.Now()) {
+		return loginAttempts{}
+	}
+	return entry
+}
@@ -0,0 +1,109 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+)
+
+type pendingEntry[T any] struct {
+	value     T
+	expiresAt time.Time
+}
+
+// pendingStates holds values that can be consumed once before they expire.
+type pendingStates[T any] struct {
+	mu      sync.Mutex
+	entries map[string]pendingEntry[T]
+}
+
+func newPendingStates[T any]() *pendingStates[T] {
+	return &pendingStates[T]{entries: make(map[string]pendingEntry[T])}
+}
+
+func (p *pendingStates[T]) save(key string, value T, expiration time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.entries[key] = pendingEntry[T]{value: value, expiresAt: time.Now().Add(expiration)}
+}
+
+func (p *pendingStates[T]) consume(key string) (T, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	entry, ok := p.entries[key]
+	delete(p.entries, key)
+	if !ok || !entry.expiresAt.After(time.Now()) {
+		var zero T
+		return zero, false
+	}
+	return entry.value, true
+}
+
+func (p *pendingStates[T]) deleteExpired() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	for key, entry := range p.entries {
+		if !entry.expiresAt.After(now) {
+			delete(p.entries, key)
+		}
+	}
+}
+
+type OAuthStateStore struct {
+	states *pendingStates[authinterface.OAuthState]
+}
+
+func NewOAuthStateStore() authinterface.OAuthStateStore {
+	return &OAuthStateStore{states: newPendingStates[authinterface.OAuthState]()}
+}
+
+func (s *OAuthStateStore) Save(ctx context.Context, state *authinterface.OAuthState, expiration time.Duration) error {
+	s.states.save(state.State, *state, expiration)
+	return nil
+}
+
+func (s *OAuthStateStore) Consume(ctx context.Context, state string) (*authinterface.OAuthState, error) {
+	stored, ok := s.states.consume(state)
+	if !ok {
+		return nil, errors.New("oauth state not found")
+	}
+	return &stored, nil
+}
+
+func (s *OAuthStateStore) DeleteExpired(ctx context.Context) error {
+	s.states.deleteExpired()
+	return nil
+}
+
+type WebAuthnSessionStore struct {
+	sessions *pendingStates[authinterface.WebAuthnSession]
+}
+
+func NewWebAuthnSessionStore() authinterface.WebAuthnSessionStore {
+	return &WebAuthnSessionStore{sessions: newPendingStates[authinterface.WebAuthnSession]()}
+}
+
+func (s *WebAuthnSessionStore) Save(ctx context.Context, session *authinterface.WebAuthnSession, expiration time.Duration) error {
+	s.sessions.save(session.Challenge, *session, expiration)
+	return nil
+}
+
+func (s *WebAuthnSessionStore) Consume(ctx context.Context, challenge string) (*authinterface.WebAuthnSession, error) {
+	session, ok := s.sessions.consume(challenge)
+	if !ok {
+		return nil, errors.New("webauthn session not found")
+	}
+	return &session, nil
+}
+
+func (s *WebAuthnSessionStore) DeleteExpired(ctx context.Context) error {
+	s.sessions.deleteExpired()
+	return nil
+}
@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	"github.com/google/uuid"
+)
+
+// RevocationList keeps revoked session ids until the last access token
+// issued for them expires.
+type RevocationList struct {
+	mu      sync.RWMutex
+	revoked map[uuid.UUID]time.Time
+}
+
+func NewRevocationList() authinterface.RevocationList {
+	return &RevocationList{revoked: make(map[uuid.UUID]time.Time)}
+}
+
+func (l *RevocationList) Revoke(ctx context.Context, sessionID uuid.UUID, until time.Time) error {
+	if !until.After(time.Now()) {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.revoked[sessionID] = until
+	return nil
+}
+
+func (l *RevocationList) IsRevoked(ctx context.Context, sessionID uuid.UUID) (bool, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	until, ok := l.revoked[sessionID]
+	return ok && until.After(time.Now()), nil
+}
+
+func (l *RevocationList) DeleteExpired(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	for sessionID, until := range l.revoked {
+		if !until.After(now) {
+			delete(l.revoked, sessionID)
+		}
+	}
+	return nil
+}
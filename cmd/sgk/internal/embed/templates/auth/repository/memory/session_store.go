@@ -0,0 +1,275 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	authmodel "{{.Project.GoModule}}/internal/auth/model"
+	"github.com/google/uuid"
+)
+
+var errSessionNotFound = errors.New("session not found")
+
+type rotatedToken struct {
+	sessionID uuid.UUID
+	expiresAt time.Time
+}
+
+// SessionStore keeps sessions in process memory, for local development and
+// tests. Expired entries are ignored by lookups straight away and freed by
+// DeleteExpired. Sessions do not survive a restart and are not shared
+// between instances.
+type SessionStore struct {
+	mu        sync.RWMutex
+	sessions  map[uuid.UUID]authmodel.Session
+	byToken   map[string]uuid.UUID
+	byRefresh map[string]uuid.UUID
+	byUser    map[uuid.UUID]map[uuid.UUID]struct{}
+	rotated   map[string]rotatedToken
+}
+
+func NewSessionStore() authinterface.SessionStore {
+	return &SessionStore{
+		sessions:  make(map[uuid.UUID]authmodel.Session),
+		byToken:   make(map[string]uuid.UUID),
+		byRefresh: make(map[string]uuid.UUID),
+		byUser:    make(map[uuid.UUID]map[uuid.UUID]struct{}),
+		rotated:   make(map[string]rotatedToken),
+	}
+}
+
+func (s *SessionStore) Store(ctx context.Context, session authinterface.Session) error {
+	if !session.GetExpiresAt().After(time.Now()) {
+		return errors.New("session already expired")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.store(toModel(session))
+	return nil
+}
+
+func (s *SessionStore) Get(ctx context.Context, token string) (authinterface.Session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	session, ok := s.sessions[s.byToken[token]]
+	if !ok || session.Token != token || !session.ExpiresAt.After(time.Now()) {
+		return nil, errSessionNotFound
+	}
+	return &session, nil
+}
+
+func (s *SessionStore) GetByID(ctx context.Context, sessionID uuid.UUID) (authinterface.Session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	session, ok := s.live(sessionID)
+	if !ok {
+		return nil, errSessionNotFound
+	}
+	return &session, nil
+}
+
+func (s *SessionStore) GetByRefreshToken(ctx context.Context, refreshToken string) (authinterface.Session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	id, ok := s.byRefresh[refreshToken]
+	if !ok || refreshToken == "" {
+		return nil, errSessionNotFound
+	}
+	session, ok := s.live(id)
+	if !ok {
+		return nil, errSessionNotFound
+	}
+	return &session, nil
+}
+
+func (s *SessionStore) ListByUser(ctx context.Context, userID uuid.UUID) ([]authinterface.Session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sessions := make([]authinterface.Session, 0, len(s.byUser[userID]))
+	for id := range s.byUser[userID] {
+		if session, ok := s.live(id); ok {
+			sessions = append(sessions, &session)
+		}
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].GetLastSeenAt().After(sessions[j].GetLastSeenAt())
+	})
+
+	return sessions, nil
+}
+
+// Rotate replaces the session holding refreshToken with next under the store
+// lock, so only one of several concurrent refreshes succeeds. The old token
+// is remembered until it would have expired to detect later reuse.
+func (s *SessionStore) Rotate(ctx context.Context, refreshToken string, next authinterface.Session) error {
+	if !next.GetExpiresAt().After(time.Now()) {
+		return errors.New("session already expired")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, ok := s.byRefresh[refreshToken]
+	if !ok || refreshToken == "" {
+		return authinterface.ErrRefreshTokenReused
+	}
+	previous, ok := s.live(id)
+	if !ok {
+		return authinterface.ErrRefreshTokenReused
+	}
+
+	s.remove(id)
+	s.rotated[refreshToken] = rotatedToken{sessionID: id, expiresAt: previous.RefreshExpiresAt}
+	s.store(toModel(next))
+	return nil
+}
+
+func (s *SessionStore) GetFamilyByRotatedToken(ctx context.Context, refreshToken string) (uuid.UUID, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rotated, ok := s.rotated[refreshToken]
+	if !ok || !rotated.expiresAt.After(time.Now()) {
+		return uuid.Nil, errSessionNotFound
+	}
+	return rotated.sessionID, nil
+}
+
+func (s *SessionStore) Touch(ctx context.Context, sessionID uuid.UUID, seenAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.live(sessionID)
+	if !ok {
+		return errSessionNotFound
+	}
+	session.LastSeenAt = seenAt
+	s.sessions[sessionID] = session
+	return nil
+}
+
+func (s *SessionStore) Delete(ctx context.Context, userID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id := range s.byUser[userID] {
+		s.remove(id)
+	}
+	return nil
+}
+
+func (s *SessionStore) DeleteByID(ctx context.Context, sessionID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.live(sessionID); !ok {
+		return errSessionNotFound
+	}
+	s.remove(sessionID)
+	return nil
+}
+
+func (s *SessionStore) DeleteByToken(ctx context.Context, token string) error {
+	session, err := s.Get(ctx, token)
+	if err != nil {
+		return err
+	}
+	return s.DeleteByID(ctx, session.GetID())
+}
+
+// DeleteExpired frees sessions whose access and refresh tokens have both
+// expired, along with rotated tokens past their expiry.
+func (s *SessionStore) DeleteExpired(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for id, session := range s.sessions {
+		if !session.ExpiresAt.After(now) && !session.RefreshExpiresAt.After(now) {
+			s.remove(id)
+		}
+	}
+	for token, rotated := range s.rotated {
+		if !rotated.expiresAt.After(now) {
+			delete(s.rotated, token)
+		}
+	}
+	return nil
+}
+
+// live returns the session while its access or refresh token is still valid.
+func (s *SessionStore) live(id uuid.UUID) (authmodel.Session, bool) {
+	session, ok := s.sessions[id]
+	if !ok {
+		return authmodel.Session{}, false
+	}
+	now := time.Now()
+	return session, session.ExpiresAt.After(now) || session.RefreshExpiresAt.After(now)
+}
+
+func (s *SessionStore) store(session authmodel.Session) {
+	if _, ok := s.sessions[session.ID]; ok {
+		s.remove(session.ID)
+	}
+
+	s.sessions[session.ID] = session
+	s.byToken[session.Token] = session.ID
+	if session.RefreshToken != "" {
+		s.byRefresh[session.RefreshToken] = session.ID
+	}
+	if s.byUser[session.UserID] == nil {
+		s.byUser[session.UserID] = make(map[uuid.UUID]struct{})
+	}
+	s.byUser[session.UserID][session.ID] = struct{}{}
+}
+
+func (s *SessionStore) remove(id uuid.UUID) {
+	session, ok := s.sessions[id]
+	if !ok {
+		return
+	}
+
+	delete(s.sessions, id)
+	if s.byToken[session.Token] == id {
+		delete(s.byToken, session.Token)
+	}
+	if s.byRefresh[session.RefreshToken] == id {
+		delete(s.byRefresh, session.RefreshToken)
+	}
+	delete(s.byUser[session.UserID], id)
+	if len(s.byUser[session.UserID]) == 0 {
+		delete(s.byUser, session.UserID)
+	}
+}
+
+// toModel copies a session so later changes by the caller do not leak into
+// the store.
+func toModel(session authinterface.Session) authmodel.Session {
+	model := authmodel.Session{
+		ID:               session.GetID(),
+		UserID:           session.GetUserID(),
+		Token:            session.GetToken(),
+		RefreshToken:     session.GetRefreshToken(),
+		ExpiresAt:        session.GetExpiresAt(),
+		RefreshExpiresAt: session.GetRefreshExpiresAt(),
+		UserAgent:        session.GetUserAgent(),
+		IPAddress:        session.GetIPAddress(),
+		CreatedAt:        session.GetCreatedAt(),
+		LastSeenAt:       session.GetLastSeenAt(),
+	}
+	if impersonatorID := session.GetImpersonatorID(); impersonatorID != uuid.Nil {
+		model.ImpersonatorID = &impersonatorID
+	}
+	return model
+}
@@ -0,0 +1,38 @@
+package memory
+
+import (
+	"testing"
+
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	"{{.Project.GoModule}}/internal/auth/repository/sessionstoretest"
+)
+
+func TestSessionStore(t *testing.T) {
+	sessionstoretest.Run(t, func(t *testing.T) authinterface.SessionStore {
+		return NewSessionStore()
+	})
+}
+
+func TestLoginAttemptTracker(t *testing.T) {
+	sessionstoretest.RunLoginAttemptTracker(t, func(t *testing.T) authinterface.LoginAttemptTracker {
+		return NewLoginAttemptTracker()
+	})
+}
+
+func TestRevocationList(t *testing.T) {
+	sessionstoretest.RunRevocationList(t, func(t *testing.T) authinterface.RevocationList {
+		return NewRevocationList()
+	})
+}
+
+func TestOAuthStateStore(t *testing.T) {
+	sessionstoretest.RunOAuthStateStore(t, func(t *testing.T) authinterface.OAuthStateStore {
+		return NewOAuthStateStore()
+	})
+}
+
+func TestWebAuthnSessionStore(t *testing.T) {
+	sessionstoretest.RunWebAuthnSessionStore(t, func(t *testing.T) authinterface.WebAuthnSessionStore {
+		return NewWebAuthnSessionStore()
+	})
+}
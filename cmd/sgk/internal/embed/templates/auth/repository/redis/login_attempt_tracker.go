@@ -0,0 +1,82 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	"github.com/redis/go-redis/v9"
+)
+
+// LoginAttemptTracker keeps each key's state in a hash that expires with
+// the failure window or the lock, whichever is later.
+type LoginAttemptTracker struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewLoginAttemptTracker(client *redis.Client, prefix string) authinterface.LoginAttemptTracker {
+	if prefix == "" {
+		prefix = "login_attempts"
+	}
+	return &LoginAttemptTracker{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+func (t *LoginAttemptTracker) Get(ctx context.Context, key string) (*authinterface.LoginAttempts, error) {
+	values, err := t.client.HGetAll(ctx, t.key(key)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	attempts := &authinterface.LoginAttempts{}
+	if failures, err := strconv.Atoi(values["failures"]); err == nil {
+		attempts.Failures = failures
+	}
+	if last, err := strconv.ParseInt(values["last_failure"], 10, 64); err == nil {
+		attempts.LastFailureAt = time.Unix(0, last)
+	}
+	if until, err := strconv.ParseInt(values["locked_until"], 10, 64); err == nil {
+		attempts.LockedUntil = time.Unix(0, until)
+	}
+	return attempts, nil
+}
+
+func (t *LoginAttemptTracker) RecordFailure(ctx context.Context, key string, window time.Duration) (*authinterface.LoginAttempts, error) {
+	redisKey := t.key(key)
+
+	_, err := t.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HIncrBy(ctx, redisKey, "failures", 1)
+		pipe.HSet(ctx, redisKey, "last_failure", time.Now().UnixNano())
+		pipe.PExpire(ctx, redisKey, window)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to record login failure: %w", err)
+	}
+
+	return t.Get(ctx, key)
+}
+
+func (t *LoginAttemptTracker) Lock(ctx context.Context, key string, until time.Time) error {
+	redisKey := t.key(key)
+
+	_, err := t.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, redisKey, "failures", 0, "locked_until", until.UnixNano())
+		pipe.ExpireAt(ctx, redisKey, until)
+		return nil
+	})
+	return err
+}
+
+func (t *LoginAttemptTracker) Reset(ctx context.Context, key string) error {
+	return t.client.Del(ctx, t.key(key)).Err()
+}
+
+func (t *LoginAttemptTracker) key(key string) string {
+	return fmt.Sprintf("%s:%s", t.prefix, key)
+}
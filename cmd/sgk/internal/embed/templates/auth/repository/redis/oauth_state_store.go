@@ -0,0 +1,55 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	"github.com/redis/go-redis/v9"
+)
+
+type OAuthStateStore struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewOAuthStateStore(client *redis.Client, prefix string) authinterface.OAuthStateStore {
+	if prefix == "" {
+		prefix = "oauth_state"
+	}
+	return &OAuthStateStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+func (s *OAuthStateStore) Save(ctx context.Context, state *authinterface.OAuthState, expiration time.Duration) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal oauth state: %w", err)
+	}
+	return s.client.Set(ctx, s.key(state.State), data, expiration).Err()
+}
+
+func (s *OAuthStateStore) Consume(ctx context.Context, state string) (*authinterface.OAuthState, error) {
+	data, err := s.client.GetDel(ctx, s.key(state)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, errors.New("oauth state not found")
+		}
+		return nil, err
+	}
+
+	var stored authinterface.OAuthState
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal oauth state: %w", err)
+	}
+	return &stored, nil
+}
+
+func (s *OAuthStateStore) key(state string) string {
+	return fmt.Sprintf("%s:%s", s.prefix, state)
+}
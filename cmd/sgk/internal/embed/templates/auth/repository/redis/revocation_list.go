@@ -0,0 +1,48 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// RevocationList stores revoked session ids until the last access token
+// issued for them expires, which keeps the list small.
+type RevocationList struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewRevocationList(client *redis.Client, prefix string) authinterface.RevocationList {
+	if prefix == "" {
+		prefix = "revoked"
+	}
+	return &RevocationList{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+func (l *RevocationList) Revoke(ctx context.Context, sessionID uuid.UUID, until time.Time) error {
+	ttl := time.Until(until)
+	if ttl <= 0 {
+		return nil
+	}
+	return l.client.Set(ctx, l.key(sessionID), 1, ttl).Err()
+}
+
+func (l *RevocationList) IsRevoked(ctx context.Context, sessionID uuid.UUID) (bool, error) {
+	count, err := l.client.Exists(ctx, l.key(sessionID)).Result()
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (l *RevocationList) key(sessionID uuid.UUID) string {
+	return fmt.Sprintf("%s:%s", l.prefix, sessionID)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 	"time"
 	
 	authinterface "{{.Project.GoModule}}/internal/auth/interface"
@@ -13,6 +14,11 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+var errSessionNotFound = errors.New("session not found")
+
+// SessionStore keeps each session under <prefix>:id:<session id>, indexed by
+// its access and refresh tokens. The session ids of a user are tracked in the
+// <prefix>:user:<user id> set so every device can be listed and revoked.
 type SessionStore struct {
 	client *redis.Client
 	prefix string
@@ -39,83 +45,163 @@ func (s *SessionStore) Store(ctx context.Context, session authinterface.Session)
 		return errors.New("session already expired")
 	}
 	
-	tokenKey := s.key("token", session.GetToken())
-	if err := s.client.Set(ctx, tokenKey, data, ttl).Err(); err != nil {
-		return err
+	refreshTTL := time.Until(session.GetRefreshExpiresAt())
+	if refreshTTL < ttl {
+		refreshTTL = ttl
 	}
 	
-	refreshTTL := time.Until(session.GetRefreshExpiresAt())
-	if refreshTTL > 0 {
-		refreshKey := s.key("refresh", session.GetRefreshToken())
-		if err := s.client.Set(ctx, refreshKey, data, refreshTTL).Err(); err != nil {
-			s.client.Del(ctx, tokenKey)
-			return err
+	sessionID := session.GetID().String()
+	userKey := s.key("user", session.GetUserID().String())
+	
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, s.key("id", sessionID), data, refreshTTL)
+		pipe.Set(ctx, s.key("token", session.GetToken()), sessionID, ttl)
+		if session.GetRefreshToken() != "" {
+			pipe.Set(ctx, s.key("refresh", session.GetRefreshToken()), sessionID, refreshTTL)
 		}
+		pipe.SAdd(ctx, userKey, sessionID)
+		// The index must outlive the user's longest session, so a shorter
+		// one only sets its TTL when it has none and never lowers it.
+		pipe.Do(ctx, "PEXPIRE", userKey, refreshTTL.Milliseconds(), "NX")
+		pipe.Do(ctx, "PEXPIRE", userKey, refreshTTL.Milliseconds(), "GT")
+		return nil
+	})
+	
+	return err
+}
+
+func (s *SessionStore) Get(ctx context.Context, token string) (authinterface.Session, error) {
+	return s.getByIndex(ctx, s.key("token", token))
+}
+
+func (s *SessionStore) GetByID(ctx context.Context, sessionID uuid.UUID) (authinterface.Session, error) {
+	session, err := s.load(ctx, sessionID.String())
+	if err != nil {
+		return nil, err
 	}
+	return session, nil
+}
+
+func (s *SessionStore) GetByRefreshToken(ctx context.Context, refreshToken string) (authinterface.Session, error) {
+	return s.getByIndex(ctx, s.key("refresh", refreshToken))
+}
+
+func (s *SessionStore) ListByUser(ctx context.Context, userID uuid.UUID) ([]authinterface.Session, error) {
+	userKey := s.key("user", userID.String())
 	
-	userKey := s.key("user", session.GetUserID().String())
-	if err := s.client.Set(ctx, userKey, session.GetToken(), ttl).Err(); err != nil {
-		s.client.Del(ctx, tokenKey)
-		s.client.Del(ctx, s.key("refresh", session.GetRefreshToken()))
-		return err
+	ids, err := s.client.SMembers(ctx, userKey).Result()
+	if err != nil {
+		return nil, err
 	}
 	
-	return nil
+	sessions := make([]authinterface.Session, 0, len(ids))
+	for _, id := range ids {
+		session, err := s.load(ctx, id)
+		if errors.Is(err, errSessionNotFound) {
+			s.client.SRem(ctx, userKey, id)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].GetLastSeenAt().After(sessions[j].GetLastSeenAt())
+	})
+	
+	return sessions, nil
 }
 
-func (s *SessionStore) Get(ctx context.Context, token string) (authinterface.Session, error) {
-	key := s.key("token", token)
-	data, err := s.client.Get(ctx, key).Bytes()
+// Rotate replaces the session's refresh token with next's. The old token is
+// consumed atomically, so only one of several concurrent refreshes succeeds,
+// and it is remembered under <prefix>:rotated:<token> to detect later reuse.
+func (s *SessionStore) Rotate(ctx context.Context, refreshToken string, next authinterface.Session) error {
+	id, err := s.client.GetDel(ctx, s.key("refresh", refreshToken)).Result()
 	if err != nil {
 		if errors.Is(err, redis.Nil) {
-			return nil, errors.New("session not found")
+			return authinterface.ErrRefreshTokenReused
 		}
-		return nil, err
+		return err
 	}
 	
-	var session authmodel.Session
-	if err := json.Unmarshal(data, &session); err != nil {
-		return nil, err
+	rotatedTTL := time.Until(next.GetRefreshExpiresAt())
+	if previous, err := s.load(ctx, id); err == nil {
+		s.client.Del(ctx, s.key("token", previous.Token))
+		rotatedTTL = time.Until(previous.RefreshExpiresAt)
 	}
 	
-	return &session, nil
+	if rotatedTTL > 0 {
+		if err := s.client.Set(ctx, s.key("rotated", refreshToken), id, rotatedTTL).Err(); err != nil {
+			return err
+		}
+	}
+	
+	return s.Store(ctx, next)
 }
 
-func (s *SessionStore) GetByRefreshToken(ctx context.Context, refreshToken string) (authinterface.Session, error) {
-	key := s.key("refresh", refreshToken)
-	data, err := s.client.Get(ctx, key).Bytes()
+func (s *SessionStore) GetFamilyByRotatedToken(ctx context.Context, refreshToken string) (uuid.UUID, error) {
+	id, err := s.client.Get(ctx, s.key("rotated", refreshToken)).Result()
 	if err != nil {
 		if errors.Is(err, redis.Nil) {
-			return nil, errors.New("session not found")
+			return uuid.Nil, errSessionNotFound
 		}
-		return nil, err
+		return uuid.Nil, err
 	}
 	
-	var session authmodel.Session
-	if err := json.Unmarshal(data, &session); err != nil {
-		return nil, err
+	return uuid.Parse(id)
+}
+
+func (s *SessionStore) Touch(ctx context.Context, sessionID uuid.UUID, seenAt time.Time) error {
+	session, err := s.load(ctx, sessionID.String())
+	if err != nil {
+		return err
 	}
 	
-	return &session, nil
+	session.LastSeenAt = seenAt
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	
+	return s.client.SetArgs(ctx, s.key("id", sessionID.String()), data, redis.SetArgs{KeepTTL: true, Mode: "XX"}).Err()
 }
 
 func (s *SessionStore) Delete(ctx context.Context, userID uuid.UUID) error {
 	userKey := s.key("user", userID.String())
 	
-	token, err := s.client.Get(ctx, userKey).Result()
-	if err != nil && !errors.Is(err, redis.Nil) {
+	ids, err := s.client.SMembers(ctx, userKey).Result()
+	if err != nil {
 		return err
 	}
 	
-	if token != "" {
-		session, err := s.Get(ctx, token)
-		if err == nil {
-			s.client.Del(ctx, s.key("refresh", session.GetRefreshToken()))
+	keys := []string{userKey}
+	for _, id := range ids {
+		session, err := s.load(ctx, id)
+		if err != nil {
+			keys = append(keys, s.key("id", id))
+			continue
 		}
-		s.client.Del(ctx, s.key("token", token))
+		keys = append(keys, s.sessionKeys(session)...)
+	}
+	
+	return s.client.Del(ctx, keys...).Err()
+}
+
+func (s *SessionStore) DeleteByID(ctx context.Context, sessionID uuid.UUID) error {
+	session, err := s.load(ctx, sessionID.String())
+	if err != nil {
+		return err
 	}
 	
-	return s.client.Del(ctx, userKey).Err()
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, s.sessionKeys(session)...)
+		pipe.SRem(ctx, s.key("user", session.UserID.String()), sessionID.String())
+		return nil
+	})
+	
+	return err
 }
 
 func (s *SessionStore) DeleteByToken(ctx context.Context, token string) error {
@@ -124,17 +210,78 @@ func (s *SessionStore) DeleteByToken(ctx context.Context, token string) error {
 		return err
 	}
 	
-	keys := []string{
-		s.key("token", token),
-		s.key("refresh", session.GetRefreshToken()),
-		s.key("user", session.GetUserID().String()),
+	return s.DeleteByID(ctx, session.GetID())
+}
+
+// DeleteExpired drops ids whose session record has expired from the per-user
+// sets. The records and token indexes themselves expire through their TTLs.
+func (s *SessionStore) DeleteExpired(ctx context.Context) error {
+	iter := s.client.Scan(ctx, 0, s.key("user", "*"), 100).Iterator()
+	for iter.Next(ctx) {
+		userKey := iter.Val()
+		
+		ids, err := s.client.SMembers(ctx, userKey).Result()
+		if err != nil {
+			return err
+		}
+		
+		for _, id := range ids {
+			exists, err := s.client.Exists(ctx, s.key("id", id)).Result()
+			if err != nil {
+				return err
+			}
+			if exists == 0 {
+				s.client.SRem(ctx, userKey, id)
+			}
+		}
 	}
 	
-	return s.client.Del(ctx, keys...).Err()
+	return iter.Err()
 }
 
-func (s *SessionStore) DeleteExpired(ctx context.Context) error {
-	return nil
+func (s *SessionStore) getByIndex(ctx context.Context, indexKey string) (authinterface.Session, error) {
+	id, err := s.client.Get(ctx, indexKey).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, errSessionNotFound
+		}
+		return nil, err
+	}
+	
+	session, err := s.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (s *SessionStore) load(ctx context.Context, id string) (*authmodel.Session, error) {
+	data, err := s.client.Get(ctx, s.key("id", id)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, errSessionNotFound
+		}
+		return nil, err
+	}
+	
+	var session authmodel.Session
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, err
+	}
+	
+	return &session, nil
+}
+
+// sessionKeys returns the record and token index keys of a session.
+func (s *SessionStore) sessionKeys(session *authmodel.Session) []string {
+	keys := []string{
+		s.key("id", session.ID.String()),
+		s.key("token", session.Token),
+	}
+	if session.RefreshToken != "" {
+		keys = append(keys, s.key("refresh", session.RefreshToken))
+	}
+	return keys
 }
 
 func (s *SessionStore) key(parts ...string) string {
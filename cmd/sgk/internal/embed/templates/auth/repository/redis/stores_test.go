@@ -0,0 +1,69 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	"{{.Project.GoModule}}/internal/auth/repository/sessionstoretest"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// newTestClient starts an in-process Redis server. miniredis only expires
+// keys when its clock is moved, so the clock is advanced alongside real time.
+func newTestClient(t *testing.T) *redis.Client {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+
+	stop := make(chan struct{})
+	t.Cleanup(func() { close(stop) })
+	go func() {
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				server.FastForward(10 * time.Millisecond)
+			}
+		}
+	}()
+
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestSessionStore(t *testing.T) {
+	sessionstoretest.Run(t, func(t *testing.T) authinterface.SessionStore {
+		return NewSessionStore(newTestClient(t), "session")
+	})
+}
+
+func TestLoginAttemptTracker(t *testing.T) {
+	sessionstoretest.RunLoginAttemptTracker(t, func(t *testing.T) authinterface.LoginAttemptTracker {
+		return NewLoginAttemptTracker(newTestClient(t), "login_attempts")
+	})
+}
+
+func TestRevocationList(t *testing.T) {
+	sessionstoretest.RunRevocationList(t, func(t *testing.T) authinterface.RevocationList {
+		return NewRevocationList(newTestClient(t), "revoked")
+	})
+}
+
+func TestOAuthStateStore(t *testing.T) {
+	sessionstoretest.RunOAuthStateStore(t, func(t *testing.T) authinterface.OAuthStateStore {
+		return NewOAuthStateStore(newTestClient(t), "oauth_state")
+	})
+}
+
+func TestWebAuthnSessionStore(t *testing.T) {
+	sessionstoretest.RunWebAuthnSessionStore(t, func(t *testing.T) authinterface.WebAuthnSessionStore {
+		return NewWebAuthnSessionStore(newTestClient(t), "webauthn")
+	})
+}
@@ -0,0 +1,55 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	"github.com/redis/go-redis/v9"
+)
+
+type WebAuthnSessionStore struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewWebAuthnSessionStore(client *redis.Client, prefix string) authinterface.WebAuthnSessionStore {
+	if prefix == "" {
+		prefix = "webauthn"
+	}
+	return &WebAuthnSessionStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+func (s *WebAuthnSessionStore) Save(ctx context.Context, session *authinterface.WebAuthnSession, expiration time.Duration) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webauthn session: %w", err)
+	}
+	return s.client.Set(ctx, s.key(session.Challenge), data, expiration).Err()
+}
+
+func (s *WebAuthnSessionStore) Consume(ctx context.Context, challenge string) (*authinterface.WebAuthnSession, error) {
+	data, err := s.client.GetDel(ctx, s.key(challenge)).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, errors.New("webauthn session not found")
+		}
+		return nil, err
+	}
+
+	var session authinterface.WebAuthnSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal webauthn session: %w", err)
+	}
+	return &session, nil
+}
+
+func (s *WebAuthnSessionStore) key(challenge string) string {
+	return fmt.Sprintf("%s:%s", s.prefix, challenge)
+}
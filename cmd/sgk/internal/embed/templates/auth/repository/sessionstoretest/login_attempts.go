@@ -0,0 +1,159 @@
+package sessionstoretest
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+)
+
+// RunLoginAttemptTracker checks a LoginAttemptTracker the same way Run checks
+// a SessionStore.
+func RunLoginAttemptTracker(t *testing.T, newTracker func(t *testing.T) authinterface.LoginAttemptTracker) {
+	t.Run("UnknownKey", func(t *testing.T) {
+		attempts, err := newTracker(t).Get(context.Background(), "unknown")
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		if attempts.Failures != 0 || attempts.IsLocked(time.Now()) {
+			t.Errorf("got %+v for an unknown key, want no failures and no lock", attempts)
+		}
+	})
+
+	t.Run("RecordFailure", func(t *testing.T) {
+		ctx := context.Background()
+		tracker := newTracker(t)
+
+		recordFailure(t, tracker, "a", time.Hour)
+		attempts := recordFailure(t, tracker, "a", time.Hour)
+		if attempts.Failures != 2 {
+			t.Errorf("RecordFailure returned %d failures, want 2", attempts.Failures)
+		}
+		if time.Since(attempts.LastFailureAt) > time.Minute {
+			t.Errorf("last failure at %v, want about now", attempts.LastFailureAt)
+		}
+
+		other, err := tracker.Get(ctx, "b")
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		if other.Failures != 0 {
+			t.Errorf("failures leaked to another key: %d", other.Failures)
+		}
+	})
+
+	t.Run("ConcurrentFailures", func(t *testing.T) {
+		tracker := newTracker(t)
+
+		const failures = 10
+		var wg sync.WaitGroup
+		for i := 0; i < failures; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := tracker.RecordFailure(context.Background(), "a", time.Hour); err != nil {
+					t.Errorf("RecordFailure: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		attempts, err := tracker.Get(context.Background(), "a")
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		if attempts.Failures != failures {
+			t.Errorf("got %d failures after %d concurrent ones", attempts.Failures, failures)
+		}
+	})
+
+	t.Run("WindowExpiry", func(t *testing.T) {
+		tracker := newTracker(t)
+		recordFailure(t, tracker, "a", expiryWait/2)
+
+		time.Sleep(expiryWait)
+
+		attempts, err := tracker.Get(context.Background(), "a")
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		if attempts.Failures != 0 {
+			t.Errorf("failures were kept past their window: %d", attempts.Failures)
+		}
+		if attempts := recordFailure(t, tracker, "a", time.Hour); attempts.Failures != 1 {
+			t.Errorf("counting restarted at %d, want 1", attempts.Failures)
+		}
+	})
+
+	t.Run("Lock", func(t *testing.T) {
+		ctx := context.Background()
+		tracker := newTracker(t)
+		recordFailure(t, tracker, "a", time.Hour)
+
+		until := time.Now().Add(time.Hour)
+		if err := tracker.Lock(ctx, "a", until); err != nil {
+			t.Fatalf("Lock: %v", err)
+		}
+
+		attempts, err := tracker.Get(ctx, "a")
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		if !attempts.IsLocked(time.Now()) || attempts.IsLocked(until.Add(time.Second)) {
+			t.Errorf("got lock until %v, want %v", attempts.LockedUntil, until)
+		}
+		if attempts.Failures != 0 {
+			t.Errorf("Lock kept %d failures, want the count cleared", attempts.Failures)
+		}
+	})
+
+	t.Run("LockExpiry", func(t *testing.T) {
+		ctx := context.Background()
+		tracker := newTracker(t)
+		if err := tracker.Lock(ctx, "a", time.Now().Add(expiryWait/2)); err != nil {
+			t.Fatalf("Lock: %v", err)
+		}
+
+		time.Sleep(expiryWait)
+
+		attempts, err := tracker.Get(ctx, "a")
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		if attempts.IsLocked(time.Now()) {
+			t.Error("the key is still locked after the lock ended")
+		}
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		ctx := context.Background()
+		tracker := newTracker(t)
+		recordFailure(t, tracker, "a", time.Hour)
+		if err := tracker.Lock(ctx, "a", time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("Lock: %v", err)
+		}
+
+		if err := tracker.Reset(ctx, "a"); err != nil {
+			t.Fatalf("Reset: %v", err)
+		}
+
+		attempts, err := tracker.Get(ctx, "a")
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		if attempts.Failures != 0 || attempts.IsLocked(time.Now()) {
+			t.Errorf("got %+v after Reset, want no failures and no lock", attempts)
+		}
+	})
+}
+
+func recordFailure(t *testing.T, tracker authinterface.LoginAttemptTracker, key string, window time.Duration) *authinterface.LoginAttempts {
+	t.Helper()
+	attempts, err := tracker.RecordFailure(context.Background(), key, window)
+	if err != nil {
+		t.Fatalf("RecordFailure: %v", err)
+	}
+	return attempts
+}
@@ -0,0 +1,145 @@
+package sessionstoretest
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	"github.com/google/uuid"
+)
+
+// RunOAuthStateStore checks an OAuthStateStore the same way Run checks a
+// SessionStore.
+func RunOAuthStateStore(t *testing.T, newStore func(t *testing.T) authinterface.OAuthStateStore) {
+	runConsumeOnce(t, func(t *testing.T) consumeOnceStore {
+		store := newStore(t)
+		return consumeOnceStore{
+			save: func(key string, expiration time.Duration) error {
+				return store.Save(context.Background(), &authinterface.OAuthState{
+					State:        key,
+					Provider:     "oidc",
+					Nonce:        "nonce-" + key,
+					CodeVerifier: "verifier-" + key,
+					AccountID:    uuid.New(),
+					CreatedAt:    time.Now(),
+				}, expiration)
+			},
+			consume: func(key string) error {
+				state, err := store.Consume(context.Background(), key)
+				if err != nil {
+					return err
+				}
+				if state.State != key || state.Nonce != "nonce-"+key || state.CodeVerifier != "verifier-"+key || state.Provider != "oidc" {
+					t.Errorf("Consume returned %+v, not the state saved under %q", state, key)
+				}
+				return nil
+			},
+		}
+	})
+}
+
+// RunWebAuthnSessionStore checks a WebAuthnSessionStore the same way Run
+// checks a SessionStore.
+func RunWebAuthnSessionStore(t *testing.T, newStore func(t *testing.T) authinterface.WebAuthnSessionStore) {
+	runConsumeOnce(t, func(t *testing.T) consumeOnceStore {
+		store := newStore(t)
+		accountID := uuid.New()
+		return consumeOnceStore{
+			save: func(key string, expiration time.Duration) error {
+				return store.Save(context.Background(), &authinterface.WebAuthnSession{
+					Challenge: key,
+					AccountID: accountID,
+					CreatedAt: time.Now(),
+				}, expiration)
+			},
+			consume: func(key string) error {
+				session, err := store.Consume(context.Background(), key)
+				if err != nil {
+					return err
+				}
+				if session.Challenge != key || session.AccountID != accountID {
+					t.Errorf("Consume returned %+v, not the session saved under %q", session, key)
+				}
+				return nil
+			},
+		}
+	})
+}
+
+// consumeOnceStore adapts the OAuth state and WebAuthn session stores, which
+// share their save-then-consume-once contract.
+type consumeOnceStore struct {
+	save    func(key string, expiration time.Duration) error
+	consume func(key string) error
+}
+
+func runConsumeOnce(t *testing.T, newStore func(t *testing.T) consumeOnceStore) {
+	t.Run("Consume", func(t *testing.T) {
+		store := newStore(t)
+		key := uuid.NewString()
+		if err := store.save(key, time.Hour); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+
+		if err := store.consume(key); err != nil {
+			t.Fatalf("Consume: %v", err)
+		}
+		if err := store.consume(key); err == nil {
+			t.Error("a value could be consumed twice")
+		}
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		if err := newStore(t).consume(uuid.NewString()); err == nil {
+			t.Error("Consume found an unknown key")
+		}
+	})
+
+	t.Run("Expiry", func(t *testing.T) {
+		store := newStore(t)
+		key := uuid.NewString()
+		if err := store.save(key, expiryWait/2); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+
+		time.Sleep(expiryWait)
+
+		if err := store.consume(key); err == nil {
+			t.Error("an expired value could be consumed")
+		}
+	})
+
+	t.Run("ConcurrentConsume", func(t *testing.T) {
+		store := newStore(t)
+		key := uuid.NewString()
+		if err := store.save(key, time.Hour); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+
+		const attempts = 8
+		var (
+			wg        sync.WaitGroup
+			mu        sync.Mutex
+			succeeded int
+		)
+		for i := 0; i < attempts; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				err := store.consume(key)
+				mu.Lock()
+				defer mu.Unlock()
+				if err == nil {
+					succeeded++
+				}
+			}()
+		}
+		wg.Wait()
+
+		if succeeded != 1 {
+			t.Errorf("%d of %d concurrent consumers got the value, want exactly 1", succeeded, attempts)
+		}
+	})
+}
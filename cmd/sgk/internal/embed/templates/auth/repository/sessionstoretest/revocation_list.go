@@ -0,0 +1,60 @@
+package sessionstoretest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	"github.com/google/uuid"
+)
+
+// RunRevocationList checks a RevocationList the same way Run checks a
+// SessionStore.
+func RunRevocationList(t *testing.T, newList func(t *testing.T) authinterface.RevocationList) {
+	t.Run("Revoke", func(t *testing.T) {
+		ctx := context.Background()
+		list := newList(t)
+		sessionID := uuid.New()
+
+		if err := list.Revoke(ctx, sessionID, time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("Revoke: %v", err)
+		}
+
+		assertRevoked(t, list, sessionID, true)
+		assertRevoked(t, list, uuid.New(), false)
+	})
+
+	t.Run("RevokeInPast", func(t *testing.T) {
+		list := newList(t)
+		sessionID := uuid.New()
+
+		if err := list.Revoke(context.Background(), sessionID, time.Now().Add(-time.Minute)); err != nil {
+			t.Fatalf("Revoke: %v", err)
+		}
+		assertRevoked(t, list, sessionID, false)
+	})
+
+	t.Run("Expiry", func(t *testing.T) {
+		list := newList(t)
+		sessionID := uuid.New()
+
+		if err := list.Revoke(context.Background(), sessionID, time.Now().Add(expiryWait/2)); err != nil {
+			t.Fatalf("Revoke: %v", err)
+		}
+
+		time.Sleep(expiryWait)
+		assertRevoked(t, list, sessionID, false)
+	})
+}
+
+func assertRevoked(t *testing.T, list authinterface.RevocationList, sessionID uuid.UUID, want bool) {
+	t.Helper()
+	revoked, err := list.IsRevoked(context.Background(), sessionID)
+	if err != nil {
+		t.Fatalf("IsRevoked: %v", err)
+	}
+	if revoked != want {
+		t.Errorf("IsRevoked(%s) = %v, want %v", sessionID, revoked, want)
+	}
+}
@@ -0,0 +1,434 @@
+package sessionstoretest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	authmodel "{{.Project.GoModule}}/internal/auth/model"
+	"github.com/google/uuid"
+)
+
+// Run checks that a SessionStore behaves the way the auth service relies on.
+// Every implementation must pass it, and the Run functions for the other
+// stores selected by AUTH_SESSION_STORE likewise. Call it from the store's
+// own test with a constructor returning an empty store:
+//
+//	func TestSessionStore(t *testing.T) {
+//		sessionstoretest.Run(t, func(t *testing.T) authinterface.SessionStore {
+//			return authmemory.NewSessionStore()
+//		})
+//	}
+func Run(t *testing.T, newStore func(t *testing.T) authinterface.SessionStore) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, store authinterface.SessionStore)
+	}{
+		{"StoreAndGet", testStoreAndGet},
+		{"StoreRejectsExpired", testStoreRejectsExpired},
+		{"UnknownSession", testUnknownSession},
+		{"ListByUser", testListByUser},
+		{"Touch", testTouch},
+		{"Rotate", testRotate},
+		{"ConcurrentRotate", testConcurrentRotate},
+		{"DeleteByID", testDeleteByID},
+		{"DeleteByToken", testDeleteByToken},
+		{"DeleteByUser", testDeleteByUser},
+		{"AccessTokenExpiry", testAccessTokenExpiry},
+		{"SessionExpiry", testSessionExpiry},
+		{"ShortSessionKeepsOthers", testShortSessionKeepsOthers},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newStore(t))
+		})
+	}
+}
+
+// expiryWait is how long the expiry tests wait for short-lived sessions to
+// lapse.
+const expiryWait = 300 * time.Millisecond
+
+func newSession(userID uuid.UUID) *authmodel.Session {
+	// Whole seconds survive every backend's timestamp precision
+	now := time.Now().Truncate(time.Second)
+	return &authmodel.Session{
+		ID:               uuid.New(),
+		UserID:           userID,
+		Token:            uuid.NewString(),
+		RefreshToken:     uuid.NewString(),
+		ExpiresAt:        now.Add(15 * time.Minute),
+		RefreshExpiresAt: now.Add(24 * time.Hour),
+		UserAgent:        "sessionstoretest",
+		IPAddress:        "192.0.2.1",
+		CreatedAt:        now,
+		LastSeenAt:       now,
+	}
+}
+
+// next returns the session that replaces session on refresh: same id and
+// user, new tokens.
+func next(session *authmodel.Session) *authmodel.Session {
+	rotated := *session
+	rotated.Token = uuid.NewString()
+	rotated.RefreshToken = uuid.NewString()
+	return &rotated
+}
+
+func store(t *testing.T, s authinterface.SessionStore, session *authmodel.Session) {
+	t.Helper()
+	if err := s.Store(context.Background(), session); err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+}
+
+func assertSession(t *testing.T, got authinterface.Session, want *authmodel.Session) {
+	t.Helper()
+	if got == nil {
+		t.Fatalf("got no session, want %s", want.ID)
+	}
+	if got.GetID() != want.ID || got.GetUserID() != want.UserID {
+		t.Errorf("got session %s of user %s, want %s of user %s", got.GetID(), got.GetUserID(), want.ID, want.UserID)
+	}
+	if got.GetToken() != want.Token || got.GetRefreshToken() != want.RefreshToken {
+		t.Errorf("session tokens do not match what was stored")
+	}
+	if !got.GetExpiresAt().Equal(want.ExpiresAt) || !got.GetRefreshExpiresAt().Equal(want.RefreshExpiresAt) {
+		t.Errorf("got expiry %v/%v, want %v/%v", got.GetExpiresAt(), got.GetRefreshExpiresAt(), want.ExpiresAt, want.RefreshExpiresAt)
+	}
+	if got.GetUserAgent() != want.UserAgent || got.GetIPAddress() != want.IPAddress {
+		t.Errorf("got device %q from %q, want %q from %q", got.GetUserAgent(), got.GetIPAddress(), want.UserAgent, want.IPAddress)
+	}
+	if !got.GetLastSeenAt().Equal(want.LastSeenAt) {
+		t.Errorf("got last seen %v, want %v", got.GetLastSeenAt(), want.LastSeenAt)
+	}
+	if got.GetImpersonatorID() != want.GetImpersonatorID() {
+		t.Errorf("got impersonator %s, want %s", got.GetImpersonatorID(), want.GetImpersonatorID())
+	}
+}
+
+func assertGone(t *testing.T, s authinterface.SessionStore, session *authmodel.Session) {
+	t.Helper()
+	ctx := context.Background()
+	if _, err := s.Get(ctx, session.Token); err == nil {
+		t.Errorf("Get still finds session %s", session.ID)
+	}
+	if _, err := s.GetByID(ctx, session.ID); err == nil {
+		t.Errorf("GetByID still finds session %s", session.ID)
+	}
+	if _, err := s.GetByRefreshToken(ctx, session.RefreshToken); err == nil {
+		t.Errorf("GetByRefreshToken still finds session %s", session.ID)
+	}
+}
+
+func testStoreAndGet(t *testing.T, s authinterface.SessionStore) {
+	ctx := context.Background()
+	session := newSession(uuid.New())
+	impersonatorID := uuid.New()
+	session.ImpersonatorID = &impersonatorID
+	store(t, s, session)
+
+	got, err := s.Get(ctx, session.Token)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	assertSession(t, got, session)
+
+	got, err = s.GetByID(ctx, session.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	assertSession(t, got, session)
+
+	got, err = s.GetByRefreshToken(ctx, session.RefreshToken)
+	if err != nil {
+		t.Fatalf("GetByRefreshToken: %v", err)
+	}
+	assertSession(t, got, session)
+}
+
+func testStoreRejectsExpired(t *testing.T, s authinterface.SessionStore) {
+	session := newSession(uuid.New())
+	session.ExpiresAt = time.Now().Add(-time.Minute)
+
+	if err := s.Store(context.Background(), session); err == nil {
+		t.Fatal("Store accepted an expired session")
+	}
+}
+
+func testUnknownSession(t *testing.T, s authinterface.SessionStore) {
+	ctx := context.Background()
+	assertGone(t, s, newSession(uuid.New()))
+
+	if _, err := s.GetFamilyByRotatedToken(ctx, uuid.NewString()); err == nil {
+		t.Error("GetFamilyByRotatedToken found an unknown token")
+	}
+	if err := s.Touch(ctx, uuid.New(), time.Now()); err == nil {
+		t.Error("Touch succeeded for an unknown session")
+	}
+	if err := s.DeleteByID(ctx, uuid.New()); err == nil {
+		t.Error("DeleteByID succeeded for an unknown session")
+	}
+	if err := s.DeleteByToken(ctx, uuid.NewString()); err == nil {
+		t.Error("DeleteByToken succeeded for an unknown token")
+	}
+
+	sessions, err := s.ListByUser(ctx, uuid.New())
+	if err != nil {
+		t.Fatalf("ListByUser: %v", err)
+	}
+	if len(sessions) != 0 {
+		t.Errorf("ListByUser returned %d sessions for an unknown user", len(sessions))
+	}
+}
+
+func testListByUser(t *testing.T, s authinterface.SessionStore) {
+	userID := uuid.New()
+
+	oldest := newSession(userID)
+	oldest.LastSeenAt = oldest.LastSeenAt.Add(-2 * time.Hour)
+	newest := newSession(userID)
+	middle := newSession(userID)
+	middle.LastSeenAt = middle.LastSeenAt.Add(-time.Hour)
+
+	for _, session := range []*authmodel.Session{oldest, newest, middle, newSession(uuid.New())} {
+		store(t, s, session)
+	}
+
+	sessions, err := s.ListByUser(context.Background(), userID)
+	if err != nil {
+		t.Fatalf("ListByUser: %v", err)
+	}
+	if len(sessions) != 3 {
+		t.Fatalf("ListByUser returned %d sessions, want 3", len(sessions))
+	}
+	for i, want := range []*authmodel.Session{newest, middle, oldest} {
+		if sessions[i].GetID() != want.ID {
+			t.Errorf("session %d is %s, want %s; sessions must be ordered by last seen, newest first", i, sessions[i].GetID(), want.ID)
+		}
+	}
+}
+
+func testTouch(t *testing.T, s authinterface.SessionStore) {
+	ctx := context.Background()
+	session := newSession(uuid.New())
+	store(t, s, session)
+
+	seenAt := session.LastSeenAt.Add(time.Minute)
+	if err := s.Touch(ctx, session.ID, seenAt); err != nil {
+		t.Fatalf("Touch: %v", err)
+	}
+
+	session.LastSeenAt = seenAt
+	got, err := s.Get(ctx, session.Token)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	assertSession(t, got, session)
+}
+
+func testRotate(t *testing.T, s authinterface.SessionStore) {
+	ctx := context.Background()
+	session := newSession(uuid.New())
+	store(t, s, session)
+
+	rotated := next(session)
+	if err := s.Rotate(ctx, session.RefreshToken, rotated); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+
+	if _, err := s.Get(ctx, session.Token); err == nil {
+		t.Error("the previous access token still resolves after Rotate")
+	}
+	if _, err := s.GetByRefreshToken(ctx, session.RefreshToken); err == nil {
+		t.Error("the previous refresh token still resolves after Rotate")
+	}
+
+	got, err := s.GetByRefreshToken(ctx, rotated.RefreshToken)
+	if err != nil {
+		t.Fatalf("GetByRefreshToken: %v", err)
+	}
+	assertSession(t, got, rotated)
+
+	family, err := s.GetFamilyByRotatedToken(ctx, session.RefreshToken)
+	if err != nil {
+		t.Fatalf("GetFamilyByRotatedToken: %v", err)
+	}
+	if family != session.ID {
+		t.Errorf("GetFamilyByRotatedToken = %s, want %s", family, session.ID)
+	}
+
+	if err := s.Rotate(ctx, session.RefreshToken, next(rotated)); !errors.Is(err, authinterface.ErrRefreshTokenReused) {
+		t.Errorf("Rotate with a used token returned %v, want ErrRefreshTokenReused", err)
+	}
+	if err := s.Rotate(ctx, uuid.NewString(), next(rotated)); !errors.Is(err, authinterface.ErrRefreshTokenReused) {
+		t.Errorf("Rotate with an unknown token returned %v, want ErrRefreshTokenReused", err)
+	}
+}
+
+func testConcurrentRotate(t *testing.T, s authinterface.SessionStore) {
+	session := newSession(uuid.New())
+	store(t, s, session)
+
+	const attempts = 8
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		succeeded int
+	)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := s.Rotate(context.Background(), session.RefreshToken, next(session))
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil {
+				succeeded++
+			}
+		}()
+	}
+	wg.Wait()
+
+	if succeeded != 1 {
+		t.Errorf("%d of %d concurrent rotations succeeded, want exactly 1", succeeded, attempts)
+	}
+}
+
+func testDeleteByID(t *testing.T, s authinterface.SessionStore) {
+	ctx := context.Background()
+	userID := uuid.New()
+	session, other := newSession(userID), newSession(userID)
+	store(t, s, session)
+	store(t, s, other)
+
+	if err := s.DeleteByID(ctx, session.ID); err != nil {
+		t.Fatalf("DeleteByID: %v", err)
+	}
+	assertGone(t, s, session)
+
+	sessions, err := s.ListByUser(ctx, userID)
+	if err != nil {
+		t.Fatalf("ListByUser: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].GetID() != other.ID {
+		t.Errorf("ListByUser after DeleteByID returned %d sessions, want only %s", len(sessions), other.ID)
+	}
+}
+
+func testDeleteByToken(t *testing.T, s authinterface.SessionStore) {
+	ctx := context.Background()
+	session := newSession(uuid.New())
+	store(t, s, session)
+
+	if err := s.DeleteByToken(ctx, session.Token); err != nil {
+		t.Fatalf("DeleteByToken: %v", err)
+	}
+	assertGone(t, s, session)
+}
+
+func testDeleteByUser(t *testing.T, s authinterface.SessionStore) {
+	ctx := context.Background()
+	userID := uuid.New()
+	first, second, other := newSession(userID), newSession(userID), newSession(uuid.New())
+	for _, session := range []*authmodel.Session{first, second, other} {
+		store(t, s, session)
+	}
+
+	if err := s.Delete(ctx, userID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	assertGone(t, s, first)
+	assertGone(t, s, second)
+
+	if _, err := s.Get(ctx, other.Token); err != nil {
+		t.Errorf("Delete removed another user's session: %v", err)
+	}
+}
+
+// testAccessTokenExpiry checks that an expired access token is rejected while
+// the session can still be refreshed.
+func testAccessTokenExpiry(t *testing.T, s authinterface.SessionStore) {
+	ctx := context.Background()
+	session := newSession(uuid.New())
+	session.ExpiresAt = time.Now().Add(expiryWait / 2)
+	store(t, s, session)
+
+	time.Sleep(expiryWait)
+
+	if _, err := s.Get(ctx, session.Token); err == nil {
+		t.Error("Get still finds the session after its access token expired")
+	}
+	if _, err := s.GetByRefreshToken(ctx, session.RefreshToken); err != nil {
+		t.Errorf("GetByRefreshToken: %v", err)
+	}
+	if _, err := s.GetByID(ctx, session.ID); err != nil {
+		t.Errorf("GetByID: %v", err)
+	}
+}
+
+// testSessionExpiry checks that a session is gone once its refresh token has
+// expired too, and that DeleteExpired copes with it.
+func testSessionExpiry(t *testing.T, s authinterface.SessionStore) {
+	ctx := context.Background()
+	session := newSession(uuid.New())
+	session.ExpiresAt = time.Now().Add(expiryWait / 2)
+	session.RefreshExpiresAt = session.ExpiresAt
+	live := newSession(session.UserID)
+	store(t, s, session)
+	store(t, s, live)
+
+	time.Sleep(expiryWait)
+
+	assertGone(t, s, session)
+	if err := s.Touch(ctx, session.ID, time.Now()); err == nil {
+		t.Error("Touch succeeded for an expired session")
+	}
+
+	if err := s.DeleteExpired(ctx); err != nil {
+		t.Fatalf("DeleteExpired: %v", err)
+	}
+	assertGone(t, s, session)
+
+	sessions, err := s.ListByUser(ctx, session.UserID)
+	if err != nil {
+		t.Fatalf("ListByUser: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].GetID() != live.ID {
+		t.Errorf("ListByUser returned %d sessions, want only the live one", len(sessions))
+	}
+}
+
+// testShortSessionKeepsOthers checks that a short-lived session, such as an
+// impersonation, expiring does not hide the user's longer-lived sessions
+// from ListByUser and Delete.
+func testShortSessionKeepsOthers(t *testing.T, s authinterface.SessionStore) {
+	ctx := context.Background()
+	userID := uuid.New()
+	long := newSession(userID)
+	short := newSession(userID)
+	short.ExpiresAt = time.Now().Add(expiryWait / 2)
+	short.RefreshExpiresAt = short.ExpiresAt
+	store(t, s, long)
+	store(t, s, short)
+
+	// Outlast the one-second granularity of Redis EXPIRE
+	time.Sleep(time.Second + expiryWait)
+
+	sessions, err := s.ListByUser(ctx, userID)
+	if err != nil {
+		t.Fatalf("ListByUser: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].GetID() != long.ID {
+		t.Errorf("ListByUser returned %d sessions, want only the long-lived one", len(sessions))
+	}
+
+	if err := s.Delete(ctx, userID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	assertGone(t, s, long)
+}
@@ -0,0 +1,165 @@
+package authservice
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	authconstants "{{.Project.GoModule}}/internal/auth/constants"
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	authmodel "{{.Project.GoModule}}/internal/auth/model"
+	"{{.Project.GoModule}}/internal/core"
+)
+
+// DeleteAccount soft deletes an account after confirming its password and
+// signs out every session. The account and the data every module holds on
+// it are purged once the grace period ends; until then the link emailed to
+// the owner restores it. Accounts without a password (social or passkey
+// only) are confirmed by the session alone. It returns when the purge is due.
+func (s *AuthService) DeleteAccount(ctx context.Context, accountID uuid.UUID, password string) (time.Time, error) {
+	account, err := s.accountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return time.Time{}, core.NewAppError(core.ErrCodeNotFound, authconstants.ErrAccountNotFound)
+	}
+
+	if account.GetPasswordHash() != "" {
+		if err := s.passwordHasher.Verify(password, account.GetPasswordHash()); err != nil {
+			return time.Time{}, core.NewAppError(core.ErrCodeBadRequest, authconstants.ErrInvalidPassword)
+		}
+	}
+
+	if err := s.endAllSessions(ctx, accountID); err != nil {
+		fmt.Printf("Failed to delete sessions: %v\n", err)
+	}
+
+	gracePeriod := s.config.GetAccountDeletionGracePeriod()
+	if gracePeriod <= 0 {
+		if err := s.purgeAccount(ctx, account); err != nil {
+			return time.Time{}, core.NewAppError(core.ErrCodeInternalServer, "failed to delete account", err)
+		}
+		return time.Now(), nil
+	}
+
+	if err := s.accountRepo.Delete(ctx, accountID); err != nil {
+		return time.Time{}, core.NewAppError(core.ErrCodeInternalServer, "failed to delete account", err)
+	}
+
+	purgeAt := time.Now().Add(gracePeriod)
+	token := &authmodel.Token{
+		ID:        uuid.New(),
+		AccountID: accountID,
+		Token:     s.tokenGenerator.GenerateSecureToken(),
+		Type:      authinterface.TokenTypeAccountRestore,
+		ExpiresAt: purgeAt,
+	}
+
+	if err := s.tokenRepo.Create(ctx, token); err != nil {
+		fmt.Printf("Failed to create account restore token: %v\n", err)
+	} else if err := s.emailSender.SendAccountDeletionScheduled(account.GetEmail(), token.Token, purgeAt); err != nil {
+		fmt.Printf("Failed to send account deletion email: %v\n", err)
+	}
+
+	s.emitEvent(ctx, authinterface.AuthEvent{
+		Type:      authinterface.AuthEventAccountDeletionScheduled,
+		AccountID: accountID,
+		Metadata:  map[string]any{"purge_at": purgeAt},
+	})
+
+	return purgeAt, nil
+}
+
+// RestoreAccount cancels a pending deletion. The owner signs in again
+// afterwards since all sessions ended when the account was deleted.
+func (s *AuthService) RestoreAccount(ctx context.Context, tokenStr string) error {
+	token, err := s.tokenRepo.GetByToken(ctx, tokenStr)
+	if err != nil || token.GetType() != authinterface.TokenTypeAccountRestore {
+		return core.NewAppError(core.ErrCodeBadRequest, authconstants.ErrInvalidToken)
+	}
+
+	if token.GetUsed() {
+		return core.NewAppError(core.ErrCodeBadRequest, authconstants.ErrTokenAlreadyUsed)
+	}
+
+	if token.IsExpired() {
+		return core.NewAppError(core.ErrCodeBadRequest, authconstants.ErrTokenExpired)
+	}
+
+	if err := s.tokenRepo.MarkAsUsed(ctx, token.GetID()); err != nil {
+		return core.NewAppError(core.ErrCodeBadRequest, authconstants.ErrTokenAlreadyUsed)
+	}
+
+	if err := s.accountRepo.Restore(ctx, token.GetAccountID()); err != nil {
+		return core.NewAppError(core.ErrCodeNotFound, authconstants.ErrAccountNotFound)
+	}
+
+	s.emitEvent(ctx, authinterface.AuthEvent{
+		Type:      authinterface.AuthEventAccountRestored,
+		AccountID: token.GetAccountID(),
+	})
+
+	return nil
+}
+
+// PurgeDeletedAccounts permanently removes the accounts whose grace period
+// has ended. An account whose data could not be fully erased is kept and
+// retried on the next run.
+func (s *AuthService) PurgeDeletedAccounts(ctx context.Context) error {
+	accounts, err := s.accountRepo.ListDeletedBefore(ctx, time.Now().Add(-s.config.GetAccountDeletionGracePeriod()))
+	if err != nil {
+		return fmt.Errorf("failed to list deleted accounts: %w", err)
+	}
+
+	for _, account := range accounts {
+		if err := s.purgeAccount(ctx, account); err != nil {
+			fmt.Printf("Failed to purge account %s: %v\n", account.GetID(), err)
+		}
+	}
+
+	return nil
+}
+
+// StartAccountPurger runs PurgeDeletedAccounts every interval until ctx is
+// done.
+func (s *AuthService) StartAccountPurger(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.PurgeDeletedAccounts(ctx); err != nil {
+					fmt.Printf("Failed to purge deleted accounts: %v\n", err)
+				}
+			}
+		}
+	}()
+}
+
+func (s *AuthService) purgeAccount(ctx context.Context, account authinterface.Account) error {
+	subject := core.DataSubject{UserID: account.GetID(), Email: account.GetEmail()}
+
+	// Auth registers itself with the registry; erasing directly covers
+	// services built without one.
+	if s.userData != nil {
+		if err := s.userData.Erase(ctx, subject); err != nil {
+			return err
+		}
+	} else if err := s.DeleteUserData(ctx, subject); err != nil {
+		return err
+	}
+
+	if err := s.accountRepo.Purge(ctx, account.GetID()); err != nil {
+		return err
+	}
+
+	s.emitEvent(ctx, authinterface.AuthEvent{
+		Type:      authinterface.AuthEventAccountPurged,
+		AccountID: account.GetID(),
+	})
+
+	return nil
+}
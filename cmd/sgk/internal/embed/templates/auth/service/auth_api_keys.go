@@ -0,0 +1,221 @@
+package authservice
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	authconstants "{{.Project.GoModule}}/internal/auth/constants"
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	authmodel "{{.Project.GoModule}}/internal/auth/model"
+	"{{.Project.GoModule}}/internal/core"
+)
+
+// API keys look like sk_<prefix>_<secret>. The prefix is stored in the clear
+// to find the key; the secret is only kept as a SHA-256 hash, which is enough
+// for 256 bits of random data.
+const (
+	apiKeyMarker         = "sk_"
+	apiKeyPrefixBytes    = 6
+	apiKeySecretBytes    = 32
+	maxAPIKeysPerAccount = 25
+)
+
+// CreateAPIKey issues a key for the account. Scopes must be permissions the
+// account holds; an empty list lets the key act with all of them. The full
+// key is only returned here.
+func (s *AuthService) CreateAPIKey(ctx context.Context, accountID uuid.UUID, opts authinterface.CreateAPIKeyOptions) (*authinterface.CreatedAPIKey, error) {
+	if opts.ExpiresAt != nil && !opts.ExpiresAt.After(time.Now()) {
+		return nil, core.NewAppError(core.ErrCodeValidation, "expiry must be in the future")
+	}
+
+	allowedIPs, err := normalizeIPAllowlist(opts.AllowedIPs)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := s.apiKeyRepo.ListByAccount(ctx, accountID)
+	if err != nil {
+		return nil, core.NewAppError(core.ErrCodeInternalServer, "failed to list api keys")
+	}
+	if len(keys) >= maxAPIKeysPerAccount {
+		return nil, core.NewAppError(core.ErrCodeConflict, authconstants.ErrTooManyAPIKeys)
+	}
+
+	for _, scope := range opts.Scopes {
+		allowed := false
+		if s.roleLookup != nil {
+			allowed, err = s.roleLookup.UserHasPermission(ctx, accountID, scope)
+			if err != nil {
+				return nil, core.NewAppError(core.ErrCodeInternalServer, "failed to check permissions")
+			}
+		}
+		if !allowed {
+			return nil, core.NewAppError(core.ErrCodeForbidden, authconstants.ErrInvalidAPIKeyScope)
+		}
+	}
+
+	prefix, err := randomHex(apiKeyPrefixBytes)
+	if err != nil {
+		return nil, core.NewAppError(core.ErrCodeInternalServer, "failed to generate api key")
+	}
+	secret, err := randomHex(apiKeySecretBytes)
+	if err != nil {
+		return nil, core.NewAppError(core.ErrCodeInternalServer, "failed to generate api key")
+	}
+
+	key := &authmodel.APIKey{
+		ID:         uuid.New(),
+		AccountID:  accountID,
+		Name:       opts.Name,
+		Prefix:     prefix,
+		SecretHash: hashAPIKeySecret(secret),
+		Scopes:     opts.Scopes,
+		AllowedIPs: allowedIPs,
+		ExpiresAt:  opts.ExpiresAt,
+	}
+	if key.Scopes == nil {
+		key.Scopes = []string{}
+	}
+
+	if err := s.apiKeyRepo.Create(ctx, key); err != nil {
+		return nil, core.NewAppError(core.ErrCodeInternalServer, "failed to create api key")
+	}
+
+	s.emitEvent(ctx, authinterface.AuthEvent{
+		Type:      authinterface.AuthEventAPIKeyCreated,
+		AccountID: accountID,
+		Metadata: map[string]any{
+			"api_key_id": key.ID.String(),
+			"name":       key.Name,
+			"scopes":     key.Scopes,
+		},
+	})
+
+	return &authinterface.CreatedAPIKey{
+		APIKey: key,
+		Key:    apiKeyMarker + prefix + "_" + secret,
+	}, nil
+}
+
+func (s *AuthService) ListAPIKeys(ctx context.Context, accountID uuid.UUID) ([]authinterface.APIKey, error) {
+	keys, err := s.apiKeyRepo.ListByAccount(ctx, accountID)
+	if err != nil {
+		return nil, core.NewAppError(core.ErrCodeInternalServer, "failed to list api keys")
+	}
+	return keys, nil
+}
+
+func (s *AuthService) RevokeAPIKey(ctx context.Context, accountID, keyID uuid.UUID) error {
+	if err := s.apiKeyRepo.Delete(ctx, accountID, keyID); err != nil {
+		return core.NewAppError(core.ErrCodeNotFound, authconstants.ErrAPIKeyNotFound)
+	}
+
+	s.emitEvent(ctx, authinterface.AuthEvent{
+		Type:      authinterface.AuthEventAPIKeyRevoked,
+		AccountID: accountID,
+		Metadata:  map[string]any{"api_key_id": keyID.String()},
+	})
+
+	return nil
+}
+
+// AuthenticateAPIKey resolves a presented key to its account. ipAddress is
+// checked against the key's allowlist.
+func (s *AuthService) AuthenticateAPIKey(ctx context.Context, rawKey, ipAddress string) (authinterface.Account, authinterface.APIKey, error) {
+	prefix, secret, ok := strings.Cut(strings.TrimPrefix(rawKey, apiKeyMarker), "_")
+	if !ok || !strings.HasPrefix(rawKey, apiKeyMarker) || prefix == "" || secret == "" {
+		return nil, nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrInvalidAPIKey)
+	}
+
+	key, err := s.apiKeyRepo.GetByPrefix(ctx, prefix)
+	if err != nil {
+		return nil, nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrInvalidAPIKey)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashAPIKeySecret(secret)), []byte(key.GetSecretHash())) != 1 {
+		return nil, nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrInvalidAPIKey)
+	}
+
+	if key.IsExpired() {
+		return nil, nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrAPIKeyExpired)
+	}
+
+	if !ipAllowed(key.GetAllowedIPs(), ipAddress) {
+		return nil, nil, core.NewAppError(core.ErrCodeForbidden, authconstants.ErrAPIKeyIPNotAllowed)
+	}
+
+	account, err := s.GetAccount(ctx, key.GetAccountID())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if lastUsed := key.GetLastUsedAt(); lastUsed == nil || time.Since(*lastUsed) > sessionTouchInterval {
+		if err := s.apiKeyRepo.UpdateLastUsed(ctx, key.GetID(), time.Now()); err != nil {
+			fmt.Printf("Failed to update api key usage: %v\n", err)
+		}
+	}
+
+	return account, key, nil
+}
+
+func hashAPIKeySecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// normalizeIPAllowlist accepts addresses and CIDR ranges and stores them in
+// canonical form.
+func normalizeIPAllowlist(entries []string) ([]string, error) {
+	result := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			result = append(result, network.String())
+			continue
+		}
+		ip := net.ParseIP(entry)
+		if ip == nil {
+			return nil, core.NewAppError(core.ErrCodeValidation, authconstants.ErrInvalidIPAllowlist)
+		}
+		result = append(result, ip.String())
+	}
+	return result, nil
+}
+
+func ipAllowed(allowlist []string, ipAddress string) bool {
+	if len(allowlist) == 0 {
+		return true
+	}
+
+	ip := net.ParseIP(ipAddress)
+	if ip == nil {
+		return false
+	}
+
+	for _, entry := range allowlist {
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if network.Contains(ip) {
+				return true
+			}
+		} else if allowed := net.ParseIP(entry); allowed != nil && allowed.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
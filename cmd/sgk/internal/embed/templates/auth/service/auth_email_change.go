@@ -0,0 +1,195 @@
+package authservice
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	authconstants "{{.Project.GoModule}}/internal/auth/constants"
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	authmodel "{{.Project.GoModule}}/internal/auth/model"
+	"{{.Project.GoModule}}/internal/core"
+)
+
+// RequestEmailChange emails a confirmation link to newEmail. The address
+// only changes once that link is used, so a typo or a hijacked session
+// cannot move the account to an address the owner does not control.
+func (s *AuthService) RequestEmailChange(ctx context.Context, accountID uuid.UUID, password, newEmail string) error {
+	newEmail = strings.ToLower(strings.TrimSpace(newEmail))
+
+	account, err := s.accountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return core.NewAppError(core.ErrCodeNotFound, authconstants.ErrAccountNotFound)
+	}
+
+	if err := s.passwordHasher.Verify(password, account.GetPasswordHash()); err != nil {
+		return core.NewAppError(core.ErrCodeBadRequest, authconstants.ErrInvalidPassword)
+	}
+
+	if newEmail == account.GetEmail() {
+		return core.NewAppError(core.ErrCodeBadRequest, authconstants.ErrEmailUnchanged)
+	}
+
+	exists, err := s.accountRepo.ExistsByEmail(ctx, newEmail)
+	if err != nil {
+		return core.NewAppError(core.ErrCodeInternalServer, "failed to check email existence")
+	}
+	if exists {
+		return core.NewAppError(core.ErrCodeConflict, authconstants.ErrEmailInUse)
+	}
+
+	s.invalidateTokens(ctx, accountID, authinterface.TokenTypeEmailChange)
+
+	token := &authmodel.Token{
+		ID:        uuid.New(),
+		AccountID: accountID,
+		Token:     s.tokenGenerator.GenerateSecureToken(),
+		Type:      authinterface.TokenTypeEmailChange,
+		Email:     newEmail,
+		ExpiresAt: time.Now().Add(s.config.GetVerificationTokenExpiration()),
+	}
+
+	if err := s.tokenRepo.Create(ctx, token); err != nil {
+		return core.NewAppError(core.ErrCodeInternalServer, "failed to create email change token")
+	}
+
+	if err := s.emailSender.SendEmailChangeConfirmation(newEmail, token.Token); err != nil {
+		return core.NewAppError(core.ErrCodeInternalServer, "failed to send confirmation email")
+	}
+
+	return nil
+}
+
+// ConfirmEmailChange switches the account to the confirmed address and
+// sends the previous address a link that undoes the change.
+func (s *AuthService) ConfirmEmailChange(ctx context.Context, tokenStr string) error {
+	token, err := s.getEmailChangeToken(ctx, tokenStr, authinterface.TokenTypeEmailChange)
+	if err != nil {
+		return err
+	}
+
+	account, err := s.accountRepo.GetByID(ctx, token.GetAccountID())
+	if err != nil {
+		return core.NewAppError(core.ErrCodeNotFound, authconstants.ErrAccountNotFound)
+	}
+
+	// The address may have been registered since the change was requested.
+	exists, err := s.accountRepo.ExistsByEmail(ctx, token.GetEmail())
+	if err != nil {
+		return core.NewAppError(core.ErrCodeInternalServer, "failed to check email existence")
+	}
+	if exists {
+		return core.NewAppError(core.ErrCodeConflict, authconstants.ErrEmailInUse)
+	}
+
+	if err := s.tokenRepo.MarkAsUsed(ctx, token.GetID()); err != nil {
+		return core.NewAppError(core.ErrCodeBadRequest, authconstants.ErrTokenAlreadyUsed)
+	}
+
+	oldEmail := account.GetEmail()
+	account.SetEmail(token.GetEmail())
+	account.SetEmailVerified(true)
+	if err := s.accountRepo.Update(ctx, account); err != nil {
+		return core.NewAppError(core.ErrCodeInternalServer, "failed to update account")
+	}
+
+	revert := &authmodel.Token{
+		ID:        uuid.New(),
+		AccountID: account.GetID(),
+		Token:     s.tokenGenerator.GenerateSecureToken(),
+		Type:      authinterface.TokenTypeEmailRevert,
+		Email:     oldEmail,
+		ExpiresAt: time.Now().Add(s.config.GetEmailChangeRevertExpiration()),
+	}
+
+	if err := s.tokenRepo.Create(ctx, revert); err != nil {
+		fmt.Printf("Failed to create email revert token: %v\n", err)
+		return nil
+	}
+
+	if err := s.emailSender.SendEmailChangedNotification(oldEmail, account.GetEmail(), revert.Token); err != nil {
+		fmt.Printf("Failed to send email changed notification: %v\n", err)
+	}
+
+	return nil
+}
+
+// RevertEmailChange restores the address an account had before a change
+// and signs out every session, since the change may not have been made by
+// the owner.
+func (s *AuthService) RevertEmailChange(ctx context.Context, tokenStr string) error {
+	token, err := s.getEmailChangeToken(ctx, tokenStr, authinterface.TokenTypeEmailRevert)
+	if err != nil {
+		return err
+	}
+
+	account, err := s.accountRepo.GetByID(ctx, token.GetAccountID())
+	if err != nil {
+		return core.NewAppError(core.ErrCodeNotFound, authconstants.ErrAccountNotFound)
+	}
+
+	if account.GetEmail() != token.GetEmail() {
+		exists, err := s.accountRepo.ExistsByEmail(ctx, token.GetEmail())
+		if err != nil {
+			return core.NewAppError(core.ErrCodeInternalServer, "failed to check email existence")
+		}
+		if exists {
+			return core.NewAppError(core.ErrCodeConflict, authconstants.ErrEmailInUse)
+		}
+	}
+
+	if err := s.tokenRepo.MarkAsUsed(ctx, token.GetID()); err != nil {
+		return core.NewAppError(core.ErrCodeBadRequest, authconstants.ErrTokenAlreadyUsed)
+	}
+
+	account.SetEmail(token.GetEmail())
+	account.SetEmailVerified(true)
+	if err := s.accountRepo.Update(ctx, account); err != nil {
+		return core.NewAppError(core.ErrCodeInternalServer, "failed to update account")
+	}
+
+	s.invalidateTokens(ctx, account.GetID(), authinterface.TokenTypeEmailChange)
+
+	if err := s.endAllSessions(ctx, account.GetID()); err != nil {
+		fmt.Printf("Failed to delete sessions: %v\n", err)
+	}
+
+	return nil
+}
+
+func (s *AuthService) getEmailChangeToken(ctx context.Context, tokenStr string, tokenType authinterface.TokenType) (authinterface.Token, error) {
+	token, err := s.tokenRepo.GetByToken(ctx, tokenStr)
+	if err != nil || token.GetType() != tokenType {
+		return nil, core.NewAppError(core.ErrCodeBadRequest, authconstants.ErrInvalidToken)
+	}
+
+	if token.GetUsed() {
+		return nil, core.NewAppError(core.ErrCodeBadRequest, authconstants.ErrTokenAlreadyUsed)
+	}
+
+	if token.IsExpired() {
+		return nil, core.NewAppError(core.ErrCodeBadRequest, authconstants.ErrTokenExpired)
+	}
+
+	return token, nil
+}
+
+// invalidateTokens marks every outstanding token of tokenType as used.
+func (s *AuthService) invalidateTokens(ctx context.Context, accountID uuid.UUID, tokenType authinterface.TokenType) {
+	tokens, err := s.tokenRepo.GetByAccountAndType(ctx, accountID, tokenType)
+	if err != nil {
+		fmt.Printf("Failed to load %s tokens: %v\n", tokenType, err)
+		return
+	}
+
+	for _, token := range tokens {
+		if token.GetUsed() {
+			continue
+		}
+		if err := s.tokenRepo.MarkAsUsed(ctx, token.GetID()); err != nil {
+			fmt.Printf("Failed to invalidate %s token: %v\n", tokenType, err)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package authservice
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	authconstants "{{.Project.GoModule}}/internal/auth/constants"
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	authmodel "{{.Project.GoModule}}/internal/auth/model"
+	"{{.Project.GoModule}}/internal/core"
+)
+
+// StartImpersonation issues a session for targetID that carries
+// impersonatorID as its actor. The caller is expected to hold
+// authconstants.PermissionImpersonate; the route enforces it. No session is
+// issued unless the start is recorded in the target's login history.
+func (s *AuthService) StartImpersonation(ctx context.Context, impersonatorID, targetID uuid.UUID, reason string) (authinterface.Session, error) {
+	if impersonatorID == targetID {
+		return nil, core.NewAppError(core.ErrCodeBadRequest, authconstants.ErrCannotImpersonate)
+	}
+
+	if _, err := s.accountRepo.GetByID(ctx, impersonatorID); err != nil {
+		return nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrAccountNotFound)
+	}
+
+	target, err := s.accountRepo.GetByID(ctx, targetID)
+	if err != nil {
+		return nil, core.NewAppError(core.ErrCodeNotFound, authconstants.ErrAccountNotFound)
+	}
+
+	if s.roleLookup != nil {
+		privileged, err := s.roleLookup.UserHasPermission(ctx, targetID, authconstants.PermissionImpersonate)
+		if err != nil {
+			return nil, core.NewAppError(core.ErrCodeInternalServer, "failed to check permissions")
+		}
+		if privileged {
+			return nil, core.NewAppError(core.ErrCodeForbidden, authconstants.ErrCannotImpersonate)
+		}
+	}
+
+	session, err := s.createSession(ctx, target, uuid.New(), impersonatorID)
+	if err != nil {
+		return nil, core.NewAppError(core.ErrCodeInternalServer, "failed to create session")
+	}
+
+	if err := s.recordImpersonation(ctx, authinterface.AuthEventImpersonationStarted, session, impersonatorID, reason); err != nil {
+		fmt.Printf("Failed to record impersonation: %v\n", err)
+		return nil, core.NewAppError(core.ErrCodeInternalServer, "failed to record impersonation")
+	}
+
+	if err := s.sessionStore.Store(ctx, session); err != nil {
+		return nil, core.NewAppError(core.ErrCodeInternalServer, "failed to store session")
+	}
+
+	s.emitEvent(ctx, authinterface.AuthEvent{
+		Type:      authinterface.AuthEventImpersonationStarted,
+		AccountID: targetID,
+		SessionID: session.ID,
+		Metadata: map[string]any{
+			"impersonator_id": impersonatorID.String(),
+			"reason":          reason,
+			"expires_at":      session.RefreshExpiresAt,
+		},
+	})
+
+	return session, nil
+}
+
+// StopImpersonation ends the impersonation session the token belongs to.
+func (s *AuthService) StopImpersonation(ctx context.Context, token string) error {
+	session, err := s.sessionStore.Get(ctx, token)
+	if err != nil {
+		return core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrSessionExpired)
+	}
+
+	impersonatorID := session.GetImpersonatorID()
+	if impersonatorID == uuid.Nil {
+		return core.NewAppError(core.ErrCodeBadRequest, authconstants.ErrNotImpersonating)
+	}
+
+	if err := s.endSession(ctx, session); err != nil {
+		fmt.Printf("Failed to end impersonation session: %v\n", err)
+		return core.NewAppError(core.ErrCodeInternalServer, "failed to end session")
+	}
+
+	if err := s.recordImpersonation(ctx, authinterface.AuthEventImpersonationStopped, session, impersonatorID, ""); err != nil {
+		fmt.Printf("Failed to record impersonation: %v\n", err)
+	}
+
+	s.emitEvent(ctx, authinterface.AuthEvent{
+		Type:      authinterface.AuthEventImpersonationStopped,
+		AccountID: session.GetUserID(),
+		SessionID: session.GetID(),
+		Metadata:  map[string]any{"impersonator_id": impersonatorID.String()},
+	})
+
+	return nil
+}
+
+// recordImpersonation keeps the start or end of an impersonation in the
+// target's login history, where it outlives the event log.
+func (s *AuthService) recordImpersonation(ctx context.Context, eventType authinterface.AuthEventType, session authinterface.Session, impersonatorID uuid.UUID, reason string) error {
+	if s.loginHistoryRepo == nil {
+		return nil
+	}
+
+	client := authinterface.ClientInfoFromContext(ctx)
+	sessionID := session.GetID()
+	return s.loginHistoryRepo.Create(ctx, &authmodel.LoginHistory{
+		ID:             uuid.New(),
+		AccountID:      session.GetUserID(),
+		SessionID:      &sessionID,
+		Success:        true,
+		Method:         string(eventType),
+		IPAddress:      client.IPAddress,
+		UserAgent:      client.UserAgent,
+		Device:         deviceName(client.UserAgent),
+		ImpersonatorID: &impersonatorID,
+		Reason:         reason,
+		CreatedAt:      time.Now(),
+	})
+}
@@ -0,0 +1,125 @@
+package authservice
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	authconstants "{{.Project.GoModule}}/internal/auth/constants"
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	"{{.Project.GoModule}}/internal/core"
+)
+
+func newImpersonationTestService(t *testing.T) (*testService, *fakeLoginHistory) {
+	t.Helper()
+
+	history := &fakeLoginHistory{}
+	return newTestService(t, AuthServiceDeps{LoginHistoryRepo: history}), history
+}
+
+func staffContext() context.Context {
+	return authinterface.WithClientInfo(context.Background(), authinterface.ClientInfo{
+		IPAddress: "203.0.113.7",
+		UserAgent: "Mozilla/5.0 Firefox/128.0",
+	})
+}
+
+func TestStartImpersonationIsRecorded(t *testing.T) {
+	ts, history := newImpersonationTestService(t)
+	staff := ts.createAccount(t, "staff@example.com")
+	customer := ts.createAccount(t, "customer@example.com")
+
+	session, err := ts.StartImpersonation(staffContext(), staff.ID, customer.ID, "ticket 42")
+	if err != nil {
+		t.Fatalf("StartImpersonation: %v", err)
+	}
+	if session.GetUserID() != customer.ID || session.GetImpersonatorID() != staff.ID {
+		t.Fatalf("session user %s impersonator %s, want %s acting as %s", session.GetUserID(), session.GetImpersonatorID(), staff.ID, customer.ID)
+	}
+
+	entries := history.list()
+	if len(entries) != 1 {
+		t.Fatalf("history entries = %d, want 1", len(entries))
+	}
+	entry := entries[0]
+	if entry.AccountID != customer.ID || entry.Method != string(authinterface.AuthEventImpersonationStarted) {
+		t.Fatalf("entry = %+v, want an impersonation start on the customer", entry)
+	}
+	if entry.ImpersonatorID == nil || *entry.ImpersonatorID != staff.ID || entry.Reason != "ticket 42" {
+		t.Fatalf("entry = %+v, want the impersonator and reason", entry)
+	}
+	if entry.SessionID == nil || *entry.SessionID != session.GetID() || entry.IPAddress != "203.0.113.7" {
+		t.Fatalf("entry = %+v, want the session and staff IP", entry)
+	}
+}
+
+func TestStartImpersonationRequiresAudit(t *testing.T) {
+	ts, history := newImpersonationTestService(t)
+	history.err = errors.New("database is down")
+	staff := ts.createAccount(t, "staff@example.com")
+	customer := ts.createAccount(t, "customer@example.com")
+
+	session, err := ts.StartImpersonation(staffContext(), staff.ID, customer.ID, "ticket 42")
+	var appErr *core.AppError
+	if !errors.As(err, &appErr) || appErr.Code != core.ErrCodeInternalServer {
+		t.Fatalf("error = %v, want an internal error", err)
+	}
+	if session != nil {
+		t.Fatal("a session was issued without an audit record")
+	}
+
+	sessions, _ := ts.sessions.ListByUser(context.Background(), customer.ID)
+	if len(sessions) != 0 {
+		t.Fatalf("sessions = %d, want none stored", len(sessions))
+	}
+}
+
+func TestStopImpersonationIsRecorded(t *testing.T) {
+	ts, history := newImpersonationTestService(t)
+	staff := ts.createAccount(t, "staff@example.com")
+	customer := ts.createAccount(t, "customer@example.com")
+	ctx := staffContext()
+
+	session, err := ts.StartImpersonation(ctx, staff.ID, customer.ID, "ticket 42")
+	if err != nil {
+		t.Fatalf("StartImpersonation: %v", err)
+	}
+
+	if err := ts.StopImpersonation(ctx, session.GetToken()); err != nil {
+		t.Fatalf("StopImpersonation: %v", err)
+	}
+	if _, err := ts.sessions.Get(ctx, session.GetToken()); err == nil {
+		t.Fatal("the impersonation session is still valid")
+	}
+
+	entries := history.list()
+	if len(entries) != 2 {
+		t.Fatalf("history entries = %d, want start and stop", len(entries))
+	}
+	stop := entries[1]
+	if stop.Method != string(authinterface.AuthEventImpersonationStopped) || stop.ImpersonatorID == nil || *stop.ImpersonatorID != staff.ID {
+		t.Fatalf("entry = %+v, want an impersonation stop by the staff account", stop)
+	}
+	if stop.SessionID == nil || *stop.SessionID != session.GetID() {
+		t.Fatalf("entry = %+v, want the impersonation session", stop)
+	}
+}
+
+func TestStopImpersonationRejectsOwnSession(t *testing.T) {
+	ts, history := newImpersonationTestService(t)
+	customer := ts.createAccount(t, "customer@example.com")
+	ctx := context.Background()
+
+	session, err := ts.createSession(ctx, customer, uuid.New(), uuid.Nil)
+	if err != nil {
+		t.Fatalf("createSession: %v", err)
+	}
+	ts.sessions.Store(ctx, session)
+
+	err = ts.StopImpersonation(ctx, session.Token)
+	assertAppError(t, err, authconstants.ErrNotImpersonating)
+	if len(history.list()) != 0 {
+		t.Fatal("a stop was recorded for a session that was not impersonating")
+	}
+}
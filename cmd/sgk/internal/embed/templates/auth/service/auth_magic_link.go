@@ -0,0 +1,98 @@
+package authservice
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	authmodel "{{.Project.GoModule}}/internal/auth/model"
+	"{{.Project.GoModule}}/internal/core"
+)
+
+// SendMagicLink emails a login link to email. When device binding is
+// enabled it returns the secret the requesting device must present to
+// VerifyMagicLink. Unknown emails and rate-limited requests succeed
+// silently so the endpoint cannot be used to probe for accounts.
+func (s *AuthService) SendMagicLink(ctx context.Context, email string) (string, error) {
+	var deviceBinding string
+	if s.config.IsMagicLinkDeviceBindingEnabled() {
+		var err error
+		if deviceBinding, err = randomURLToken(32); err != nil {
+			return "", fmt.Errorf("failed to generate device binding: %w", err)
+		}
+	}
+
+	account, err := s.accountRepo.GetByEmail(ctx, email)
+	if err != nil {
+		return deviceBinding, nil
+	}
+
+	if s.magicLinkRateLimited(ctx, account.GetID()) {
+		fmt.Printf("Magic link rate limit reached for account %s\n", account.GetID())
+		return deviceBinding, nil
+	}
+
+	token := &authmodel.Token{
+		ID:        uuid.New(),
+		AccountID: account.GetID(),
+		Token:     s.tokenGenerator.GenerateSecureToken(),
+		Type:      authinterface.TokenTypeMagicLink,
+		ExpiresAt: time.Now().Add(s.config.GetMagicLinkExpiration()),
+	}
+	if deviceBinding != "" {
+		token.DeviceBinding = hashDeviceBinding(deviceBinding)
+	}
+
+	if err := s.tokenRepo.Create(ctx, token); err != nil {
+		return "", core.NewAppError(core.ErrCodeInternalServer, "failed to create magic link token")
+	}
+
+	if err := s.emailSender.SendMagicLinkEmail(account.GetEmail(), token.Token); err != nil {
+		return "", core.NewAppError(core.ErrCodeInternalServer, "failed to send magic link")
+	}
+
+	return deviceBinding, nil
+}
+
+func (s *AuthService) VerifyMagicLink(ctx context.Context, token, deviceBinding string) (*authinterface.LoginResult, error) {
+	strategy, err := s.strategyRegistry.Get("magic_link")
+	if err != nil {
+		return nil, core.NewAppError(core.ErrCodeBadRequest, "authentication strategy not available")
+	}
+
+	ctx = withLoginMethod(ctx, strategy.Name())
+	result, err := strategy.Authenticate(ctx, map[string]any{
+		"token":          token,
+		"device_binding": deviceBinding,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.completeLogin(ctx, result.Account)
+}
+
+func (s *AuthService) magicLinkRateLimited(ctx context.Context, accountID uuid.UUID) bool {
+	limit := s.config.GetMagicLinkRequestsPerHour()
+	if limit <= 0 {
+		return false
+	}
+
+	tokens, err := s.tokenRepo.GetByAccountAndType(ctx, accountID, authinterface.TokenTypeMagicLink)
+	if err != nil {
+		fmt.Printf("Failed to load magic link tokens: %v\n", err)
+		return false
+	}
+
+	since := time.Now().Add(-time.Hour)
+	recent := 0
+	for _, token := range tokens {
+		if token.GetCreatedAt().After(since) {
+			recent++
+		}
+	}
+
+	return recent >= limit
+}
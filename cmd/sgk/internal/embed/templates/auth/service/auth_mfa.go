@@ -0,0 +1,400 @@
+package authservice
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	authconstants "{{.Project.GoModule}}/internal/auth/constants"
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	authmodel "{{.Project.GoModule}}/internal/auth/model"
+	"{{.Project.GoModule}}/internal/core"
+)
+
+const (
+	recoveryCodeCount    = 10
+	recoveryCodeLength   = 10
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+)
+
+func (s *AuthService) SetupMFA(ctx context.Context, accountID uuid.UUID) (*authinterface.MFASetup, error) {
+	account, err := s.accountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return nil, core.NewAppError(core.ErrCodeNotFound, authconstants.ErrAccountNotFound)
+	}
+
+	if account.GetMFAEnabled() {
+		return nil, core.NewAppError(core.ErrCodeConflict, authconstants.ErrMFAAlreadyEnabled)
+	}
+
+	secret, err := s.totpProvider.GenerateSecret()
+	if err != nil {
+		return nil, core.NewAppError(core.ErrCodeInternalServer, "failed to generate mfa secret")
+	}
+
+	account.SetMFASecret(secret)
+	if err := s.accountRepo.Update(ctx, account); err != nil {
+		return nil, core.NewAppError(core.ErrCodeInternalServer, "failed to update account")
+	}
+
+	return &authinterface.MFASetup{
+		Secret:          secret,
+		ProvisioningURI: s.totpProvider.ProvisioningURI(secret, account.GetEmail()),
+	}, nil
+}
+
+func (s *AuthService) SetupMFAForChallenge(ctx context.Context, mfaToken string) (*authinterface.MFASetup, error) {
+	token, err := s.getMFAChallenge(ctx, mfaToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if token.GetType() != authinterface.TokenTypeMFAEnrollment {
+		return nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrInvalidToken)
+	}
+
+	return s.SetupMFA(ctx, token.GetAccountID())
+}
+
+func (s *AuthService) ConfirmMFA(ctx context.Context, accountID uuid.UUID, code string) ([]string, error) {
+	account, err := s.accountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return nil, core.NewAppError(core.ErrCodeNotFound, authconstants.ErrAccountNotFound)
+	}
+
+	if account.GetMFAEnabled() {
+		return nil, core.NewAppError(core.ErrCodeConflict, authconstants.ErrMFAAlreadyEnabled)
+	}
+
+	if account.GetMFASecret() == "" {
+		return nil, core.NewAppError(core.ErrCodeBadRequest, authconstants.ErrMFASetupNotStarted)
+	}
+
+	step, ok := s.totpProvider.Validate(account.GetMFASecret(), code, time.Now())
+	if !ok {
+		return nil, core.NewAppError(core.ErrCodeBadRequest, authconstants.ErrInvalidMFACode)
+	}
+
+	account.SetMFAEnabled(true)
+	account.SetMFALastUsedStep(step)
+	if err := s.accountRepo.Update(ctx, account); err != nil {
+		return nil, core.NewAppError(core.ErrCodeInternalServer, "failed to update account")
+	}
+
+	return s.generateRecoveryCodes(ctx, accountID)
+}
+
+// VerifyMFA completes a login that was answered with an MFA challenge. For
+// enrollment challenges the code confirms the pending setup first, and the
+// new recovery codes are returned alongside the session. Wrong codes are
+// throttled like password guesses.
+func (s *AuthService) VerifyMFA(ctx context.Context, mfaToken, code string) (*authinterface.LoginResult, error) {
+	token, err := s.getMFAChallenge(ctx, mfaToken)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := s.accountRepo.GetByID(ctx, token.GetAccountID())
+	if err != nil {
+		return nil, core.NewAppError(core.ErrCodeNotFound, authconstants.ErrAccountNotFound)
+	}
+
+	if err := s.checkLoginAllowed(ctx, account.GetEmail()); err != nil {
+		return nil, err
+	}
+
+	ctx = withLoginMethod(ctx, "mfa")
+
+	var recoveryCodes []string
+	if account.GetMFAEnabled() {
+		if err := s.verifySecondFactor(ctx, account, code); err != nil {
+			s.emitLoginFailed(ctx, account.GetID(), authconstants.ErrInvalidMFACode, nil)
+			return nil, s.recordMFAFailure(ctx, account, token, err)
+		}
+	} else {
+		if token.GetType() != authinterface.TokenTypeMFAEnrollment {
+			return nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrInvalidToken)
+		}
+		recoveryCodes, err = s.ConfirmMFA(ctx, account.GetID(), code)
+		if err != nil {
+			return nil, s.recordMFAFailure(ctx, account, token, err)
+		}
+	}
+
+	// The challenge is redeemed before the session is issued. MarkAsUsed
+	// only succeeds once, so of two concurrent verifications only one gets
+	// a session.
+	if err := s.tokenRepo.MarkAsUsed(ctx, token.GetID()); err != nil {
+		return nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrTokenAlreadyUsed)
+	}
+	s.clearFailedLogins(ctx, account.GetEmail())
+
+	session, err := s.issueSession(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+
+	return &authinterface.LoginResult{Session: session, RecoveryCodes: recoveryCodes}, nil
+}
+
+// recordMFAFailure counts a wrong code against the challenge and, like a
+// wrong password, against the account and client IP. Once the challenge
+// reaches the limit it is invalidated, so guessing on needs another
+// password login. Errors other than a wrong code are returned unchanged.
+func (s *AuthService) recordMFAFailure(ctx context.Context, account authinterface.Account, token authinterface.Token, err error) error {
+	var appErr *core.AppError
+	if !errors.As(err, &appErr) || appErr.Message != authconstants.ErrInvalidMFACode {
+		return err
+	}
+
+	s.recordFailedLogin(ctx, account.GetEmail())
+
+	limit := s.config.GetMFAMaxAttempts()
+	if s.loginAttempts == nil || limit <= 0 {
+		return err
+	}
+
+	key := mfaAttemptKey(token.GetID())
+	attempts, recordErr := s.loginAttempts.RecordFailure(ctx, key, s.config.GetMFAChallengeExpiration())
+	if recordErr != nil {
+		fmt.Printf("Failed to record mfa failure: %v\n", recordErr)
+		return err
+	}
+
+	if attempts.Failures < limit {
+		return err
+	}
+
+	if err := s.tokenRepo.MarkAsUsed(ctx, token.GetID()); err != nil {
+		fmt.Printf("Failed to invalidate mfa challenge: %v\n", err)
+	}
+	if err := s.loginAttempts.Reset(ctx, key); err != nil {
+		fmt.Printf("Failed to reset mfa attempts: %v\n", err)
+	}
+
+	return core.NewAppError(core.ErrCodeTooManyRequests, authconstants.ErrTooManyMFAAttempts)
+}
+
+func (s *AuthService) DisableMFA(ctx context.Context, accountID uuid.UUID, code string) error {
+	account, err := s.accountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return core.NewAppError(core.ErrCodeNotFound, authconstants.ErrAccountNotFound)
+	}
+
+	if !account.GetMFAEnabled() {
+		return core.NewAppError(core.ErrCodeBadRequest, authconstants.ErrMFANotEnabled)
+	}
+
+	required, err := s.isMFARequired(ctx, accountID)
+	if err != nil {
+		return core.NewAppError(core.ErrCodeInternalServer, "failed to resolve account roles")
+	}
+	if required {
+		return core.NewAppError(core.ErrCodeForbidden, authconstants.ErrMFARequiredForRole)
+	}
+
+	if err := s.verifySecondFactor(ctx, account, code); err != nil {
+		return err
+	}
+
+	account.SetMFAEnabled(false)
+	account.SetMFASecret("")
+	account.SetMFALastUsedStep(0)
+	if err := s.accountRepo.Update(ctx, account); err != nil {
+		return core.NewAppError(core.ErrCodeInternalServer, "failed to update account")
+	}
+
+	if err := s.recoveryCodeRepo.DeleteByAccount(ctx, accountID); err != nil {
+		fmt.Printf("Failed to delete recovery codes: %v\n", err)
+	}
+
+	return nil
+}
+
+// completeLogin issues a session for an authenticated account, or an MFA
+// challenge when the account has MFA enabled or one of its roles needs it.
+// When phone verification is required, accounts without a verified phone,
+// including those with no phone at all, get a phone challenge first.
+func (s *AuthService) completeLogin(ctx context.Context, account authinterface.Account) (*authinterface.LoginResult, error) {
+	if s.config.IsPhoneVerificationRequired() && !account.GetPhoneVerified() {
+		return s.createPhoneChallenge(ctx, account)
+	}
+
+	if account.GetMFAEnabled() {
+		return s.createMFAChallenge(ctx, account.GetID(), authinterface.TokenTypeMFAChallenge)
+	}
+
+	required, err := s.isMFARequired(ctx, account.GetID())
+	if err != nil {
+		return nil, core.NewAppError(core.ErrCodeInternalServer, "failed to resolve account roles")
+	}
+	if required {
+		return s.createMFAChallenge(ctx, account.GetID(), authinterface.TokenTypeMFAEnrollment)
+	}
+
+	session, err := s.issueSession(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+
+	return &authinterface.LoginResult{Session: session}, nil
+}
+
+func (s *AuthService) issueSession(ctx context.Context, account authinterface.Account) (authinterface.Session, error) {
+	session, err := s.createSession(ctx, account, uuid.New(), uuid.Nil)
+	if err != nil {
+		return nil, core.NewAppError(core.ErrCodeInternalServer, "failed to create session")
+	}
+
+	if err := s.sessionStore.Store(ctx, session); err != nil {
+		return nil, core.NewAppError(core.ErrCodeInternalServer, "failed to create session")
+	}
+
+	s.emitEvent(ctx, authinterface.AuthEvent{
+		Type:      authinterface.AuthEventLoggedIn,
+		AccountID: account.GetID(),
+		SessionID: session.ID,
+		Metadata:  map[string]any{"method": loginMethodFromContext(ctx)},
+	})
+
+	return session, nil
+}
+
+func (s *AuthService) createMFAChallenge(ctx context.Context, accountID uuid.UUID, tokenType authinterface.TokenType) (*authinterface.LoginResult, error) {
+	token := &authmodel.Token{
+		ID:        uuid.New(),
+		AccountID: accountID,
+		Token:     s.tokenGenerator.GenerateSecureToken(),
+		Type:      tokenType,
+		ExpiresAt: time.Now().Add(s.config.GetMFAChallengeExpiration()),
+	}
+
+	if err := s.tokenRepo.Create(ctx, token); err != nil {
+		return nil, core.NewAppError(core.ErrCodeInternalServer, "failed to create mfa challenge")
+	}
+
+	return &authinterface.LoginResult{
+		Challenge: &authinterface.MFAChallenge{
+			MFARequired:        true,
+			EnrollmentRequired: tokenType == authinterface.TokenTypeMFAEnrollment,
+			Token:              token.Token,
+			ExpiresAt:          token.ExpiresAt,
+		},
+	}, nil
+}
+
+func (s *AuthService) getMFAChallenge(ctx context.Context, mfaToken string) (authinterface.Token, error) {
+	token, err := s.tokenRepo.GetByToken(ctx, mfaToken)
+	if err != nil {
+		return nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrInvalidToken)
+	}
+
+	if token.GetType() != authinterface.TokenTypeMFAChallenge && token.GetType() != authinterface.TokenTypeMFAEnrollment {
+		return nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrInvalidToken)
+	}
+
+	if token.GetUsed() {
+		return nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrTokenAlreadyUsed)
+	}
+
+	if token.IsExpired() {
+		return nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrTokenExpired)
+	}
+
+	return token, nil
+}
+
+func (s *AuthService) isMFARequired(ctx context.Context, accountID uuid.UUID) (bool, error) {
+	requiredRoles := s.config.GetMFARequiredRoles()
+	if len(requiredRoles) == 0 || s.roleLookup == nil {
+		return false, nil
+	}
+
+	roles, err := s.roleLookup.GetUserRoleNames(ctx, accountID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, role := range roles {
+		for _, required := range requiredRoles {
+			if strings.EqualFold(role, required) {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// verifySecondFactor accepts a current TOTP code or an unused recovery code.
+// TOTP codes are bound to their time step so a code cannot be replayed.
+func (s *AuthService) verifySecondFactor(ctx context.Context, account authinterface.Account, code string) error {
+	if step, ok := s.totpProvider.Validate(account.GetMFASecret(), code, time.Now()); ok {
+		if step <= account.GetMFALastUsedStep() {
+			return core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrInvalidMFACode)
+		}
+
+		account.SetMFALastUsedStep(step)
+		if err := s.accountRepo.Update(ctx, account); err != nil {
+			return core.NewAppError(core.ErrCodeInternalServer, "failed to update account")
+		}
+		return nil
+	}
+
+	consumed, err := s.recoveryCodeRepo.Consume(ctx, account.GetID(), hashRecoveryCode(code))
+	if err != nil {
+		return core.NewAppError(core.ErrCodeInternalServer, "failed to verify recovery code")
+	}
+	if !consumed {
+		return core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrInvalidMFACode)
+	}
+
+	return nil
+}
+
+func mfaAttemptKey(challengeID uuid.UUID) string {
+	return "mfa:" + challengeID.String()
+}
+
+func (s *AuthService) generateRecoveryCodes(ctx context.Context, accountID uuid.UUID) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+
+	max := big.NewInt(int64(len(recoveryCodeAlphabet)))
+	for i := range codes {
+		var b strings.Builder
+		for j := 0; j < recoveryCodeLength; j++ {
+			if j == recoveryCodeLength/2 {
+				b.WriteByte('-')
+			}
+			n, err := rand.Int(rand.Reader, max)
+			if err != nil {
+				return nil, core.NewAppError(core.ErrCodeInternalServer, "failed to generate recovery codes")
+			}
+			b.WriteByte(recoveryCodeAlphabet[n.Int64()])
+		}
+		codes[i] = b.String()
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+
+	if err := s.recoveryCodeRepo.Replace(ctx, accountID, hashes); err != nil {
+		return nil, core.NewAppError(core.ErrCodeInternalServer, "failed to store recovery codes")
+	}
+
+	return codes, nil
+}
+
+// hashRecoveryCode normalises case and separators before hashing so codes
+// are accepted however the user types them.
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
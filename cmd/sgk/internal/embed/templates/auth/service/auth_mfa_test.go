@@ -0,0 +1,182 @@
+package authservice
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	authconstants "{{.Project.GoModule}}/internal/auth/constants"
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	authmodel "{{.Project.GoModule}}/internal/auth/model"
+)
+
+// enrollMFA turns on MFA for account and returns its secret and recovery
+// codes. The confirming code uses the current TOTP step, so login tests
+// answer with the next one.
+func enrollMFA(t *testing.T, ts *testService, account *authmodel.Account) (string, []string) {
+	t.Helper()
+	ctx := context.Background()
+
+	setup, err := ts.SetupMFA(ctx, account.ID)
+	if err != nil {
+		t.Fatalf("SetupMFA: %v", err)
+	}
+
+	codes, err := ts.ConfirmMFA(ctx, account.ID, totpCodeAt(t, setup.Secret, 0))
+	if err != nil {
+		t.Fatalf("ConfirmMFA: %v", err)
+	}
+	return setup.Secret, codes
+}
+
+// totpCodeAt returns the code offset steps away from the current one.
+func totpCodeAt(t *testing.T, secret string, offset int64) string {
+	t.Helper()
+
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		t.Fatalf("decode secret: %v", err)
+	}
+	return totpCode(key, time.Now().Unix()/totpPeriod+offset)
+}
+
+func newMFAChallenge(t *testing.T, ts *testService, account *authmodel.Account) string {
+	t.Helper()
+
+	result, err := ts.createMFAChallenge(context.Background(), account.ID, authinterface.TokenTypeMFAChallenge)
+	if err != nil {
+		t.Fatalf("createMFAChallenge: %v", err)
+	}
+	return result.Challenge.Token
+}
+
+func TestVerifyMFA(t *testing.T) {
+	ts := newTestService(t, AuthServiceDeps{})
+	account := ts.createAccount(t, "mfa@example.com")
+	secret, _ := enrollMFA(t, ts, account)
+	challenge := newMFAChallenge(t, ts, account)
+	ctx := context.Background()
+
+	result, err := ts.VerifyMFA(ctx, challenge, totpCodeAt(t, secret, 1))
+	if err != nil {
+		t.Fatalf("VerifyMFA: %v", err)
+	}
+	if result.Session == nil {
+		t.Fatal("VerifyMFA did not issue a session")
+	}
+
+	_, err = ts.VerifyMFA(ctx, challenge, totpCodeAt(t, secret, 1))
+	assertAppError(t, err, authconstants.ErrTokenAlreadyUsed)
+}
+
+func TestVerifyMFARecoveryCode(t *testing.T) {
+	ts := newTestService(t, AuthServiceDeps{})
+	account := ts.createAccount(t, "mfa@example.com")
+	_, codes := enrollMFA(t, ts, account)
+	ctx := context.Background()
+
+	if _, err := ts.VerifyMFA(ctx, newMFAChallenge(t, ts, account), strings.ToUpper(codes[0])); err != nil {
+		t.Fatalf("VerifyMFA: %v", err)
+	}
+
+	_, err := ts.VerifyMFA(ctx, newMFAChallenge(t, ts, account), codes[0])
+	assertAppError(t, err, authconstants.ErrInvalidMFACode)
+}
+
+func TestVerifyMFAInvalidatesChallengeAfterMaxAttempts(t *testing.T) {
+	ts := newTestService(t, AuthServiceDeps{})
+	ts.config.SetMaxFailedLogins(0, 0)
+	account := ts.createAccount(t, "mfa@example.com")
+	secret, _ := enrollMFA(t, ts, account)
+	challenge := newMFAChallenge(t, ts, account)
+	ctx := context.Background()
+
+	limit := ts.config.GetMFAMaxAttempts()
+	for i := 1; i < limit; i++ {
+		_, err := ts.VerifyMFA(ctx, challenge, "000000")
+		assertAppError(t, err, authconstants.ErrInvalidMFACode)
+	}
+
+	_, err := ts.VerifyMFA(ctx, challenge, "000000")
+	assertAppError(t, err, authconstants.ErrTooManyMFAAttempts)
+
+	_, err = ts.VerifyMFA(ctx, challenge, totpCodeAt(t, secret, 1))
+	assertAppError(t, err, authconstants.ErrTokenAlreadyUsed)
+}
+
+func TestVerifyMFAWrongCodesLockAccount(t *testing.T) {
+	ts := newTestService(t, AuthServiceDeps{})
+	ts.config.SetMaxFailedLogins(1, 0)
+	account := ts.createAccount(t, "mfa@example.com")
+	secret, _ := enrollMFA(t, ts, account)
+	ctx := context.Background()
+
+	_, err := ts.VerifyMFA(ctx, newMFAChallenge(t, ts, account), "000000")
+	assertAppError(t, err, authconstants.ErrInvalidMFACode)
+
+	_, err = ts.VerifyMFA(ctx, newMFAChallenge(t, ts, account), totpCodeAt(t, secret, 1))
+	assertAppError(t, err, authconstants.ErrAccountLocked)
+
+	if len(ts.emails.unlocks) != 1 {
+		t.Fatalf("unlock emails = %d, want 1", len(ts.emails.unlocks))
+	}
+}
+
+// barrierRecoveryCodes holds every Consume call until n have arrived, so
+// concurrent verifications all get past the challenge lookup first.
+type barrierRecoveryCodes struct {
+	*fakeRecoveryCodes
+	arrived sync.WaitGroup
+}
+
+func (r *barrierRecoveryCodes) Consume(ctx context.Context, accountID uuid.UUID, codeHash string) (bool, error) {
+	r.arrived.Done()
+	r.arrived.Wait()
+	return r.fakeRecoveryCodes.Consume(ctx, accountID, codeHash)
+}
+
+func TestVerifyMFAConcurrentIssuesOneSession(t *testing.T) {
+	recoveryCodes := &barrierRecoveryCodes{fakeRecoveryCodes: newFakeRecoveryCodes()}
+	ts := newTestService(t, AuthServiceDeps{RecoveryCodeRepo: recoveryCodes})
+	account := ts.createAccount(t, "mfa@example.com")
+	_, codes := enrollMFA(t, ts, account)
+	challenge := newMFAChallenge(t, ts, account)
+	ctx := context.Background()
+
+	// Every request carries a different valid recovery code, so only the
+	// challenge can stop all but one of them.
+	recoveryCodes.arrived.Add(len(codes))
+	var wg sync.WaitGroup
+	errs := make([]error, len(codes))
+	for i, code := range codes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = ts.VerifyMFA(ctx, challenge, code)
+		}()
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+		} else {
+			assertAppError(t, err, authconstants.ErrTokenAlreadyUsed)
+		}
+	}
+	if succeeded != 1 {
+		t.Fatalf("successful verifications = %d, want 1", succeeded)
+	}
+
+	sessions, err := ts.sessions.ListByUser(ctx, account.ID)
+	if err != nil {
+		t.Fatalf("ListByUser: %v", err)
+	}
+	if len(sessions) != 1 {
+		t.Fatalf("sessions = %d, want 1", len(sessions))
+	}
+}
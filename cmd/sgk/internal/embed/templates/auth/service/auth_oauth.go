@@ -0,0 +1,213 @@
+package authservice
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	authconstants "{{.Project.GoModule}}/internal/auth/constants"
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	"{{.Project.GoModule}}/internal/core"
+)
+
+func (s *AuthService) GetOAuthURL(ctx context.Context, provider string) (string, string, error) {
+	return s.startOAuth(ctx, provider, uuid.Nil)
+}
+
+// GetOAuthLinkURL starts an authorization request whose callback links the
+// provider to accountID instead of signing in.
+func (s *AuthService) GetOAuthLinkURL(ctx context.Context, accountID uuid.UUID, provider string) (string, string, error) {
+	if _, err := s.accountRepo.GetByID(ctx, accountID); err != nil {
+		return "", "", core.NewAppError(core.ErrCodeNotFound, authconstants.ErrAccountNotFound)
+	}
+	return s.startOAuth(ctx, provider, accountID)
+}
+
+func (s *AuthService) HandleOAuthCallback(ctx context.Context, provider string, code string, state string) (*authinterface.LoginResult, error) {
+	return s.redeemOAuthCode(ctx, provider, code, state, true)
+}
+
+// redeemOAuthCode signs in with an authorization code, or links the
+// provider when the state was issued by GetOAuthLinkURL and allowLink is
+// set.
+func (s *AuthService) redeemOAuthCode(ctx context.Context, provider, code, state string, allowLink bool) (*authinterface.LoginResult, error) {
+	strategy, err := s.getOAuthStrategy(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := s.oauthStateStore.Consume(ctx, state)
+	if err != nil || subtle.ConstantTimeCompare([]byte(stored.Provider), []byte(strategy.Name())) != 1 {
+		return nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrInvalidOAuthState)
+	}
+
+	if stored.AccountID != uuid.Nil && !allowLink {
+		return nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrInvalidOAuthState)
+	}
+
+	credentials := map[string]any{
+		"code":          code,
+		"state":         state,
+		"code_verifier": stored.CodeVerifier,
+		"nonce":         stored.Nonce,
+	}
+	if stored.AccountID != uuid.Nil {
+		credentials["link_account_id"] = stored.AccountID
+	}
+
+	ctx = withLoginMethod(ctx, strategy.Name())
+	result, err := strategy.Authenticate(ctx, credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	if stored.AccountID != uuid.Nil {
+		providerUserID, _ := result.Metadata["provider_id"].(string)
+		identity, err := s.identityRepo.GetByProvider(ctx, strategy.Name(), providerUserID)
+		if err != nil {
+			return nil, core.NewAppError(core.ErrCodeInternalServer, "failed to link identity")
+		}
+
+		s.emitEvent(ctx, authinterface.AuthEvent{
+			Type:      authinterface.AuthEventOAuthLinked,
+			AccountID: stored.AccountID,
+			Metadata: map[string]any{
+				"provider":    strategy.Name(),
+				"identity_id": identity.GetID().String(),
+			},
+		})
+
+		return &authinterface.LoginResult{LinkedIdentity: identity}, nil
+	}
+
+	if _, isNew := result.Metadata["is_new_account"]; isNew {
+		s.emitEvent(ctx, authinterface.AuthEvent{
+			Type:      authinterface.AuthEventRegistered,
+			AccountID: result.Account.GetID(),
+			Metadata:  map[string]any{"method": strategy.Name()},
+		})
+	}
+
+	loginResult, err := s.completeLogin(ctx, result.Account)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.emailSender != nil && result.Metadata != nil {
+		if _, isNew := result.Metadata["is_new_account"]; isNew {
+			s.emailSender.SendWelcomeEmail(result.Account.GetEmail())
+		}
+	}
+
+	return loginResult, nil
+}
+
+func (s *AuthService) ListIdentities(ctx context.Context, accountID uuid.UUID) ([]authinterface.Identity, error) {
+	identities, err := s.identityRepo.ListByAccount(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list identities: %w", err)
+	}
+	return identities, nil
+}
+
+// UnlinkIdentity removes a linked provider, refusing to remove the last one
+// from an account that has no password or passkey to sign in with.
+func (s *AuthService) UnlinkIdentity(ctx context.Context, accountID, identityID uuid.UUID) error {
+	account, err := s.accountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return core.NewAppError(core.ErrCodeNotFound, authconstants.ErrAccountNotFound)
+	}
+
+	identities, err := s.identityRepo.ListByAccount(ctx, accountID)
+	if err != nil {
+		return fmt.Errorf("failed to list identities: %w", err)
+	}
+
+	found := false
+	for _, identity := range identities {
+		if identity.GetID() == identityID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return core.NewAppError(core.ErrCodeNotFound, authconstants.ErrIdentityNotFound)
+	}
+
+	if account.GetPasswordHash() == "" && len(identities) == 1 {
+		passkeys, err := s.credentialRepo.ListByAccount(ctx, accountID)
+		if err != nil {
+			return fmt.Errorf("failed to list passkeys: %w", err)
+		}
+		if len(passkeys) == 0 {
+			return core.NewAppError(core.ErrCodeBadRequest, authconstants.ErrLastSignInMethod)
+		}
+	}
+
+	if err := s.identityRepo.Delete(ctx, accountID, identityID); err != nil {
+		return core.NewAppError(core.ErrCodeNotFound, authconstants.ErrIdentityNotFound)
+	}
+
+	return nil
+}
+
+func (s *AuthService) getOAuthStrategy(provider string) (authinterface.OAuthStrategy, error) {
+	strategy, err := s.strategyRegistry.Get(provider)
+	if err != nil {
+		return nil, core.NewAppError(core.ErrCodeBadRequest, fmt.Sprintf("provider %s not supported", provider))
+	}
+
+	oauthStrategy, ok := strategy.(authinterface.OAuthStrategy)
+	if !ok {
+		return nil, core.NewAppError(core.ErrCodeBadRequest, fmt.Sprintf("provider %s is not an OAuth provider", provider))
+	}
+
+	return oauthStrategy, nil
+}
+
+// startOAuth stores a fresh state, nonce and PKCE verifier server side and
+// returns the provider URL carrying the state, nonce and code challenge,
+// along with the state.
+func (s *AuthService) startOAuth(ctx context.Context, provider string, accountID uuid.UUID) (string, string, error) {
+	strategy, err := s.getOAuthStrategy(provider)
+	if err != nil {
+		return "", "", err
+	}
+
+	stored := &authinterface.OAuthState{
+		Provider:  strategy.Name(),
+		AccountID: accountID,
+		CreatedAt: time.Now(),
+	}
+	for _, value := range []*string{&stored.State, &stored.Nonce, &stored.CodeVerifier} {
+		if *value, err = randomURLToken(32); err != nil {
+			return "", "", fmt.Errorf("failed to generate oauth state: %w", err)
+		}
+	}
+
+	if err := s.oauthStateStore.Save(ctx, stored, s.config.GetOAuthStateExpiration()); err != nil {
+		return "", "", fmt.Errorf("failed to store oauth state: %w", err)
+	}
+
+	challenge := sha256.Sum256([]byte(stored.CodeVerifier))
+
+	authURL := strategy.GetAuthURL(authinterface.OAuthRequest{
+		State:         stored.State,
+		Nonce:         stored.Nonce,
+		CodeChallenge: base64.RawURLEncoding.EncodeToString(challenge[:]),
+	})
+	return authURL, stored.State, nil
+}
+
+func randomURLToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,150 @@
+package authservice
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/google/uuid"
+	authconstants "{{.Project.GoModule}}/internal/auth/constants"
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	authmodel "{{.Project.GoModule}}/internal/auth/model"
+	"{{.Project.GoModule}}/internal/auth/repository/memory"
+)
+
+// stubOAuthStrategy signs every code in as account, and records a linked
+// identity when asked to link.
+type stubOAuthStrategy struct {
+	authinterface.OAuthStrategy
+	account       *authmodel.Account
+	authenticated int
+}
+
+func (s *stubOAuthStrategy) Name() string { return "stub" }
+
+func (s *stubOAuthStrategy) Type() authinterface.AuthStrategyType {
+	return authinterface.StrategyTypeOAuth
+}
+
+func (s *stubOAuthStrategy) GetAuthURL(request authinterface.OAuthRequest) string {
+	return "https://provider.example.com/authorize?state=" + url.QueryEscape(request.State)
+}
+
+func (s *stubOAuthStrategy) ValidateCredentials(credentials map[string]any) error {
+	return nil
+}
+
+func (s *stubOAuthStrategy) Authenticate(ctx context.Context, credentials map[string]any) (*authinterface.AuthResult, error) {
+	s.authenticated++
+	return &authinterface.AuthResult{
+		Account:  s.account,
+		Metadata: map[string]any{"provider_id": "provider-user"},
+	}, nil
+}
+
+type fakeIdentities struct {
+	authinterface.IdentityRepository
+}
+
+func (fakeIdentities) GetByProvider(ctx context.Context, provider, providerUserID string) (authinterface.Identity, error) {
+	if providerUserID != "provider-user" {
+		return nil, errors.New("identity not found")
+	}
+	return &authmodel.Identity{ID: uuid.New(), Provider: provider, ProviderUserID: providerUserID}, nil
+}
+
+func newOAuthTestService(t *testing.T) (*testService, *stubOAuthStrategy) {
+	t.Helper()
+
+	strategy := &stubOAuthStrategy{}
+	registry := NewStrategyRegistry()
+	if err := registry.Register(strategy); err != nil {
+		t.Fatalf("register strategy: %v", err)
+	}
+
+	ts := newTestService(t, AuthServiceDeps{
+		StrategyRegistry: registry,
+		OAuthStateStore:  memory.NewOAuthStateStore(),
+		IdentityRepo:     fakeIdentities{},
+	})
+	strategy.account = ts.createAccount(t, "oauth@example.com")
+	return ts, strategy
+}
+
+func TestGetOAuthURLReturnsState(t *testing.T) {
+	ts, _ := newOAuthTestService(t)
+
+	authURL, state, err := ts.GetOAuthURL(context.Background(), "stub")
+	if err != nil {
+		t.Fatalf("GetOAuthURL: %v", err)
+	}
+
+	parsed, _ := url.Parse(authURL)
+	if state == "" || parsed.Query().Get("state") != state {
+		t.Fatalf("state = %q, want the one in %s", state, authURL)
+	}
+}
+
+func TestOAuthLoginWithCode(t *testing.T) {
+	ts, strategy := newOAuthTestService(t)
+	ctx := context.Background()
+
+	_, state, err := ts.GetOAuthURL(ctx, "stub")
+	if err != nil {
+		t.Fatalf("GetOAuthURL: %v", err)
+	}
+
+	result, err := ts.Login(ctx, &authmodel.LoginRequest{
+		Strategy:    "stub",
+		Credentials: map[string]any{"code": "code", "state": state},
+	})
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if result.Session == nil || result.Session.GetUserID() != strategy.account.ID {
+		t.Fatalf("result = %+v, want a session for the account", result)
+	}
+}
+
+func TestOAuthLoginRejectsLinkState(t *testing.T) {
+	ts, strategy := newOAuthTestService(t)
+	ctx := context.Background()
+	victim := ts.createAccount(t, "victim@example.com")
+
+	_, state, err := ts.GetOAuthLinkURL(ctx, victim.ID, "stub")
+	if err != nil {
+		t.Fatalf("GetOAuthLinkURL: %v", err)
+	}
+
+	_, err = ts.Login(ctx, &authmodel.LoginRequest{
+		Strategy:    "stub",
+		Credentials: map[string]any{"code": "code", "state": state},
+	})
+	assertAppError(t, err, authconstants.ErrInvalidOAuthState)
+	if strategy.authenticated != 0 {
+		t.Fatal("the code was redeemed for a link state")
+	}
+}
+
+func TestOAuthCallbackLinksIdentity(t *testing.T) {
+	ts, _ := newOAuthTestService(t)
+	ctx := context.Background()
+	owner := ts.createAccount(t, "owner@example.com")
+
+	_, state, err := ts.GetOAuthLinkURL(ctx, owner.ID, "stub")
+	if err != nil {
+		t.Fatalf("GetOAuthLinkURL: %v", err)
+	}
+
+	result, err := ts.HandleOAuthCallback(ctx, "stub", "code", state)
+	if err != nil {
+		t.Fatalf("HandleOAuthCallback: %v", err)
+	}
+	if result.LinkedIdentity == nil || result.Session != nil {
+		t.Fatalf("result = %+v, want only the linked identity", result)
+	}
+
+	_, err = ts.HandleOAuthCallback(ctx, "stub", "code", state)
+	assertAppError(t, err, authconstants.ErrInvalidOAuthState)
+}
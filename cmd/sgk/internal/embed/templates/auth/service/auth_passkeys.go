@@ -0,0 +1,230 @@
+package authservice
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	authconstants "{{.Project.GoModule}}/internal/auth/constants"
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	authmodel "{{.Project.GoModule}}/internal/auth/model"
+	"{{.Project.GoModule}}/internal/core"
+)
+
+func (s *AuthService) BeginPasskeyRegistration(ctx context.Context, accountID uuid.UUID) (*authinterface.WebAuthnCreationOptions, error) {
+	account, err := s.accountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return nil, core.NewAppError(core.ErrCodeNotFound, authconstants.ErrAccountNotFound)
+	}
+
+	existing, err := s.credentialRepo.ListByAccount(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list passkeys: %w", err)
+	}
+
+	challenge, err := s.startWebAuthnCeremony(ctx, authinterface.WebAuthnCeremonyRegistration, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	params := make([]authinterface.WebAuthnCredentialParameter, len(webAuthnAlgorithms))
+	for i, alg := range webAuthnAlgorithms {
+		params[i] = authinterface.WebAuthnCredentialParameter{Type: "public-key", Alg: alg}
+	}
+
+	return &authinterface.WebAuthnCreationOptions{
+		Challenge: challenge,
+		RP: authinterface.WebAuthnRelyingParty{
+			ID:   s.config.GetWebAuthnRPID(),
+			Name: s.config.GetWebAuthnRPName(),
+		},
+		User: authinterface.WebAuthnUser{
+			ID:          base64.RawURLEncoding.EncodeToString(accountID[:]),
+			Name:        account.GetEmail(),
+			DisplayName: account.GetEmail(),
+		},
+		PubKeyCredParams:   params,
+		Timeout:            s.config.GetWebAuthnTimeout().Milliseconds(),
+		ExcludeCredentials: webAuthnDescriptors(existing),
+		AuthenticatorSelection: authinterface.WebAuthnAuthenticatorSelection{
+			ResidentKey:      "preferred",
+			UserVerification: "required",
+		},
+		Attestation: "none",
+	}, nil
+}
+
+func (s *AuthService) FinishPasskeyRegistration(ctx context.Context, accountID uuid.UUID, name string, credential *authinterface.WebAuthnCredentialResponse) (authinterface.WebAuthnCredential, error) {
+	clientData, _, err := parseClientData(credential.Response.ClientDataJSON, "webauthn.create", s.config.GetWebAuthnOrigins())
+	if err != nil {
+		return nil, core.NewAppError(core.ErrCodeBadRequest, authconstants.ErrInvalidPasskey, err)
+	}
+
+	session, err := s.webAuthnSessions.Consume(ctx, clientData.Challenge)
+	if err != nil || session.Ceremony != authinterface.WebAuthnCeremonyRegistration || session.AccountID != accountID {
+		return nil, core.NewAppError(core.ErrCodeBadRequest, authconstants.ErrPasskeyChallenge)
+	}
+
+	authData, err := parseAttestationObject(credential.Response.AttestationObject)
+	if err != nil {
+		return nil, core.NewAppError(core.ErrCodeBadRequest, authconstants.ErrInvalidPasskey, err)
+	}
+
+	if err := authData.verify(s.config.GetWebAuthnRPID()); err != nil {
+		return nil, core.NewAppError(core.ErrCodeBadRequest, authconstants.ErrInvalidPasskey, err)
+	}
+
+	if authData.CredentialID == nil {
+		return nil, core.NewAppError(core.ErrCodeBadRequest, authconstants.ErrInvalidPasskey)
+	}
+
+	if _, _, err := parseCOSEKey(authData.PublicKey); err != nil {
+		return nil, core.NewAppError(core.ErrCodeBadRequest, authconstants.ErrInvalidPasskey, err)
+	}
+
+	credentialID := base64.RawURLEncoding.EncodeToString(authData.CredentialID)
+	if _, err := s.credentialRepo.GetByCredentialID(ctx, credentialID); err == nil {
+		return nil, core.NewAppError(core.ErrCodeConflict, authconstants.ErrPasskeyExists)
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = fmt.Sprintf("Passkey added %s", time.Now().Format("2006-01-02"))
+	}
+
+	passkey := &authmodel.WebAuthnCredential{
+		ID:           uuid.New(),
+		AccountID:    accountID,
+		CredentialID: credentialID,
+		PublicKey:    authData.PublicKey,
+		SignCount:    authData.SignCount,
+		Transports:   credential.Response.Transports,
+		Name:         name,
+	}
+
+	if err := s.credentialRepo.Create(ctx, passkey); err != nil {
+		return nil, core.NewAppError(core.ErrCodeInternalServer, "failed to save passkey")
+	}
+
+	return passkey, nil
+}
+
+// BeginPasskeyLogin starts a login ceremony. With an email the browser is
+// told which passkeys the account has; without one the user picks a
+// discoverable passkey. Unknown emails get an empty list rather than an
+// error so the endpoint does not reveal which accounts exist.
+func (s *AuthService) BeginPasskeyLogin(ctx context.Context, email string) (*authinterface.WebAuthnRequestOptions, error) {
+	accountID := uuid.Nil
+	allowed := []authinterface.WebAuthnCredentialDescriptor{}
+
+	if email != "" {
+		if account, err := s.accountRepo.GetByEmail(ctx, email); err == nil {
+			accountID = account.GetID()
+			credentials, err := s.credentialRepo.ListByAccount(ctx, accountID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list passkeys: %w", err)
+			}
+			allowed = webAuthnDescriptors(credentials)
+		}
+	}
+
+	challenge, err := s.startWebAuthnCeremony(ctx, authinterface.WebAuthnCeremonyLogin, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &authinterface.WebAuthnRequestOptions{
+		Challenge:        challenge,
+		Timeout:          s.config.GetWebAuthnTimeout().Milliseconds(),
+		RPID:             s.config.GetWebAuthnRPID(),
+		AllowCredentials: allowed,
+		UserVerification: "required",
+	}, nil
+}
+
+func (s *AuthService) FinishPasskeyLogin(ctx context.Context, credential *authinterface.WebAuthnCredentialResponse) (*authinterface.LoginResult, error) {
+	strategy, err := s.strategyRegistry.Get("webauthn")
+	if err != nil {
+		return nil, core.NewAppError(core.ErrCodeBadRequest, "authentication strategy not available")
+	}
+
+	ctx = withLoginMethod(ctx, "passkey")
+	result, err := strategy.Authenticate(ctx, map[string]any{
+		"credential": credential,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.completeLogin(ctx, result.Account)
+}
+
+func (s *AuthService) ListPasskeys(ctx context.Context, accountID uuid.UUID) ([]authinterface.WebAuthnCredential, error) {
+	credentials, err := s.credentialRepo.ListByAccount(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list passkeys: %w", err)
+	}
+	return credentials, nil
+}
+
+// DeletePasskey removes a passkey unless it is the only way left to sign in
+// to an account without a password or linked provider.
+func (s *AuthService) DeletePasskey(ctx context.Context, accountID, passkeyID uuid.UUID) error {
+	account, err := s.accountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return core.NewAppError(core.ErrCodeNotFound, authconstants.ErrAccountNotFound)
+	}
+
+	if account.GetPasswordHash() == "" {
+		passkeys, err := s.credentialRepo.ListByAccount(ctx, accountID)
+		if err != nil {
+			return fmt.Errorf("failed to list passkeys: %w", err)
+		}
+		identities, err := s.identityRepo.ListByAccount(ctx, accountID)
+		if err != nil {
+			return fmt.Errorf("failed to list identities: %w", err)
+		}
+		if len(passkeys) == 1 && len(identities) == 0 {
+			return core.NewAppError(core.ErrCodeBadRequest, authconstants.ErrLastSignInMethod)
+		}
+	}
+
+	if err := s.credentialRepo.Delete(ctx, accountID, passkeyID); err != nil {
+		return core.NewAppError(core.ErrCodeNotFound, authconstants.ErrPasskeyNotFound)
+	}
+	return nil
+}
+
+func (s *AuthService) startWebAuthnCeremony(ctx context.Context, ceremony authinterface.WebAuthnCeremony, accountID uuid.UUID) (string, error) {
+	challenge, err := randomURLToken(32)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate challenge: %w", err)
+	}
+
+	session := &authinterface.WebAuthnSession{
+		Challenge: challenge,
+		Ceremony:  ceremony,
+		AccountID: accountID,
+		CreatedAt: time.Now(),
+	}
+	if err := s.webAuthnSessions.Save(ctx, session, s.config.GetWebAuthnTimeout()); err != nil {
+		return "", fmt.Errorf("failed to store challenge: %w", err)
+	}
+
+	return challenge, nil
+}
+
+func webAuthnDescriptors(credentials []authinterface.WebAuthnCredential) []authinterface.WebAuthnCredentialDescriptor {
+	descriptors := make([]authinterface.WebAuthnCredentialDescriptor, len(credentials))
+	for i, credential := range credentials {
+		descriptors[i] = authinterface.WebAuthnCredentialDescriptor{
+			Type:       "public-key",
+			ID:         credential.GetCredentialID(),
+			Transports: credential.GetTransports(),
+		}
+	}
+	return descriptors
+}
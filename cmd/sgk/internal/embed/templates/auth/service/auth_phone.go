@@ -0,0 +1,313 @@
+package authservice
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	authconstants "{{.Project.GoModule}}/internal/auth/constants"
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	authmodel "{{.Project.GoModule}}/internal/auth/model"
+	"{{.Project.GoModule}}/internal/core"
+)
+
+// SendPhoneVerification texts a new six digit code to the account's phone
+// and invalidates the codes sent before it.
+func (s *AuthService) SendPhoneVerification(ctx context.Context, accountID uuid.UUID) error {
+	account, err := s.accountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return core.NewAppError(core.ErrCodeNotFound, authconstants.ErrAccountNotFound)
+	}
+
+	if account.GetPhoneVerified() {
+		return nil
+	}
+
+	if account.GetPhone() == "" {
+		return core.NewAppError(core.ErrCodeBadRequest, authconstants.ErrPhoneRequired)
+	}
+
+	tokens, err := s.tokenRepo.GetByAccountAndType(ctx, accountID, authinterface.TokenTypePhoneVerification)
+	if err != nil {
+		return core.NewAppError(core.ErrCodeInternalServer, "failed to get verification tokens")
+	}
+
+	if err := s.checkPhoneResend(tokens); err != nil {
+		return err
+	}
+
+	s.invalidateTokens(ctx, accountID, authinterface.TokenTypePhoneVerification)
+
+	code := s.tokenGenerator.GenerateToken()
+	tokenID := uuid.New()
+
+	token := &authmodel.Token{
+		ID:        tokenID,
+		AccountID: accountID,
+		Token:     hashPhoneCode(tokenID, accountID, code),
+		Type:      authinterface.TokenTypePhoneVerification,
+		ExpiresAt: time.Now().Add(s.config.GetPhoneVerificationExpiration()),
+	}
+
+	if err := s.tokenRepo.Create(ctx, token); err != nil {
+		return core.NewAppError(core.ErrCodeInternalServer, "failed to create verification token")
+	}
+
+	s.resetPhoneCodeAttempts(ctx, accountID)
+
+	if err := s.smsSender.SendVerificationSMS(account.GetPhone(), code); err != nil {
+		return core.NewAppError(core.ErrCodeInternalServer, "failed to send verification code", err)
+	}
+
+	return nil
+}
+
+func (s *AuthService) VerifyPhone(ctx context.Context, accountID uuid.UUID, code string) error {
+	tokens, err := s.tokenRepo.GetByAccountAndType(ctx, accountID, authinterface.TokenTypePhoneVerification)
+	if err != nil {
+		return core.NewAppError(core.ErrCodeInternalServer, "failed to get verification tokens")
+	}
+
+	var validToken authinterface.Token
+	for _, token := range tokens {
+		if token.GetUsed() || token.IsExpired() {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(token.GetToken()), []byte(hashPhoneCode(token.GetID(), accountID, code))) == 1 {
+			validToken = token
+			break
+		}
+	}
+
+	if validToken == nil {
+		return s.recordPhoneCodeFailure(ctx, accountID)
+	}
+
+	if err := s.tokenRepo.MarkAsUsed(ctx, validToken.GetID()); err != nil {
+		return core.NewAppError(core.ErrCodeBadRequest, authconstants.ErrTokenAlreadyUsed)
+	}
+
+	account, err := s.accountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return core.NewAppError(core.ErrCodeNotFound, authconstants.ErrAccountNotFound)
+	}
+
+	account.SetPhoneVerified(true)
+	if err := s.accountRepo.Update(ctx, account); err != nil {
+		return core.NewAppError(core.ErrCodeInternalServer, "failed to update account")
+	}
+
+	s.resetPhoneCodeAttempts(ctx, accountID)
+
+	return nil
+}
+
+// VerifyPhoneChallenge verifies the phone of an account that was stopped
+// at login and continues that login.
+func (s *AuthService) VerifyPhoneChallenge(ctx context.Context, phoneToken, code string) (*authinterface.LoginResult, error) {
+	token, err := s.tokenRepo.GetByToken(ctx, phoneToken)
+	if err != nil || token.GetType() != authinterface.TokenTypePhoneChallenge {
+		return nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrInvalidToken)
+	}
+
+	if token.GetUsed() {
+		return nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrTokenAlreadyUsed)
+	}
+
+	if token.IsExpired() {
+		return nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrTokenExpired)
+	}
+
+	if err := s.VerifyPhone(ctx, token.GetAccountID(), code); err != nil {
+		return nil, err
+	}
+
+	if err := s.tokenRepo.MarkAsUsed(ctx, token.GetID()); err != nil {
+		return nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrTokenAlreadyUsed)
+	}
+
+	account, err := s.accountRepo.GetByID(ctx, token.GetAccountID())
+	if err != nil {
+		return nil, core.NewAppError(core.ErrCodeNotFound, authconstants.ErrAccountNotFound)
+	}
+
+	return s.completeLogin(withLoginMethod(ctx, "phone"), account)
+}
+
+// SetChallengePhone adds a phone to an account that was stopped at login
+// without one, or corrects the unverified number it gave, and texts it a
+// code for VerifyPhoneChallenge.
+func (s *AuthService) SetChallengePhone(ctx context.Context, phoneToken, phone string) error {
+	token, err := s.tokenRepo.GetByToken(ctx, phoneToken)
+	if err != nil || token.GetType() != authinterface.TokenTypePhoneChallenge {
+		return core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrInvalidToken)
+	}
+
+	if token.GetUsed() {
+		return core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrTokenAlreadyUsed)
+	}
+
+	if token.IsExpired() {
+		return core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrTokenExpired)
+	}
+
+	account, err := s.accountRepo.GetByID(ctx, token.GetAccountID())
+	if err != nil {
+		return core.NewAppError(core.ErrCodeNotFound, authconstants.ErrAccountNotFound)
+	}
+
+	if account.GetPhoneVerified() {
+		return core.NewAppError(core.ErrCodeBadRequest, authconstants.ErrPhoneAlreadyVerified)
+	}
+
+	if phone != account.GetPhone() {
+		if err := s.setPhone(ctx, account, phone); err != nil {
+			return err
+		}
+		if err := s.accountRepo.Update(ctx, account); err != nil {
+			return core.NewAppError(core.ErrCodeInternalServer, "failed to update account")
+		}
+	}
+
+	return s.SendPhoneVerification(ctx, account.GetID())
+}
+
+// setPhone changes the account's phone, which has to be verified again. The
+// phone cannot be removed while verification is required. The caller saves
+// the account.
+func (s *AuthService) setPhone(ctx context.Context, account authinterface.Account, phone string) error {
+	if phone == "" {
+		if s.config.IsPhoneVerificationRequired() {
+			return core.NewAppError(core.ErrCodeBadRequest, authconstants.ErrPhoneCannotBeRemoved)
+		}
+	} else {
+		exists, err := s.accountRepo.ExistsByPhone(ctx, phone)
+		if err != nil {
+			return core.NewAppError(core.ErrCodeInternalServer, "failed to check phone existence")
+		}
+		if exists {
+			return core.NewAppError(core.ErrCodeConflict, authconstants.ErrPhoneInUse)
+		}
+	}
+
+	account.SetPhone(phone)
+	account.SetPhoneVerified(false)
+	return nil
+}
+
+// createPhoneChallenge texts a code to an account that has to verify its
+// phone before signing in. A code sent moments ago stays valid, so resend
+// throttling does not fail the login. Accounts without a phone get a
+// challenge that asks for one instead.
+func (s *AuthService) createPhoneChallenge(ctx context.Context, account authinterface.Account) (*authinterface.LoginResult, error) {
+	phoneRequired := account.GetPhone() == ""
+	if !phoneRequired {
+		if err := s.SendPhoneVerification(ctx, account.GetID()); err != nil {
+			var appErr *core.AppError
+			if !errors.As(err, &appErr) || appErr.Code != core.ErrCodeTooManyRequests {
+				return nil, err
+			}
+		}
+	}
+
+	token := &authmodel.Token{
+		ID:        uuid.New(),
+		AccountID: account.GetID(),
+		Token:     s.tokenGenerator.GenerateSecureToken(),
+		Type:      authinterface.TokenTypePhoneChallenge,
+		ExpiresAt: time.Now().Add(s.config.GetPhoneVerificationExpiration()),
+	}
+
+	if err := s.tokenRepo.Create(ctx, token); err != nil {
+		return nil, core.NewAppError(core.ErrCodeInternalServer, "failed to create phone challenge")
+	}
+
+	return &authinterface.LoginResult{
+		PhoneChallenge: &authinterface.PhoneChallenge{
+			PhoneVerificationRequired: true,
+			PhoneRequired:             phoneRequired,
+			Token:                     token.Token,
+			ExpiresAt:                 token.ExpiresAt,
+		},
+	}, nil
+}
+
+func (s *AuthService) checkPhoneResend(tokens []authinterface.Token) error {
+	now := time.Now()
+	since := now.Add(-time.Hour)
+
+	var latest time.Time
+	recent := 0
+	for _, token := range tokens {
+		if token.GetCreatedAt().After(latest) {
+			latest = token.GetCreatedAt()
+		}
+		if token.GetCreatedAt().After(since) {
+			recent++
+		}
+	}
+
+	if next := latest.Add(s.config.GetPhoneVerificationResendInterval()); now.Before(next) {
+		return loginBlocked(core.ErrCodeTooManyRequests, authconstants.ErrCodeRecentlySent, next.Sub(now))
+	}
+
+	if limit := s.config.GetPhoneVerificationsPerHour(); limit > 0 && recent >= limit {
+		return loginBlocked(core.ErrCodeTooManyRequests, authconstants.ErrCodeRecentlySent, time.Hour)
+	}
+
+	return nil
+}
+
+// recordPhoneCodeFailure counts a wrong code. Once the limit is reached the
+// outstanding codes are invalidated, so guessing on needs a new code, which
+// is subject to resend throttling.
+func (s *AuthService) recordPhoneCodeFailure(ctx context.Context, accountID uuid.UUID) error {
+	invalid := core.NewAppError(core.ErrCodeBadRequest, authconstants.ErrInvalidToken)
+
+	limit := s.config.GetPhoneVerificationMaxAttempts()
+	if s.loginAttempts == nil || limit <= 0 {
+		return invalid
+	}
+
+	attempts, err := s.loginAttempts.RecordFailure(ctx, phoneAttemptKey(accountID), s.config.GetPhoneVerificationExpiration())
+	if err != nil {
+		fmt.Printf("Failed to record verification failure: %v\n", err)
+		return invalid
+	}
+
+	if attempts.Failures < limit {
+		return invalid
+	}
+
+	s.invalidateTokens(ctx, accountID, authinterface.TokenTypePhoneVerification)
+	s.resetPhoneCodeAttempts(ctx, accountID)
+
+	return core.NewAppError(core.ErrCodeTooManyRequests, authconstants.ErrTooManyCodeAttempts)
+}
+
+func (s *AuthService) resetPhoneCodeAttempts(ctx context.Context, accountID uuid.UUID) {
+	if s.loginAttempts == nil {
+		return
+	}
+
+	if err := s.loginAttempts.Reset(ctx, phoneAttemptKey(accountID)); err != nil {
+		fmt.Printf("Failed to reset verification attempts: %v\n", err)
+	}
+}
+
+// hashPhoneCode is the stored form of a phone code. Codes are only six
+// digits, so different accounts are often sent the same one; keying the hash
+// to the token keeps the stored values unique.
+func hashPhoneCode(tokenID, accountID uuid.UUID, code string) string {
+	sum := sha256.Sum256([]byte(tokenID.String() + ":" + accountID.String() + ":" + code))
+	return hex.EncodeToString(sum[:])
+}
+
+func phoneAttemptKey(accountID uuid.UUID) string {
+	return "phone:" + accountID.String()
+}
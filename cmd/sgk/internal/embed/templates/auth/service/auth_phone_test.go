@@ -0,0 +1,231 @@
+package authservice
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	authconstants "{{.Project.GoModule}}/internal/auth/constants"
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	authmodel "{{.Project.GoModule}}/internal/auth/model"
+)
+
+func newPhoneTestService(t *testing.T) *testService {
+	t.Helper()
+
+	ts := newTestService(t, AuthServiceDeps{})
+	ts.config.phoneVerificationRequired = true
+	return ts
+}
+
+func TestLoginWithoutPhoneRequiresOne(t *testing.T) {
+	ts := newPhoneTestService(t)
+	account := ts.createAccount(t, "nophone@example.com")
+	ctx := context.Background()
+
+	result, err := ts.completeLogin(ctx, account)
+	if err != nil {
+		t.Fatalf("completeLogin: %v", err)
+	}
+	if result.Session != nil || result.PhoneChallenge == nil {
+		t.Fatalf("result = %+v, want a phone challenge instead of a session", result)
+	}
+	if !result.PhoneChallenge.PhoneRequired {
+		t.Fatal("challenge does not ask for a phone")
+	}
+	if ts.sms.sentCount() != 0 {
+		t.Fatal("a code was sent to an account without a phone")
+	}
+
+	challenge := result.PhoneChallenge.Token
+	if err := ts.SetChallengePhone(ctx, challenge, "+15550001111"); err != nil {
+		t.Fatalf("SetChallengePhone: %v", err)
+	}
+
+	code := ts.sms.lastCode("+15550001111")
+	if code == "" {
+		t.Fatal("no code was texted to the new phone")
+	}
+
+	result, err = ts.VerifyPhoneChallenge(ctx, challenge, code)
+	if err != nil {
+		t.Fatalf("VerifyPhoneChallenge: %v", err)
+	}
+	if result.Session == nil {
+		t.Fatalf("result = %+v, want a session", result)
+	}
+
+	stored, _ := ts.accounts.GetByID(ctx, account.ID)
+	if stored.GetPhone() != "+15550001111" || !stored.GetPhoneVerified() {
+		t.Fatalf("phone = %q verified %v, want the new phone verified", stored.GetPhone(), stored.GetPhoneVerified())
+	}
+
+	err = ts.SetChallengePhone(ctx, challenge, "+15550002222")
+	assertAppError(t, err, authconstants.ErrTokenAlreadyUsed)
+}
+
+func TestLoginWithUnverifiedPhone(t *testing.T) {
+	ts := newPhoneTestService(t)
+	account := ts.createAccount(t, "unverified@example.com")
+	account.Phone = "+15550003333"
+	ts.accounts.Update(context.Background(), account)
+
+	result, err := ts.completeLogin(context.Background(), account)
+	if err != nil {
+		t.Fatalf("completeLogin: %v", err)
+	}
+	if result.PhoneChallenge == nil || result.PhoneChallenge.PhoneRequired {
+		t.Fatalf("result = %+v, want a challenge for the existing phone", result)
+	}
+	if ts.sms.lastCode("+15550003333") == "" {
+		t.Fatal("no code was texted")
+	}
+}
+
+func TestSetChallengePhoneRejectsOtherTokens(t *testing.T) {
+	ts := newPhoneTestService(t)
+	account := ts.createAccount(t, "mfa@example.com")
+	mfaChallenge := newMFAChallenge(t, ts, account)
+
+	err := ts.SetChallengePhone(context.Background(), mfaChallenge, "+15550004444")
+	assertAppError(t, err, authconstants.ErrInvalidToken)
+}
+
+func TestSetChallengePhoneRejectsPhoneInUse(t *testing.T) {
+	ts := newPhoneTestService(t)
+	ctx := context.Background()
+
+	owner := ts.createAccount(t, "owner@example.com")
+	owner.Phone = "+15550005555"
+	ts.accounts.Update(ctx, owner)
+
+	account := ts.createAccount(t, "nophone@example.com")
+	result, err := ts.completeLogin(ctx, account)
+	if err != nil {
+		t.Fatalf("completeLogin: %v", err)
+	}
+
+	err = ts.SetChallengePhone(ctx, result.PhoneChallenge.Token, "+15550005555")
+	assertAppError(t, err, authconstants.ErrPhoneInUse)
+}
+
+func TestUpdateAccountPhone(t *testing.T) {
+	ctx := context.Background()
+	empty := ""
+
+	t.Run("cannot remove while required", func(t *testing.T) {
+		ts := newPhoneTestService(t)
+		account := ts.createAccount(t, "user@example.com")
+		account.Phone = "+15550006666"
+		account.PhoneVerified = true
+		ts.accounts.Update(ctx, account)
+
+		_, err := ts.UpdateAccount(ctx, account.ID, authinterface.AccountUpdates{Phone: &empty})
+		assertAppError(t, err, authconstants.ErrPhoneCannotBeRemoved)
+
+		stored, _ := ts.accounts.GetByID(ctx, account.ID)
+		if stored.GetPhone() != "+15550006666" || !stored.GetPhoneVerified() {
+			t.Fatal("the verified phone was changed")
+		}
+	})
+
+	t.Run("can remove when not required", func(t *testing.T) {
+		ts := newTestService(t, AuthServiceDeps{})
+		account := ts.createAccount(t, "user@example.com")
+		account.Phone = "+15550006666"
+		ts.accounts.Update(ctx, account)
+
+		updated, err := ts.UpdateAccount(ctx, account.ID, authinterface.AccountUpdates{Phone: &empty})
+		if err != nil {
+			t.Fatalf("UpdateAccount: %v", err)
+		}
+		if updated.GetPhone() != "" {
+			t.Fatalf("phone = %q, want it removed", updated.GetPhone())
+		}
+	})
+
+	t.Run("unchanged phone stays verified", func(t *testing.T) {
+		ts := newPhoneTestService(t)
+		account := ts.createAccount(t, "user@example.com")
+		account.Phone = "+15550006666"
+		account.PhoneVerified = true
+		ts.accounts.Update(ctx, account)
+
+		phone := "+15550006666"
+		updated, err := ts.UpdateAccount(ctx, account.ID, authinterface.AccountUpdates{Phone: &phone})
+		if err != nil {
+			t.Fatalf("UpdateAccount: %v", err)
+		}
+		if !updated.GetPhoneVerified() {
+			t.Fatal("resubmitting the same phone reset its verification")
+		}
+	})
+
+	t.Run("new phone must be verified", func(t *testing.T) {
+		ts := newPhoneTestService(t)
+		account := ts.createAccount(t, "user@example.com")
+		account.Phone = "+15550006666"
+		account.PhoneVerified = true
+		ts.accounts.Update(ctx, account)
+
+		phone := "+15550007777"
+		updated, err := ts.UpdateAccount(ctx, account.ID, authinterface.AccountUpdates{Phone: &phone})
+		if err != nil {
+			t.Fatalf("UpdateAccount: %v", err)
+		}
+		if updated.GetPhone() != phone || updated.GetPhoneVerified() {
+			t.Fatalf("phone = %q verified %v, want the new phone unverified", updated.GetPhone(), updated.GetPhoneVerified())
+		}
+
+		result, err := ts.completeLogin(ctx, updated)
+		if err != nil {
+			t.Fatalf("completeLogin: %v", err)
+		}
+		if result.PhoneChallenge == nil {
+			t.Fatal("login with an unverified new phone did not ask to verify it")
+		}
+	})
+}
+
+// sameCodeGenerator sends every account the same phone code.
+type sameCodeGenerator struct {
+	authinterface.TokenGenerator
+}
+
+func (sameCodeGenerator) GenerateToken() string {
+	return "123456"
+}
+
+func TestPhoneCodesAreStoredHashed(t *testing.T) {
+	ts := newTestService(t, AuthServiceDeps{})
+	ts.config.phoneVerificationResend = 0
+	ts.tokenGenerator = sameCodeGenerator{ts.tokenGenerator}
+	ctx := context.Background()
+
+	var accounts []*authmodel.Account
+	for i, phone := range []string{"+15550008881", "+15550008882"} {
+		account := ts.createAccount(t, fmt.Sprintf("user%d@example.com", i))
+		account.Phone = phone
+		ts.accounts.Update(ctx, account)
+		accounts = append(accounts, account)
+
+		for range 2 {
+			if err := ts.SendPhoneVerification(ctx, account.ID); err != nil {
+				t.Fatalf("SendPhoneVerification: %v", err)
+			}
+		}
+	}
+
+	tokens, _ := ts.tokens.GetByAccountAndType(ctx, accounts[0].ID, authinterface.TokenTypePhoneVerification)
+	for _, token := range tokens {
+		if token.GetToken() == "123456" {
+			t.Fatal("the code was stored as sent")
+		}
+	}
+
+	for _, account := range accounts {
+		if err := ts.VerifyPhone(ctx, account.ID, "123456"); err != nil {
+			t.Fatalf("VerifyPhone: %v", err)
+		}
+	}
+}
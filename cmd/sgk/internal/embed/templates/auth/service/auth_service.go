@@ -2,6 +2,7 @@ package authservice
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -20,8 +21,49 @@ type AuthService struct {
 	passwordHasher   authinterface.PasswordHasher
 	tokenGenerator   authinterface.TokenGenerator
 	emailSender      authinterface.EmailSender
+	smsSender        authinterface.SMSSender
 	config           authinterface.AuthConfig
 	strategyRegistry authinterface.StrategyRegistry
+	recoveryCodeRepo authinterface.RecoveryCodeRepository
+	totpProvider     authinterface.TOTPProvider
+	roleLookup       core.RoleLookup
+	eventHook        authinterface.AuthEventHook
+	keyProvider      authinterface.KeyProvider
+	revocationList   authinterface.RevocationList
+	identityRepo     authinterface.IdentityRepository
+	oauthStateStore  authinterface.OAuthStateStore
+	credentialRepo   authinterface.WebAuthnCredentialRepository
+	webAuthnSessions authinterface.WebAuthnSessionStore
+	loginAttempts    authinterface.LoginAttemptTracker
+	passwordPolicy   authinterface.PasswordPolicy
+	apiKeyRepo       authinterface.APIKeyRepository
+	loginHistoryRepo authinterface.LoginHistoryRepository
+	userData         *core.UserDataRegistry
+}
+
+// AuthServiceDeps holds the collaborators behind individual auth features.
+// A feature's dependencies must be set for it to work; RoleLookup,
+// EventHook, LoginAttempts and UserData may be left nil to turn off role
+// claims, event delivery, login throttling and user data export.
+type AuthServiceDeps struct {
+	EmailSender      authinterface.EmailSender
+	SMSSender        authinterface.SMSSender
+	StrategyRegistry authinterface.StrategyRegistry
+	RecoveryCodeRepo authinterface.RecoveryCodeRepository
+	TOTPProvider     authinterface.TOTPProvider
+	RoleLookup       core.RoleLookup
+	EventHook        authinterface.AuthEventHook
+	KeyProvider      authinterface.KeyProvider
+	RevocationList   authinterface.RevocationList
+	IdentityRepo     authinterface.IdentityRepository
+	OAuthStateStore  authinterface.OAuthStateStore
+	CredentialRepo   authinterface.WebAuthnCredentialRepository
+	WebAuthnSessions authinterface.WebAuthnSessionStore
+	LoginAttempts    authinterface.LoginAttemptTracker
+	PasswordPolicy   authinterface.PasswordPolicy
+	APIKeyRepo       authinterface.APIKeyRepository
+	LoginHistoryRepo authinterface.LoginHistoryRepository
+	UserData         *core.UserDataRegistry
 }
 
 func NewAuthService(
@@ -30,19 +72,34 @@ func NewAuthService(
 	sessionStore authinterface.SessionStore,
 	passwordHasher authinterface.PasswordHasher,
 	tokenGenerator authinterface.TokenGenerator,
-	emailSender authinterface.EmailSender,
 	config authinterface.AuthConfig,
-	strategyRegistry authinterface.StrategyRegistry,
+	deps AuthServiceDeps,
 ) *AuthService {
 	return &AuthService{
-		accountRepo:    accountRepo,
-		tokenRepo:      tokenRepo,
-		sessionStore:   sessionStore,
-		passwordHasher: passwordHasher,
+		accountRepo:      accountRepo,
+		tokenRepo:        tokenRepo,
+		sessionStore:     sessionStore,
+		passwordHasher:   passwordHasher,
 		tokenGenerator:   tokenGenerator,
-		emailSender:      emailSender,
+		emailSender:      deps.EmailSender,
+		smsSender:        deps.SMSSender,
 		config:           config,
-		strategyRegistry: strategyRegistry,
+		strategyRegistry: deps.StrategyRegistry,
+		recoveryCodeRepo: deps.RecoveryCodeRepo,
+		totpProvider:     deps.TOTPProvider,
+		roleLookup:       deps.RoleLookup,
+		eventHook:        deps.EventHook,
+		keyProvider:      deps.KeyProvider,
+		revocationList:   deps.RevocationList,
+		identityRepo:     deps.IdentityRepo,
+		oauthStateStore:  deps.OAuthStateStore,
+		credentialRepo:   deps.CredentialRepo,
+		webAuthnSessions: deps.WebAuthnSessions,
+		loginAttempts:    deps.LoginAttempts,
+		passwordPolicy:   deps.PasswordPolicy,
+		apiKeyRepo:       deps.APIKeyRepo,
+		loginHistoryRepo: deps.LoginHistoryRepo,
+		userData:         deps.UserData,
 	}
 }
 
@@ -59,6 +116,17 @@ func (s *AuthService) Register(ctx context.Context, req authinterface.RegisterRe
 		return nil, fmt.Errorf(authconstants.ErrAccountAlreadyExists)
 	}
 
+	if s.config.IsPhoneVerificationRequired() && req.GetPhone() == "" {
+		return nil, core.NewAppError(core.ErrCodeValidation, authconstants.ErrPhoneRequired)
+	}
+
+	if err := s.checkPasswordPolicy(ctx, authinterface.PasswordCandidate{
+		Password:   req.GetPassword(),
+		UserInputs: passwordUserInputs(req.GetEmail(), req.GetPhone()),
+	}); err != nil {
+		return nil, err
+	}
+
 	passwordHash, err := s.passwordHasher.Hash(req.GetPassword())
 	if err != nil {
 		return nil, fmt.Errorf("failed to hash password: %w", err)
@@ -74,6 +142,7 @@ func (s *AuthService) Register(ctx context.Context, req authinterface.RegisterRe
 	if err := s.accountRepo.Create(ctx, account); err != nil {
 		return nil, fmt.Errorf("failed to create account: %w", err)
 	}
+	s.rememberPassword(ctx, account.ID, passwordHash)
 
 	if s.config.IsEmailVerificationRequired() {
 		if err := s.SendEmailVerification(ctx, account.ID); err != nil {
@@ -81,14 +150,26 @@ func (s *AuthService) Register(ctx context.Context, req authinterface.RegisterRe
 		}
 	}
 
+	if s.config.IsPhoneVerificationRequired() {
+		if err := s.SendPhoneVerification(ctx, account.ID); err != nil {
+			fmt.Printf("Failed to send phone verification: %v\n", err)
+		}
+	}
+
 	if err := s.emailSender.SendWelcomeEmail(account.Email); err != nil {
 		fmt.Printf("Failed to send welcome email: %v\n", err)
 	}
 
+	s.emitEvent(ctx, authinterface.AuthEvent{
+		Type:      authinterface.AuthEventRegistered,
+		AccountID: account.ID,
+		Metadata:  map[string]any{"method": "email_password"},
+	})
+
 	return account, nil
 }
 
-func (s *AuthService) Login(ctx context.Context, req authinterface.LoginRequest) (authinterface.Session, error) {
+func (s *AuthService) Login(ctx context.Context, req authinterface.LoginRequest) (*authinterface.LoginResult, error) {
 	if err := req.Validate(); err != nil {
 		return nil, err
 	}
@@ -101,32 +182,90 @@ func (s *AuthService) Login(ctx context.Context, req authinterface.LoginRequest)
 		}
 	}
 
+	ctx = withLoginMethod(ctx, strategy.Name())
+
 	credentials := req.GetCredentials()
 	if err := strategy.ValidateCredentials(credentials); err != nil {
 		return nil, core.NewAppError(core.ErrCodeBadRequest, err.Error())
 	}
 
+	// OAuth codes are only redeemed together with the state they were
+	// issued for, so they take the same path as the callback. Linking needs
+	// the callback, which checks the browser that started it.
+	if _, ok := strategy.(authinterface.OAuthStrategy); ok {
+		code, _ := credentials["code"].(string)
+		state, _ := credentials["state"].(string)
+		return s.redeemOAuthCode(ctx, strategy.Name(), code, state, false)
+	}
+
+	// Only password guesses are throttled; the other strategies redeem
+	// single-use secrets that cannot be brute-forced.
+	email, _ := credentials["email"].(string)
+	throttled := strategy.Type() == authinterface.StrategyTypeLocal && email != ""
+	if throttled {
+		if err := s.checkLoginAllowed(ctx, email); err != nil {
+			return nil, err
+		}
+	}
+
 	result, err := strategy.Authenticate(ctx, credentials)
 	if err != nil {
+		var appErr *core.AppError
+		if errors.As(err, &appErr) && appErr.Code == core.ErrCodeUnauthorized {
+			if throttled {
+				s.recordFailedLogin(ctx, email)
+			}
+			accountID := uuid.Nil
+			if email != "" {
+				if account, lookupErr := s.accountRepo.GetByEmail(ctx, email); lookupErr == nil {
+					accountID = account.GetID()
+				}
+			}
+			s.emitLoginFailed(ctx, accountID, appErr.Message, map[string]any{"email": email})
+		}
 		return nil, err
 	}
 
+	if throttled {
+		s.clearFailedLogins(ctx, email)
+	}
+
+	if password, ok := credentials["password"].(string); ok && strategy.Type() == authinterface.StrategyTypeLocal {
+		s.rehashPassword(ctx, result.Account, password)
+	}
+
 	if s.config.IsEmailVerificationRequired() && result.NeedsVerification {
 		return nil, core.NewAppError(core.ErrCodeForbidden, authconstants.ErrEmailNotVerified)
 	}
 
-	session := s.createSession(result.AccountID)
+	return s.completeLogin(ctx, result.Account)
+}
 
-	if err := s.sessionStore.Store(ctx, session); err != nil {
-		return nil, core.NewAppError(core.ErrCodeInternalServer, "failed to create session")
+// rehashPassword replaces an outdated password hash after the password was
+// verified. Failures are only logged since the login itself succeeded.
+func (s *AuthService) rehashPassword(ctx context.Context, account authinterface.Account, password string) {
+	if !s.passwordHasher.NeedsRehash(account.GetPasswordHash()) {
+		return
 	}
 
-	return session, nil
+	passwordHash, err := s.passwordHasher.Hash(password)
+	if err != nil {
+		fmt.Printf("Failed to rehash password: %v\n", err)
+		return
+	}
+
+	account.SetPasswordHash(passwordHash)
+	if err := s.accountRepo.Update(ctx, account); err != nil {
+		fmt.Printf("Failed to update password hash: %v\n", err)
+	}
 }
 
 func (s *AuthService) RefreshSession(ctx context.Context, refreshToken string) (authinterface.Session, error) {
 	oldSession, err := s.sessionStore.GetByRefreshToken(ctx, refreshToken)
 	if err != nil {
+		if familyID, err := s.sessionStore.GetFamilyByRotatedToken(ctx, refreshToken); err == nil {
+			s.revokeTokenFamily(ctx, familyID)
+		}
 		return nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrInvalidToken)
 	}
 
@@ -134,21 +273,49 @@ func (s *AuthService) RefreshSession(ctx context.Context, refreshToken string) (
 		return nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrTokenExpired)
 	}
 
-	if err := s.sessionStore.DeleteByToken(ctx, oldSession.GetToken()); err != nil {
-		fmt.Printf("Failed to delete old session: %v\n", err)
+	account, err := s.accountRepo.GetByID(ctx, oldSession.GetUserID())
+	if err != nil {
+		return nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrAccountNotFound)
 	}
 
-	newSession := s.createSession(oldSession.GetUserID())
+	newSession, err := s.createSession(ctx, account, oldSession.GetID(), oldSession.GetImpersonatorID())
+	if err != nil {
+		return nil, core.NewAppError(core.ErrCodeInternalServer, "failed to create session")
+	}
+	newSession.CreatedAt = oldSession.GetCreatedAt()
+	if newSession.ImpersonatorID != nil {
+		// Refreshing never extends an impersonation past its original end.
+		newSession.RefreshExpiresAt = oldSession.GetRefreshExpiresAt()
+	}
 
-	if err := s.sessionStore.Store(ctx, newSession); err != nil {
+	if err := s.sessionStore.Rotate(ctx, refreshToken, newSession); err != nil {
+		if errors.Is(err, authinterface.ErrRefreshTokenReused) {
+			s.revokeTokenFamily(ctx, oldSession.GetID())
+			return nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrInvalidToken)
+		}
 		return nil, core.NewAppError(core.ErrCodeInternalServer, "failed to create session")
 	}
 
 	return newSession, nil
 }
 
-func (s *AuthService) Logout(ctx context.Context, userID uuid.UUID) error {
-	return s.sessionStore.Delete(ctx, userID)
+func (s *AuthService) Logout(ctx context.Context, token string) error {
+	session, err := s.sessionStore.Get(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	if err := s.endSession(ctx, session); err != nil {
+		return err
+	}
+
+	s.emitEvent(ctx, authinterface.AuthEvent{
+		Type:      authinterface.AuthEventLoggedOut,
+		AccountID: session.GetUserID(),
+		SessionID: session.GetID(),
+	})
+
+	return nil
 }
 
 func (s *AuthService) SendEmailVerification(ctx context.Context, accountID uuid.UUID) error {
@@ -208,72 +375,11 @@ func (s *AuthService) VerifyEmail(ctx context.Context, tokenStr string) error {
 		fmt.Printf("Failed to mark token as used: %v\n", err)
 	}
 
-	return nil
-}
-
-// TODO: Implement phone verification
-// func (s *AuthService) SendPhoneVerification(ctx context.Context, accountID uuid.UUID) error {
-// account, err := s.accountRepo.GetByID(ctx, accountID)
-// if err != nil {
-// 	return core.NewAppError(core.ErrCodeNotFound, authconstants.ErrAccountNotFound)
-// }
-//
-// if account.GetPhoneVerified() {
-// 	return nil
-// }
-//
-// if account.GetPhone() == "" {
-// 	return fmt.Errorf("no phone number associated with account")
-// }
-//
-// code := s.tokenGenerator.GenerateToken()[:6]
-//
-// token := &authmodel.Token{
-// 	ID:        uuid.New(),
-// 	AccountID: accountID,
-// 	Token:     code,
-// 	Type:      authinterface.TokenTypePhoneVerification,
-// 	ExpiresAt: time.Now().Add(10 * time.Minute),
-// }
-//
-// if err := s.tokenRepo.Create(ctx, token); err != nil {
-// 	return core.NewAppError(core.ErrCodeInternalServer, "failed to create verification token")
-// }
-//
-// 	// return s.smsSender.SendVerificationSMS(account.GetPhone(), code)
-// }
-
-func (s *AuthService) VerifyPhone(ctx context.Context, accountID uuid.UUID, code string) error {
-	tokens, err := s.tokenRepo.GetByAccountAndType(ctx, accountID, authinterface.TokenTypePhoneVerification)
-	if err != nil {
-		return core.NewAppError(core.ErrCodeInternalServer, "failed to get verification tokens")
-	}
-
-	var validToken authinterface.Token
-	for _, token := range tokens {
-		if token.GetToken() == code && !token.GetUsed() && !token.IsExpired() {
-			validToken = token
-			break
-		}
-	}
-
-	if validToken == nil {
-		return fmt.Errorf(authconstants.ErrInvalidToken)
-	}
-
-	account, err := s.accountRepo.GetByID(ctx, accountID)
-	if err != nil {
-		return core.NewAppError(core.ErrCodeNotFound, authconstants.ErrAccountNotFound)
-	}
-
-	account.SetPhoneVerified(true)
-	if err := s.accountRepo.Update(ctx, account); err != nil {
-		return core.NewAppError(core.ErrCodeInternalServer, "failed to update account")
-	}
-
-	if err := s.tokenRepo.MarkAsUsed(ctx, validToken.GetID()); err != nil {
-		fmt.Printf("Failed to mark token as used: %v\n", err)
-	}
+	s.emitEvent(ctx, authinterface.AuthEvent{
+		Type:      authinterface.AuthEventEmailVerified,
+		AccountID: account.GetID(),
+		Metadata:  map[string]any{"email": account.GetEmail()},
+	})
 
 	return nil
 }
@@ -300,10 +406,6 @@ func (s *AuthService) SendPasswordReset(ctx context.Context, email string) error
 }
 
 func (s *AuthService) ResetPassword(ctx context.Context, tokenStr, newPassword string) error {
-	if len(newPassword) < 8 {
-		return fmt.Errorf(authconstants.ErrPasswordTooWeak)
-	}
-
 	token, err := s.tokenRepo.GetByToken(ctx, tokenStr)
 	if err != nil {
 		return fmt.Errorf(authconstants.ErrInvalidToken)
@@ -326,6 +428,10 @@ func (s *AuthService) ResetPassword(ctx context.Context, tokenStr, newPassword s
 		return core.NewAppError(core.ErrCodeNotFound, authconstants.ErrAccountNotFound)
 	}
 
+	if err := s.checkPasswordPolicy(ctx, s.passwordCandidate(account, newPassword)); err != nil {
+		return err
+	}
+
 	passwordHash, err := s.passwordHasher.Hash(newPassword)
 	if err != nil {
 		return core.NewAppError(core.ErrCodeInternalServer, "failed to hash password")
@@ -335,23 +441,27 @@ func (s *AuthService) ResetPassword(ctx context.Context, tokenStr, newPassword s
 	if err := s.accountRepo.Update(ctx, account); err != nil {
 		return core.NewAppError(core.ErrCodeInternalServer, "failed to update account")
 	}
+	s.rememberPassword(ctx, account.GetID(), passwordHash)
 
 	if err := s.tokenRepo.MarkAsUsed(ctx, token.GetID()); err != nil {
 		fmt.Printf("Failed to mark token as used: %v\n", err)
 	}
 
-	if err := s.sessionStore.Delete(ctx, account.GetID()); err != nil {
+	if err := s.endAllSessions(ctx, account.GetID()); err != nil {
 		fmt.Printf("Failed to delete sessions: %v\n", err)
 	}
 
+	s.clearFailedLogins(ctx, account.GetEmail())
+
+	s.emitEvent(ctx, authinterface.AuthEvent{
+		Type:      authinterface.AuthEventPasswordReset,
+		AccountID: account.GetID(),
+	})
+
 	return nil
 }
 
 func (s *AuthService) ChangePassword(ctx context.Context, accountID uuid.UUID, oldPassword, newPassword string) error {
-	if len(newPassword) < 8 {
-		return fmt.Errorf(authconstants.ErrPasswordTooWeak)
-	}
-
 	account, err := s.accountRepo.GetByID(ctx, accountID)
 	if err != nil {
 		return core.NewAppError(core.ErrCodeNotFound, authconstants.ErrAccountNotFound)
@@ -361,6 +471,10 @@ func (s *AuthService) ChangePassword(ctx context.Context, accountID uuid.UUID, o
 		return core.NewAppError(core.ErrCodeBadRequest, authconstants.ErrInvalidPassword)
 	}
 
+	if err := s.checkPasswordPolicy(ctx, s.passwordCandidate(account, newPassword)); err != nil {
+		return err
+	}
+
 	passwordHash, err := s.passwordHasher.Hash(newPassword)
 	if err != nil {
 		return core.NewAppError(core.ErrCodeInternalServer, "failed to hash password")
@@ -370,11 +484,17 @@ func (s *AuthService) ChangePassword(ctx context.Context, accountID uuid.UUID, o
 	if err := s.accountRepo.Update(ctx, account); err != nil {
 		return core.NewAppError(core.ErrCodeInternalServer, "failed to update account")
 	}
+	s.rememberPassword(ctx, accountID, passwordHash)
 
-	if err := s.sessionStore.Delete(ctx, accountID); err != nil {
+	if err := s.endAllSessions(ctx, accountID); err != nil {
 		fmt.Printf("Failed to delete sessions: %v\n", err)
 	}
 
+	s.emitEvent(ctx, authinterface.AuthEvent{
+		Type:      authinterface.AuthEventPasswordChanged,
+		AccountID: accountID,
+	})
+
 	return nil
 }
 
@@ -400,35 +520,9 @@ func (s *AuthService) UpdateAccount(ctx context.Context, accountID uuid.UUID, up
 		return nil, core.NewAppError(core.ErrCodeNotFound, authconstants.ErrAccountNotFound)
 	}
 
-	if updates.Email != nil {
-		if *updates.Email != account.GetEmail() {
-			exists, err := s.accountRepo.ExistsByEmail(ctx, *updates.Email)
-			if err != nil {
-				return nil, core.NewAppError(core.ErrCodeInternalServer, "failed to check email existence")
-			}
-			if exists {
-				return nil, fmt.Errorf("email already in use")
-			}
-		}
-		if acc, ok := account.(*authmodel.Account); ok {
-			acc.Email = *updates.Email
-			acc.EmailVerified = false
-		}
-	}
-
-	if updates.Phone != nil {
-		if *updates.Phone != account.GetPhone() && *updates.Phone != "" {
-			exists, err := s.accountRepo.ExistsByPhone(ctx, *updates.Phone)
-			if err != nil {
-				return nil, core.NewAppError(core.ErrCodeInternalServer, "failed to check phone existence")
-			}
-			if exists {
-				return nil, fmt.Errorf("phone already in use")
-			}
-		}
-		if acc, ok := account.(*authmodel.Account); ok {
-			acc.Phone = *updates.Phone
-			acc.PhoneVerified = false
+	if updates.Phone != nil && *updates.Phone != account.GetPhone() {
+		if err := s.setPhone(ctx, account, *updates.Phone); err != nil {
+			return nil, err
 		}
 	}
 
@@ -448,120 +542,129 @@ func (s *AuthService) UpdateAccount(ctx context.Context, accountID uuid.UUID, up
 }
 
 func (s *AuthService) ValidateSession(ctx context.Context, token string) (authinterface.Account, error) {
-	jwtToken, err := jwt.Parse(token, func(token *jwt.Token) (any, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(s.config.GetJWTSecret()), nil
-	})
-
-	if err != nil || !jwtToken.Valid {
-		return nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrInvalidToken)
-	}
+	account, _, err := s.AuthenticateToken(ctx, token)
+	return account, err
+}
 
-	claims, ok := jwtToken.Claims.(jwt.MapClaims)
-	if !ok {
-		return nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrInvalidToken)
+func (s *AuthService) AuthenticateToken(ctx context.Context, token string) (authinterface.Account, authinterface.Session, error) {
+	claims, err := s.parseClaims(token)
+	if err != nil {
+		return nil, nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrInvalidToken)
 	}
 
-	userIDStr, ok := claims["user_id"].(string)
-	if !ok {
-		return nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrInvalidToken)
+	userID, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return nil, nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrInvalidToken)
 	}
 
-	userID, err := uuid.Parse(userIDStr)
-	if err != nil {
-		return nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrInvalidToken)
+	if s.config.IsStatelessValidation() {
+		return s.authenticateStateless(ctx, token, userID, claims)
 	}
 
 	session, err := s.sessionStore.Get(ctx, token)
 	if err != nil {
-		return nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrSessionExpired)
+		return nil, nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrSessionExpired)
 	}
 
 	if session.IsExpired() {
-		return nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrSessionExpired)
+		return nil, nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrSessionExpired)
 	}
 
-	return s.GetAccount(ctx, userID)
-}
-
-func (s *AuthService) GetOAuthURL(ctx context.Context, provider string, state string) (string, error) {
-	strategy, err := s.strategyRegistry.Get(provider)
-	if err != nil {
-		return "", core.NewAppError(core.ErrCodeBadRequest, fmt.Sprintf("provider %s not supported", provider))
-	}
-	
-	oauthStrategy, ok := strategy.(authinterface.OAuthStrategy)
-	if !ok {
-		return "", core.NewAppError(core.ErrCodeBadRequest, fmt.Sprintf("provider %s is not an OAuth provider", provider))
+	if time.Since(session.GetLastSeenAt()) > sessionTouchInterval {
+		if err := s.sessionStore.Touch(ctx, session.GetID(), time.Now()); err != nil {
+			fmt.Printf("Failed to update session activity: %v\n", err)
+		}
 	}
-	
-	return oauthStrategy.GetAuthURL(state), nil
-}
 
-func (s *AuthService) HandleOAuthCallback(ctx context.Context, provider string, code string, state string) (authinterface.Session, error) {
-	strategy, err := s.strategyRegistry.Get(provider)
+	account, err := s.GetAccount(ctx, userID)
 	if err != nil {
-		return nil, core.NewAppError(core.ErrCodeBadRequest, fmt.Sprintf("provider %s not supported", provider))
+		return nil, nil, err
 	}
-	
-	credentials := map[string]any{
-		"code":  code,
-		"state": state,
-	}
-	
-	result, err := strategy.Authenticate(ctx, credentials)
-	if err != nil {
-		return nil, err
-	}
-	
-	session := s.createSession(result.AccountID)
-	
-	if err := s.sessionStore.Store(ctx, session); err != nil {
-		return nil, core.NewAppError(core.ErrCodeInternalServer, "failed to create session")
-	}
-	
-	if s.emailSender != nil && result.Metadata != nil {
-		if _, isNew := result.Metadata["is_new_account"]; isNew {
-			s.emailSender.SendWelcomeEmail(result.Account.GetEmail())
-		}
-	}
-	
-	return session, nil
+
+	return account, session, nil
 }
 
 func (s *AuthService) GetAvailableProviders(ctx context.Context) []string {
 	providers := []string{"email_password"}
-	
+
 	for _, strategy := range s.strategyRegistry.List() {
 		strat, _ := s.strategyRegistry.Get(strategy)
 		if strat != nil && strat.Type() == authinterface.StrategyTypeOAuth {
 			providers = append(providers, strategy)
 		}
 	}
-	
+
 	return providers
 }
 
-func (s *AuthService) createSession(userID uuid.UUID) authinterface.Session {
+// createSession issues a session for account. A non-nil impersonatorID makes
+// it an impersonation session, which ends after the impersonation duration.
+func (s *AuthService) createSession(ctx context.Context, account authinterface.Account, sessionID, impersonatorID uuid.UUID) (*authmodel.Session, error) {
 	now := time.Now()
+	client := authinterface.ClientInfoFromContext(ctx)
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": userID.String(),
-		"exp":     now.Add(s.config.GetJWTExpiration()).Unix(),
-		"iat":     now.Unix(),
-	})
+	claims, err := s.buildClaims(ctx, account, sessionID, impersonatorID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	key := s.keyProvider.SigningKey()
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(key.Algorithm), claims)
+	if key.ID != "" {
+		token.Header["kid"] = key.ID
+	}
 
-	tokenString, _ := token.SignedString([]byte(s.config.GetJWTSecret()))
+	tokenString, err := token.SignedString(key.Private)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign token: %w", err)
+	}
 
 	refreshToken := s.tokenGenerator.GenerateSecureToken()
 
-	return &authmodel.Session{
-		UserID:           userID,
+	session := &authmodel.Session{
+		ID:               sessionID,
+		UserID:           account.GetID(),
 		Token:            tokenString,
 		RefreshToken:     refreshToken,
 		ExpiresAt:        now.Add(s.config.GetJWTExpiration()),
 		RefreshExpiresAt: now.Add(s.config.GetRefreshTokenExpiration()),
+		UserAgent:        client.UserAgent,
+		IPAddress:        client.IPAddress,
+		CreatedAt:        now,
+		LastSeenAt:       now,
+	}
+
+	if impersonatorID != uuid.Nil {
+		session.ImpersonatorID = &impersonatorID
+		session.RefreshExpiresAt = now.Add(s.config.GetImpersonationDuration())
+	}
+
+	return session, nil
+}
+
+// verificationKey resolves the key a token was signed with from its kid
+// header and rejects tokens whose alg doesn't match that key.
+func (s *AuthService) verificationKey(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	key, err := s.keyProvider.VerificationKey(kid)
+	if err != nil {
+		return nil, err
+	}
+
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	return key.Public, nil
+}
+
+func (s *AuthService) GetJWKS(ctx context.Context) authinterface.JSONWebKeySet {
+	keySet := authinterface.JSONWebKeySet{Keys: []authinterface.JSONWebKey{}}
+	for _, key := range s.keyProvider.PublicKeys() {
+		if jwk, ok := toJSONWebKey(key); ok {
+			keySet.Keys = append(keySet.Keys, jwk)
+		}
 	}
+	return keySet
 }
@@ -0,0 +1,111 @@
+package authservice
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	authconstants "{{.Project.GoModule}}/internal/auth/constants"
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	"{{.Project.GoModule}}/internal/core"
+)
+
+// sessionTouchInterval limits how often a session's last seen time is
+// written back, so authenticated requests don't all hit the store.
+const sessionTouchInterval = time.Minute
+
+func (s *AuthService) LogoutAll(ctx context.Context, userID uuid.UUID) error {
+	if err := s.endAllSessions(ctx, userID); err != nil {
+		return core.NewAppError(core.ErrCodeInternalServer, "failed to delete sessions")
+	}
+
+	s.emitEvent(ctx, authinterface.AuthEvent{
+		Type:      authinterface.AuthEventLoggedOut,
+		AccountID: userID,
+		Metadata:  map[string]any{"all_sessions": true},
+	})
+
+	return nil
+}
+
+func (s *AuthService) ListSessions(ctx context.Context, userID uuid.UUID) ([]authinterface.Session, error) {
+	sessions, err := s.sessionStore.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, core.NewAppError(core.ErrCodeInternalServer, "failed to list sessions")
+	}
+
+	active := make([]authinterface.Session, 0, len(sessions))
+	for _, session := range sessions {
+		if !session.IsRefreshExpired() {
+			active = append(active, session)
+		}
+	}
+
+	return active, nil
+}
+
+func (s *AuthService) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	session, err := s.sessionStore.GetByID(ctx, sessionID)
+	if err != nil || session.GetUserID() != userID {
+		return core.NewAppError(core.ErrCodeNotFound, authconstants.ErrSessionNotFound)
+	}
+
+	if err := s.endSession(ctx, session); err != nil {
+		return core.NewAppError(core.ErrCodeInternalServer, "failed to revoke session")
+	}
+
+	return nil
+}
+
+// revokeTokenFamily ends the session a reused refresh token belongs to. A
+// rotated token showing up again means it leaked, so every token derived
+// from it is revoked and the event is reported.
+func (s *AuthService) revokeTokenFamily(ctx context.Context, familyID uuid.UUID) {
+	var accountID uuid.UUID
+	if session, err := s.sessionStore.GetByID(ctx, familyID); err == nil {
+		accountID = session.GetUserID()
+		if err := s.endSession(ctx, session); err != nil {
+			fmt.Printf("Failed to revoke token family: %v\n", err)
+		}
+	}
+
+	s.emitEvent(ctx, authinterface.AuthEvent{
+		Type:      authinterface.AuthEventRefreshTokenReused,
+		AccountID: accountID,
+		SessionID: familyID,
+	})
+}
+
+// endSession deletes a session and adds it to the revocation list so its
+// access tokens are rejected in stateless mode as well.
+func (s *AuthService) endSession(ctx context.Context, session authinterface.Session) error {
+	if err := s.sessionStore.DeleteByID(ctx, session.GetID()); err != nil {
+		return err
+	}
+	s.revokeSessionTokens(ctx, session)
+	return nil
+}
+
+func (s *AuthService) endAllSessions(ctx context.Context, userID uuid.UUID) error {
+	sessions, err := s.sessionStore.ListByUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.sessionStore.Delete(ctx, userID); err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		s.revokeSessionTokens(ctx, session)
+	}
+	return nil
+}
+
+func (s *AuthService) revokeSessionTokens(ctx context.Context, session authinterface.Session) {
+	if err := s.revocationList.Revoke(ctx, session.GetID(), session.GetExpiresAt()); err != nil {
+		fmt.Printf("Failed to revoke session tokens: %v\n", err)
+	}
+}
+
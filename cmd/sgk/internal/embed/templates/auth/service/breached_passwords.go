@@ -0,0 +1,185 @@
+package authservice
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+)
+
+var bloomFilterMagic = []byte("SGKBLOOM")
+
+// BloomFilter is a compact set of breached passwords. Entries are the upper
+// case hex SHA-1 of a password, the format used by the Have I Been Pwned
+// downloads, so those lists can be loaded as they are. Lookups have a small
+// false positive rate and no false negatives.
+type BloomFilter struct {
+	bits   []byte
+	size   uint64
+	hashes uint32
+}
+
+// NewBloomFilter sizes a filter for expected entries at falsePositiveRate.
+func NewBloomFilter(expected int, falsePositiveRate float64) *BloomFilter {
+	if expected < 1 {
+		expected = 1
+	}
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		falsePositiveRate = 0.001
+	}
+
+	// Small lists get a minimum size, which keeps their false positive
+	// rate below the target instead of at it.
+	size := uint64(math.Ceil(-float64(expected) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	if size < 1024 {
+		size = 1024
+	}
+	size = (size + 7) / 8 * 8
+	hashes := uint32(math.Ceil(-math.Log2(falsePositiveRate)))
+
+	return &BloomFilter{
+		bits:   make([]byte, size/8),
+		size:   size,
+		hashes: hashes,
+	}
+}
+
+// AddPassword adds a plain text password.
+func (f *BloomFilter) AddPassword(password string) {
+	f.addEntry(sha1Hex(password))
+}
+
+// AddHash adds a hex SHA-1 password hash.
+func (f *BloomFilter) AddHash(hash string) {
+	f.addEntry(strings.ToUpper(hash))
+}
+
+func (f *BloomFilter) IsBreached(password string) bool {
+	h1, h2 := f.locations(sha1Hex(password))
+	for i := uint64(0); i < uint64(f.hashes); i++ {
+		bit := (h1 + i*h2) % f.size
+		if f.bits[bit/8]&(1<<(bit%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// WriteTo stores the filter in the format read by LoadBreachedPasswords.
+func (f *BloomFilter) WriteTo(w io.Writer) (int64, error) {
+	header := make([]byte, len(bloomFilterMagic)+12)
+	copy(header, bloomFilterMagic)
+	binary.BigEndian.PutUint32(header[len(bloomFilterMagic):], f.hashes)
+	binary.BigEndian.PutUint64(header[len(bloomFilterMagic)+4:], f.size)
+
+	n, err := w.Write(header)
+	if err != nil {
+		return int64(n), err
+	}
+	m, err := w.Write(f.bits)
+	return int64(n + m), err
+}
+
+func (f *BloomFilter) addEntry(entry string) {
+	h1, h2 := f.locations(entry)
+	for i := uint64(0); i < uint64(f.hashes); i++ {
+		bit := (h1 + i*h2) % f.size
+		f.bits[bit/8] |= 1 << (bit % 8)
+	}
+}
+
+// locations derives the two base hashes for double hashing. The second one
+// is forced odd so the probe sequence never collapses to a single bit.
+func (f *BloomFilter) locations(entry string) (uint64, uint64) {
+	sum := sha256.Sum256([]byte(entry))
+	return binary.BigEndian.Uint64(sum[:8]), binary.BigEndian.Uint64(sum[8:16]) | 1
+}
+
+// LoadBreachedPasswords reads a breached password list. Files written by
+// BloomFilter.WriteTo are loaded directly; anything else is read as text
+// with one entry per line, either a plain password or a hex SHA-1 hash
+// optionally followed by ":count".
+func LoadBreachedPasswords(path string) (authinterface.BreachedPasswordChecker, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached password list: %w", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	magic, err := reader.Peek(len(bloomFilterMagic))
+	if err == nil && bytes.Equal(magic, bloomFilterMagic) {
+		return readBloomFilter(reader)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	// Estimate the entry count from the size of a hash line so the filter
+	// is allocated once.
+	filter := NewBloomFilter(int(info.Size()/42)+1, 0.001)
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if hash, _, _ := strings.Cut(line, ":"); isSHA1Hex(hash) {
+			filter.AddHash(hash)
+		} else {
+			filter.AddPassword(line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read breached password list: %w", err)
+	}
+
+	return filter, nil
+}
+
+func readBloomFilter(r io.Reader) (*BloomFilter, error) {
+	header := make([]byte, len(bloomFilterMagic)+12)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("invalid bloom filter header: %w", err)
+	}
+
+	filter := &BloomFilter{
+		hashes: binary.BigEndian.Uint32(header[len(bloomFilterMagic):]),
+		size:   binary.BigEndian.Uint64(header[len(bloomFilterMagic)+4:]),
+	}
+	if filter.hashes == 0 || filter.size == 0 || filter.size%8 != 0 {
+		return nil, fmt.Errorf("invalid bloom filter parameters")
+	}
+
+	filter.bits = make([]byte, filter.size/8)
+	if _, err := io.ReadFull(r, filter.bits); err != nil {
+		return nil, fmt.Errorf("truncated bloom filter: %w", err)
+	}
+
+	return filter, nil
+}
+
+func sha1Hex(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+func isSHA1Hex(value string) bool {
+	if len(value) != sha1.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(value)
+	return err == nil
+}
@@ -0,0 +1,142 @@
+package authservice
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// cborMap keeps its entries in order so encoded test data is deterministic.
+type cborMap []cborPair
+
+type cborPair struct {
+	key, value any
+}
+
+// encodeCBOR encodes the subset of CBOR the tests feed to decodeCBOR.
+func encodeCBOR(value any) []byte {
+	switch v := value.(type) {
+	case int:
+		if v < 0 {
+			return cborHead(1, uint64(-1-v))
+		}
+		return cborHead(0, uint64(v))
+	case int64:
+		return encodeCBOR(int(v))
+	case bool:
+		if v {
+			return []byte{0xf5}
+		}
+		return []byte{0xf4}
+	case nil:
+		return []byte{0xf6}
+	case []byte:
+		return append(cborHead(2, uint64(len(v))), v...)
+	case string:
+		return append(cborHead(3, uint64(len(v))), v...)
+	case []any:
+		out := cborHead(4, uint64(len(v)))
+		for _, item := range v {
+			out = append(out, encodeCBOR(item)...)
+		}
+		return out
+	case cborMap:
+		out := cborHead(5, uint64(len(v)))
+		for _, pair := range v {
+			out = append(out, encodeCBOR(pair.key)...)
+			out = append(out, encodeCBOR(pair.value)...)
+		}
+		return out
+	default:
+		panic("encodeCBOR: unsupported type")
+	}
+}
+
+func cborHead(major byte, arg uint64) []byte {
+	major <<= 5
+	switch {
+	case arg < 24:
+		return []byte{major | byte(arg)}
+	case arg <= 0xff:
+		return []byte{major | 24, byte(arg)}
+	case arg <= 0xffff:
+		return binary.BigEndian.AppendUint16([]byte{major | 25}, uint16(arg))
+	case arg <= 0xffffffff:
+		return binary.BigEndian.AppendUint32([]byte{major | 26}, uint32(arg))
+	default:
+		return binary.BigEndian.AppendUint64([]byte{major | 27}, arg)
+	}
+}
+
+func TestDecodeCBOR(t *testing.T) {
+	tests := []struct {
+		name  string
+		input any
+		want  any
+	}{
+		{"small int", 10, int64(10)},
+		{"uint8 int", 100, int64(100)},
+		{"uint16 int", 1000, int64(1000)},
+		{"uint32 int", 100000, int64(100000)},
+		{"negative int", -257, int64(-257)},
+		{"bytes", []byte{1, 2, 3}, []byte{1, 2, 3}},
+		{"text", "fido-u2f", "fido-u2f"},
+		{"bool", true, true},
+		{"null", nil, nil},
+		{"array", []any{1, "a"}, []any{int64(1), "a"}},
+		{"map", cborMap{{1, 2}, {"fmt", "none"}}, map[any]any{int64(1): int64(2), "fmt": "none"}},
+		{"nested", cborMap{{"attStmt", cborMap{}}}, map[any]any{"attStmt": map[any]any{}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := append(encodeCBOR(tt.input), 0xaa)
+
+			got, rest, err := decodeCBOR(data)
+			if err != nil {
+				t.Fatalf("decodeCBOR: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("decodeCBOR = %#v, want %#v", got, tt.want)
+			}
+			if !bytes.Equal(rest, []byte{0xaa}) {
+				t.Fatalf("rest = %x, want aa", rest)
+			}
+		})
+	}
+}
+
+func TestDecodeCBORMalformed(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []byte
+		want  string
+	}{
+		{"empty", nil, "unexpected end"},
+		{"truncated argument", []byte{0x19, 0x01}, "unexpected end"},
+		{"truncated bytes", []byte{0x45, 0x01, 0x02}, "unexpected end"},
+		{"truncated array", []byte{0x82, 0x01}, "unexpected end"},
+		{"oversized array", []byte{0x9a, 0xff, 0xff, 0xff, 0xff}, "unexpected end"},
+		{"oversized map", []byte{0xba, 0xff, 0xff, 0xff, 0xff, 0x01, 0x01}, "unexpected end"},
+		{"indefinite length", []byte{0x9f, 0x01, 0xff}, "indefinite"},
+		{"integer overflow", []byte{0x1b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, "overflow"},
+		{"duplicate key", []byte{0xa2, 0x01, 0x01, 0x01, 0x02}, "duplicate"},
+		{"byte string key", []byte{0xa1, 0x41, 0x00, 0x01}, "key type"},
+		{"simple value", []byte{0xf0}, "simple value"},
+		{"too deep", append(bytes.Repeat([]byte{0x81}, cborMaxDepth+1), 0x00), "too deep"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := decodeCBOR(tt.input)
+			if err == nil {
+				t.Fatal("decodeCBOR accepted malformed input")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("error = %q, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
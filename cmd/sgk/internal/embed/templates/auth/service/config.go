@@ -1,10 +1,10 @@
 package authservice
 
 import (
+	"net/http"
 	"time"
 	
 	authinterface "{{.Project.GoModule}}/internal/auth/interface"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type DefaultAuthConfig struct {
@@ -13,21 +13,88 @@ type DefaultAuthConfig struct {
 	refreshTokenExpiration         time.Duration
 	verificationTokenExpiration    time.Duration
 	passwordResetTokenExpiration   time.Duration
+	emailChangeRevertExpiration    time.Duration
+	accountDeletionGracePeriod     time.Duration
+	impersonationDuration          time.Duration
+	cookieSettings                 authinterface.CookieSettings
+	newDeviceAlerts                bool
+	sessionStore                   string
+	passwordHashAlgorithm          string
 	bcryptCost                     int
+	argon2Params                   authinterface.Argon2Params
+	passwordRules                  authinterface.PasswordRules
 	emailVerificationRequired      bool
 	phoneVerificationRequired      bool
+	phoneVerificationExpiration    time.Duration
+	phoneVerificationMaxAttempts   int
+	phoneVerificationResend        time.Duration
+	phoneVerificationsPerHour      int
+	mfaChallengeExpiration         time.Duration
+	mfaMaxAttempts                 int
+	mfaRequiredRoles               []string
+	jwtIssuer                      string
+	jwtAudience                    []string
+	roleClaimsEnabled              bool
+	statelessValidation            bool
+	oauthStateExpiration           time.Duration
+	magicLinkExpiration            time.Duration
+	magicLinkRequestsPerHour       int
+	magicLinkDeviceBinding         bool
+	webAuthnRPID                   string
+	webAuthnRPName                 string
+	webAuthnOrigins                []string
+	webAuthnTimeout                time.Duration
+	maxFailedLogins                int
+	maxFailedLoginsPerIP           int
+	failedLoginWindow              time.Duration
+	lockoutDuration                time.Duration
+	loginDelayBase                 time.Duration
 }
 
-func NewDefaultAuthConfig() authinterface.AuthConfig {
+func NewDefaultAuthConfig() *DefaultAuthConfig {
 	return &DefaultAuthConfig{
-		jwtSecret:                      "your-secret-key", // Should be loaded from env
+		jwtSecret:                      "your-secret-key", // Replaced by core.Config.JWTSecret in ProvideAuthConfig
 		jwtExpiration:                  15 * time.Minute,
 		refreshTokenExpiration:         7 * 24 * time.Hour,
 		verificationTokenExpiration:    24 * time.Hour,
 		passwordResetTokenExpiration:   1 * time.Hour,
-		bcryptCost:                     bcrypt.DefaultCost,
+		emailChangeRevertExpiration:    7 * 24 * time.Hour,
+		accountDeletionGracePeriod:     30 * 24 * time.Hour,
+		impersonationDuration:          time.Hour,
+		cookieSettings: authinterface.CookieSettings{
+			SameSite: http.SameSiteLaxMode,
+			Secure:   true,
+		},
+		newDeviceAlerts:                true,
+		sessionStore:                   authinterface.SessionStoreRedis,
+		passwordHashAlgorithm:          authinterface.PasswordHashArgon2id,
+		bcryptCost:                     12,
+		argon2Params:                   DefaultArgon2Params(),
+		passwordRules: authinterface.PasswordRules{
+			MinLength:          8,
+			MaxLength:          128,
+			DisallowUserInputs: true,
+		},
 		emailVerificationRequired:      true,
 		phoneVerificationRequired:      false,
+		phoneVerificationExpiration:    10 * time.Minute,
+		phoneVerificationMaxAttempts:   5,
+		phoneVerificationResend:        time.Minute,
+		phoneVerificationsPerHour:      5,
+		mfaChallengeExpiration:         5 * time.Minute,
+		mfaMaxAttempts:                 5,
+		roleClaimsEnabled:              true,
+		oauthStateExpiration:           10 * time.Minute,
+		magicLinkExpiration:            15 * time.Minute,
+		magicLinkRequestsPerHour:       5,
+		webAuthnRPID:                   "localhost",
+		webAuthnOrigins:                []string{"http://localhost:8080"},
+		webAuthnTimeout:                5 * time.Minute,
+		maxFailedLogins:                5,
+		maxFailedLoginsPerIP:           50,
+		failedLoginWindow:              15 * time.Minute,
+		lockoutDuration:                15 * time.Minute,
+		loginDelayBase:                 time.Second,
 	}
 }
 
@@ -35,6 +102,10 @@ func (c *DefaultAuthConfig) GetJWTSecret() string {
 	return c.jwtSecret
 }
 
+func (c *DefaultAuthConfig) SetJWTSecret(secret string) {
+	c.jwtSecret = secret
+}
+
 func (c *DefaultAuthConfig) GetJWTExpiration() time.Duration {
 	return c.jwtExpiration
 }
@@ -51,14 +122,250 @@ func (c *DefaultAuthConfig) GetPasswordResetTokenExpiration() time.Duration {
 	return c.passwordResetTokenExpiration
 }
 
+func (c *DefaultAuthConfig) GetPasswordHashAlgorithm() string {
+	return c.passwordHashAlgorithm
+}
+
+func (c *DefaultAuthConfig) GetEmailChangeRevertExpiration() time.Duration {
+	return c.emailChangeRevertExpiration
+}
+
+func (c *DefaultAuthConfig) GetAccountDeletionGracePeriod() time.Duration {
+	return c.accountDeletionGracePeriod
+}
+
+// SetAccountDeletionGracePeriod sets how long a deleted account can still be
+// restored before it is purged. Zero purges accounts immediately.
+func (c *DefaultAuthConfig) SetAccountDeletionGracePeriod(period time.Duration) {
+	c.accountDeletionGracePeriod = period
+}
+
+func (c *DefaultAuthConfig) GetImpersonationDuration() time.Duration {
+	return c.impersonationDuration
+}
+
+// SetImpersonationDuration sets how long an impersonation session lasts. It
+// cannot be extended by refreshing.
+func (c *DefaultAuthConfig) SetImpersonationDuration(duration time.Duration) {
+	c.impersonationDuration = duration
+}
+
+func (c *DefaultAuthConfig) GetCookieSettings() authinterface.CookieSettings {
+	return c.cookieSettings
+}
+
+// SetCookieSettings switches cookie mode on or off and sets the attributes
+// of the session cookies.
+func (c *DefaultAuthConfig) SetCookieSettings(settings authinterface.CookieSettings) {
+	c.cookieSettings = settings
+}
+
+func (c *DefaultAuthConfig) IsNewDeviceAlertEnabled() bool {
+	return c.newDeviceAlerts
+}
+
+// SetNewDeviceAlertEnabled emails account owners when their account signs
+// in from a device it has not used before.
+func (c *DefaultAuthConfig) SetNewDeviceAlertEnabled(enabled bool) {
+	c.newDeviceAlerts = enabled
+}
+
+func (c *DefaultAuthConfig) GetSessionStore() string {
+	return c.sessionStore
+}
+
+// SetSessionStore selects where sessions and other short-lived auth state
+// are kept: redis, gorm or memory.
+func (c *DefaultAuthConfig) SetSessionStore(store string) {
+	c.sessionStore = store
+}
+
 func (c *DefaultAuthConfig) GetBcryptCost() int {
 	return c.bcryptCost
 }
 
+func (c *DefaultAuthConfig) GetArgon2Params() authinterface.Argon2Params {
+	return c.argon2Params
+}
+
+// SetPasswordHashAlgorithm selects the algorithm for new hashes, either
+// argon2id or bcrypt. Hashes made with the other one still verify and are
+// upgraded on login.
+func (c *DefaultAuthConfig) SetPasswordHashAlgorithm(algorithm string) {
+	c.passwordHashAlgorithm = algorithm
+}
+
+func (c *DefaultAuthConfig) SetBcryptCost(cost int) {
+	c.bcryptCost = cost
+}
+
+func (c *DefaultAuthConfig) SetArgon2Params(params authinterface.Argon2Params) {
+	c.argon2Params = params
+}
+
+func (c *DefaultAuthConfig) GetPasswordRules() authinterface.PasswordRules {
+	return c.passwordRules
+}
+
+func (c *DefaultAuthConfig) SetPasswordRules(rules authinterface.PasswordRules) {
+	c.passwordRules = rules
+}
+
 func (c *DefaultAuthConfig) IsEmailVerificationRequired() bool {
 	return c.emailVerificationRequired
 }
 
 func (c *DefaultAuthConfig) IsPhoneVerificationRequired() bool {
 	return c.phoneVerificationRequired
+}
+
+func (c *DefaultAuthConfig) GetPhoneVerificationExpiration() time.Duration {
+	return c.phoneVerificationExpiration
+}
+
+func (c *DefaultAuthConfig) GetPhoneVerificationMaxAttempts() int {
+	return c.phoneVerificationMaxAttempts
+}
+
+func (c *DefaultAuthConfig) GetPhoneVerificationResendInterval() time.Duration {
+	return c.phoneVerificationResend
+}
+
+func (c *DefaultAuthConfig) GetPhoneVerificationsPerHour() int {
+	return c.phoneVerificationsPerHour
+}
+
+// SetPhoneVerificationRequired makes accounts with a phone number verify it
+// before they can sign in, and requires a phone number on registration.
+func (c *DefaultAuthConfig) SetPhoneVerificationRequired(required bool) {
+	c.phoneVerificationRequired = required
+}
+
+func (c *DefaultAuthConfig) GetMFAChallengeExpiration() time.Duration {
+	return c.mfaChallengeExpiration
+}
+
+func (c *DefaultAuthConfig) GetMFAMaxAttempts() int {
+	return c.mfaMaxAttempts
+}
+
+func (c *DefaultAuthConfig) GetOAuthStateExpiration() time.Duration {
+	return c.oauthStateExpiration
+}
+
+func (c *DefaultAuthConfig) GetMagicLinkExpiration() time.Duration {
+	return c.magicLinkExpiration
+}
+
+func (c *DefaultAuthConfig) GetMagicLinkRequestsPerHour() int {
+	return c.magicLinkRequestsPerHour
+}
+
+func (c *DefaultAuthConfig) IsMagicLinkDeviceBindingEnabled() bool {
+	return c.magicLinkDeviceBinding
+}
+
+// SetMagicLinkDeviceBinding requires magic links to be opened on the device
+// that requested them.
+func (c *DefaultAuthConfig) SetMagicLinkDeviceBinding(enabled bool) {
+	c.magicLinkDeviceBinding = enabled
+}
+
+func (c *DefaultAuthConfig) GetWebAuthnRPID() string {
+	return c.webAuthnRPID
+}
+
+func (c *DefaultAuthConfig) GetWebAuthnRPName() string {
+	return c.webAuthnRPName
+}
+
+func (c *DefaultAuthConfig) GetWebAuthnOrigins() []string {
+	return c.webAuthnOrigins
+}
+
+func (c *DefaultAuthConfig) GetWebAuthnTimeout() time.Duration {
+	return c.webAuthnTimeout
+}
+
+// SetWebAuthnRelyingParty sets the passkey relying party. rpID must be the
+// host of every origin (or a registrable suffix of it).
+func (c *DefaultAuthConfig) SetWebAuthnRelyingParty(rpID, rpName string, origins []string) {
+	c.webAuthnRPID = rpID
+	c.webAuthnRPName = rpName
+	c.webAuthnOrigins = origins
+}
+
+func (c *DefaultAuthConfig) GetMaxFailedLogins() int {
+	return c.maxFailedLogins
+}
+
+func (c *DefaultAuthConfig) GetMaxFailedLoginsPerIP() int {
+	return c.maxFailedLoginsPerIP
+}
+
+func (c *DefaultAuthConfig) GetFailedLoginWindow() time.Duration {
+	return c.failedLoginWindow
+}
+
+func (c *DefaultAuthConfig) GetLockoutDuration() time.Duration {
+	return c.lockoutDuration
+}
+
+func (c *DefaultAuthConfig) GetLoginDelayBase() time.Duration {
+	return c.loginDelayBase
+}
+
+// SetMaxFailedLogins sets how many failed logins lock an account and an IP
+// address. Zero disables the respective lockout.
+func (c *DefaultAuthConfig) SetMaxFailedLogins(perAccount, perIP int) {
+	c.maxFailedLogins = perAccount
+	c.maxFailedLoginsPerIP = perIP
+}
+
+func (c *DefaultAuthConfig) SetLockoutDuration(duration time.Duration) {
+	c.lockoutDuration = duration
+}
+
+func (c *DefaultAuthConfig) GetMFARequiredRoles() []string {
+	return c.mfaRequiredRoles
+}
+
+// SetMFARequiredRoles makes MFA mandatory for accounts holding any of roles.
+func (c *DefaultAuthConfig) SetMFARequiredRoles(roles []string) {
+	c.mfaRequiredRoles = roles
+}
+
+func (c *DefaultAuthConfig) GetJWTIssuer() string {
+	return c.jwtIssuer
+}
+
+func (c *DefaultAuthConfig) SetJWTIssuer(issuer string) {
+	c.jwtIssuer = issuer
+}
+
+func (c *DefaultAuthConfig) GetJWTAudience() []string {
+	return c.jwtAudience
+}
+
+func (c *DefaultAuthConfig) SetJWTAudience(audience []string) {
+	c.jwtAudience = audience
+}
+
+func (c *DefaultAuthConfig) IsRoleClaimsEnabled() bool {
+	return c.roleClaimsEnabled
+}
+
+func (c *DefaultAuthConfig) SetRoleClaimsEnabled(enabled bool) {
+	c.roleClaimsEnabled = enabled
+}
+
+// IsStatelessValidation reports whether access tokens are trusted on their
+// signed claims alone, checking only the revocation list instead of loading
+// the session and account for every request.
+func (c *DefaultAuthConfig) IsStatelessValidation() bool {
+	return c.statelessValidation
+}
+
+func (c *DefaultAuthConfig) SetStatelessValidation(enabled bool) {
+	c.statelessValidation = enabled
 }
\ No newline at end of file
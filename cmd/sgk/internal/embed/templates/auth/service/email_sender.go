@@ -3,6 +3,7 @@ package authservice
 import (
 	"context"
 	"fmt"
+	"time"
 	
 	authinterface "{{.Project.GoModule}}/internal/auth/interface"
 	emailinterface "{{.Project.GoModule}}/internal/email/interface"
@@ -33,6 +34,41 @@ func (s *MockEmailSender) SendWelcomeEmail(email string) error {
 	return nil
 }
 
+func (s *MockEmailSender) SendMagicLinkEmail(email, token string) error {
+	fmt.Printf("Sending magic link email to %s\n", email)
+	fmt.Printf("Login link: %s/auth/magic-link?token=%s\n", s.baseURL, token)
+	return nil
+}
+
+func (s *MockEmailSender) SendAccountUnlockEmail(email, token string) error {
+	fmt.Printf("Sending account unlock email to %s\n", email)
+	fmt.Printf("Unlock link: %s/auth/unlock?token=%s\n", s.baseURL, token)
+	return nil
+}
+
+func (s *MockEmailSender) SendEmailChangeConfirmation(newEmail, token string) error {
+	fmt.Printf("Sending email change confirmation to %s\n", newEmail)
+	fmt.Printf("Confirmation link: %s/auth/confirm-email-change?token=%s\n", s.baseURL, token)
+	return nil
+}
+
+func (s *MockEmailSender) SendEmailChangedNotification(oldEmail, newEmail, revertToken string) error {
+	fmt.Printf("Sending email changed notice to %s (now %s)\n", oldEmail, newEmail)
+	fmt.Printf("Revert link: %s/auth/revert-email-change?token=%s\n", s.baseURL, revertToken)
+	return nil
+}
+
+func (s *MockEmailSender) SendAccountDeletionScheduled(email, restoreToken string, purgeAt time.Time) error {
+	fmt.Printf("Sending account deletion notice to %s (purge at %s)\n", email, purgeAt.Format(time.RFC3339))
+	fmt.Printf("Restore link: %s/auth/restore-account?token=%s\n", s.baseURL, restoreToken)
+	return nil
+}
+
+func (s *MockEmailSender) SendNewDeviceLogin(email, userAgent, ipAddress string, loggedInAt time.Time) error {
+	fmt.Printf("Sending new device sign-in notice to %s (%s from %s at %s)\n", email, userAgent, ipAddress, loggedInAt.Format(time.RFC3339))
+	return nil
+}
+
 type EmailSenderAdapter struct {
 	emailService emailinterface.EmailService
 	baseURL      string
@@ -131,5 +167,195 @@ func (a *EmailSenderAdapter) SendWelcomeEmail(email string) error {
 		return a.emailService.Send(ctx, []string{email}, subject, body)
 	}
 	
+	return nil
+}
+
+func (a *EmailSenderAdapter) SendMagicLinkEmail(email, token string) error {
+	ctx := context.Background()
+	
+	loginLink := fmt.Sprintf("%s/auth/magic-link?token=%s", a.baseURL, token)
+	
+	data := map[string]interface{}{
+		"email":     email,
+		"loginLink": loginLink,
+		"baseURL":   a.baseURL,
+	}
+	
+	err := a.emailService.SendTemplate(ctx, []string{email}, "magic-link", data)
+	if err != nil {
+		subject := "Your Login Link"
+		body := fmt.Sprintf(
+			"Hello,\n\n"+
+			"Click the link below to sign in:\n\n"+
+			"%s\n\n"+
+			"This link will expire in 15 minutes and can only be used once.\n\n"+
+			"If you didn't request this, please ignore this email.\n\n"+
+			"Best regards,\n"+
+			"The Team",
+			loginLink,
+		)
+		
+		return a.emailService.Send(ctx, []string{email}, subject, body)
+	}
+	
+	return nil
+}
+
+func (a *EmailSenderAdapter) SendAccountUnlockEmail(email, token string) error {
+	ctx := context.Background()
+	
+	unlockLink := fmt.Sprintf("%s/auth/unlock?token=%s", a.baseURL, token)
+	
+	data := map[string]interface{}{
+		"email":      email,
+		"unlockLink": unlockLink,
+		"baseURL":    a.baseURL,
+	}
+	
+	err := a.emailService.SendTemplate(ctx, []string{email}, "account-unlock", data)
+	if err != nil {
+		subject := "Your Account Has Been Locked"
+		body := fmt.Sprintf(
+			"Hello,\n\n"+
+			"We locked your account after several failed sign-in attempts.\n\n"+
+			"If this was you, unlock it now with the link below:\n\n"+
+			"%s\n\n"+
+			"If it wasn't you, consider changing your password once you are signed in.\n\n"+
+			"Best regards,\n"+
+			"The Team",
+			unlockLink,
+		)
+		
+		return a.emailService.Send(ctx, []string{email}, subject, body)
+	}
+	
+	return nil
+}
+
+func (a *EmailSenderAdapter) SendEmailChangeConfirmation(newEmail, token string) error {
+	ctx := context.Background()
+	
+	confirmLink := fmt.Sprintf("%s/auth/confirm-email-change?token=%s", a.baseURL, token)
+	
+	data := map[string]interface{}{
+		"email":       newEmail,
+		"confirmLink": confirmLink,
+		"baseURL":     a.baseURL,
+	}
+	
+	err := a.emailService.SendTemplate(ctx, []string{newEmail}, "email-change-confirmation", data)
+	if err != nil {
+		subject := "Confirm Your New Email Address"
+		body := fmt.Sprintf(
+			"Hello,\n\n"+
+			"Please confirm that you want to use this address for your account by clicking the link below:\n\n"+
+			"%s\n\n"+
+			"If you didn't request this, please ignore this email.\n\n"+
+			"Best regards,\n"+
+			"The Team",
+			confirmLink,
+		)
+		
+		return a.emailService.Send(ctx, []string{newEmail}, subject, body)
+	}
+	
+	return nil
+}
+
+func (a *EmailSenderAdapter) SendEmailChangedNotification(oldEmail, newEmail, revertToken string) error {
+	ctx := context.Background()
+	
+	revertLink := fmt.Sprintf("%s/auth/revert-email-change?token=%s", a.baseURL, revertToken)
+	
+	data := map[string]interface{}{
+		"email":      oldEmail,
+		"newEmail":   newEmail,
+		"revertLink": revertLink,
+		"baseURL":    a.baseURL,
+	}
+	
+	err := a.emailService.SendTemplate(ctx, []string{oldEmail}, "email-changed", data)
+	if err != nil {
+		subject := "Your Email Address Was Changed"
+		body := fmt.Sprintf(
+			"Hello,\n\n"+
+			"The email address of your account was changed to %s.\n\n"+
+			"If you didn't make this change, restore your previous address and sign out all devices with the link below:\n\n"+
+			"%s\n\n"+
+			"Best regards,\n"+
+			"The Team",
+			newEmail,
+			revertLink,
+		)
+		
+		return a.emailService.Send(ctx, []string{oldEmail}, subject, body)
+	}
+	
+	return nil
+}
+
+func (a *EmailSenderAdapter) SendAccountDeletionScheduled(email, restoreToken string, purgeAt time.Time) error {
+	ctx := context.Background()
+	
+	restoreLink := fmt.Sprintf("%s/auth/restore-account?token=%s", a.baseURL, restoreToken)
+	
+	data := map[string]interface{}{
+		"email":       email,
+		"restoreLink": restoreLink,
+		"purgeAt":     purgeAt.Format("January 2, 2006"),
+		"baseURL":     a.baseURL,
+	}
+	
+	err := a.emailService.SendTemplate(ctx, []string{email}, "account-deletion-scheduled", data)
+	if err != nil {
+		subject := "Your Account Is Scheduled for Deletion"
+		body := fmt.Sprintf(
+			"Hello,\n\n"+
+			"Your account and its data will be permanently deleted on %s.\n\n"+
+			"If you change your mind, or didn't request this, restore your account with the link below before then:\n\n"+
+			"%s\n\n"+
+			"Best regards,\n"+
+			"The Team",
+			purgeAt.Format("January 2, 2006"),
+			restoreLink,
+		)
+		
+		return a.emailService.Send(ctx, []string{email}, subject, body)
+	}
+	
+	return nil
+}
+
+func (a *EmailSenderAdapter) SendNewDeviceLogin(email, userAgent, ipAddress string, loggedInAt time.Time) error {
+	ctx := context.Background()
+	
+	data := map[string]interface{}{
+		"email":      email,
+		"userAgent":  userAgent,
+		"ipAddress":  ipAddress,
+		"loggedInAt": loggedInAt.UTC().Format("January 2, 2006 15:04 MST"),
+		"baseURL":    a.baseURL,
+	}
+	
+	err := a.emailService.SendTemplate(ctx, []string{email}, "new-device-login", data)
+	if err != nil {
+		subject := "New Sign-In to Your Account"
+		body := fmt.Sprintf(
+			"Hello,\n\n"+
+			"Your account was just signed in to from a device it hasn't used before:\n\n"+
+			"Device: %s\n"+
+			"IP address: %s\n"+
+			"Time: %s\n\n"+
+			"If this was you, you can ignore this email. If not, change your password and sign out of all sessions right away.\n\n"+
+			"Best regards,\n"+
+			"The Team",
+			userAgent,
+			ipAddress,
+			loggedInAt.UTC().Format("January 2, 2006 15:04 MST"),
+		)
+		
+		return a.emailService.Send(ctx, []string{email}, subject, body)
+	}
+	
 	return nil
 }
\ No newline at end of file
@@ -0,0 +1,97 @@
+package authservice
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+)
+
+type LogEventHook struct{}
+
+func NewLogEventHook() authinterface.AuthEventHook {
+	return &LogEventHook{}
+}
+
+func (h *LogEventHook) HandleAuthEvent(ctx context.Context, event authinterface.AuthEvent) {
+	if impersonatorID, ok := event.Metadata["impersonator_id"]; ok {
+		reason, _ := event.Metadata["reason"].(string)
+		fmt.Printf("Auth event %s: account=%s session=%s ip=%s impersonator=%v reason=%q\n", event.Type, event.AccountID, event.SessionID, event.IPAddress, impersonatorID, reason)
+		return
+	}
+	fmt.Printf("Auth event %s: account=%s session=%s ip=%s\n", event.Type, event.AccountID, event.SessionID, event.IPAddress)
+}
+
+type eventSubscription struct {
+	hook  authinterface.AuthEventHook
+	types map[authinterface.AuthEventType]bool
+}
+
+// EventDispatcher fans auth events out to subscribed hooks in the order
+// they subscribed. A hook that panics is logged and skipped so it cannot
+// break the request that raised the event.
+type EventDispatcher struct {
+	mu            sync.RWMutex
+	subscriptions []eventSubscription
+}
+
+func NewEventDispatcher() *EventDispatcher {
+	return &EventDispatcher{}
+}
+
+func (d *EventDispatcher) Subscribe(hook authinterface.AuthEventHook, types ...authinterface.AuthEventType) {
+	subscription := eventSubscription{hook: hook}
+	if len(types) > 0 {
+		subscription.types = make(map[authinterface.AuthEventType]bool, len(types))
+		for _, eventType := range types {
+			subscription.types[eventType] = true
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.subscriptions = append(d.subscriptions, subscription)
+}
+
+func (d *EventDispatcher) HandleAuthEvent(ctx context.Context, event authinterface.AuthEvent) {
+	d.mu.RLock()
+	subscriptions := d.subscriptions
+	d.mu.RUnlock()
+
+	for _, subscription := range subscriptions {
+		if subscription.types != nil && !subscription.types[event.Type] {
+			continue
+		}
+		dispatchEvent(ctx, subscription.hook, event)
+	}
+}
+
+func dispatchEvent(ctx context.Context, hook authinterface.AuthEventHook, event authinterface.AuthEvent) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Printf("Auth event hook panicked on %s: %v\n", event.Type, r)
+		}
+	}()
+	hook.HandleAuthEvent(ctx, event)
+}
+
+func (s *AuthService) emitEvent(ctx context.Context, event authinterface.AuthEvent) {
+	if s.eventHook == nil {
+		return
+	}
+
+	client := authinterface.ClientInfoFromContext(ctx)
+	if event.IPAddress == "" {
+		event.IPAddress = client.IPAddress
+	}
+	if event.UserAgent == "" {
+		event.UserAgent = client.UserAgent
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	s.eventHook.HandleAuthEvent(ctx, event)
+}
@@ -0,0 +1,434 @@
+package authservice
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	authmodel "{{.Project.GoModule}}/internal/auth/model"
+	"{{.Project.GoModule}}/internal/auth/repository/memory"
+	"{{.Project.GoModule}}/internal/core"
+)
+
+// The fakes embed their interface and implement only what the tests reach;
+// anything else panics on the nil embedded value.
+
+type fakeAccounts struct {
+	authinterface.AccountRepository
+	mu       sync.Mutex
+	accounts map[uuid.UUID]authmodel.Account
+}
+
+func newFakeAccounts() *fakeAccounts {
+	return &fakeAccounts{accounts: make(map[uuid.UUID]authmodel.Account)}
+}
+
+func (r *fakeAccounts) Create(ctx context.Context, account authinterface.Account) error {
+	return r.Update(ctx, account)
+}
+
+func (r *fakeAccounts) GetByID(ctx context.Context, id uuid.UUID) (authinterface.Account, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	account, ok := r.accounts[id]
+	if !ok {
+		return nil, errors.New("account not found")
+	}
+	return &account, nil
+}
+
+func (r *fakeAccounts) GetByEmail(ctx context.Context, email string) (authinterface.Account, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, account := range r.accounts {
+		if account.Email == email {
+			return &account, nil
+		}
+	}
+	return nil, errors.New("account not found")
+}
+
+func (r *fakeAccounts) ExistsByPhone(ctx context.Context, phone string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, account := range r.accounts {
+		if account.Phone == phone {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeAccounts) Update(ctx context.Context, account authinterface.Account) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.accounts[account.GetID()] = *account.(*authmodel.Account)
+	return nil
+}
+
+type fakeTokens struct {
+	authinterface.TokenRepository
+	mu     sync.Mutex
+	tokens map[string]authmodel.Token
+}
+
+func newFakeTokens() *fakeTokens {
+	return &fakeTokens{tokens: make(map[string]authmodel.Token)}
+}
+
+// Create rejects a token value that is already stored, like the unique
+// index on the tokens table.
+func (r *fakeTokens) Create(ctx context.Context, token authinterface.Token) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.tokens[token.GetToken()]; exists {
+		return errors.New("duplicate key value violates unique constraint")
+	}
+
+	stored := *token.(*authmodel.Token)
+	if stored.CreatedAt.IsZero() {
+		stored.CreatedAt = time.Now()
+	}
+	r.tokens[token.GetToken()] = stored
+	return nil
+}
+
+func (r *fakeTokens) GetByAccountAndType(ctx context.Context, accountID uuid.UUID, tokenType authinterface.TokenType) ([]authinterface.Token, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var tokens []authinterface.Token
+	for _, token := range r.tokens {
+		if token.AccountID == accountID && token.Type == tokenType {
+			tokens = append(tokens, &token)
+		}
+	}
+	return tokens, nil
+}
+
+func (r *fakeTokens) GetByToken(ctx context.Context, value string) (authinterface.Token, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	token, ok := r.tokens[value]
+	if !ok {
+		return nil, errors.New("token not found")
+	}
+	return &token, nil
+}
+
+func (r *fakeTokens) MarkAsUsed(ctx context.Context, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for value, token := range r.tokens {
+		if token.ID == id && !token.Used {
+			token.Used = true
+			r.tokens[value] = token
+			return nil
+		}
+	}
+	return errors.New("token not found or already used")
+}
+
+type fakeRecoveryCodes struct {
+	mu     sync.Mutex
+	hashes map[uuid.UUID]map[string]bool
+}
+
+func newFakeRecoveryCodes() *fakeRecoveryCodes {
+	return &fakeRecoveryCodes{hashes: make(map[uuid.UUID]map[string]bool)}
+}
+
+func (r *fakeRecoveryCodes) Replace(ctx context.Context, accountID uuid.UUID, codeHashes []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.hashes[accountID] = make(map[string]bool, len(codeHashes))
+	for _, hash := range codeHashes {
+		r.hashes[accountID][hash] = true
+	}
+	return nil
+}
+
+func (r *fakeRecoveryCodes) Consume(ctx context.Context, accountID uuid.UUID, codeHash string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.hashes[accountID][codeHash] {
+		return false, nil
+	}
+	delete(r.hashes[accountID], codeHash)
+	return true, nil
+}
+
+func (r *fakeRecoveryCodes) DeleteByAccount(ctx context.Context, accountID uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.hashes, accountID)
+	return nil
+}
+
+type fakeEmailSender struct {
+	authinterface.EmailSender
+	mu      sync.Mutex
+	unlocks []string
+}
+
+func (s *fakeEmailSender) SendAccountUnlockEmail(email, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.unlocks = append(s.unlocks, email)
+	return nil
+}
+
+// fakeSMSSender keeps the last code texted to each phone.
+type fakeSMSSender struct {
+	mu    sync.Mutex
+	codes map[string]string
+	sent  int
+}
+
+func newFakeSMSSender() *fakeSMSSender {
+	return &fakeSMSSender{codes: make(map[string]string)}
+}
+
+func (s *fakeSMSSender) SendVerificationSMS(phone, code string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.codes[phone] = code
+	s.sent++
+	return nil
+}
+
+func (s *fakeSMSSender) lastCode(phone string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.codes[phone]
+}
+
+func (s *fakeSMSSender) sentCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.sent
+}
+
+type fakeCredentials struct {
+	mu          sync.Mutex
+	credentials map[string]authmodel.WebAuthnCredential
+}
+
+func newFakeCredentials() *fakeCredentials {
+	return &fakeCredentials{credentials: make(map[string]authmodel.WebAuthnCredential)}
+}
+
+func (r *fakeCredentials) Create(ctx context.Context, credential authinterface.WebAuthnCredential) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.credentials[credential.GetCredentialID()] = *credential.(*authmodel.WebAuthnCredential)
+	return nil
+}
+
+func (r *fakeCredentials) GetByCredentialID(ctx context.Context, credentialID string) (authinterface.WebAuthnCredential, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	credential, ok := r.credentials[credentialID]
+	if !ok {
+		return nil, errors.New("passkey not found")
+	}
+	return &credential, nil
+}
+
+func (r *fakeCredentials) ListByAccount(ctx context.Context, accountID uuid.UUID) ([]authinterface.WebAuthnCredential, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var credentials []authinterface.WebAuthnCredential
+	for _, credential := range r.credentials {
+		if credential.AccountID == accountID {
+			credentials = append(credentials, &credential)
+		}
+	}
+	return credentials, nil
+}
+
+// UpdateSignCount only moves the counter forward, like the gorm repository.
+func (r *fakeCredentials) UpdateSignCount(ctx context.Context, id uuid.UUID, signCount uint32, usedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, credential := range r.credentials {
+		if credential.ID != id {
+			continue
+		}
+		if signCount > 0 && credential.SignCount >= signCount {
+			break
+		}
+		credential.SignCount = signCount
+		credential.LastUsedAt = &usedAt
+		r.credentials[key] = credential
+		return nil
+	}
+	return errors.New("sign count did not increase")
+}
+
+func (r *fakeCredentials) Delete(ctx context.Context, accountID, id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, credential := range r.credentials {
+		if credential.ID == id && credential.AccountID == accountID {
+			delete(r.credentials, key)
+			return nil
+		}
+	}
+	return errors.New("passkey not found")
+}
+
+type fakeLoginHistory struct {
+	authinterface.LoginHistoryRepository
+	mu      sync.Mutex
+	entries []authmodel.LoginHistory
+	err     error
+}
+
+func (r *fakeLoginHistory) Create(ctx context.Context, entry authinterface.LoginHistoryEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return r.err
+	}
+	r.entries = append(r.entries, *entry.(*authmodel.LoginHistory))
+	return nil
+}
+
+func (r *fakeLoginHistory) list() []authmodel.LoginHistory {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]authmodel.LoginHistory(nil), r.entries...)
+}
+
+// recordingHook keeps every event it receives.
+type recordingHook struct {
+	mu     sync.Mutex
+	events []authinterface.AuthEvent
+}
+
+func (h *recordingHook) HandleAuthEvent(ctx context.Context, event authinterface.AuthEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.events = append(h.events, event)
+}
+
+func (h *recordingHook) ofType(eventType authinterface.AuthEventType) []authinterface.AuthEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var events []authinterface.AuthEvent
+	for _, event := range h.events {
+		if event.Type == eventType {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+type testService struct {
+	*AuthService
+	config   *DefaultAuthConfig
+	accounts *fakeAccounts
+	tokens   *fakeTokens
+	sessions authinterface.SessionStore
+	emails   *fakeEmailSender
+	sms      *fakeSMSSender
+}
+
+// newTestService wires an AuthService to in-memory fakes. deps may set
+// further collaborators; the ones set here are filled in when left nil.
+func newTestService(t *testing.T, deps AuthServiceDeps) *testService {
+	t.Helper()
+
+	config := NewDefaultAuthConfig()
+	ts := &testService{
+		config:   config,
+		accounts: newFakeAccounts(),
+		tokens:   newFakeTokens(),
+		sessions: memory.NewSessionStore(),
+		emails:   &fakeEmailSender{},
+		sms:      newFakeSMSSender(),
+	}
+
+	if deps.EmailSender == nil {
+		deps.EmailSender = ts.emails
+	}
+	if deps.SMSSender == nil {
+		deps.SMSSender = ts.sms
+	}
+	if deps.RecoveryCodeRepo == nil {
+		deps.RecoveryCodeRepo = newFakeRecoveryCodes()
+	}
+	if deps.TOTPProvider == nil {
+		deps.TOTPProvider = NewTOTPProvider("test")
+	}
+	if deps.KeyProvider == nil {
+		deps.KeyProvider = NewHMACKeyProvider("test-secret")
+	}
+	if deps.LoginAttempts == nil {
+		deps.LoginAttempts = memory.NewLoginAttemptTracker()
+	}
+	if deps.RevocationList == nil {
+		deps.RevocationList = memory.NewRevocationList()
+	}
+
+	ts.AuthService = NewAuthService(
+		ts.accounts,
+		ts.tokens,
+		ts.sessions,
+		NewBcryptPasswordHasher(4),
+		NewTokenGenerator(),
+		config,
+		deps,
+	)
+	return ts
+}
+
+func (ts *testService) createAccount(t *testing.T, email string) *authmodel.Account {
+	t.Helper()
+
+	account := &authmodel.Account{ID: uuid.New(), Email: email, EmailVerified: true}
+	if err := ts.accounts.Create(context.Background(), account); err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+	return account
+}
+
+func assertAppError(t *testing.T, err error, message string) {
+	t.Helper()
+
+	var appErr *core.AppError
+	if !errors.As(err, &appErr) {
+		t.Fatalf("error = %v, want app error %q", err, message)
+	}
+	if appErr.Message != message {
+		t.Fatalf("error = %q, want %q", appErr.Message, message)
+	}
+}
@@ -0,0 +1,268 @@
+package authservice
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+)
+
+type HMACKeyProvider struct {
+	key *authinterface.SigningKey
+}
+
+// NewHMACKeyProvider signs and verifies HS256 tokens with a shared secret.
+func NewHMACKeyProvider(secret string) authinterface.KeyProvider {
+	return &HMACKeyProvider{
+		key: &authinterface.SigningKey{
+			Algorithm: "HS256",
+			Private:   []byte(secret),
+			Public:    []byte(secret),
+		},
+	}
+}
+
+func (p *HMACKeyProvider) SigningKey() *authinterface.SigningKey {
+	return p.key
+}
+
+func (p *HMACKeyProvider) VerificationKey(kid string) (*authinterface.SigningKey, error) {
+	return p.key, nil
+}
+
+func (p *HMACKeyProvider) PublicKeys() []*authinterface.SigningKey {
+	return nil
+}
+
+type PEMKeyProvider struct {
+	signing      *authinterface.SigningKey
+	verification map[string]*authinterface.SigningKey
+	public       []*authinterface.SigningKey
+}
+
+// NewPEMKeyProvider signs with the RSA (RS256) or Ed25519 (EdDSA) private key
+// in signingKeyFile. Keys in verificationKeyFiles, public or private, are only
+// used to verify tokens, which keeps tokens signed by a retired key valid
+// until they expire. Key IDs are derived from the public key.
+func NewPEMKeyProvider(signingKeyFile string, verificationKeyFiles []string) (authinterface.KeyProvider, error) {
+	data, err := os.ReadFile(signingKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+
+	signing, err := parsePEMKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key %s: %w", signingKeyFile, err)
+	}
+	if signing.Private == nil {
+		return nil, fmt.Errorf("signing key %s is not a private key", signingKeyFile)
+	}
+
+	provider := &PEMKeyProvider{
+		signing:      signing,
+		verification: map[string]*authinterface.SigningKey{signing.ID: signing},
+		public:       []*authinterface.SigningKey{signing},
+	}
+
+	for _, file := range verificationKeyFiles {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read verification key: %w", err)
+		}
+
+		key, err := parsePEMKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse verification key %s: %w", file, err)
+		}
+
+		if _, exists := provider.verification[key.ID]; exists {
+			continue
+		}
+		key.Private = nil
+		provider.verification[key.ID] = key
+		provider.public = append(provider.public, key)
+	}
+
+	return provider, nil
+}
+
+func (p *PEMKeyProvider) SigningKey() *authinterface.SigningKey {
+	return p.signing
+}
+
+func (p *PEMKeyProvider) VerificationKey(kid string) (*authinterface.SigningKey, error) {
+	key, ok := p.verification[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	return key, nil
+}
+
+func (p *PEMKeyProvider) PublicKeys() []*authinterface.SigningKey {
+	return p.public
+}
+
+func parsePEMKey(data []byte) (*authinterface.SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+
+	var private crypto.Signer
+	var public crypto.PublicKey
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+		private = signer
+		public = signer.Public()
+	case "RSA PRIVATE KEY":
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		private = key
+		public = key.Public()
+	case "PUBLIC KEY":
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		public = key
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+
+	var algorithm string
+	switch public.(type) {
+	case *rsa.PublicKey:
+		algorithm = "RS256"
+	case ed25519.PublicKey:
+		algorithm = "EdDSA"
+	default:
+		return nil, fmt.Errorf("unsupported key type %T, expected RSA or Ed25519", public)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(der)
+
+	key := &authinterface.SigningKey{
+		ID:        base64.RawURLEncoding.EncodeToString(sum[:16]),
+		Algorithm: algorithm,
+		Public:    public,
+	}
+	if private != nil {
+		key.Private = private
+	}
+
+	return key, nil
+}
+
+func toJSONWebKey(key *authinterface.SigningKey) (authinterface.JSONWebKey, bool) {
+	jwk := authinterface.JSONWebKey{
+		Use: "sig",
+		Kid: key.ID,
+		Alg: key.Algorithm,
+	}
+
+	switch public := key.Public.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	default:
+		return jwk, false
+	}
+
+	return jwk, true
+}
+
+// fromJSONWebKey is the inverse of toJSONWebKey, used to read keys published
+// by external identity providers.
+func fromJSONWebKey(jwk authinterface.JSONWebKey) (*authinterface.SigningKey, error) {
+	decode := base64.RawURLEncoding.DecodeString
+
+	key := &authinterface.SigningKey{
+		ID:        jwk.Kid,
+		Algorithm: jwk.Alg,
+	}
+
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decode(jwk.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA modulus: %w", err)
+		}
+		e, err := decode(jwk.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA exponent: %w", err)
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("invalid RSA exponent")
+		}
+		key.Public = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+		x, err := decode(jwk.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EC x coordinate: %w", err)
+		}
+		y, err := decode(jwk.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EC y coordinate: %w", err)
+		}
+		public := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(public.X, public.Y) {
+			return nil, fmt.Errorf("EC point is not on curve %s", jwk.Crv)
+		}
+		key.Public = public
+	case "OKP":
+		if jwk.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+		x, err := decode(jwk.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 key")
+		}
+		key.Public = ed25519.PublicKey(x)
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", jwk.Kty)
+	}
+
+	return key, nil
+}
@@ -0,0 +1,142 @@
+package authservice
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	authmodel "{{.Project.GoModule}}/internal/auth/model"
+	"{{.Project.GoModule}}/internal/core"
+)
+
+const (
+	defaultLoginHistoryLimit = 50
+	maxLoginHistoryLimit     = 200
+)
+
+var versionPattern = regexp.MustCompile(`\d+([._]\d+)*`)
+
+// LoginHistoryRecorder subscribes to logged_in and login_failed events and
+// keeps them as the account's login history. The first login from a device
+// an account has not used before is reported to its owner by email.
+type LoginHistoryRecorder struct {
+	historyRepo     authinterface.LoginHistoryRepository
+	accountRepo     authinterface.AccountRepository
+	emailSender     authinterface.EmailSender
+	newDeviceAlerts bool
+}
+
+func NewLoginHistoryRecorder(
+	historyRepo authinterface.LoginHistoryRepository,
+	accountRepo authinterface.AccountRepository,
+	emailSender authinterface.EmailSender,
+	newDeviceAlerts bool,
+) *LoginHistoryRecorder {
+	return &LoginHistoryRecorder{
+		historyRepo:     historyRepo,
+		accountRepo:     accountRepo,
+		emailSender:     emailSender,
+		newDeviceAlerts: newDeviceAlerts,
+	}
+}
+
+func (r *LoginHistoryRecorder) HandleAuthEvent(ctx context.Context, event authinterface.AuthEvent) {
+	if event.AccountID == uuid.Nil {
+		return
+	}
+
+	entry := &authmodel.LoginHistory{
+		ID:        uuid.New(),
+		AccountID: event.AccountID,
+		Success:   event.Type == authinterface.AuthEventLoggedIn,
+		IPAddress: event.IPAddress,
+		UserAgent: event.UserAgent,
+		Device:    deviceName(event.UserAgent),
+		CreatedAt: event.OccurredAt,
+	}
+	entry.Method, _ = event.Metadata["method"].(string)
+	entry.FailureReason, _ = event.Metadata["reason"].(string)
+	if event.SessionID != uuid.Nil {
+		sessionID := event.SessionID
+		entry.SessionID = &sessionID
+	}
+
+	if entry.Success {
+		// The first login of an account is the device it signed up on
+		returning, err := r.historyRepo.HasLoggedIn(ctx, event.AccountID)
+		if err != nil {
+			fmt.Printf("Failed to check login history: %v\n", err)
+		} else if returning {
+			known, err := r.historyRepo.HasLoggedInFrom(ctx, event.AccountID, entry.Device)
+			if err != nil {
+				fmt.Printf("Failed to check login history: %v\n", err)
+			}
+			entry.NewDevice = err == nil && !known
+		}
+	}
+
+	if err := r.historyRepo.Create(ctx, entry); err != nil {
+		fmt.Printf("Failed to record login: %v\n", err)
+	}
+
+	if entry.NewDevice && r.newDeviceAlerts {
+		account, err := r.accountRepo.GetByID(ctx, event.AccountID)
+		if err != nil {
+			fmt.Printf("Failed to load account for new device alert: %v\n", err)
+			return
+		}
+		if err := r.emailSender.SendNewDeviceLogin(account.GetEmail(), entry.UserAgent, entry.IPAddress, entry.CreatedAt); err != nil {
+			fmt.Printf("Failed to send new device alert: %v\n", err)
+		}
+	}
+}
+
+// ListLoginHistory returns the account's most recent sign-in attempts, newest
+// first. A limit outside 1..200 falls back to 50.
+func (s *AuthService) ListLoginHistory(ctx context.Context, accountID uuid.UUID, limit int) ([]authinterface.LoginHistoryEntry, error) {
+	if limit <= 0 || limit > maxLoginHistoryLimit {
+		limit = defaultLoginHistoryLimit
+	}
+
+	entries, err := s.loginHistoryRepo.ListByAccount(ctx, accountID, limit)
+	if err != nil {
+		return nil, core.NewAppError(core.ErrCodeInternalServer, "failed to list login history")
+	}
+	return entries, nil
+}
+
+type loginMethodKey struct{}
+
+// withLoginMethod records how the user is signing in so the logged_in and
+// login_failed events can report it.
+func withLoginMethod(ctx context.Context, method string) context.Context {
+	return context.WithValue(ctx, loginMethodKey{}, method)
+}
+
+func loginMethodFromContext(ctx context.Context) string {
+	method, _ := ctx.Value(loginMethodKey{}).(string)
+	return method
+}
+
+func (s *AuthService) emitLoginFailed(ctx context.Context, accountID uuid.UUID, reason string, metadata map[string]any) {
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+	metadata["method"] = loginMethodFromContext(ctx)
+	metadata["reason"] = reason
+
+	s.emitEvent(ctx, authinterface.AuthEvent{
+		Type:      authinterface.AuthEventLoginFailed,
+		AccountID: accountID,
+		Metadata:  metadata,
+	})
+}
+
+// deviceName reduces a user agent to its browser and platform by dropping
+// version numbers.
+func deviceName(userAgent string) string {
+	return strings.Join(strings.Fields(versionPattern.ReplaceAllString(strings.ToLower(userAgent), "")), " ")
+}
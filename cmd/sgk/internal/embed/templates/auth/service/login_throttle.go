@@ -0,0 +1,210 @@
+package authservice
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	authconstants "{{.Project.GoModule}}/internal/auth/constants"
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	authmodel "{{.Project.GoModule}}/internal/auth/model"
+	"{{.Project.GoModule}}/internal/core"
+)
+
+// maxLoginDelay caps the progressive delay between failed logins.
+const maxLoginDelay = time.Minute
+
+// UnlockAccount lifts a lockout using the token emailed when the account
+// was locked.
+func (s *AuthService) UnlockAccount(ctx context.Context, tokenStr string) error {
+	token, err := s.tokenRepo.GetByToken(ctx, tokenStr)
+	if err != nil {
+		return core.NewAppError(core.ErrCodeBadRequest, authconstants.ErrInvalidToken)
+	}
+
+	if token.GetType() != authinterface.TokenTypeAccountUnlock {
+		return core.NewAppError(core.ErrCodeBadRequest, authconstants.ErrInvalidToken)
+	}
+
+	if token.GetUsed() {
+		return core.NewAppError(core.ErrCodeBadRequest, authconstants.ErrTokenAlreadyUsed)
+	}
+
+	if token.IsExpired() {
+		return core.NewAppError(core.ErrCodeBadRequest, authconstants.ErrTokenExpired)
+	}
+
+	account, err := s.accountRepo.GetByID(ctx, token.GetAccountID())
+	if err != nil {
+		return core.NewAppError(core.ErrCodeNotFound, authconstants.ErrAccountNotFound)
+	}
+
+	if err := s.tokenRepo.MarkAsUsed(ctx, token.GetID()); err != nil {
+		return core.NewAppError(core.ErrCodeBadRequest, authconstants.ErrTokenAlreadyUsed)
+	}
+
+	s.clearFailedLogins(ctx, account.GetEmail())
+	return nil
+}
+
+// checkLoginAllowed rejects a login attempt for email while the client IP
+// or the account is locked, or while the delay after the last failure has
+// not passed yet.
+func (s *AuthService) checkLoginAllowed(ctx context.Context, email string) error {
+	if s.loginAttempts == nil {
+		return nil
+	}
+
+	now := time.Now()
+
+	if key := ipAttemptKey(ctx); key != "" && s.config.GetMaxFailedLoginsPerIP() > 0 {
+		attempts, err := s.loginAttempts.Get(ctx, key)
+		if err != nil {
+			fmt.Printf("Failed to load login attempts: %v\n", err)
+		} else if attempts.IsLocked(now) {
+			return loginBlocked(core.ErrCodeTooManyRequests, authconstants.ErrTooManyLoginAttempts, attempts.LockedUntil.Sub(now))
+		}
+	}
+
+	if s.config.GetMaxFailedLogins() <= 0 {
+		return nil
+	}
+
+	attempts, err := s.loginAttempts.Get(ctx, accountAttemptKey(email))
+	if err != nil {
+		fmt.Printf("Failed to load login attempts: %v\n", err)
+		return nil
+	}
+
+	if attempts.IsLocked(now) {
+		return loginBlocked(core.ErrCodeAccountLocked, authconstants.ErrAccountLocked, attempts.LockedUntil.Sub(now))
+	}
+
+	if attempts.Failures > 0 {
+		next := attempts.LastFailureAt.Add(s.loginDelay(attempts.Failures))
+		if now.Before(next) {
+			return loginBlocked(core.ErrCodeTooManyRequests, authconstants.ErrTooManyLoginAttempts, next.Sub(now))
+		}
+	}
+
+	return nil
+}
+
+// recordFailedLogin counts a failed login against email and the client IP
+// and locks whichever reached its threshold. Unknown emails are tracked
+// too, so lockouts do not reveal which accounts exist.
+func (s *AuthService) recordFailedLogin(ctx context.Context, email string) {
+	if s.loginAttempts == nil {
+		return
+	}
+
+	window := s.config.GetFailedLoginWindow()
+	until := time.Now().Add(s.config.GetLockoutDuration())
+
+	if key := ipAttemptKey(ctx); key != "" && s.config.GetMaxFailedLoginsPerIP() > 0 {
+		attempts, err := s.loginAttempts.RecordFailure(ctx, key, window)
+		if err != nil {
+			fmt.Printf("Failed to record login failure: %v\n", err)
+		} else if attempts.Failures >= s.config.GetMaxFailedLoginsPerIP() {
+			if err := s.loginAttempts.Lock(ctx, key, until); err != nil {
+				fmt.Printf("Failed to lock IP address: %v\n", err)
+			}
+		}
+	}
+
+	if s.config.GetMaxFailedLogins() <= 0 {
+		return
+	}
+
+	key := accountAttemptKey(email)
+	attempts, err := s.loginAttempts.RecordFailure(ctx, key, window)
+	if err != nil {
+		fmt.Printf("Failed to record login failure: %v\n", err)
+		return
+	}
+
+	if attempts.Failures < s.config.GetMaxFailedLogins() {
+		return
+	}
+
+	if err := s.loginAttempts.Lock(ctx, key, until); err != nil {
+		fmt.Printf("Failed to lock account: %v\n", err)
+		return
+	}
+
+	s.accountLocked(ctx, email, until)
+}
+
+func (s *AuthService) clearFailedLogins(ctx context.Context, email string) {
+	if s.loginAttempts == nil {
+		return
+	}
+
+	if err := s.loginAttempts.Reset(ctx, accountAttemptKey(email)); err != nil {
+		fmt.Printf("Failed to reset login attempts: %v\n", err)
+	}
+}
+
+// accountLocked reports the lockout and emails the owner a link that
+// lifts it early.
+func (s *AuthService) accountLocked(ctx context.Context, email string, until time.Time) {
+	account, err := s.accountRepo.GetByEmail(ctx, email)
+	if err != nil {
+		return
+	}
+
+	s.emitEvent(ctx, authinterface.AuthEvent{
+		Type:      authinterface.AuthEventAccountLocked,
+		AccountID: account.GetID(),
+		Metadata: map[string]any{
+			"locked_until": until,
+		},
+	})
+
+	token := &authmodel.Token{
+		ID:        uuid.New(),
+		AccountID: account.GetID(),
+		Token:     s.tokenGenerator.GenerateSecureToken(),
+		Type:      authinterface.TokenTypeAccountUnlock,
+		ExpiresAt: until,
+	}
+
+	if err := s.tokenRepo.Create(ctx, token); err != nil {
+		fmt.Printf("Failed to create unlock token: %v\n", err)
+		return
+	}
+
+	if err := s.emailSender.SendAccountUnlockEmail(account.GetEmail(), token.Token); err != nil {
+		fmt.Printf("Failed to send account unlock email: %v\n", err)
+	}
+}
+
+// loginDelay doubles the configured base delay with every failure.
+func (s *AuthService) loginDelay(failures int) time.Duration {
+	delay := s.config.GetLoginDelayBase()
+	for i := 1; i < failures && delay < maxLoginDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxLoginDelay {
+		delay = maxLoginDelay
+	}
+	return delay
+}
+
+func loginBlocked(code, message string, retryAfter time.Duration) error {
+	return core.NewAppError(code, message, &authinterface.LoginBlockedError{RetryAfter: retryAfter})
+}
+
+func accountAttemptKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipAttemptKey(ctx context.Context) string {
+	ip := authinterface.ClientInfoFromContext(ctx).IPAddress
+	if ip == "" {
+		return ""
+	}
+	return "ip:" + ip
+}
@@ -0,0 +1,212 @@
+package authservice
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	authconstants "{{.Project.GoModule}}/internal/auth/constants"
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	authmodel "{{.Project.GoModule}}/internal/auth/model"
+	"{{.Project.GoModule}}/internal/core"
+)
+
+const throttlePassword = "correct-password"
+
+// newThrottleTestService signs in with email and password and has no login
+// delay unless a test sets one.
+func newThrottleTestService(t *testing.T) *testService {
+	t.Helper()
+
+	ts := newTestService(t, AuthServiceDeps{})
+	registry := NewStrategyRegistry()
+	if err := registry.Register(NewEmailPasswordStrategy(ts.accounts, ts.passwordHasher)); err != nil {
+		t.Fatalf("register strategy: %v", err)
+	}
+	ts.strategyRegistry = registry
+	ts.config.loginDelayBase = 0
+	return ts
+}
+
+func (ts *testService) createAccountWithPassword(t *testing.T, email string) *authmodel.Account {
+	t.Helper()
+
+	account := ts.createAccount(t, email)
+	hash, err := ts.passwordHasher.Hash(throttlePassword)
+	if err != nil {
+		t.Fatalf("hash password: %v", err)
+	}
+	account.PasswordHash = hash
+	ts.accounts.Update(context.Background(), account)
+	return account
+}
+
+func (ts *testService) loginWithPassword(ctx context.Context, email, password string) error {
+	_, err := ts.Login(ctx, &authmodel.LoginRequest{
+		Credentials: map[string]any{"email": email, "password": password},
+	})
+	return err
+}
+
+func fromIP(ip string) context.Context {
+	return authinterface.WithClientInfo(context.Background(), authinterface.ClientInfo{IPAddress: ip})
+}
+
+// assertBlocked checks err is a throttling error with code and a retry hint
+// of roughly retryAfter.
+func assertBlocked(t *testing.T, err error, code string, retryAfter time.Duration) {
+	t.Helper()
+
+	var appErr *core.AppError
+	if !errors.As(err, &appErr) || appErr.Code != code {
+		t.Fatalf("error = %v, want %s", err, code)
+	}
+
+	var blocked *authinterface.LoginBlockedError
+	if !errors.As(err, &blocked) {
+		t.Fatalf("error = %v, want a retry hint", err)
+	}
+	if blocked.RetryAfter <= 0 || blocked.RetryAfter > retryAfter || blocked.RetryAfter < retryAfter-time.Second {
+		t.Fatalf("retry after = %s, want about %s", blocked.RetryAfter, retryAfter)
+	}
+}
+
+func TestLoginDelay(t *testing.T) {
+	ts := newThrottleTestService(t)
+	ts.config.loginDelayBase = 30 * time.Second
+	account := ts.createAccountWithPassword(t, "user@example.com")
+	ctx := context.Background()
+
+	err := ts.loginWithPassword(ctx, account.Email, "wrong-password")
+	assertAppError(t, err, authconstants.ErrInvalidCredentials)
+
+	err = ts.loginWithPassword(ctx, account.Email, throttlePassword)
+	assertBlocked(t, err, core.ErrCodeTooManyRequests, 30*time.Second)
+}
+
+func TestLoginDelayDoubles(t *testing.T) {
+	ts := newThrottleTestService(t)
+	ts.config.loginDelayBase = time.Second
+
+	for failures, want := range map[int]time.Duration{
+		1:  time.Second,
+		2:  2 * time.Second,
+		3:  4 * time.Second,
+		7:  time.Minute,
+		40: time.Minute,
+	} {
+		if got := ts.loginDelay(failures); got != want {
+			t.Errorf("delay after %d failures = %s, want %s", failures, got, want)
+		}
+	}
+}
+
+func TestSuccessfulLoginClearsFailures(t *testing.T) {
+	ts := newThrottleTestService(t)
+	ts.config.maxFailedLogins = 3
+	account := ts.createAccountWithPassword(t, "user@example.com")
+	ctx := context.Background()
+
+	for round := 0; round < 3; round++ {
+		for i := 0; i < 2; i++ {
+			ts.loginWithPassword(ctx, account.Email, "wrong-password")
+		}
+		if err := ts.loginWithPassword(ctx, account.Email, throttlePassword); err != nil {
+			t.Fatalf("round %d: Login: %v", round, err)
+		}
+	}
+}
+
+func TestAccountLockout(t *testing.T) {
+	ts := newThrottleTestService(t)
+	ts.config.maxFailedLogins = 3
+	account := ts.createAccountWithPassword(t, "user@example.com")
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		err := ts.loginWithPassword(ctx, account.Email, "wrong-password")
+		assertAppError(t, err, authconstants.ErrInvalidCredentials)
+	}
+
+	err := ts.loginWithPassword(ctx, account.Email, throttlePassword)
+	assertBlocked(t, err, core.ErrCodeAccountLocked, ts.config.lockoutDuration)
+
+	if len(ts.emails.unlocks) != 1 || ts.emails.unlocks[0] != account.Email {
+		t.Fatalf("unlock emails = %v, want one to %s", ts.emails.unlocks, account.Email)
+	}
+}
+
+func TestUnknownEmailLockout(t *testing.T) {
+	ts := newThrottleTestService(t)
+	ts.config.maxFailedLogins = 3
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		ts.loginWithPassword(ctx, "nobody@example.com", "wrong-password")
+	}
+
+	err := ts.loginWithPassword(ctx, "nobody@example.com", "wrong-password")
+	assertBlocked(t, err, core.ErrCodeAccountLocked, ts.config.lockoutDuration)
+	if len(ts.emails.unlocks) != 0 {
+		t.Fatalf("unlock emails = %v, want none for an unknown email", ts.emails.unlocks)
+	}
+}
+
+func TestUnlockAccount(t *testing.T) {
+	ts := newThrottleTestService(t)
+	ts.config.maxFailedLogins = 3
+	account := ts.createAccountWithPassword(t, "user@example.com")
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		ts.loginWithPassword(ctx, account.Email, "wrong-password")
+	}
+
+	tokens, _ := ts.tokens.GetByAccountAndType(ctx, account.ID, authinterface.TokenTypeAccountUnlock)
+	if len(tokens) != 1 {
+		t.Fatalf("unlock tokens = %d, want 1", len(tokens))
+	}
+	unlock := tokens[0].GetToken()
+
+	if err := ts.UnlockAccount(ctx, unlock); err != nil {
+		t.Fatalf("UnlockAccount: %v", err)
+	}
+	if err := ts.loginWithPassword(ctx, account.Email, throttlePassword); err != nil {
+		t.Fatalf("Login after unlock: %v", err)
+	}
+
+	err := ts.UnlockAccount(ctx, unlock)
+	assertAppError(t, err, authconstants.ErrTokenAlreadyUsed)
+
+	other := &authmodel.Token{
+		ID:        uuid.New(),
+		AccountID: account.ID,
+		Token:     "reset-token",
+		Type:      authinterface.TokenTypePasswordReset,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	ts.tokens.Create(ctx, other)
+	err = ts.UnlockAccount(ctx, other.Token)
+	assertAppError(t, err, authconstants.ErrInvalidToken)
+}
+
+func TestIPLockout(t *testing.T) {
+	ts := newThrottleTestService(t)
+	ts.config.maxFailedLoginsPerIP = 3
+	ts.config.maxFailedLogins = 100
+	target := ts.createAccountWithPassword(t, "target@example.com")
+
+	attacker := fromIP("203.0.113.7")
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		ts.loginWithPassword(attacker, email, "wrong-password")
+	}
+
+	err := ts.loginWithPassword(attacker, target.Email, throttlePassword)
+	assertBlocked(t, err, core.ErrCodeTooManyRequests, ts.config.lockoutDuration)
+
+	if err := ts.loginWithPassword(fromIP("198.51.100.1"), target.Email, throttlePassword); err != nil {
+		t.Fatalf("Login from another IP: %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package authservice
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	authconstants "{{.Project.GoModule}}/internal/auth/constants"
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	authmodel "{{.Project.GoModule}}/internal/auth/model"
+	"{{.Project.GoModule}}/internal/core"
+)
+
+// authenticateOAuth is the Authenticate implementation shared by the OAuth
+// strategies. The service puts the stored PKCE verifier, nonce and, when
+// linking, the account id into credentials after checking the state.
+func authenticateOAuth(
+	ctx context.Context,
+	strategy authinterface.OAuthStrategy,
+	accountRepo authinterface.AccountRepository,
+	identityRepo authinterface.IdentityRepository,
+	credentials map[string]any,
+) (*authinterface.AuthResult, error) {
+	code, ok := credentials["code"].(string)
+	if !ok || code == "" {
+		return nil, fmt.Errorf("authorization code is required")
+	}
+
+	exchange := authinterface.OAuthExchange{Code: code}
+	exchange.CodeVerifier, _ = credentials["code_verifier"].(string)
+	exchange.Nonce, _ = credentials["nonce"].(string)
+	linkAccountID, _ := credentials["link_account_id"].(uuid.UUID)
+
+	tokens, err := strategy.ExchangeCode(ctx, exchange)
+	if err != nil {
+		return nil, core.NewAppError(core.ErrCodeUnauthorized, "failed to exchange authorization code")
+	}
+
+	userInfo, err := strategy.GetUserInfo(ctx, tokens)
+	if err != nil {
+		return nil, core.NewAppError(core.ErrCodeUnauthorized, "failed to get user information")
+	}
+
+	account, isNew, err := resolveOAuthAccount(ctx, accountRepo, identityRepo, userInfo, linkAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	return oauthResult(account, isNew, userInfo), nil
+}
+
+// resolveOAuthAccount finds the account for a provider user through its
+// linked identity. Without one, an identity is created for the account
+// being linked, for an existing account whose email both sides verified,
+// or for a new account. Unverified emails are never used to match, since
+// anyone could claim an existing account by registering its address.
+func resolveOAuthAccount(
+	ctx context.Context,
+	accountRepo authinterface.AccountRepository,
+	identityRepo authinterface.IdentityRepository,
+	userInfo *authinterface.OAuthUserInfo,
+	linkAccountID uuid.UUID,
+) (authinterface.Account, bool, error) {
+	if userInfo.ID == "" {
+		return nil, false, core.NewAppError(core.ErrCodeUnauthorized, "provider did not return a user id")
+	}
+
+	identity, err := identityRepo.GetByProvider(ctx, userInfo.Provider, userInfo.ID)
+	if err == nil {
+		if linkAccountID != uuid.Nil && identity.GetAccountID() != linkAccountID {
+			return nil, false, core.NewAppError(core.ErrCodeConflict, authconstants.ErrIdentityAlreadyLinked)
+		}
+
+		account, err := accountRepo.GetByID(ctx, identity.GetAccountID())
+		if err != nil {
+			return nil, false, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrAccountNotFound)
+		}
+		return account, false, nil
+	}
+
+	if linkAccountID != uuid.Nil {
+		account, err := accountRepo.GetByID(ctx, linkAccountID)
+		if err != nil {
+			return nil, false, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrAccountNotFound)
+		}
+		if err := createIdentity(ctx, identityRepo, account.GetID(), userInfo); err != nil {
+			return nil, false, err
+		}
+		return account, false, nil
+	}
+
+	if userInfo.Email == "" || !userInfo.EmailVerified {
+		return nil, false, core.NewAppError(core.ErrCodeUnauthorized, "provider did not return a verified email")
+	}
+
+	email := strings.ToLower(strings.TrimSpace(userInfo.Email))
+
+	// An unverified local account may have been registered by someone else
+	// to catch the owner's first social login, so it is never linked
+	// automatically.
+	if account, err := accountRepo.GetByEmail(ctx, email); err == nil {
+		if !account.GetEmailVerified() {
+			return nil, false, core.NewAppError(core.ErrCodeConflict, authconstants.ErrOAuthAccountExists)
+		}
+		if err := createIdentity(ctx, identityRepo, account.GetID(), userInfo); err != nil {
+			return nil, false, err
+		}
+		return account, false, nil
+	}
+
+	newAccount := &authmodel.Account{
+		ID:            uuid.New(),
+		Email:         email,
+		EmailVerified: true,
+	}
+	if err := accountRepo.Create(ctx, newAccount); err != nil {
+		return nil, false, core.NewAppError(core.ErrCodeInternalServer, "failed to create account")
+	}
+
+	if err := createIdentity(ctx, identityRepo, newAccount.ID, userInfo); err != nil {
+		return nil, false, err
+	}
+
+	return newAccount, true, nil
+}
+
+func createIdentity(ctx context.Context, identityRepo authinterface.IdentityRepository, accountID uuid.UUID, userInfo *authinterface.OAuthUserInfo) error {
+	identity := &authmodel.Identity{
+		ID:             uuid.New(),
+		AccountID:      accountID,
+		Provider:       userInfo.Provider,
+		ProviderUserID: userInfo.ID,
+		Email:          strings.ToLower(strings.TrimSpace(userInfo.Email)),
+	}
+	if err := identityRepo.Create(ctx, identity); err != nil {
+		return core.NewAppError(core.ErrCodeInternalServer, "failed to link identity")
+	}
+	return nil
+}
+
+func oauthResult(account authinterface.Account, isNew bool, userInfo *authinterface.OAuthUserInfo) *authinterface.AuthResult {
+	metadata := map[string]any{
+		"auth_method": userInfo.Provider + "_oauth",
+		"provider":    userInfo.Provider,
+		"provider_id": userInfo.ID,
+		"name":        userInfo.Name,
+		"picture":     userInfo.Picture,
+	}
+	if isNew {
+		metadata["is_new_account"] = true
+	}
+
+	return &authinterface.AuthResult{
+		AccountID:         account.GetID(),
+		Account:           account,
+		NeedsVerification: false,
+		Metadata:          metadata,
+	}
+}
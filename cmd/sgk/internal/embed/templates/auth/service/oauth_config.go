@@ -1,5 +1,7 @@
 package authservice
 
+import "net/http"
+
 type OAuthConfig struct {
 	Google   *GoogleOAuthConfig   `json:"google,omitempty"`
 	GitHub   *GitHubOAuthConfig   `json:"github,omitempty"`
@@ -13,11 +15,18 @@ type GoogleOAuthConfig struct {
 	Enabled      bool   `json:"enabled"`
 }
 
+// GitHubOAuthConfig configures the GitHub strategy. The endpoint URLs
+// default to github.com and only need to be set for GitHub Enterprise or
+// a fake provider.
 type GitHubOAuthConfig struct {
-	ClientID     string `json:"client_id"`
-	ClientSecret string `json:"client_secret"`
-	RedirectURI  string `json:"redirect_uri"`
-	Enabled      bool   `json:"enabled"`
+	ClientID     string       `json:"client_id"`
+	ClientSecret string       `json:"client_secret"`
+	RedirectURI  string       `json:"redirect_uri"`
+	Enabled      bool         `json:"enabled"`
+	AuthURL      string       `json:"auth_url,omitempty"`
+	TokenURL     string       `json:"token_url,omitempty"`
+	APIURL       string       `json:"api_url,omitempty"`
+	HTTPClient   *http.Client `json:"-"`
 }
 
 type FacebookOAuthConfig struct {
@@ -25,4 +34,16 @@ type FacebookOAuthConfig struct {
 	ClientSecret string `json:"client_secret"`
 	RedirectURI  string `json:"redirect_uri"`
 	Enabled      bool   `json:"enabled"`
+}
+
+// OIDCConfig configures a generic OpenID Connect provider. Endpoints and
+// signing keys are discovered from IssuerURL.
+type OIDCConfig struct {
+	Name         string       `json:"name"`
+	IssuerURL    string       `json:"issuer_url"`
+	ClientID     string       `json:"client_id"`
+	ClientSecret string       `json:"client_secret"`
+	RedirectURI  string       `json:"redirect_uri"`
+	Scopes       []string     `json:"scopes,omitempty"`
+	HTTPClient   *http.Client `json:"-"`
 }
\ No newline at end of file
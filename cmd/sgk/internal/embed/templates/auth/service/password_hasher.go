@@ -1,10 +1,26 @@
 package authservice
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
 	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
+var errPasswordMismatch = errors.New("password does not match")
+
+// hashIdentifier is implemented by hashers that recognise their own hash
+// format, which lets MultiPasswordHasher pick one without trying each.
+type hashIdentifier interface {
+	Identifies(hash string) bool
+}
+
 type BcryptPasswordHasher struct {
 	cost int
 }
@@ -23,4 +39,176 @@ func (h *BcryptPasswordHasher) Hash(password string) (string, error) {
 
 func (h *BcryptPasswordHasher) Verify(password, hash string) error {
 	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-}
\ No newline at end of file
+}
+
+// NeedsRehash reports hashes made with a lower cost. Higher costs are kept
+// so lowering the setting never weakens stored hashes.
+func (h *BcryptPasswordHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost < h.cost
+}
+
+func (h *BcryptPasswordHasher) Identifies(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+type Argon2idPasswordHasher struct {
+	params authinterface.Argon2Params
+}
+
+// NewArgon2idPasswordHasher hashes passwords with Argon2id and stores them
+// in the PHC string format, e.g. $argon2id$v=19$m=65536,t=3,p=2$salt$hash.
+func NewArgon2idPasswordHasher(params authinterface.Argon2Params) authinterface.PasswordHasher {
+	defaults := DefaultArgon2Params()
+	if params.Memory == 0 {
+		params.Memory = defaults.Memory
+	}
+	if params.Iterations == 0 {
+		params.Iterations = defaults.Iterations
+	}
+	if params.Parallelism == 0 {
+		params.Parallelism = defaults.Parallelism
+	}
+	if params.SaltLength == 0 {
+		params.SaltLength = defaults.SaltLength
+	}
+	if params.KeyLength == 0 {
+		params.KeyLength = defaults.KeyLength
+	}
+	return &Argon2idPasswordHasher{params: params}
+}
+
+func DefaultArgon2Params() authinterface.Argon2Params {
+	return authinterface.Argon2Params{
+		Memory:      64 * 1024,
+		Iterations:  3,
+		Parallelism: 2,
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+}
+
+func (h *Argon2idPasswordHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.params.Iterations, h.params.Memory, h.params.Parallelism, h.params.KeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version,
+		h.params.Memory,
+		h.params.Iterations,
+		h.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (h *Argon2idPasswordHasher) Verify(password, hash string) error {
+	params, salt, key, err := parseArgon2idHash(hash)
+	if err != nil {
+		return err
+	}
+
+	computed := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	if subtle.ConstantTimeCompare(computed, key) != 1 {
+		return errPasswordMismatch
+	}
+	return nil
+}
+
+// NeedsRehash reports hashes whose parameters differ from the configured
+// ones, in either direction, so tuning takes effect on the next login.
+func (h *Argon2idPasswordHasher) NeedsRehash(hash string) bool {
+	params, salt, _, err := parseArgon2idHash(hash)
+	if err != nil {
+		return true
+	}
+	return params.Memory != h.params.Memory ||
+		params.Iterations != h.params.Iterations ||
+		params.Parallelism != h.params.Parallelism ||
+		params.KeyLength != h.params.KeyLength ||
+		uint32(len(salt)) != h.params.SaltLength
+}
+
+func (h *Argon2idPasswordHasher) Identifies(hash string) bool {
+	return strings.HasPrefix(hash, "$argon2id$")
+}
+
+func parseArgon2idHash(hash string) (authinterface.Argon2Params, []byte, []byte, error) {
+	var params authinterface.Argon2Params
+
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return params, nil, nil, fmt.Errorf("not an argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("unsupported argon2 version")
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2 parameters: %w", err)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2 salt: %w", err)
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, fmt.Errorf("invalid argon2 hash")
+	}
+
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+	return params, salt, key, nil
+}
+
+// MultiPasswordHasher hashes new passwords with its primary hasher and
+// verifies hashes made by any of its hashers, chosen by the hash prefix.
+// Hashes not made by the primary hasher need a rehash, which is how
+// accounts migrate to a new algorithm as users sign in.
+type MultiPasswordHasher struct {
+	primary authinterface.PasswordHasher
+	hashers []authinterface.PasswordHasher
+}
+
+func NewMultiPasswordHasher(primary authinterface.PasswordHasher, legacy ...authinterface.PasswordHasher) authinterface.PasswordHasher {
+	return &MultiPasswordHasher{
+		primary: primary,
+		hashers: append([]authinterface.PasswordHasher{primary}, legacy...),
+	}
+}
+
+func (h *MultiPasswordHasher) Hash(password string) (string, error) {
+	return h.primary.Hash(password)
+}
+
+func (h *MultiPasswordHasher) Verify(password, hash string) error {
+	hasher := h.hasherFor(hash)
+	if hasher == nil {
+		return fmt.Errorf("unsupported password hash format")
+	}
+	return hasher.Verify(password, hash)
+}
+
+func (h *MultiPasswordHasher) NeedsRehash(hash string) bool {
+	if h.hasherFor(hash) != h.primary {
+		return true
+	}
+	return h.primary.NeedsRehash(hash)
+}
+
+func (h *MultiPasswordHasher) hasherFor(hash string) authinterface.PasswordHasher {
+	for _, hasher := range h.hashers {
+		if identifier, ok := hasher.(hashIdentifier); ok && identifier.Identifies(hash) {
+			return hasher
+		}
+	}
+	return nil
+}
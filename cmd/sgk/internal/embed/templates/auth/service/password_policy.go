@@ -0,0 +1,201 @@
+package authservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	authconstants "{{.Project.GoModule}}/internal/auth/constants"
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	"{{.Project.GoModule}}/internal/core"
+)
+
+// minUserInputLength keeps short values, such as a two letter email local
+// part, from rejecting unrelated passwords.
+const minUserInputLength = 3
+
+type DefaultPasswordPolicy struct {
+	rules          authinterface.PasswordRules
+	passwordHasher authinterface.PasswordHasher
+	historyRepo    authinterface.PasswordHistoryRepository
+	breached       authinterface.BreachedPasswordChecker
+}
+
+// NewDefaultPasswordPolicy enforces rules. historyRepo and breached may be
+// nil to skip the reuse and breach checks.
+func NewDefaultPasswordPolicy(
+	rules authinterface.PasswordRules,
+	passwordHasher authinterface.PasswordHasher,
+	historyRepo authinterface.PasswordHistoryRepository,
+	breached authinterface.BreachedPasswordChecker,
+) authinterface.PasswordPolicy {
+	return &DefaultPasswordPolicy{
+		rules:          rules,
+		passwordHasher: passwordHasher,
+		historyRepo:    historyRepo,
+		breached:       breached,
+	}
+}
+
+func (p *DefaultPasswordPolicy) Validate(ctx context.Context, candidate authinterface.PasswordCandidate) error {
+	var violations []authinterface.PasswordViolation
+	violate := func(code, message string) {
+		violations = append(violations, authinterface.PasswordViolation{Code: code, Message: message})
+	}
+
+	password := candidate.Password
+	length := utf8.RuneCountInString(password)
+	if length < p.rules.MinLength {
+		violate(authinterface.PasswordViolationTooShort, fmt.Sprintf("password must be at least %d characters long", p.rules.MinLength))
+	}
+	if p.rules.MaxLength > 0 && length > p.rules.MaxLength {
+		violate(authinterface.PasswordViolationTooLong, fmt.Sprintf("password must be at most %d characters long", p.rules.MaxLength))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if p.rules.RequireUppercase && !hasUpper {
+		violate(authinterface.PasswordViolationMissingUppercase, "password must contain an uppercase letter")
+	}
+	if p.rules.RequireLowercase && !hasLower {
+		violate(authinterface.PasswordViolationMissingLowercase, "password must contain a lowercase letter")
+	}
+	if p.rules.RequireDigit && !hasDigit {
+		violate(authinterface.PasswordViolationMissingDigit, "password must contain a digit")
+	}
+	if p.rules.RequireSymbol && !hasSymbol {
+		violate(authinterface.PasswordViolationMissingSymbol, "password must contain a symbol")
+	}
+
+	if p.rules.DisallowUserInputs && containsUserInput(password, candidate.UserInputs) {
+		violate(authinterface.PasswordViolationUserInput, "password must not contain your email or name")
+	}
+
+	if p.breached != nil && p.breached.IsBreached(password) {
+		violate(authinterface.PasswordViolationBreached, "password has appeared in a data breach, choose a different one")
+	}
+
+	reused, err := p.isReused(ctx, candidate)
+	if err != nil {
+		return err
+	}
+	if reused {
+		violate(authinterface.PasswordViolationReused, fmt.Sprintf("password must differ from your last %d passwords", p.rules.HistorySize))
+	}
+
+	if len(violations) > 0 {
+		return &authinterface.PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+func (p *DefaultPasswordPolicy) Remember(ctx context.Context, accountID uuid.UUID, passwordHash string) error {
+	if p.historyRepo == nil || p.rules.HistorySize <= 0 {
+		return nil
+	}
+
+	if err := p.historyRepo.Add(ctx, accountID, passwordHash); err != nil {
+		return fmt.Errorf("failed to store password history: %w", err)
+	}
+	return p.historyRepo.Prune(ctx, accountID, p.rules.HistorySize)
+}
+
+func (p *DefaultPasswordPolicy) Forget(ctx context.Context, accountID uuid.UUID) error {
+	if p.historyRepo == nil {
+		return nil
+	}
+	return p.historyRepo.DeleteByAccount(ctx, accountID)
+}
+
+// isReused compares the password with the current hash and the recent
+// history. The current hash is checked separately so accounts created
+// before history was enabled are covered too.
+func (p *DefaultPasswordPolicy) isReused(ctx context.Context, candidate authinterface.PasswordCandidate) (bool, error) {
+	if p.historyRepo == nil || p.rules.HistorySize <= 0 || candidate.AccountID == uuid.Nil {
+		return false, nil
+	}
+
+	hashes, err := p.historyRepo.ListRecent(ctx, candidate.AccountID, p.rules.HistorySize)
+	if err != nil {
+		return false, fmt.Errorf("failed to load password history: %w", err)
+	}
+	if candidate.CurrentHash != "" {
+		hashes = append(hashes, candidate.CurrentHash)
+	}
+
+	for _, hash := range hashes {
+		if p.passwordHasher.Verify(candidate.Password, hash) == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func containsUserInput(password string, inputs []string) bool {
+	lower := strings.ToLower(password)
+	for _, input := range inputs {
+		input = strings.ToLower(strings.TrimSpace(input))
+		if utf8.RuneCountInString(input) >= minUserInputLength && strings.Contains(lower, input) {
+			return true
+		}
+	}
+	return false
+}
+
+// passwordUserInputs lists the parts of an account's email and phone a
+// password must not contain.
+func passwordUserInputs(email, phone string) []string {
+	inputs := []string{email}
+	if local, _, ok := strings.Cut(email, "@"); ok {
+		inputs = append(inputs, local)
+	}
+	if phone != "" {
+		inputs = append(inputs, strings.TrimPrefix(phone, "+"))
+	}
+	return inputs
+}
+
+// checkPasswordPolicy turns policy violations into a validation error that
+// keeps the individual violations as its cause.
+func (s *AuthService) checkPasswordPolicy(ctx context.Context, candidate authinterface.PasswordCandidate) error {
+	err := s.passwordPolicy.Validate(ctx, candidate)
+	if err == nil {
+		return nil
+	}
+
+	var policyErr *authinterface.PasswordPolicyError
+	if errors.As(err, &policyErr) {
+		return core.NewAppError(core.ErrCodeValidation, authconstants.ErrPasswordTooWeak, policyErr)
+	}
+	return core.NewAppError(core.ErrCodeInternalServer, "failed to check password", err)
+}
+
+func (s *AuthService) passwordCandidate(account authinterface.Account, password string) authinterface.PasswordCandidate {
+	return authinterface.PasswordCandidate{
+		Password:    password,
+		AccountID:   account.GetID(),
+		CurrentHash: account.GetPasswordHash(),
+		UserInputs:  passwordUserInputs(account.GetEmail(), account.GetPhone()),
+	}
+}
+
+func (s *AuthService) rememberPassword(ctx context.Context, accountID uuid.UUID, passwordHash string) {
+	if err := s.passwordPolicy.Remember(ctx, accountID, passwordHash); err != nil {
+		fmt.Printf("Failed to remember password: %v\n", err)
+	}
+}
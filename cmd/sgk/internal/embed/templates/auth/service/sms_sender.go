@@ -0,0 +1,88 @@
+package authservice
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+)
+
+type MockSMSSender struct{}
+
+// NewMockSMSSender prints messages instead of sending them, for development.
+func NewMockSMSSender() authinterface.SMSSender {
+	return &MockSMSSender{}
+}
+
+func (s *MockSMSSender) SendVerificationSMS(phone, code string) error {
+	fmt.Printf("Sending verification SMS to %s\n", phone)
+	fmt.Printf("Verification code: %s\n", code)
+	return nil
+}
+
+type HTTPSMSConfig struct {
+	URL        string
+	APIKey     string
+	From       string
+	HTTPClient *http.Client
+}
+
+// HTTPSMSSender posts messages as JSON to an SMS provider or a small relay
+// in front of one:
+//
+//	POST <URL>
+//	Authorization: Bearer <APIKey>
+//	{"to": "+15551234567", "from": "<From>", "body": "..."}
+//
+// Any 2xx response counts as accepted.
+type HTTPSMSSender struct {
+	config HTTPSMSConfig
+}
+
+func NewHTTPSMSSender(config HTTPSMSConfig) authinterface.SMSSender {
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &HTTPSMSSender{config: config}
+}
+
+func (s *HTTPSMSSender) SendVerificationSMS(phone, code string) error {
+	return s.send(phone, fmt.Sprintf("Your verification code is %s", code))
+}
+
+func (s *HTTPSMSSender) send(to, body string) error {
+	payload, err := json.Marshal(map[string]string{
+		"to":   to,
+		"from": s.config.From,
+		"body": body,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.config.URL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create SMS request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.config.APIKey)
+	}
+
+	resp, err := s.config.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send SMS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("SMS provider returned %s: %s", resp.Status, bytes.TrimSpace(detail))
+	}
+
+	return nil
+}
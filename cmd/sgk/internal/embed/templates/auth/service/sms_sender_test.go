@@ -0,0 +1,121 @@
+package authservice
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPSMSSender(t *testing.T) {
+	var got struct {
+		method        string
+		contentType   string
+		authorization string
+		body          map[string]string
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got.method = r.Method
+		got.contentType = r.Header.Get("Content-Type")
+		got.authorization = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&got.body); err != nil {
+			t.Errorf("decode request body: %v", err)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	sender := NewHTTPSMSSender(HTTPSMSConfig{URL: server.URL, APIKey: "sms-key", From: "Acme"})
+	if err := sender.SendVerificationSMS("+15551234567", "123456"); err != nil {
+		t.Fatalf("SendVerificationSMS: %v", err)
+	}
+
+	if got.method != http.MethodPost {
+		t.Errorf("method = %s, want POST", got.method)
+	}
+	if got.contentType != "application/json" {
+		t.Errorf("content type = %q, want application/json", got.contentType)
+	}
+	if got.authorization != "Bearer sms-key" {
+		t.Errorf("authorization = %q, want the API key as a bearer token", got.authorization)
+	}
+	want := map[string]string{
+		"to":   "+15551234567",
+		"from": "Acme",
+		"body": "Your verification code is 123456",
+	}
+	for field, value := range want {
+		if got.body[field] != value {
+			t.Errorf("body %s = %q, want %q", field, got.body[field], value)
+		}
+	}
+	if len(got.body) != len(want) {
+		t.Errorf("body = %v, want only %v", got.body, want)
+	}
+}
+
+func TestHTTPSMSSenderWithoutAPIKey(t *testing.T) {
+	var authorization []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Values("Authorization")
+	}))
+	defer server.Close()
+
+	sender := NewHTTPSMSSender(HTTPSMSConfig{URL: server.URL})
+	if err := sender.SendVerificationSMS("+15551234567", "123456"); err != nil {
+		t.Fatalf("SendVerificationSMS: %v", err)
+	}
+	if len(authorization) != 0 {
+		t.Fatalf("authorization = %v, want none without an API key", authorization)
+	}
+}
+
+func TestHTTPSMSSenderErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   []string
+	}{
+		{"client error", http.StatusBadRequest, `{"error":"invalid number"}` + "\n", []string{"400 Bad Request", `{"error":"invalid number"}`}},
+		{"auth error", http.StatusUnauthorized, "bad key", []string{"401 Unauthorized", "bad key"}},
+		{"server error", http.StatusBadGateway, "", []string{"502 Bad Gateway"}},
+		{"redirect", http.StatusNotModified, "", []string{"304 Not Modified"}},
+		{"long detail", http.StatusInternalServerError, strings.Repeat("x", 2048), []string{"500 Internal Server Error", strings.Repeat("x", 512)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			err := NewHTTPSMSSender(HTTPSMSConfig{URL: server.URL}).SendVerificationSMS("+15551234567", "123456")
+			if err == nil {
+				t.Fatalf("SendVerificationSMS accepted a %d response", tt.status)
+			}
+			for _, part := range tt.want {
+				if !strings.Contains(err.Error(), part) {
+					t.Errorf("error = %q, want it to contain %q", err, part)
+				}
+			}
+			if len(err.Error()) > 600 {
+				t.Errorf("error is %d bytes long, want the provider detail truncated", len(err.Error()))
+			}
+		})
+	}
+}
+
+func TestHTTPSMSSenderUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	err := NewHTTPSMSSender(HTTPSMSConfig{URL: server.URL}).SendVerificationSMS("+15551234567", "123456")
+	if err == nil || !strings.Contains(err.Error(), "failed to send SMS") {
+		t.Fatalf("SendVerificationSMS error = %v, want a send failure", err)
+	}
+}
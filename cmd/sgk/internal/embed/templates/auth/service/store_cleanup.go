@@ -0,0 +1,60 @@
+package authservice
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+)
+
+// StoreCleanup periodically removes expired entries from stores that do not
+// expire them on their own. The container stops it on shutdown.
+type StoreCleanup struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// StartStoreCleanup runs DeleteExpired on every store each interval until
+// Shutdown is called.
+func StartStoreCleanup(interval time.Duration, stores ...authinterface.ExpiringStore) *StoreCleanup {
+	ctx, cancel := context.WithCancel(context.Background())
+	cleanup := &StoreCleanup{
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	if len(stores) == 0 {
+		close(cleanup.done)
+		return cleanup
+	}
+
+	go func() {
+		defer close(cleanup.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				for _, store := range stores {
+					if err := store.DeleteExpired(ctx); err != nil && ctx.Err() == nil {
+						fmt.Printf("Failed to delete expired auth state: %v\n", err)
+					}
+				}
+			}
+		}
+	}()
+
+	return cleanup
+}
+
+// Shutdown stops the cleanup and waits for a running pass to finish.
+func (c *StoreCleanup) Shutdown() error {
+	c.cancel()
+	<-c.done
+	return nil
+}
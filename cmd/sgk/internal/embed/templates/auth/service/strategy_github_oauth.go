@@ -0,0 +1,235 @@
+package authservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+)
+
+const (
+	githubAuthURL  = "https://github.com/login/oauth/authorize"
+	githubTokenURL = "https://github.com/login/oauth/access_token"
+	githubAPIURL   = "https://api.github.com"
+)
+
+type GitHubOAuthStrategy struct {
+	accountRepo  authinterface.AccountRepository
+	identityRepo authinterface.IdentityRepository
+	clientID     string
+	clientSecret string
+	redirectURI  string
+	authURL      string
+	tokenURL     string
+	apiURL       string
+	httpClient   *http.Client
+	scopes       []string
+}
+
+func NewGitHubOAuthStrategy(
+	accountRepo authinterface.AccountRepository,
+	identityRepo authinterface.IdentityRepository,
+	config GitHubOAuthConfig,
+) authinterface.OAuthStrategy {
+	strategy := &GitHubOAuthStrategy{
+		accountRepo:  accountRepo,
+		identityRepo: identityRepo,
+		clientID:     config.ClientID,
+		clientSecret: config.ClientSecret,
+		redirectURI:  config.RedirectURI,
+		authURL:      config.AuthURL,
+		tokenURL:     config.TokenURL,
+		apiURL:       strings.TrimSuffix(config.APIURL, "/"),
+		httpClient:   config.HTTPClient,
+		scopes:       []string{"read:user", "user:email"},
+	}
+
+	if strategy.authURL == "" {
+		strategy.authURL = githubAuthURL
+	}
+	if strategy.tokenURL == "" {
+		strategy.tokenURL = githubTokenURL
+	}
+	if strategy.apiURL == "" {
+		strategy.apiURL = githubAPIURL
+	}
+	if strategy.httpClient == nil {
+		strategy.httpClient = http.DefaultClient
+	}
+
+	return strategy
+}
+
+func (s *GitHubOAuthStrategy) Name() string {
+	return "github"
+}
+
+func (s *GitHubOAuthStrategy) Type() authinterface.AuthStrategyType {
+	return authinterface.StrategyTypeOAuth
+}
+
+func (s *GitHubOAuthStrategy) GetAuthURL(request authinterface.OAuthRequest) string {
+	params := url.Values{}
+	params.Add("client_id", s.clientID)
+	params.Add("redirect_uri", s.redirectURI)
+	params.Add("scope", strings.Join(s.scopes, " "))
+	params.Add("state", request.State)
+	params.Add("code_challenge", request.CodeChallenge)
+	params.Add("code_challenge_method", "S256")
+
+	return fmt.Sprintf("%s?%s", s.authURL, params.Encode())
+}
+
+func (s *GitHubOAuthStrategy) ExchangeCode(ctx context.Context, exchange authinterface.OAuthExchange) (*authinterface.OAuthTokens, error) {
+	data := url.Values{}
+	data.Set("code", exchange.Code)
+	data.Set("code_verifier", exchange.CodeVerifier)
+	data.Set("client_id", s.clientID)
+	data.Set("client_secret", s.clientSecret)
+	data.Set("redirect_uri", s.redirectURI)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.tokenURL, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to exchange code: %s", string(body))
+	}
+
+	// GitHub reports exchange failures with a 200 and an error field.
+	var result struct {
+		AccessToken      string `json:"access_token"`
+		TokenType        string `json:"token_type"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode token response: %w", err)
+	}
+
+	if result.Error != "" {
+		return nil, fmt.Errorf("failed to exchange code: %s: %s", result.Error, result.ErrorDescription)
+	}
+
+	if result.AccessToken == "" {
+		return nil, fmt.Errorf("failed to exchange code: no access token returned")
+	}
+
+	return &authinterface.OAuthTokens{
+		AccessToken: result.AccessToken,
+		TokenType:   result.TokenType,
+	}, nil
+}
+
+func (s *GitHubOAuthStrategy) GetUserInfo(ctx context.Context, tokens *authinterface.OAuthTokens) (*authinterface.OAuthUserInfo, error) {
+	var githubUser struct {
+		ID        int64  `json:"id"`
+		Login     string `json:"login"`
+		Name      string `json:"name"`
+		Email     string `json:"email"`
+		AvatarURL string `json:"avatar_url"`
+	}
+
+	if err := s.getJSON(ctx, tokens.AccessToken, "/user", &githubUser); err != nil {
+		return nil, fmt.Errorf("failed to get user info: %w", err)
+	}
+
+	// The profile email is whatever the user made public and says nothing
+	// about verification, so the emails API is the source of truth.
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+
+	if err := s.getJSON(ctx, tokens.AccessToken, "/user/emails", &emails); err != nil {
+		return nil, fmt.Errorf("failed to get user emails: %w", err)
+	}
+
+	email := ""
+	for _, candidate := range emails {
+		if !candidate.Verified {
+			continue
+		}
+		if candidate.Primary {
+			email = candidate.Email
+			break
+		}
+		if email == "" {
+			email = candidate.Email
+		}
+	}
+
+	name := githubUser.Name
+	if name == "" {
+		name = githubUser.Login
+	}
+
+	return &authinterface.OAuthUserInfo{
+		ID:            strconv.FormatInt(githubUser.ID, 10),
+		Email:         email,
+		EmailVerified: email != "",
+		Name:          name,
+		Picture:       githubUser.AvatarURL,
+		Provider:      "github",
+		Raw: map[string]any{
+			"id":         githubUser.ID,
+			"login":      githubUser.Login,
+			"name":       githubUser.Name,
+			"email":      githubUser.Email,
+			"avatar_url": githubUser.AvatarURL,
+		},
+	}, nil
+}
+
+func (s *GitHubOAuthStrategy) Authenticate(ctx context.Context, credentials map[string]any) (*authinterface.AuthResult, error) {
+	return authenticateOAuth(ctx, s, s.accountRepo, s.identityRepo, credentials)
+}
+
+func (s *GitHubOAuthStrategy) ValidateCredentials(credentials map[string]any) error {
+	code, ok := credentials["code"].(string)
+	if !ok || code == "" {
+		return fmt.Errorf("authorization code is required")
+	}
+
+	return nil
+}
+
+func (s *GitHubOAuthStrategy) getJSON(ctx context.Context, accessToken string, path string, target any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.apiURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s returned %d: %s", path, resp.StatusCode, string(body))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(target)
+}
@@ -0,0 +1,218 @@
+package authservice
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+)
+
+type githubEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+// fakeGitHub serves the OAuth token endpoint and the two API endpoints the
+// strategy reads.
+type fakeGitHub struct {
+	server *httptest.Server
+	user   map[string]any
+	emails []githubEmail
+
+	mu        sync.Mutex
+	tokenForm url.Values
+}
+
+func newFakeGitHub(t *testing.T) *fakeGitHub {
+	t.Helper()
+
+	g := &fakeGitHub{
+		user: map[string]any{"id": 42, "login": "octocat", "name": "The Octocat", "avatar_url": "https://avatars.example/42"},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login/oauth/access_token", g.handleToken)
+	mux.HandleFunc("/api/user", g.authorized(func(w http.ResponseWriter, r *http.Request) { writeJSON(w, g.user) }))
+	mux.HandleFunc("/api/user/emails", g.authorized(func(w http.ResponseWriter, r *http.Request) { writeJSON(w, g.emails) }))
+
+	g.server = httptest.NewServer(mux)
+	t.Cleanup(g.server.Close)
+	return g
+}
+
+func (g *fakeGitHub) strategy() authinterface.OAuthStrategy {
+	return NewGitHubOAuthStrategy(nil, nil, GitHubOAuthConfig{
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+		RedirectURI:  "https://app.example/callback",
+		AuthURL:      g.server.URL + "/login/oauth/authorize",
+		TokenURL:     g.server.URL + "/login/oauth/access_token",
+		APIURL:       g.server.URL + "/api/",
+		HTTPClient:   g.server.Client(),
+	})
+}
+
+func (g *fakeGitHub) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	g.mu.Lock()
+	g.tokenForm = r.PostForm
+	g.mu.Unlock()
+
+	switch r.PostForm.Get("code") {
+	case "good-code":
+		writeJSON(w, map[string]any{"access_token": "gho_token", "token_type": "bearer"})
+	case "server-error":
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	default:
+		// GitHub reports a bad code with a 200 response.
+		writeJSON(w, map[string]any{"error": "bad_verification_code", "error_description": "The code passed is incorrect or expired."})
+	}
+}
+
+func (g *fakeGitHub) authorized(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer gho_token" {
+			http.Error(w, `{"message":"Bad credentials"}`, http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (g *fakeGitHub) lastTokenForm() url.Values {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.tokenForm
+}
+
+func TestGitHubExchangeCode(t *testing.T) {
+	g := newFakeGitHub(t)
+	strategy := g.strategy()
+	ctx := context.Background()
+
+	tokens, err := strategy.ExchangeCode(ctx, authinterface.OAuthExchange{Code: "good-code", CodeVerifier: "verifier"})
+	if err != nil {
+		t.Fatalf("ExchangeCode: %v", err)
+	}
+	if tokens.AccessToken != "gho_token" {
+		t.Fatalf("access token = %q, want gho_token", tokens.AccessToken)
+	}
+
+	form := g.lastTokenForm()
+	want := map[string]string{
+		"code":          "good-code",
+		"code_verifier": "verifier",
+		"client_id":     "client-id",
+		"client_secret": "client-secret",
+		"redirect_uri":  "https://app.example/callback",
+	}
+	for name, value := range want {
+		if got := form.Get(name); got != value {
+			t.Errorf("token request %s = %q, want %q", name, got, value)
+		}
+	}
+
+	_, err = strategy.ExchangeCode(ctx, authinterface.OAuthExchange{Code: "expired-code"})
+	if err == nil || !strings.Contains(err.Error(), "bad_verification_code") {
+		t.Fatalf("ExchangeCode error = %v, want the error reported in the 200 response", err)
+	}
+
+	_, err = strategy.ExchangeCode(ctx, authinterface.OAuthExchange{Code: "server-error"})
+	if err == nil || !strings.Contains(err.Error(), "unavailable") {
+		t.Fatalf("ExchangeCode error = %v, want the provider's error", err)
+	}
+}
+
+func TestGitHubGetUserInfo(t *testing.T) {
+	g := newFakeGitHub(t)
+	g.emails = []githubEmail{{Email: "octocat@example.com", Primary: true, Verified: true}}
+
+	info, err := g.strategy().GetUserInfo(context.Background(), &authinterface.OAuthTokens{AccessToken: "gho_token"})
+	if err != nil {
+		t.Fatalf("GetUserInfo: %v", err)
+	}
+
+	if info.ID != "42" || info.Name != "The Octocat" || info.Picture != "https://avatars.example/42" || info.Provider != "github" {
+		t.Fatalf("user info = %+v", info)
+	}
+
+	_, err = g.strategy().GetUserInfo(context.Background(), &authinterface.OAuthTokens{AccessToken: "revoked"})
+	if err == nil {
+		t.Fatal("GetUserInfo accepted a rejected access token")
+	}
+}
+
+func TestGitHubEmailSelection(t *testing.T) {
+	tests := []struct {
+		name   string
+		emails []githubEmail
+		want   string
+	}{
+		{
+			name: "verified primary",
+			emails: []githubEmail{
+				{Email: "work@example.com", Verified: true},
+				{Email: "primary@example.com", Primary: true, Verified: true},
+			},
+			want: "primary@example.com",
+		},
+		{
+			name: "unverified primary",
+			emails: []githubEmail{
+				{Email: "primary@example.com", Primary: true},
+				{Email: "work@example.com", Verified: true},
+				{Email: "home@example.com", Verified: true},
+			},
+			want: "work@example.com",
+		},
+		{
+			name:   "nothing verified",
+			emails: []githubEmail{{Email: "primary@example.com", Primary: true}},
+			want:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newFakeGitHub(t)
+			// The public profile email is never trusted.
+			g.user["email"] = "public@example.com"
+			g.emails = tt.emails
+
+			info, err := g.strategy().GetUserInfo(context.Background(), &authinterface.OAuthTokens{AccessToken: "gho_token"})
+			if err != nil {
+				t.Fatalf("GetUserInfo: %v", err)
+			}
+			if info.Email != tt.want {
+				t.Fatalf("email = %q, want %q", info.Email, tt.want)
+			}
+			if info.EmailVerified != (tt.want != "") {
+				t.Fatalf("email verified = %v, want %v", info.EmailVerified, tt.want != "")
+			}
+		})
+	}
+}
+
+func TestGitHubNameFallsBackToLogin(t *testing.T) {
+	g := newFakeGitHub(t)
+	delete(g.user, "name")
+
+	info, err := g.strategy().GetUserInfo(context.Background(), &authinterface.OAuthTokens{AccessToken: "gho_token"})
+	if err != nil {
+		t.Fatalf("GetUserInfo: %v", err)
+	}
+	if info.Name != "octocat" {
+		t.Fatalf("name = %q, want the login", info.Name)
+	}
+}
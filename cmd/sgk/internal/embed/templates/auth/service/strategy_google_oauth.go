@@ -10,13 +10,11 @@ import (
 	"strings"
 	
 	authinterface "{{.Project.GoModule}}/internal/auth/interface"
-	authmodel "{{.Project.GoModule}}/internal/auth/model"
-	"{{.Project.GoModule}}/internal/core"
-	"github.com/google/uuid"
 )
 
 type GoogleOAuthStrategy struct {
 	accountRepo    authinterface.AccountRepository
+	identityRepo   authinterface.IdentityRepository
 	clientID       string
 	clientSecret   string
 	redirectURI    string
@@ -25,12 +23,14 @@ type GoogleOAuthStrategy struct {
 
 func NewGoogleOAuthStrategy(
 	accountRepo authinterface.AccountRepository,
+	identityRepo authinterface.IdentityRepository,
 	clientID string,
 	clientSecret string,
 	redirectURI string,
 ) authinterface.OAuthStrategy {
 	return &GoogleOAuthStrategy{
 		accountRepo:  accountRepo,
+		identityRepo: identityRepo,
 		clientID:     clientID,
 		clientSecret: clientSecret,
 		redirectURI:  redirectURI,
@@ -46,24 +46,28 @@ func (s *GoogleOAuthStrategy) Type() authinterface.AuthStrategyType {
 	return authinterface.StrategyTypeOAuth
 }
 
-func (s *GoogleOAuthStrategy) GetAuthURL(state string) string {
+func (s *GoogleOAuthStrategy) GetAuthURL(request authinterface.OAuthRequest) string {
 	params := url.Values{}
 	params.Add("client_id", s.clientID)
 	params.Add("redirect_uri", s.redirectURI)
 	params.Add("response_type", "code")
 	params.Add("scope", strings.Join(s.scopes, " "))
-	params.Add("state", state)
+	params.Add("state", request.State)
+	params.Add("nonce", request.Nonce)
+	params.Add("code_challenge", request.CodeChallenge)
+	params.Add("code_challenge_method", "S256")
 	params.Add("access_type", "offline")
 	params.Add("prompt", "consent")
 	
 	return fmt.Sprintf("https://accounts.google.com/o/oauth2/v2/auth?%s", params.Encode())
 }
 
-func (s *GoogleOAuthStrategy) ExchangeCode(ctx context.Context, code string) (*authinterface.OAuthTokens, error) {
+func (s *GoogleOAuthStrategy) ExchangeCode(ctx context.Context, exchange authinterface.OAuthExchange) (*authinterface.OAuthTokens, error) {
 	tokenURL := "https://oauth2.googleapis.com/token"
 	
 	data := url.Values{}
-	data.Set("code", code)
+	data.Set("code", exchange.Code)
+	data.Set("code_verifier", exchange.CodeVerifier)
 	data.Set("client_id", s.clientID)
 	data.Set("client_secret", s.clientSecret)
 	data.Set("redirect_uri", s.redirectURI)
@@ -151,46 +155,7 @@ func (s *GoogleOAuthStrategy) GetUserInfo(ctx context.Context, tokens *authinter
 }
 
 func (s *GoogleOAuthStrategy) Authenticate(ctx context.Context, credentials map[string]any) (*authinterface.AuthResult, error) {
-	code, ok := credentials["code"].(string)
-	if !ok || code == "" {
-		return nil, fmt.Errorf("authorization code is required")
-	}
-	
-	tokens, err := s.ExchangeCode(ctx, code)
-	if err != nil {
-		return nil, core.NewAppError(core.ErrCodeUnauthorized, "failed to exchange authorization code")
-	}
-	
-	userInfo, err := s.GetUserInfo(ctx, tokens)
-	if err != nil {
-		return nil, core.NewAppError(core.ErrCodeUnauthorized, "failed to get user information")
-	}
-	
-	account, err := s.accountRepo.GetByEmail(ctx, userInfo.Email)
-	if err != nil {
-		account = &authmodel.Account{
-			ID:            uuid.New(),
-			Email:         userInfo.Email,
-			EmailVerified: userInfo.EmailVerified,
-			PasswordHash:  "", // No password for OAuth users
-		}
-		
-		if err := s.accountRepo.Create(ctx, account); err != nil {
-			return nil, core.NewAppError(core.ErrCodeInternalServer, "failed to create account")
-		}
-	}
-	
-	return &authinterface.AuthResult{
-		AccountID:         account.GetID(),
-		Account:           account,
-		NeedsVerification: false, // Google already verified the email
-		Metadata: map[string]any{
-			"auth_method": "google_oauth",
-			"provider_id": userInfo.ID,
-			"name":        userInfo.Name,
-			"picture":     userInfo.Picture,
-		},
-	}, nil
+	return authenticateOAuth(ctx, s, s.accountRepo, s.identityRepo, credentials)
 }
 
 func (s *GoogleOAuthStrategy) ValidateCredentials(credentials map[string]any) error {
@@ -0,0 +1,425 @@
+package authservice
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+)
+
+// oidcKeyRefreshInterval limits how often an unknown kid can trigger a JWKS
+// fetch, so forged tokens cannot be used to hammer the provider.
+const oidcKeyRefreshInterval = time.Minute
+
+var oidcSigningMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+type oidcDiscovery struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserInfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+}
+
+type oidcClaims struct {
+	jwt.RegisteredClaims
+	Email         string `json:"email"`
+	EmailVerified any    `json:"email_verified"`
+	Name          string `json:"name"`
+	Picture       string `json:"picture"`
+	Nonce         string `json:"nonce"`
+}
+
+// OIDCStrategy signs users in with any OpenID Connect provider. Endpoints
+// come from the provider's discovery document and ID tokens are verified
+// against its published JWKS.
+type OIDCStrategy struct {
+	accountRepo  authinterface.AccountRepository
+	identityRepo authinterface.IdentityRepository
+	name         string
+	clientID     string
+	clientSecret string
+	redirectURI  string
+	scopes       []string
+	httpClient   *http.Client
+	discovery    oidcDiscovery
+	postAuth     bool
+
+	keysMu      sync.RWMutex
+	keys        map[string]*authinterface.SigningKey
+	keysFetched time.Time
+}
+
+// NewOIDCStrategy fetches the provider's discovery document and signing keys.
+func NewOIDCStrategy(
+	ctx context.Context,
+	accountRepo authinterface.AccountRepository,
+	identityRepo authinterface.IdentityRepository,
+	config OIDCConfig,
+) (authinterface.OAuthStrategy, error) {
+	if config.IssuerURL == "" || config.ClientID == "" {
+		return nil, fmt.Errorf("OIDC issuer URL and client ID are required")
+	}
+
+	strategy := &OIDCStrategy{
+		accountRepo:  accountRepo,
+		identityRepo: identityRepo,
+		name:         config.Name,
+		clientID:     config.ClientID,
+		clientSecret: config.ClientSecret,
+		redirectURI:  config.RedirectURI,
+		scopes:       config.Scopes,
+		httpClient:   config.HTTPClient,
+	}
+
+	if strategy.name == "" {
+		strategy.name = "oidc"
+	}
+	if len(strategy.scopes) == 0 {
+		strategy.scopes = []string{"openid", "email", "profile"}
+	}
+	if strategy.httpClient == nil {
+		strategy.httpClient = http.DefaultClient
+	}
+
+	issuer := strings.TrimSuffix(config.IssuerURL, "/")
+	if err := strategy.getJSON(ctx, issuer+"/.well-known/openid-configuration", "", &strategy.discovery); err != nil {
+		return nil, fmt.Errorf("failed to fetch OIDC discovery document: %w", err)
+	}
+
+	if strings.TrimSuffix(strategy.discovery.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("OIDC discovery issuer %q does not match %q", strategy.discovery.Issuer, config.IssuerURL)
+	}
+
+	if strategy.discovery.AuthorizationEndpoint == "" || strategy.discovery.TokenEndpoint == "" || strategy.discovery.JWKSURI == "" {
+		return nil, fmt.Errorf("OIDC discovery document is missing required endpoints")
+	}
+
+	// client_secret_basic is the spec default; only fall back to posting
+	// the secret when the provider does not accept basic auth.
+	methods := strategy.discovery.TokenEndpointAuthMethodsSupported
+	strategy.postAuth = len(methods) > 0 && !containsAny(methods, []string{"client_secret_basic"}) && containsAny(methods, []string{"client_secret_post"})
+
+	if err := strategy.refreshKeys(ctx); err != nil {
+		return nil, err
+	}
+
+	return strategy, nil
+}
+
+func (s *OIDCStrategy) Name() string {
+	return s.name
+}
+
+func (s *OIDCStrategy) Type() authinterface.AuthStrategyType {
+	return authinterface.StrategyTypeOAuth
+}
+
+func (s *OIDCStrategy) GetAuthURL(request authinterface.OAuthRequest) string {
+	params := url.Values{}
+	params.Add("client_id", s.clientID)
+	params.Add("redirect_uri", s.redirectURI)
+	params.Add("response_type", "code")
+	params.Add("scope", strings.Join(s.scopes, " "))
+	params.Add("state", request.State)
+	params.Add("nonce", request.Nonce)
+	params.Add("code_challenge", request.CodeChallenge)
+	params.Add("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(s.discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	return s.discovery.AuthorizationEndpoint + separator + params.Encode()
+}
+
+func (s *OIDCStrategy) ExchangeCode(ctx context.Context, exchange authinterface.OAuthExchange) (*authinterface.OAuthTokens, error) {
+	data := url.Values{}
+	data.Set("code", exchange.Code)
+	data.Set("code_verifier", exchange.CodeVerifier)
+	data.Set("redirect_uri", s.redirectURI)
+	data.Set("grant_type", "authorization_code")
+	if s.postAuth {
+		data.Set("client_id", s.clientID)
+		data.Set("client_secret", s.clientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.discovery.TokenEndpoint, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if !s.postAuth {
+		req.SetBasicAuth(url.QueryEscape(s.clientID), url.QueryEscape(s.clientSecret))
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to exchange code: %s", string(body))
+	}
+
+	var result struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    int    `json:"expires_in"`
+		TokenType    string `json:"token_type"`
+		IDToken      string `json:"id_token"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode token response: %w", err)
+	}
+
+	if result.IDToken == "" {
+		return nil, fmt.Errorf("failed to exchange code: no id_token returned")
+	}
+
+	return &authinterface.OAuthTokens{
+		AccessToken:  result.AccessToken,
+		RefreshToken: result.RefreshToken,
+		ExpiresIn:    result.ExpiresIn,
+		TokenType:    result.TokenType,
+		IDToken:      result.IDToken,
+		Nonce:        exchange.Nonce,
+	}, nil
+}
+
+func (s *OIDCStrategy) GetUserInfo(ctx context.Context, tokens *authinterface.OAuthTokens) (*authinterface.OAuthUserInfo, error) {
+	claims := &oidcClaims{}
+	_, err := jwt.ParseWithClaims(tokens.IDToken, claims, s.keyFunc(ctx),
+		jwt.WithValidMethods(oidcSigningMethods),
+		jwt.WithIssuer(s.discovery.Issuer),
+		jwt.WithAudience(s.clientID),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id_token: %w", err)
+	}
+
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("invalid id_token: missing subject")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(tokens.Nonce)) != 1 {
+		return nil, fmt.Errorf("invalid id_token: nonce mismatch")
+	}
+
+	userInfo := &authinterface.OAuthUserInfo{
+		ID:            claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: isTrueClaim(claims.EmailVerified),
+		Name:          claims.Name,
+		Picture:       claims.Picture,
+		Provider:      s.name,
+		Raw: map[string]any{
+			"sub":            claims.Subject,
+			"email":          claims.Email,
+			"email_verified": claims.EmailVerified,
+			"name":           claims.Name,
+			"picture":        claims.Picture,
+		},
+	}
+
+	// Some providers keep the ID token minimal and only expose the email
+	// through the userinfo endpoint.
+	if userInfo.Email == "" && s.discovery.UserInfoEndpoint != "" && tokens.AccessToken != "" {
+		var profile struct {
+			Sub           string `json:"sub"`
+			Email         string `json:"email"`
+			EmailVerified any    `json:"email_verified"`
+			Name          string `json:"name"`
+			Picture       string `json:"picture"`
+		}
+		if err := s.getJSON(ctx, s.discovery.UserInfoEndpoint, tokens.AccessToken, &profile); err != nil {
+			return nil, fmt.Errorf("failed to get user info: %w", err)
+		}
+		if profile.Sub != claims.Subject {
+			return nil, fmt.Errorf("userinfo subject does not match id_token")
+		}
+
+		userInfo.Email = profile.Email
+		userInfo.EmailVerified = isTrueClaim(profile.EmailVerified)
+		if userInfo.Name == "" {
+			userInfo.Name = profile.Name
+		}
+		if userInfo.Picture == "" {
+			userInfo.Picture = profile.Picture
+		}
+	}
+
+	return userInfo, nil
+}
+
+func (s *OIDCStrategy) Authenticate(ctx context.Context, credentials map[string]any) (*authinterface.AuthResult, error) {
+	return authenticateOAuth(ctx, s, s.accountRepo, s.identityRepo, credentials)
+}
+
+func (s *OIDCStrategy) ValidateCredentials(credentials map[string]any) error {
+	code, ok := credentials["code"].(string)
+	if !ok || code == "" {
+		return fmt.Errorf("authorization code is required")
+	}
+
+	return nil
+}
+
+func (s *OIDCStrategy) keyFunc(ctx context.Context) jwt.Keyfunc {
+	return func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+
+		key, err := s.lookupKey(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+
+		if key.Algorithm != "" && key.Algorithm != token.Method.Alg() {
+			return nil, fmt.Errorf("key %s is not valid for %s", key.ID, token.Method.Alg())
+		}
+
+		switch token.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+			if _, ok := key.Public.(*rsa.PublicKey); !ok {
+				return nil, fmt.Errorf("key %s is not an RSA key", key.ID)
+			}
+		case *jwt.SigningMethodECDSA:
+			if _, ok := key.Public.(*ecdsa.PublicKey); !ok {
+				return nil, fmt.Errorf("key %s is not an EC key", key.ID)
+			}
+		case *jwt.SigningMethodEd25519:
+			if _, ok := key.Public.(ed25519.PublicKey); !ok {
+				return nil, fmt.Errorf("key %s is not an Ed25519 key", key.ID)
+			}
+		default:
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+
+		return key.Public, nil
+	}
+}
+
+// lookupKey finds the key for kid, refetching the JWKS once per interval
+// when the provider has rotated to a key we have not seen yet.
+func (s *OIDCStrategy) lookupKey(ctx context.Context, kid string) (*authinterface.SigningKey, error) {
+	if key := s.cachedKey(kid); key != nil {
+		return key, nil
+	}
+
+	s.keysMu.RLock()
+	recentlyFetched := time.Since(s.keysFetched) < oidcKeyRefreshInterval
+	s.keysMu.RUnlock()
+
+	if !recentlyFetched {
+		if err := s.refreshKeys(ctx); err != nil {
+			return nil, err
+		}
+		if key := s.cachedKey(kid); key != nil {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+func (s *OIDCStrategy) cachedKey(kid string) *authinterface.SigningKey {
+	s.keysMu.RLock()
+	defer s.keysMu.RUnlock()
+
+	if key, ok := s.keys[kid]; ok {
+		return key
+	}
+
+	// Providers with a single key may omit kid from their tokens.
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key
+		}
+	}
+
+	return nil
+}
+
+func (s *OIDCStrategy) refreshKeys(ctx context.Context) error {
+	var keySet authinterface.JSONWebKeySet
+	if err := s.getJSON(ctx, s.discovery.JWKSURI, "", &keySet); err != nil {
+		return fmt.Errorf("failed to fetch OIDC signing keys: %w", err)
+	}
+
+	keys := make(map[string]*authinterface.SigningKey, len(keySet.Keys))
+	for _, jwk := range keySet.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		key, err := fromJSONWebKey(jwk)
+		if err != nil {
+			// Skip keys we cannot use rather than failing the whole set.
+			continue
+		}
+		keys[key.ID] = key
+	}
+
+	s.keysMu.Lock()
+	s.keys = keys
+	s.keysFetched = time.Now()
+	s.keysMu.Unlock()
+
+	return nil
+}
+
+func (s *OIDCStrategy) getJSON(ctx context.Context, endpoint string, accessToken string, target any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if accessToken != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s returned %d: %s", endpoint, resp.StatusCode, string(body))
+	}
+
+	return json.NewDecoder(resp.Body).Decode(target)
+}
+
+// isTrueClaim accepts email_verified as either a boolean or the string
+// "true", since providers disagree on the encoding.
+func isTrueClaim(value any) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		return strings.EqualFold(v, "true")
+	default:
+		return false
+	}
+}
@@ -0,0 +1,421 @@
+package authservice
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+)
+
+const (
+	testOIDCClientID     = "client-id"
+	testOIDCClientSecret = "client-secret"
+	testOIDCCode         = "good-code"
+	testOIDCNonce        = "nonce-1"
+)
+
+// fakeOIDCProvider serves discovery, JWKS, token and userinfo endpoints.
+// The hooks let a test change what it publishes and issues.
+type fakeOIDCProvider struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	kid    string
+
+	editDiscovery func(doc map[string]any)
+	editClaims    func(claims jwt.MapClaims)
+	signIDToken   func(claims jwt.MapClaims) string
+	userInfo      map[string]any
+
+	mu        sync.Mutex
+	tokenForm url.Values
+	basicUser string
+	basicPass string
+}
+
+func newFakeOIDCProvider(t *testing.T) *fakeOIDCProvider {
+	t.Helper()
+
+	p := &fakeOIDCProvider{t: t, key: newRSAKey(t), kid: "key-1"}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", p.handleDiscovery)
+	mux.HandleFunc("/jwks", p.handleJWKS)
+	mux.HandleFunc("/token", p.handleToken)
+	mux.HandleFunc("/userinfo", p.handleUserInfo)
+
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+func newRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate RSA key: %v", err)
+	}
+	return key
+}
+
+func (p *fakeOIDCProvider) strategy(t *testing.T) authinterface.OAuthStrategy {
+	t.Helper()
+
+	strategy, err := p.newStrategy()
+	if err != nil {
+		t.Fatalf("NewOIDCStrategy: %v", err)
+	}
+	return strategy
+}
+
+func (p *fakeOIDCProvider) newStrategy() (authinterface.OAuthStrategy, error) {
+	return NewOIDCStrategy(context.Background(), nil, nil, OIDCConfig{
+		Name:         "acme",
+		IssuerURL:    p.server.URL,
+		ClientID:     testOIDCClientID,
+		ClientSecret: testOIDCClientSecret,
+		RedirectURI:  "https://app.example/callback",
+		HTTPClient:   p.server.Client(),
+	})
+}
+
+func (p *fakeOIDCProvider) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	doc := map[string]any{
+		"issuer":                 p.server.URL,
+		"authorization_endpoint": p.server.URL + "/authorize",
+		"token_endpoint":         p.server.URL + "/token",
+		"userinfo_endpoint":      p.server.URL + "/userinfo",
+		"jwks_uri":               p.server.URL + "/jwks",
+	}
+	if p.editDiscovery != nil {
+		p.editDiscovery(doc)
+	}
+	writeJSON(w, doc)
+}
+
+func (p *fakeOIDCProvider) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	jwk, _ := toJSONWebKey(&authinterface.SigningKey{ID: p.kid, Algorithm: "RS256", Public: &p.key.PublicKey})
+	writeJSON(w, authinterface.JSONWebKeySet{Keys: []authinterface.JSONWebKey{jwk}})
+}
+
+func (p *fakeOIDCProvider) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	p.mu.Lock()
+	p.tokenForm = r.PostForm
+	p.basicUser, p.basicPass, _ = r.BasicAuth()
+	p.mu.Unlock()
+
+	if r.PostForm.Get("code") != testOIDCCode {
+		w.WriteHeader(http.StatusBadRequest)
+		writeJSON(w, map[string]any{"error": "invalid_grant"})
+		return
+	}
+
+	writeJSON(w, map[string]any{
+		"access_token": "access-token",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     p.idToken(),
+	})
+}
+
+// tokenRequest returns the form and basic auth credentials of the last
+// token request.
+func (p *fakeOIDCProvider) tokenRequest() (url.Values, string, string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.tokenForm, p.basicUser, p.basicPass
+}
+
+func (p *fakeOIDCProvider) handleUserInfo(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer access-token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	writeJSON(w, p.userInfo)
+}
+
+func (p *fakeOIDCProvider) idToken() string {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            p.server.URL,
+		"sub":            "user-1",
+		"aud":            testOIDCClientID,
+		"exp":            now.Add(5 * time.Minute).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          testOIDCNonce,
+		"email":          "oidc@example.com",
+		"email_verified": true,
+		"name":           "Oidc User",
+	}
+	if p.editClaims != nil {
+		p.editClaims(claims)
+	}
+	if p.signIDToken != nil {
+		return p.signIDToken(claims)
+	}
+	return signRS256(p.t, p.key, p.kid, claims)
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Errorf("sign id_token: %v", err)
+	}
+	return signed
+}
+
+func writeJSON(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+// login runs the callback half of the flow against the provider.
+func (p *fakeOIDCProvider) login(strategy authinterface.OAuthStrategy) (*authinterface.OAuthUserInfo, error) {
+	ctx := context.Background()
+
+	tokens, err := strategy.ExchangeCode(ctx, authinterface.OAuthExchange{
+		Code:         testOIDCCode,
+		CodeVerifier: "verifier",
+		Nonce:        testOIDCNonce,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return strategy.GetUserInfo(ctx, tokens)
+}
+
+func TestOIDCDiscovery(t *testing.T) {
+	p := newFakeOIDCProvider(t)
+	strategy := p.strategy(t)
+
+	if strategy.Name() != "acme" {
+		t.Fatalf("Name = %q, want acme", strategy.Name())
+	}
+
+	authURL, err := url.Parse(strategy.GetAuthURL(authinterface.OAuthRequest{
+		State:         "state-1",
+		Nonce:         testOIDCNonce,
+		CodeChallenge: "challenge",
+	}))
+	if err != nil {
+		t.Fatalf("parse auth URL: %v", err)
+	}
+
+	if got := authURL.Scheme + "://" + authURL.Host + authURL.Path; got != p.server.URL+"/authorize" {
+		t.Fatalf("auth endpoint = %q, want the discovered one", got)
+	}
+
+	want := map[string]string{
+		"client_id":             testOIDCClientID,
+		"redirect_uri":          "https://app.example/callback",
+		"response_type":         "code",
+		"scope":                 "openid email profile",
+		"state":                 "state-1",
+		"nonce":                 testOIDCNonce,
+		"code_challenge":        "challenge",
+		"code_challenge_method": "S256",
+	}
+	for name, value := range want {
+		if got := authURL.Query().Get(name); got != value {
+			t.Errorf("auth URL %s = %q, want %q", name, got, value)
+		}
+	}
+}
+
+func TestOIDCDiscoveryRejectsInvalidDocument(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(doc map[string]any)
+	}{
+		{"issuer mismatch", func(doc map[string]any) { doc["issuer"] = "https://evil.example" }},
+		{"missing token endpoint", func(doc map[string]any) { delete(doc, "token_endpoint") }},
+		{"missing jwks uri", func(doc map[string]any) { delete(doc, "jwks_uri") }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newFakeOIDCProvider(t)
+			p.editDiscovery = tt.edit
+
+			if _, err := p.newStrategy(); err == nil {
+				t.Fatal("NewOIDCStrategy accepted an invalid discovery document")
+			}
+		})
+	}
+}
+
+func TestOIDCExchangeCode(t *testing.T) {
+	t.Run("client secret basic", func(t *testing.T) {
+		p := newFakeOIDCProvider(t)
+
+		if _, err := p.login(p.strategy(t)); err != nil {
+			t.Fatalf("login: %v", err)
+		}
+
+		form, user, pass := p.tokenRequest()
+		if form.Get("grant_type") != "authorization_code" || form.Get("code_verifier") != "verifier" ||
+			form.Get("redirect_uri") != "https://app.example/callback" {
+			t.Fatalf("token request form = %v", form)
+		}
+		if form.Has("client_secret") {
+			t.Fatal("client secret was posted although basic auth is supported")
+		}
+		if user != testOIDCClientID || pass != testOIDCClientSecret {
+			t.Fatalf("basic auth = %q:%q, want the client credentials", user, pass)
+		}
+	})
+
+	t.Run("client secret post", func(t *testing.T) {
+		p := newFakeOIDCProvider(t)
+		p.editDiscovery = func(doc map[string]any) {
+			doc["token_endpoint_auth_methods_supported"] = []string{"client_secret_post"}
+		}
+
+		if _, err := p.login(p.strategy(t)); err != nil {
+			t.Fatalf("login: %v", err)
+		}
+
+		form, user, _ := p.tokenRequest()
+		if form.Get("client_id") != testOIDCClientID || form.Get("client_secret") != testOIDCClientSecret {
+			t.Fatalf("token request form = %v, want the client credentials", form)
+		}
+		if user != "" {
+			t.Fatal("basic auth was sent although only client_secret_post is supported")
+		}
+	})
+
+	t.Run("rejected code", func(t *testing.T) {
+		p := newFakeOIDCProvider(t)
+
+		_, err := p.strategy(t).ExchangeCode(context.Background(), authinterface.OAuthExchange{Code: "bad-code"})
+		if err == nil || !strings.Contains(err.Error(), "invalid_grant") {
+			t.Fatalf("ExchangeCode error = %v, want the provider's error", err)
+		}
+	})
+}
+
+func TestOIDCGetUserInfo(t *testing.T) {
+	p := newFakeOIDCProvider(t)
+
+	info, err := p.login(p.strategy(t))
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+
+	if info.ID != "user-1" || info.Email != "oidc@example.com" || !info.EmailVerified ||
+		info.Name != "Oidc User" || info.Provider != "acme" {
+		t.Fatalf("user info = %+v", info)
+	}
+}
+
+func TestOIDCRejectsInvalidIDToken(t *testing.T) {
+	otherKey := newRSAKey(t)
+
+	tests := []struct {
+		name  string
+		edit  func(claims jwt.MapClaims)
+		sign  func(t *testing.T, p *fakeOIDCProvider, claims jwt.MapClaims) string
+		error string
+	}{
+		{name: "nonce mismatch", edit: func(c jwt.MapClaims) { c["nonce"] = "replayed" }, error: "nonce"},
+		{name: "missing nonce", edit: func(c jwt.MapClaims) { delete(c, "nonce") }, error: "nonce"},
+		{name: "wrong audience", edit: func(c jwt.MapClaims) { c["aud"] = "other-client" }, error: "aud"},
+		{name: "wrong issuer", edit: func(c jwt.MapClaims) { c["iss"] = "https://evil.example" }, error: "iss"},
+		{name: "expired", edit: func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() }, error: "expired"},
+		{name: "missing expiry", edit: func(c jwt.MapClaims) { delete(c, "exp") }, error: "exp"},
+		{name: "missing subject", edit: func(c jwt.MapClaims) { delete(c, "sub") }, error: "subject"},
+		{
+			name: "signed by another key",
+			sign: func(t *testing.T, p *fakeOIDCProvider, c jwt.MapClaims) string {
+				return signRS256(t, otherKey, p.kid, c)
+			},
+			error: "signature",
+		},
+		{
+			name: "unknown key id",
+			sign: func(t *testing.T, p *fakeOIDCProvider, c jwt.MapClaims) string {
+				return signRS256(t, otherKey, "key-2", c)
+			},
+			error: "unknown signing key",
+		},
+		{
+			name: "symmetric algorithm",
+			sign: func(t *testing.T, p *fakeOIDCProvider, c jwt.MapClaims) string {
+				token := jwt.NewWithClaims(jwt.SigningMethodHS256, c)
+				token.Header["kid"] = p.kid
+				signed, _ := token.SignedString([]byte(testOIDCClientSecret))
+				return signed
+			},
+			error: "signing method",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newFakeOIDCProvider(t)
+			p.editClaims = tt.edit
+			if tt.sign != nil {
+				p.signIDToken = func(claims jwt.MapClaims) string { return tt.sign(t, p, claims) }
+			}
+
+			_, err := p.login(p.strategy(t))
+			if err == nil {
+				t.Fatal("login accepted an invalid id_token")
+			}
+			if !strings.Contains(err.Error(), tt.error) {
+				t.Fatalf("error = %q, want it to mention %q", err, tt.error)
+			}
+		})
+	}
+}
+
+func TestOIDCUserInfoFallback(t *testing.T) {
+	minimalToken := func(c jwt.MapClaims) {
+		delete(c, "email")
+		delete(c, "email_verified")
+		delete(c, "name")
+	}
+
+	t.Run("fills in the email", func(t *testing.T) {
+		p := newFakeOIDCProvider(t)
+		p.editClaims = minimalToken
+		p.userInfo = map[string]any{"sub": "user-1", "email": "info@example.com", "email_verified": "true", "name": "Info User"}
+
+		info, err := p.login(p.strategy(t))
+		if err != nil {
+			t.Fatalf("login: %v", err)
+		}
+		if info.Email != "info@example.com" || !info.EmailVerified || info.Name != "Info User" {
+			t.Fatalf("user info = %+v", info)
+		}
+	})
+
+	t.Run("rejects another subject", func(t *testing.T) {
+		p := newFakeOIDCProvider(t)
+		p.editClaims = minimalToken
+		p.userInfo = map[string]any{"sub": "user-2", "email": "other@example.com", "email_verified": true}
+
+		if _, err := p.login(p.strategy(t)); err == nil || !strings.Contains(err.Error(), "subject") {
+			t.Fatalf("login error = %v, want a subject mismatch", err)
+		}
+	})
+}
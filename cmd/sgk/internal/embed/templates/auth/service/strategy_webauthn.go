@@ -0,0 +1,200 @@
+package authservice
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	authconstants "{{.Project.GoModule}}/internal/auth/constants"
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	"{{.Project.GoModule}}/internal/core"
+)
+
+// WebAuthnStrategy signs users in with a passkey assertion. The challenge
+// must come from AuthService.BeginPasskeyLogin.
+type WebAuthnStrategy struct {
+	accountRepo    authinterface.AccountRepository
+	credentialRepo authinterface.WebAuthnCredentialRepository
+	sessionStore   authinterface.WebAuthnSessionStore
+	config         authinterface.AuthConfig
+	eventHook      authinterface.AuthEventHook
+}
+
+func NewWebAuthnStrategy(
+	accountRepo authinterface.AccountRepository,
+	credentialRepo authinterface.WebAuthnCredentialRepository,
+	sessionStore authinterface.WebAuthnSessionStore,
+	config authinterface.AuthConfig,
+	eventHook authinterface.AuthEventHook,
+) authinterface.AuthStrategy {
+	return &WebAuthnStrategy{
+		accountRepo:    accountRepo,
+		credentialRepo: credentialRepo,
+		sessionStore:   sessionStore,
+		config:         config,
+		eventHook:      eventHook,
+	}
+}
+
+func (s *WebAuthnStrategy) Name() string {
+	return "webauthn"
+}
+
+func (s *WebAuthnStrategy) Type() authinterface.AuthStrategyType {
+	return authinterface.StrategyTypePasswordless
+}
+
+func (s *WebAuthnStrategy) Authenticate(ctx context.Context, credentials map[string]any) (*authinterface.AuthResult, error) {
+	credential, err := webAuthnCredentialFrom(credentials)
+	if err != nil {
+		return nil, core.NewAppError(core.ErrCodeBadRequest, authconstants.ErrInvalidPasskey)
+	}
+
+	clientData, clientDataJSON, err := parseClientData(credential.Response.ClientDataJSON, "webauthn.get", s.config.GetWebAuthnOrigins())
+	if err != nil {
+		return nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrInvalidPasskey, err)
+	}
+
+	session, err := s.sessionStore.Consume(ctx, clientData.Challenge)
+	if err != nil || session.Ceremony != authinterface.WebAuthnCeremonyLogin {
+		return nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrPasskeyChallenge)
+	}
+
+	rawID, err := decodeBase64URL(webAuthnRawID(credential))
+	if err != nil {
+		return nil, core.NewAppError(core.ErrCodeBadRequest, authconstants.ErrInvalidPasskey)
+	}
+
+	stored, err := s.credentialRepo.GetByCredentialID(ctx, base64.RawURLEncoding.EncodeToString(rawID))
+	if err != nil {
+		return nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrPasskeyNotFound)
+	}
+
+	if session.AccountID != uuid.Nil && session.AccountID != stored.GetAccountID() {
+		return nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrPasskeyNotFound)
+	}
+
+	if credential.Response.UserHandle != "" {
+		userHandle, err := decodeBase64URL(credential.Response.UserHandle)
+		accountID := stored.GetAccountID()
+		if err != nil || !bytes.Equal(userHandle, accountID[:]) {
+			return nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrInvalidPasskey)
+		}
+	}
+
+	authDataBytes, err := decodeBase64URL(credential.Response.AuthenticatorData)
+	if err != nil {
+		return nil, core.NewAppError(core.ErrCodeBadRequest, authconstants.ErrInvalidPasskey)
+	}
+
+	authData, err := parseAuthenticatorData(authDataBytes)
+	if err != nil {
+		return nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrInvalidPasskey, err)
+	}
+
+	if err := authData.verify(s.config.GetWebAuthnRPID()); err != nil {
+		return nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrInvalidPasskey, err)
+	}
+
+	signature, err := decodeBase64URL(credential.Response.Signature)
+	if err != nil {
+		return nil, core.NewAppError(core.ErrCodeBadRequest, authconstants.ErrInvalidPasskey)
+	}
+
+	if err := verifyWebAuthnSignature(stored.GetPublicKey(), authDataBytes, clientDataJSON, signature); err != nil {
+		return nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrInvalidPasskey, err)
+	}
+
+	// Authenticators that keep a counter must increase it on every use. A
+	// counter that stands still or goes back means a second copy of the
+	// key is in use, so the login is refused and reported.
+	if authData.SignCount != 0 || stored.GetSignCount() != 0 {
+		if authData.SignCount <= stored.GetSignCount() {
+			s.reportCounterRegression(ctx, stored, authData.SignCount)
+			return nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrPasskeyCloned)
+		}
+	}
+
+	if err := s.credentialRepo.UpdateSignCount(ctx, stored.GetID(), authData.SignCount, time.Now()); err != nil {
+		s.reportCounterRegression(ctx, stored, authData.SignCount)
+		return nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrPasskeyCloned)
+	}
+
+	account, err := s.accountRepo.GetByID(ctx, stored.GetAccountID())
+	if err != nil {
+		return nil, core.NewAppError(core.ErrCodeUnauthorized, authconstants.ErrAccountNotFound)
+	}
+
+	return &authinterface.AuthResult{
+		AccountID:         account.GetID(),
+		Account:           account,
+		NeedsVerification: false,
+		Metadata: map[string]any{
+			"auth_method": "webauthn",
+			"passkey_id":  stored.GetID(),
+		},
+	}, nil
+}
+
+func (s *WebAuthnStrategy) ValidateCredentials(credentials map[string]any) error {
+	if _, err := webAuthnCredentialFrom(credentials); err != nil {
+		return fmt.Errorf("passkey credential is required")
+	}
+
+	return nil
+}
+
+func (s *WebAuthnStrategy) reportCounterRegression(ctx context.Context, credential authinterface.WebAuthnCredential, signCount uint32) {
+	if s.eventHook == nil {
+		return
+	}
+
+	client := authinterface.ClientInfoFromContext(ctx)
+	s.eventHook.HandleAuthEvent(ctx, authinterface.AuthEvent{
+		Type:       authinterface.AuthEventPasskeyCounterRegression,
+		AccountID:  credential.GetAccountID(),
+		IPAddress:  client.IPAddress,
+		UserAgent:  client.UserAgent,
+		OccurredAt: time.Now(),
+		Metadata: map[string]any{
+			"passkey_id":           credential.GetID(),
+			"stored_sign_count":    credential.GetSignCount(),
+			"presented_sign_count": signCount,
+		},
+	})
+}
+
+// webAuthnCredentialFrom accepts the credential either as parsed by the
+// service or as the raw JSON object posted to the generic login endpoint.
+func webAuthnCredentialFrom(credentials map[string]any) (*authinterface.WebAuthnCredentialResponse, error) {
+	switch value := credentials["credential"].(type) {
+	case *authinterface.WebAuthnCredentialResponse:
+		if value == nil {
+			return nil, fmt.Errorf("credential is required")
+		}
+		return value, nil
+	case map[string]any:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		var credential authinterface.WebAuthnCredentialResponse
+		if err := json.Unmarshal(data, &credential); err != nil {
+			return nil, err
+		}
+		return &credential, nil
+	default:
+		return nil, fmt.Errorf("credential is required")
+	}
+}
+
+func webAuthnRawID(credential *authinterface.WebAuthnCredentialResponse) string {
+	if credential.RawID != "" {
+		return credential.RawID
+	}
+	return credential.ID
+}
@@ -0,0 +1,177 @@
+package authservice
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	authconstants "{{.Project.GoModule}}/internal/auth/constants"
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	"{{.Project.GoModule}}/internal/core"
+)
+
+type sessionExport struct {
+	ID         uuid.UUID `json:"id"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	IPAddress  string    `json:"ip_address,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+type tokenExport struct {
+	Type      authinterface.TokenType `json:"type"`
+	Used      bool                    `json:"used"`
+	CreatedAt time.Time               `json:"created_at"`
+	ExpiresAt time.Time               `json:"expires_at"`
+}
+
+// ExportAccountData collects everything the installed modules store about
+// an account into one archive.
+func (s *AuthService) ExportAccountData(ctx context.Context, accountID uuid.UUID) (*authinterface.AccountDataExport, error) {
+	account, err := s.accountRepo.GetByID(ctx, accountID)
+	if err != nil {
+		return nil, core.NewAppError(core.ErrCodeNotFound, authconstants.ErrAccountNotFound)
+	}
+
+	subject := core.DataSubject{UserID: account.GetID(), Email: account.GetEmail()}
+
+	var data map[string]any
+	if s.userData != nil {
+		data, err = s.userData.Export(ctx, subject)
+	} else {
+		var auth any
+		auth, err = s.ExportUserData(ctx, subject)
+		data = map[string]any{"auth": auth}
+	}
+	if err != nil {
+		return nil, core.NewAppError(core.ErrCodeInternalServer, "failed to export account data", err)
+	}
+
+	return &authinterface.AccountDataExport{
+		AccountID:  account.GetID(),
+		ExportedAt: time.Now().UTC(),
+		Data:       data,
+	}, nil
+}
+
+// ExportUserData returns the account with its sessions, linked identities,
+// passkeys, API keys, recent login history and tokens. Secrets such as token values and key material are
+// left out.
+func (s *AuthService) ExportUserData(ctx context.Context, subject core.DataSubject) (any, error) {
+	account, err := s.accountRepo.GetByID(ctx, subject.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.sessionStore.ListByUser(ctx, subject.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	sessionExports := make([]sessionExport, 0, len(sessions))
+	for _, session := range sessions {
+		sessionExports = append(sessionExports, sessionExport{
+			ID:         session.GetID(),
+			UserAgent:  session.GetUserAgent(),
+			IPAddress:  session.GetIPAddress(),
+			CreatedAt:  session.GetCreatedAt(),
+			LastSeenAt: session.GetLastSeenAt(),
+			ExpiresAt:  session.GetRefreshExpiresAt(),
+		})
+	}
+
+	identities, err := s.identityRepo.ListByAccount(ctx, subject.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list identities: %w", err)
+	}
+
+	passkeys, err := s.credentialRepo.ListByAccount(ctx, subject.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list passkeys: %w", err)
+	}
+
+	apiKeys, err := s.apiKeyRepo.ListByAccount(ctx, subject.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+
+	loginHistory, err := s.loginHistoryRepo.ListByAccount(ctx, subject.UserID, maxLoginHistoryLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list login history: %w", err)
+	}
+
+	tokens, err := s.tokenRepo.ListByAccount(ctx, subject.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tokens: %w", err)
+	}
+	tokenExports := make([]tokenExport, 0, len(tokens))
+	for _, token := range tokens {
+		tokenExports = append(tokenExports, tokenExport{
+			Type:      token.GetType(),
+			Used:      token.GetUsed(),
+			CreatedAt: token.GetCreatedAt(),
+			ExpiresAt: token.GetExpiresAt(),
+		})
+	}
+
+	return map[string]any{
+		"account":       account,
+		"sessions":      sessionExports,
+		"identities":    identities,
+		"passkeys":      passkeys,
+		"api_keys":      apiKeys,
+		"login_history": loginHistory,
+		"tokens":        tokenExports,
+	}, nil
+}
+
+// DeleteUserData removes everything the auth module keeps about an account
+// except the account row itself, which PurgeDeletedAccounts deletes last.
+func (s *AuthService) DeleteUserData(ctx context.Context, subject core.DataSubject) error {
+	if err := s.endAllSessions(ctx, subject.UserID); err != nil {
+		return fmt.Errorf("failed to delete sessions: %w", err)
+	}
+
+	identities, err := s.identityRepo.ListByAccount(ctx, subject.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to list identities: %w", err)
+	}
+	for _, identity := range identities {
+		if err := s.identityRepo.Delete(ctx, subject.UserID, identity.GetID()); err != nil {
+			return fmt.Errorf("failed to delete identity: %w", err)
+		}
+	}
+
+	passkeys, err := s.credentialRepo.ListByAccount(ctx, subject.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to list passkeys: %w", err)
+	}
+	for _, passkey := range passkeys {
+		if err := s.credentialRepo.Delete(ctx, subject.UserID, passkey.GetID()); err != nil {
+			return fmt.Errorf("failed to delete passkey: %w", err)
+		}
+	}
+
+	if err := s.apiKeyRepo.DeleteByAccount(ctx, subject.UserID); err != nil {
+		return fmt.Errorf("failed to delete api keys: %w", err)
+	}
+
+	if err := s.loginHistoryRepo.DeleteByAccount(ctx, subject.UserID); err != nil {
+		return fmt.Errorf("failed to delete login history: %w", err)
+	}
+
+	if err := s.tokenRepo.DeleteByAccount(ctx, subject.UserID); err != nil {
+		return fmt.Errorf("failed to delete tokens: %w", err)
+	}
+
+	if err := s.recoveryCodeRepo.DeleteByAccount(ctx, subject.UserID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	if err := s.passwordPolicy.Forget(ctx, subject.UserID); err != nil {
+		return fmt.Errorf("failed to delete password history: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,242 @@
+package authservice
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// COSE algorithm identifiers offered to authenticators, in order of
+// preference.
+const (
+	coseAlgES256 int64 = -7
+	coseAlgEdDSA int64 = -8
+	coseAlgRS256 int64 = -257
+)
+
+var webAuthnAlgorithms = []int64{coseAlgES256, coseAlgEdDSA, coseAlgRS256}
+
+const (
+	authDataFlagUserPresent      byte = 0x01
+	authDataFlagUserVerified     byte = 0x04
+	authDataFlagAttestedCredData byte = 0x40
+)
+
+type webAuthnClientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+type webAuthnAuthData struct {
+	RPIDHash     []byte
+	Flags        byte
+	SignCount    uint32
+	CredentialID []byte
+	PublicKey    []byte
+}
+
+// decodeBase64URL accepts base64url with or without padding, as browsers
+// and libraries disagree on it.
+func decodeBase64URL(value string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+}
+
+// parseClientData decodes clientDataJSON and checks the ceremony type and
+// origin. The challenge is checked by the caller, which uses it to look up
+// the pending ceremony.
+func parseClientData(encoded, expectedType string, origins []string) (*webAuthnClientData, []byte, error) {
+	raw, err := decodeBase64URL(encoded)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid clientDataJSON encoding: %w", err)
+	}
+
+	var clientData webAuthnClientData
+	if err := json.Unmarshal(raw, &clientData); err != nil {
+		return nil, nil, fmt.Errorf("invalid clientDataJSON: %w", err)
+	}
+
+	if clientData.Type != expectedType {
+		return nil, nil, fmt.Errorf("unexpected client data type %q", clientData.Type)
+	}
+
+	if clientData.CrossOrigin {
+		return nil, nil, errors.New("cross-origin ceremonies are not allowed")
+	}
+
+	if !containsAny(origins, []string{strings.TrimSuffix(clientData.Origin, "/")}) {
+		return nil, nil, fmt.Errorf("origin %q is not allowed", clientData.Origin)
+	}
+
+	return &clientData, raw, nil
+}
+
+func parseAuthenticatorData(data []byte) (*webAuthnAuthData, error) {
+	if len(data) < 37 {
+		return nil, errors.New("authenticator data too short")
+	}
+
+	authData := &webAuthnAuthData{
+		RPIDHash:  data[:32],
+		Flags:     data[32],
+		SignCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+
+	if authData.Flags&authDataFlagAttestedCredData == 0 {
+		return authData, nil
+	}
+
+	rest := data[37:]
+	if len(rest) < 18 {
+		return nil, errors.New("attested credential data too short")
+	}
+	idLength := int(binary.BigEndian.Uint16(rest[16:18]))
+	rest = rest[18:]
+	if idLength == 0 || len(rest) < idLength {
+		return nil, errors.New("invalid credential id length")
+	}
+	authData.CredentialID = rest[:idLength]
+	rest = rest[idLength:]
+
+	// The COSE key is followed by optional extensions, so its length is
+	// only known after decoding it.
+	_, after, err := decodeCBOR(rest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid credential public key: %w", err)
+	}
+	authData.PublicKey = rest[:len(rest)-len(after)]
+
+	return authData, nil
+}
+
+// verify checks the relying party hash and that the user was present and
+// verified; passkeys replace the password, so verification is mandatory.
+func (d *webAuthnAuthData) verify(rpID string) error {
+	expected := sha256.Sum256([]byte(rpID))
+	if !bytes.Equal(d.RPIDHash, expected[:]) {
+		return errors.New("relying party id mismatch")
+	}
+	if d.Flags&authDataFlagUserPresent == 0 {
+		return errors.New("user presence flag not set")
+	}
+	if d.Flags&authDataFlagUserVerified == 0 {
+		return errors.New("user verification flag not set")
+	}
+	return nil
+}
+
+// parseAttestationObject returns the authenticator data from an attestation
+// object. The attestation statement is not verified: registration asks for
+// "none" attestation, so there is no trust chain to check.
+func parseAttestationObject(encoded string) (*webAuthnAuthData, error) {
+	raw, err := decodeBase64URL(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid attestationObject encoding: %w", err)
+	}
+
+	decoded, _, err := decodeCBOR(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid attestationObject: %w", err)
+	}
+
+	object, ok := decoded.(map[any]any)
+	if !ok {
+		return nil, errors.New("attestationObject is not a map")
+	}
+
+	authDataBytes, ok := object["authData"].([]byte)
+	if !ok {
+		return nil, errors.New("attestationObject has no authData")
+	}
+
+	return parseAuthenticatorData(authDataBytes)
+}
+
+// parseCOSEKey converts a COSE_Key into a Go public key and its algorithm.
+func parseCOSEKey(data []byte) (crypto.PublicKey, int64, error) {
+	decoded, _, err := decodeCBOR(data)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	key, ok := decoded.(map[any]any)
+	if !ok {
+		return nil, 0, errors.New("COSE key is not a map")
+	}
+
+	kty, _ := key[int64(1)].(int64)
+	alg, _ := key[int64(3)].(int64)
+
+	switch alg {
+	case coseAlgES256:
+		crv, _ := key[int64(-1)].(int64)
+		x, _ := key[int64(-2)].([]byte)
+		y, _ := key[int64(-3)].([]byte)
+		if kty != 2 || crv != 1 || len(x) != 32 || len(y) != 32 {
+			return nil, 0, errors.New("invalid ES256 key")
+		}
+		public := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !public.Curve.IsOnCurve(public.X, public.Y) {
+			return nil, 0, errors.New("ES256 point is not on the curve")
+		}
+		return public, alg, nil
+	case coseAlgEdDSA:
+		crv, _ := key[int64(-1)].(int64)
+		x, _ := key[int64(-2)].([]byte)
+		if kty != 1 || crv != 6 || len(x) != ed25519.PublicKeySize {
+			return nil, 0, errors.New("invalid EdDSA key")
+		}
+		return ed25519.PublicKey(x), alg, nil
+	case coseAlgRS256:
+		n, _ := key[int64(-1)].([]byte)
+		e, _ := key[int64(-2)].([]byte)
+		if kty != 3 || len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return nil, 0, errors.New("invalid RS256 key")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, alg, nil
+	default:
+		return nil, 0, fmt.Errorf("unsupported COSE algorithm %d", alg)
+	}
+}
+
+// verifyWebAuthnSignature checks an assertion signature, which covers the
+// authenticator data followed by the SHA-256 of clientDataJSON.
+func verifyWebAuthnSignature(coseKey, authData, clientDataJSON, signature []byte) error {
+	publicKey, alg, err := parseCOSEKey(coseKey)
+	if err != nil {
+		return err
+	}
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte(nil), authData...), clientDataHash[:]...)
+	digest := sha256.Sum256(signed)
+
+	switch alg {
+	case coseAlgES256:
+		if !ecdsa.VerifyASN1(publicKey.(*ecdsa.PublicKey), digest[:], signature) {
+			return errors.New("invalid signature")
+		}
+	case coseAlgEdDSA:
+		if !ed25519.Verify(publicKey.(ed25519.PublicKey), signed, signature) {
+			return errors.New("invalid signature")
+		}
+	case coseAlgRS256:
+		if err := rsa.VerifyPKCS1v15(publicKey.(*rsa.PublicKey), crypto.SHA256, digest[:], signature); err != nil {
+			return errors.New("invalid signature")
+		}
+	}
+
+	return nil
+}
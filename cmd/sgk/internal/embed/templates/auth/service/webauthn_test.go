@@ -0,0 +1,363 @@
+package authservice
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	authconstants "{{.Project.GoModule}}/internal/auth/constants"
+	authinterface "{{.Project.GoModule}}/internal/auth/interface"
+	authmodel "{{.Project.GoModule}}/internal/auth/model"
+	"{{.Project.GoModule}}/internal/auth/repository/memory"
+)
+
+// softAuthenticator is an ES256 platform authenticator in software. It
+// produces the same credentials a browser would pass on from a real one.
+type softAuthenticator struct {
+	t            *testing.T
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	signCount    uint32
+	rpID         string
+	origin       string
+}
+
+func newSoftAuthenticator(t *testing.T) *softAuthenticator {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	credentialID := make([]byte, 16)
+	if _, err := rand.Read(credentialID); err != nil {
+		t.Fatalf("generate credential id: %v", err)
+	}
+
+	return &softAuthenticator{
+		t:            t,
+		key:          key,
+		credentialID: credentialID,
+		rpID:         "localhost",
+		origin:       "http://localhost:8080",
+	}
+}
+
+func (a *softAuthenticator) coseKey() []byte {
+	return encodeCBOR(cborMap{
+		{1, 2},
+		{3, int(coseAlgES256)},
+		{-1, 1},
+		{-2, a.key.PublicKey.X.FillBytes(make([]byte, 32))},
+		{-3, a.key.PublicKey.Y.FillBytes(make([]byte, 32))},
+	})
+}
+
+func (a *softAuthenticator) authenticatorData(attested bool) []byte {
+	rpIDHash := sha256.Sum256([]byte(a.rpID))
+	flags := authDataFlagUserPresent | authDataFlagUserVerified
+	if attested {
+		flags |= authDataFlagAttestedCredData
+	}
+
+	data := append(rpIDHash[:], flags)
+	data = binary.BigEndian.AppendUint32(data, a.signCount)
+	if attested {
+		data = append(data, make([]byte, 16)...) // AAGUID
+		data = binary.BigEndian.AppendUint16(data, uint16(len(a.credentialID)))
+		data = append(data, a.credentialID...)
+		data = append(data, a.coseKey()...)
+	}
+	return data
+}
+
+func (a *softAuthenticator) clientData(ceremonyType, challenge string) []byte {
+	data, err := json.Marshal(webAuthnClientData{
+		Type:      ceremonyType,
+		Challenge: challenge,
+		Origin:    a.origin,
+	})
+	if err != nil {
+		a.t.Fatalf("marshal client data: %v", err)
+	}
+	return data
+}
+
+// create answers a registration ceremony with "none" attestation.
+func (a *softAuthenticator) create(challenge string) *authinterface.WebAuthnCredentialResponse {
+	attestation := encodeCBOR(cborMap{
+		{"fmt", "none"},
+		{"attStmt", cborMap{}},
+		{"authData", a.authenticatorData(true)},
+	})
+	return a.credential(authinterface.WebAuthnAuthenticatorResponse{
+		ClientDataJSON:    encodeBase64URL(a.clientData("webauthn.create", challenge)),
+		AttestationObject: encodeBase64URL(attestation),
+	})
+}
+
+// get answers a login ceremony, bumping the counter first as
+// authenticators do.
+func (a *softAuthenticator) get(challenge string, userID uuid.UUID) *authinterface.WebAuthnCredentialResponse {
+	a.signCount++
+
+	authData := a.authenticatorData(false)
+	clientData := a.clientData("webauthn.get", challenge)
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte(nil), authData...), clientDataHash[:]...))
+
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		a.t.Fatalf("sign assertion: %v", err)
+	}
+
+	return a.credential(authinterface.WebAuthnAuthenticatorResponse{
+		ClientDataJSON:    encodeBase64URL(clientData),
+		AuthenticatorData: encodeBase64URL(authData),
+		Signature:         encodeBase64URL(signature),
+		UserHandle:        encodeBase64URL(userID[:]),
+	})
+}
+
+func (a *softAuthenticator) credential(response authinterface.WebAuthnAuthenticatorResponse) *authinterface.WebAuthnCredentialResponse {
+	id := encodeBase64URL(a.credentialID)
+	return &authinterface.WebAuthnCredentialResponse{ID: id, RawID: id, Type: "public-key", Response: response}
+}
+
+func encodeBase64URL(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+type passkeyTest struct {
+	*testService
+	credentials *fakeCredentials
+	events      *recordingHook
+	account     *authmodel.Account
+}
+
+func newPasskeyTest(t *testing.T) *passkeyTest {
+	t.Helper()
+
+	credentials := newFakeCredentials()
+	events := &recordingHook{}
+	webAuthnSessions := memory.NewWebAuthnSessionStore()
+	registry := NewStrategyRegistry()
+
+	pt := &passkeyTest{credentials: credentials, events: events}
+	pt.testService = newTestService(t, AuthServiceDeps{
+		StrategyRegistry: registry,
+		CredentialRepo:   credentials,
+		WebAuthnSessions: webAuthnSessions,
+		EventHook:        events,
+	})
+
+	strategy := NewWebAuthnStrategy(pt.accounts, credentials, webAuthnSessions, pt.config, events)
+	if err := registry.Register(strategy); err != nil {
+		t.Fatalf("register strategy: %v", err)
+	}
+
+	pt.account = pt.createAccount(t, "passkey@example.com")
+	return pt
+}
+
+func (pt *passkeyTest) register(t *testing.T, authenticator *softAuthenticator) authinterface.WebAuthnCredential {
+	t.Helper()
+	ctx := context.Background()
+
+	options, err := pt.BeginPasskeyRegistration(ctx, pt.account.ID)
+	if err != nil {
+		t.Fatalf("BeginPasskeyRegistration: %v", err)
+	}
+
+	passkey, err := pt.FinishPasskeyRegistration(ctx, pt.account.ID, "laptop", authenticator.create(options.Challenge))
+	if err != nil {
+		t.Fatalf("FinishPasskeyRegistration: %v", err)
+	}
+	return passkey
+}
+
+func (pt *passkeyTest) login(t *testing.T, authenticator *softAuthenticator) (*authinterface.LoginResult, error) {
+	t.Helper()
+	ctx := context.Background()
+
+	options, err := pt.BeginPasskeyLogin(ctx, pt.account.Email)
+	if err != nil {
+		t.Fatalf("BeginPasskeyLogin: %v", err)
+	}
+	return pt.FinishPasskeyLogin(ctx, authenticator.get(options.Challenge, pt.account.ID))
+}
+
+func TestPasskeyRegistrationAndLogin(t *testing.T) {
+	pt := newPasskeyTest(t)
+	authenticator := newSoftAuthenticator(t)
+	ctx := context.Background()
+
+	passkey := pt.register(t, authenticator)
+	if passkey.GetCredentialID() != encodeBase64URL(authenticator.credentialID) {
+		t.Fatalf("credential id = %q, want %q", passkey.GetCredentialID(), encodeBase64URL(authenticator.credentialID))
+	}
+	if passkey.GetName() != "laptop" {
+		t.Fatalf("name = %q, want laptop", passkey.GetName())
+	}
+
+	options, err := pt.BeginPasskeyLogin(ctx, pt.account.Email)
+	if err != nil {
+		t.Fatalf("BeginPasskeyLogin: %v", err)
+	}
+	if len(options.AllowCredentials) != 1 || options.AllowCredentials[0].ID != passkey.GetCredentialID() {
+		t.Fatalf("allowCredentials = %+v, want the registered passkey", options.AllowCredentials)
+	}
+
+	assertion := authenticator.get(options.Challenge, pt.account.ID)
+	result, err := pt.FinishPasskeyLogin(ctx, assertion)
+	if err != nil {
+		t.Fatalf("FinishPasskeyLogin: %v", err)
+	}
+	if result.Session == nil || result.Session.GetUserID() != pt.account.ID {
+		t.Fatalf("FinishPasskeyLogin session = %+v, want one for the account", result.Session)
+	}
+
+	stored, err := pt.credentials.GetByCredentialID(ctx, passkey.GetCredentialID())
+	if err != nil {
+		t.Fatalf("GetByCredentialID: %v", err)
+	}
+	if stored.GetSignCount() != authenticator.signCount {
+		t.Fatalf("stored sign count = %d, want %d", stored.GetSignCount(), authenticator.signCount)
+	}
+
+	// Each challenge can be answered once.
+	_, err = pt.FinishPasskeyLogin(ctx, assertion)
+	assertAppError(t, err, authconstants.ErrPasskeyChallenge)
+}
+
+func TestPasskeyRegistrationRejectsMalformedAttestation(t *testing.T) {
+	authenticator := newSoftAuthenticator(t)
+	valid := authenticator.authenticatorData(true)
+	// The COSE key sits at the end of the attested credential data.
+	truncatedKey := valid[:len(valid)-10]
+
+	tests := []struct {
+		name        string
+		attestation []byte
+	}{
+		{"truncated cbor", encodeCBOR(cborMap{{"fmt", "none"}, {"authData", valid}})[:20]},
+		{"indefinite length map", []byte{0xbf, 0x63, 'f', 'm', 't', 0x64, 'n', 'o', 'n', 'e', 0xff}},
+		{"not a map", encodeCBOR([]any{"none", valid})},
+		{"missing authData", encodeCBOR(cborMap{{"fmt", "none"}})},
+		{"truncated public key", encodeCBOR(cborMap{{"fmt", "none"}, {"authData", truncatedKey}})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pt := newPasskeyTest(t)
+			ctx := context.Background()
+
+			options, err := pt.BeginPasskeyRegistration(ctx, pt.account.ID)
+			if err != nil {
+				t.Fatalf("BeginPasskeyRegistration: %v", err)
+			}
+
+			credential := authenticator.create(options.Challenge)
+			credential.Response.AttestationObject = encodeBase64URL(tt.attestation)
+
+			_, err = pt.FinishPasskeyRegistration(ctx, pt.account.ID, "laptop", credential)
+			assertAppError(t, err, authconstants.ErrInvalidPasskey)
+
+			if passkeys, _ := pt.credentials.ListByAccount(ctx, pt.account.ID); len(passkeys) != 0 {
+				t.Fatalf("stored %d passkeys from a malformed attestation", len(passkeys))
+			}
+		})
+	}
+}
+
+func TestPasskeyLoginRejectsInvalidAssertion(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(a *softAuthenticator, credential *authinterface.WebAuthnCredentialResponse)
+	}{
+		{"wrong origin", func(a *softAuthenticator, credential *authinterface.WebAuthnCredentialResponse) {
+			var clientData webAuthnClientData
+			raw, _ := decodeBase64URL(credential.Response.ClientDataJSON)
+			_ = json.Unmarshal(raw, &clientData)
+			a.origin = "https://evil.example"
+			credential.Response.ClientDataJSON = encodeBase64URL(a.clientData("webauthn.get", clientData.Challenge))
+		}},
+		{"signature by another key", func(a *softAuthenticator, credential *authinterface.WebAuthnCredentialResponse) {
+			other := newSoftAuthenticator(a.t)
+			other.signCount = a.signCount
+			raw, _ := decodeBase64URL(credential.Response.ClientDataJSON)
+			var clientData webAuthnClientData
+			_ = json.Unmarshal(raw, &clientData)
+			credential.Response.Signature = other.get(clientData.Challenge, uuid.Nil).Response.Signature
+		}},
+		{"wrong relying party", func(a *softAuthenticator, credential *authinterface.WebAuthnCredentialResponse) {
+			a.rpID = "evil.example"
+			credential.Response.AuthenticatorData = encodeBase64URL(a.authenticatorData(false))
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pt := newPasskeyTest(t)
+			authenticator := newSoftAuthenticator(t)
+			pt.register(t, authenticator)
+			ctx := context.Background()
+
+			options, err := pt.BeginPasskeyLogin(ctx, pt.account.Email)
+			if err != nil {
+				t.Fatalf("BeginPasskeyLogin: %v", err)
+			}
+
+			credential := authenticator.get(options.Challenge, pt.account.ID)
+			tt.tamper(authenticator, credential)
+
+			_, err = pt.FinishPasskeyLogin(ctx, credential)
+			assertAppError(t, err, authconstants.ErrInvalidPasskey)
+		})
+	}
+}
+
+func TestPasskeyLoginRejectsCounterRegression(t *testing.T) {
+	pt := newPasskeyTest(t)
+	authenticator := newSoftAuthenticator(t)
+	pt.register(t, authenticator)
+
+	for i := 0; i < 2; i++ {
+		if _, err := pt.login(t, authenticator); err != nil {
+			t.Fatalf("login %d: %v", i+1, err)
+		}
+	}
+
+	// A cloned key replays an older counter value.
+	authenticator.signCount = 0
+	_, err := pt.login(t, authenticator)
+	assertAppError(t, err, authconstants.ErrPasskeyCloned)
+
+	events := pt.events.ofType(authinterface.AuthEventPasskeyCounterRegression)
+	if len(events) != 1 {
+		t.Fatalf("counter regression events = %d, want 1", len(events))
+	}
+	if events[0].AccountID != pt.account.ID {
+		t.Fatalf("event account = %s, want %s", events[0].AccountID, pt.account.ID)
+	}
+	if got := events[0].Metadata["stored_sign_count"]; got != uint32(2) {
+		t.Fatalf("stored_sign_count = %v, want 2", got)
+	}
+	if got := events[0].Metadata["presented_sign_count"]; got != uint32(1) {
+		t.Fatalf("presented_sign_count = %v, want 1", got)
+	}
+
+	// The counter is unchanged, so the genuine authenticator still works.
+	authenticator.signCount = 2
+	if _, err := pt.login(t, authenticator); err != nil {
+		t.Fatalf("login after regression: %v", err)
+	}
+}
@@ -42,9 +42,15 @@ type Config struct {
 	RateLimitEnabled bool
 	RateLimitRPM     int
 
-	CORSAllowedOrigins []string
-	CORSAllowedMethods []string
-	CORSAllowedHeaders []string
+	CORSAllowedOrigins   []string
+	CORSAllowedMethods   []string
+	CORSAllowedHeaders   []string
+	CORSAllowCredentials bool
+
+	// TrustedProxies are the IPs and CIDR ranges of the reverse proxies in
+	// front of the server. Client IPs are only read from X-Forwarded-For
+	// when the request came through one of them.
+	TrustedProxies []string
 
 	LogLevel  string
 	LogFormat string
@@ -85,9 +91,12 @@ func LoadConfig() *Config {
 		RateLimitEnabled: getEnvBool(EnvRateLimitEnabled, DefaultRateLimitEnabled),
 		RateLimitRPM:     getEnvInt(EnvRateLimitRPM, DefaultRateLimitRPM),
 
-		CORSAllowedOrigins: getEnvSlice(EnvCORSAllowedOrigins, DefaultCORSOrigins),
-		CORSAllowedMethods: getEnvSlice(EnvCORSAllowedMethods, DefaultCORSMethods),
-		CORSAllowedHeaders: getEnvSlice(EnvCORSAllowedHeaders, DefaultCORSHeaders),
+		CORSAllowedOrigins:   getEnvSlice(EnvCORSAllowedOrigins, DefaultCORSOrigins),
+		CORSAllowedMethods:   getEnvSlice(EnvCORSAllowedMethods, DefaultCORSMethods),
+		CORSAllowedHeaders:   getEnvSlice(EnvCORSAllowedHeaders, DefaultCORSHeaders),
+		CORSAllowCredentials: getEnvBool(EnvCORSAllowCredentials, false),
+
+		TrustedProxies: getEnvSlice(EnvTrustedProxies, nil),
 
 		LogLevel:  getEnv(EnvLogLevel, DefaultLogLevel),
 		LogFormat: getEnv(EnvLogFormat, DefaultLogFormat),
@@ -34,9 +34,12 @@ const (
 	EnvRateLimitEnabled = "RATE_LIMIT_ENABLED"
 	EnvRateLimitRPM     = "RATE_LIMIT_RPM"
 
-	EnvCORSAllowedOrigins = "CORS_ALLOWED_ORIGINS"
-	EnvCORSAllowedMethods = "CORS_ALLOWED_METHODS"
-	EnvCORSAllowedHeaders = "CORS_ALLOWED_HEADERS"
+	EnvCORSAllowedOrigins   = "CORS_ALLOWED_ORIGINS"
+	EnvCORSAllowedMethods   = "CORS_ALLOWED_METHODS"
+	EnvCORSAllowedHeaders   = "CORS_ALLOWED_HEADERS"
+	EnvCORSAllowCredentials = "CORS_ALLOW_CREDENTIALS"
+
+	EnvTrustedProxies = "TRUSTED_PROXIES"
 
 	EnvLogLevel  = "LOG_LEVEL"
 	EnvLogFormat = "LOG_FORMAT"
@@ -146,4 +149,5 @@ const (
 	ErrMsgServerStart        = "Server failed to start: %v"
 	ErrMsgModuleRegistration = "Failed to register %s module: %v"
 	ErrMsgConfigValidation   = "Configuration validation failed: %v"
+	ErrMsgTrustedProxy       = "Invalid trusted proxy %q: want an IP address or CIDR range"
 )
@@ -2,6 +2,8 @@ package core
 
 import (
 	"fmt"
+	"net"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -30,13 +32,20 @@ func ProvideEcho(i *do.Injector) (*echo.Echo, error) {
 
 	e := echo.New()
 
+	ipExtractor, err := newIPExtractor(config.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	e.IPExtractor = ipExtractor
+
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
 
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins: config.CORSAllowedOrigins,
-		AllowMethods: config.CORSAllowedMethods,
-		AllowHeaders: config.CORSAllowedHeaders,
+		AllowOrigins:     config.CORSAllowedOrigins,
+		AllowMethods:     config.CORSAllowedMethods,
+		AllowHeaders:     config.CORSAllowedHeaders,
+		AllowCredentials: config.CORSAllowCredentials,
 	}))
 
 	if config.RateLimitEnabled {
@@ -48,6 +57,40 @@ func ProvideEcho(i *do.Injector) (*echo.Echo, error) {
 	return e, nil
 }
 
+// newIPExtractor reads the client IP from the connection, unless trusted
+// proxies are configured, in which case X-Forwarded-For is followed back
+// through them. Forwarding headers from anyone else are ignored, since
+// clients can set them to anything.
+func newIPExtractor(trustedProxies []string) (echo.IPExtractor, error) {
+	if len(trustedProxies) == 0 {
+		return echo.ExtractIPDirect(), nil
+	}
+
+	options := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, proxy := range trustedProxies {
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil {
+				if ip.To4() != nil {
+					proxy += "/32"
+				} else {
+					proxy += "/128"
+				}
+			}
+		}
+		_, ipRange, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf(ErrMsgTrustedProxy, proxy)
+		}
+		options = append(options, echo.TrustIPRange(ipRange))
+	}
+
+	return echo.ExtractIPFromXFFHeader(options...), nil
+}
+
 func ProvideDatabase(i *do.Injector) (*gorm.DB, error) {
 	config := do.MustInvoke[*Config](i)
 
@@ -63,6 +106,7 @@ func RegisterCoreServices(container *Container) error {
 	do.Provide(container, ProvideConfig)
 	do.Provide(container, ProvideEcho)
 	do.Provide(container, ProvideDatabase)
+	do.Provide(container, ProvideUserDataRegistry)
 
 	return nil
 }
@@ -0,0 +1,81 @@
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/samber/do"
+)
+
+// clientIP serves one request from remoteAddr through the echo instance
+// ProvideEcho builds and returns the IP handlers see.
+func clientIP(t *testing.T, config *Config, remoteAddr string, headers map[string]string) string {
+	t.Helper()
+
+	injector := do.New()
+	do.ProvideValue(injector, config)
+	e, err := ProvideEcho(injector)
+	if err != nil {
+		t.Fatalf("ProvideEcho: %v", err)
+	}
+	e.GET("/ip", func(c echo.Context) error {
+		return c.String(http.StatusOK, c.RealIP())
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+	req.RemoteAddr = remoteAddr
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec.Body.String()
+}
+
+func TestClientIPIgnoresSpoofedHeaders(t *testing.T) {
+	spoofed := map[string]string{
+		echo.HeaderXForwardedFor: "198.51.100.1",
+		echo.HeaderXRealIP:       "198.51.100.2",
+	}
+
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		remoteAddr     string
+		want           string
+	}{
+		{"no proxies", nil, "203.0.113.7:4000", "203.0.113.7"},
+		{"loopback is not trusted by default", nil, "127.0.0.1:4000", "127.0.0.1"},
+		{"untrusted peer", []string{"10.0.0.1"}, "203.0.113.7:4000", "203.0.113.7"},
+		{"trusted proxy", []string{"10.0.0.1"}, "10.0.0.1:4000", "198.51.100.1"},
+		{"trusted range", []string{"10.0.0.0/8"}, "10.1.2.3:4000", "198.51.100.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{TrustedProxies: tt.trustedProxies}
+			if got := clientIP(t, config, tt.remoteAddr, spoofed); got != tt.want {
+				t.Fatalf("client IP = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientIPSkipsTrustedHops(t *testing.T) {
+	config := &Config{TrustedProxies: []string{"10.0.0.0/8"}}
+	headers := map[string]string{echo.HeaderXForwardedFor: "198.51.100.1, 203.0.113.9, 10.0.0.2"}
+
+	if got := clientIP(t, config, "10.0.0.1:4000", headers); got != "203.0.113.9" {
+		t.Fatalf("client IP = %q, want the address the proxies received the request from", got)
+	}
+}
+
+func TestInvalidTrustedProxy(t *testing.T) {
+	injector := do.New()
+	do.ProvideValue(injector, &Config{TrustedProxies: []string{"proxy.internal"}})
+	if _, err := ProvideEcho(injector); err == nil {
+		t.Fatal("ProvideEcho accepted a trusted proxy that is not an IP or range")
+	}
+}
@@ -33,13 +33,15 @@ func NewAppError(code, message string, cause ...error) *AppError {
 }
 
 const (
-	ErrCodeValidation     = "VALIDATION_ERROR"
-	ErrCodeNotFound       = "NOT_FOUND"
-	ErrCodeUnauthorized   = "UNAUTHORIZED"
-	ErrCodeForbidden      = "FORBIDDEN"
-	ErrCodeConflict       = "CONFLICT"
-	ErrCodeInternalServer = "INTERNAL_SERVER_ERROR"
-	ErrCodeBadRequest     = "BAD_REQUEST"
+	ErrCodeValidation      = "VALIDATION_ERROR"
+	ErrCodeNotFound        = "NOT_FOUND"
+	ErrCodeUnauthorized    = "UNAUTHORIZED"
+	ErrCodeForbidden       = "FORBIDDEN"
+	ErrCodeConflict        = "CONFLICT"
+	ErrCodeInternalServer  = "INTERNAL_SERVER_ERROR"
+	ErrCodeBadRequest      = "BAD_REQUEST"
+	ErrCodeAccountLocked   = "ACCOUNT_LOCKED"
+	ErrCodeTooManyRequests = "TOO_MANY_REQUESTS"
 )
 
 func NewValidationError(message string) *AppError {
@@ -0,0 +1,51 @@
+package core
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/samber/do"
+)
+
+// RoleLookup resolves the roles and permissions of a user. It is provided by
+// the role module so other modules can depend on roles without importing it.
+type RoleLookup interface {
+	GetUserRoleNames(ctx context.Context, userID uuid.UUID) ([]string, error)
+	GetUserPermissions(ctx context.Context, userID uuid.UUID) ([]string, error)
+	UserHasPermission(ctx context.Context, userID uuid.UUID, permission string) (bool, error)
+}
+
+type optionalRoleLookup struct {
+	injector *do.Injector
+}
+
+// OptionalRoleLookup returns a RoleLookup that resolves the role module's
+// lookup on first use and reports no roles or permissions when the module is
+// not installed.
+func OptionalRoleLookup(i *do.Injector) RoleLookup {
+	return &optionalRoleLookup{injector: i}
+}
+
+func (l *optionalRoleLookup) GetUserRoleNames(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	lookup, err := do.Invoke[RoleLookup](l.injector)
+	if err != nil {
+		return nil, nil
+	}
+	return lookup.GetUserRoleNames(ctx, userID)
+}
+
+func (l *optionalRoleLookup) GetUserPermissions(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	lookup, err := do.Invoke[RoleLookup](l.injector)
+	if err != nil {
+		return nil, nil
+	}
+	return lookup.GetUserPermissions(ctx, userID)
+}
+
+func (l *optionalRoleLookup) UserHasPermission(ctx context.Context, userID uuid.UUID, permission string) (bool, error) {
+	lookup, err := do.Invoke[RoleLookup](l.injector)
+	if err != nil {
+		return false, nil
+	}
+	return lookup.UserHasPermission(ctx, userID, permission)
+}
@@ -0,0 +1,41 @@
+package core
+
+import (
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ContextKeyScopes holds the scopes a request is limited to. It is set for
+// requests authenticated with an API key; requests without it act with the
+// user's full permissions.
+const ContextKeyScopes = "scopes"
+
+// ScopesAllow reports whether scopes grant permission. A scope matches the
+// permission exactly, through "*", or through a "resource:*" wildcard, the
+// same rules role permissions follow.
+func ScopesAllow(scopes []string, permission string) bool {
+	for _, scope := range scopes {
+		if scope == permission || scope == "*" {
+			return true
+		}
+		if strings.HasSuffix(scope, ":*") && strings.HasPrefix(permission, strings.TrimSuffix(scope, "*")) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetScopes returns the scopes the request is limited to and whether it is
+// limited at all.
+func GetScopes(c echo.Context) ([]string, bool) {
+	scopes, ok := c.Get(ContextKeyScopes).([]string)
+	return scopes, ok
+}
+
+// RequestAllows reports whether the request's scopes, if any, grant
+// permission. It does not check the user's own permissions.
+func RequestAllows(c echo.Context, permission string) bool {
+	scopes, ok := GetScopes(c)
+	return !ok || ScopesAllow(scopes, permission)
+}
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/samber/do"
+)
+
+// DataSubject identifies the user whose personal data is exported or erased.
+type DataSubject struct {
+	UserID uuid.UUID
+	Email  string
+}
+
+// UserDataExporter is implemented by modules that store personal data, so a
+// user's data export can include it. The result is encoded as JSON.
+type UserDataExporter interface {
+	ExportUserData(ctx context.Context, subject DataSubject) (any, error)
+}
+
+// UserDataEraser is implemented by modules that remove a user's personal data
+// when their account is purged.
+type UserDataEraser interface {
+	DeleteUserData(ctx context.Context, subject DataSubject) error
+}
+
+// UserDataHandler both exports and erases a module's user data.
+type UserDataHandler interface {
+	UserDataExporter
+	UserDataEraser
+}
+
+// UserDataRegistry collects the user data handlers of every installed module.
+type UserDataRegistry struct {
+	mu       sync.RWMutex
+	handlers map[string]UserDataHandler
+}
+
+func NewUserDataRegistry() *UserDataRegistry {
+	return &UserDataRegistry{handlers: make(map[string]UserDataHandler)}
+}
+
+func ProvideUserDataRegistry(i *do.Injector) (*UserDataRegistry, error) {
+	return NewUserDataRegistry(), nil
+}
+
+// RegisterUserData adds a module's handler to the registry in the container.
+// name becomes the module's key in data exports.
+func RegisterUserData(i *do.Injector, name string, handler UserDataHandler) {
+	do.MustInvoke[*UserDataRegistry](i).Register(name, handler)
+}
+
+func (r *UserDataRegistry) Register(name string, handler UserDataHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers[name] = handler
+}
+
+// Export collects the subject's data from every module, keyed by module name.
+func (r *UserDataRegistry) Export(ctx context.Context, subject DataSubject) (map[string]any, error) {
+	data := make(map[string]any)
+	for _, name := range r.names() {
+		exported, err := r.handler(name).ExportUserData(ctx, subject)
+		if err != nil {
+			return nil, fmt.Errorf("export %s data: %w", name, err)
+		}
+		data[name] = exported
+	}
+	return data, nil
+}
+
+// Erase deletes the subject's data from every module. It keeps going when a
+// module fails so one broken module does not block the rest, and returns the
+// joined errors.
+func (r *UserDataRegistry) Erase(ctx context.Context, subject DataSubject) error {
+	var errs []error
+	for _, name := range r.names() {
+		if err := r.handler(name).DeleteUserData(ctx, subject); err != nil {
+			errs = append(errs, fmt.Errorf("erase %s data: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r *UserDataRegistry) names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.handlers))
+	for name := range r.handlers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (r *UserDataRegistry) handler(name string) UserDataHandler {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.handlers[name]
+}
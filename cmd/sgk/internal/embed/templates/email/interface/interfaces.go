@@ -3,20 +3,22 @@ package emailinterface
 import (
 	"context"
 	"time"
+
+	"{{.Project.GoModule}}/internal/core"
 )
 
 type EmailMessage struct {
 	ID          uint                   `json:"id"`
-	To          []string              `json:"to"`
-	CC          []string              `json:"cc,omitempty"`
-	BCC         []string              `json:"bcc,omitempty"`
+	To          []string              `json:"to" gorm:"serializer:json"`
+	CC          []string              `json:"cc,omitempty" gorm:"serializer:json"`
+	BCC         []string              `json:"bcc,omitempty" gorm:"serializer:json"`
 	From        string                `json:"from"`
 	Subject     string                `json:"subject"`
 	Body        string                `json:"body"`
 	HTML        string                `json:"html,omitempty"`
 	Template    string                `json:"template,omitempty"`
-	TemplateData map[string]interface{} `json:"template_data,omitempty"`
-	Attachments []Attachment          `json:"attachments,omitempty"`
+	TemplateData map[string]interface{} `json:"template_data,omitempty" gorm:"serializer:json"`
+	Attachments []Attachment          `json:"attachments,omitempty" gorm:"serializer:json"`
 	Priority    EmailPriority         `json:"priority"`
 	Status      EmailStatus           `json:"status"`
 	Attempts    int                   `json:"attempts"`
@@ -61,7 +63,7 @@ type EmailTemplate struct {
 	Subject   string    `json:"subject"`
 	Body      string    `json:"body"`
 	HTML      string    `json:"html"`
-	Variables []string  `json:"variables"`
+	Variables []string  `json:"variables" gorm:"serializer:json"`
 	Active    bool      `json:"active"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -83,6 +85,8 @@ type EmailQueue interface {
 	MarkAsFailed(ctx context.Context, id uint, err error) error
 	RetryFailed(ctx context.Context) error
 	GetStatus(ctx context.Context, id uint) (*EmailMessage, error)
+	FindByRecipient(ctx context.Context, email string) ([]*EmailMessage, error)
+	DeleteByRecipient(ctx context.Context, email string) error
 }
 
 type TemplateRepository interface {
@@ -100,4 +104,6 @@ type EmailService interface {
 	QueueEmail(ctx context.Context, message *EmailMessage) error
 	ProcessQueue(ctx context.Context) error
 	GetEmailStatus(ctx context.Context, id uint) (*EmailMessage, error)
+	ExportUserData(ctx context.Context, subject core.DataSubject) (any, error)
+	DeleteUserData(ctx context.Context, subject core.DataSubject) error
 }
\ No newline at end of file
@@ -42,14 +42,21 @@ func ProvideTemplateManager(i *do.Injector) (emailinterface.TemplateManager, err
 }
 
 func ProvideEmailSender(i *do.Injector) (emailinterface.EmailSender, error) {
-	smtpHost := os.Getenv("SMTP_HOST")
+	if emailSender == "mock" {
+		return emailservice.NewMockSender(true), nil
+	}
+
+	smtpHost := os.Getenv(core.EnvSMTPHost)
 	if smtpHost == "" {
+		if emailSender == "smtp" {
+			return nil, fmt.Errorf("%s is required for the smtp email sender", core.EnvSMTPHost)
+		}
 		return emailservice.NewMockSender(true), nil
 	}
 	
-	smtpPort, err := strconv.Atoi(getEnvWithDefault("SMTP_PORT", "587"))
+	smtpPort, err := strconv.Atoi(getEnvWithDefault(core.EnvSMTPPort, "587"))
 	if err != nil {
-		return nil, fmt.Errorf("invalid SMTP_PORT: %w", err)
+		return nil, fmt.Errorf("invalid %s: %w", core.EnvSMTPPort, err)
 	}
 	
 	useTLS, _ := strconv.ParseBool(getEnvWithDefault("SMTP_TLS", "true"))
@@ -58,9 +65,9 @@ func ProvideEmailSender(i *do.Injector) (emailinterface.EmailSender, error) {
 	config := emailservice.SMTPConfig{
 		Host:       smtpHost,
 		Port:       smtpPort,
-		Username:   os.Getenv("SMTP_USERNAME"),
-		Password:   os.Getenv("SMTP_PASSWORD"),
-		From:       getEnvWithDefault("SMTP_FROM", "noreply@example.com"),
+		Username:   os.Getenv(core.EnvSMTPUser),
+		Password:   os.Getenv(core.EnvSMTPPassword),
+		From:       getEnvWithDefault(core.EnvSMTPFrom, core.DefaultSMTPFrom),
 		FromName:   getEnvWithDefault("SMTP_FROM_NAME", ""),
 		TLS:        useTLS,
 		SkipVerify: skipVerify,
@@ -108,7 +115,9 @@ func RegisterModule(container *core.Container) error {
 	e := do.MustInvoke[*echo.Echo](container)
 	emailController := do.MustInvoke[*emailcontroller.EmailController](container)
 	
-	emailController.RegisterRoutes(e, "/api/v1/email")
+	emailController.RegisterRoutes(e, "{{.Module.RoutePrefix}}")
+	
+	core.RegisterUserData(container, "email", do.MustInvoke[emailinterface.EmailService](container))
 	
 	return nil
 }
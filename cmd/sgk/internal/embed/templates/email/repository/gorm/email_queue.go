@@ -2,6 +2,7 @@ package emailgorm
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -96,4 +97,27 @@ func (r *EmailQueueRepository) GetStatus(ctx context.Context, id uint) (*emailin
 	var message emailinterface.EmailMessage
 	err := r.db.WithContext(ctx).First(&message, id).Error
 	return &message, err
-}
\ No newline at end of file
+}
+
+// FindByRecipient returns the messages sent to email as a To, CC or BCC
+// recipient, newest first.
+func (r *EmailQueueRepository) FindByRecipient(ctx context.Context, email string) ([]*emailinterface.EmailMessage, error) {
+	var messages []*emailinterface.EmailMessage
+	err := r.recipientQuery(ctx, email).
+		Order("created_at DESC").
+		Find(&messages).Error
+	return messages, err
+}
+
+func (r *EmailQueueRepository) DeleteByRecipient(ctx context.Context, email string) error {
+	return r.recipientQuery(ctx, email).Delete(&emailinterface.EmailMessage{}).Error
+}
+
+// recipientQuery matches email against the JSON encoded recipient lists.
+func (r *EmailQueueRepository) recipientQuery(ctx context.Context, email string) *gorm.DB {
+	pattern := "%" + likeEscaper.Replace(`"`+strings.ToLower(email)+`"`) + "%"
+	return r.db.WithContext(ctx).
+		Where(`LOWER("to") LIKE ? OR LOWER(cc) LIKE ? OR LOWER(bcc) LIKE ?`, pattern, pattern, pattern)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
\ No newline at end of file
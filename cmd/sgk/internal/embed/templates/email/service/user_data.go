@@ -0,0 +1,43 @@
+package emailservice
+
+import (
+	"context"
+	"time"
+
+	"{{.Project.GoModule}}/internal/core"
+)
+
+type emailHistoryEntry struct {
+	ID        uint       `json:"id"`
+	Subject   string     `json:"subject"`
+	Template  string     `json:"template,omitempty"`
+	Status    string     `json:"status"`
+	CreatedAt time.Time  `json:"created_at"`
+	SentAt    *time.Time `json:"sent_at,omitempty"`
+}
+
+// ExportUserData lists the emails sent to the subject. Bodies are left out
+// since they can carry links that are still valid.
+func (s *EmailService) ExportUserData(ctx context.Context, subject core.DataSubject) (any, error) {
+	messages, err := s.queue.FindByRecipient(ctx, subject.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	history := make([]emailHistoryEntry, 0, len(messages))
+	for _, message := range messages {
+		history = append(history, emailHistoryEntry{
+			ID:        message.ID,
+			Subject:   message.Subject,
+			Template:  message.Template,
+			Status:    string(message.Status),
+			CreatedAt: message.CreatedAt,
+			SentAt:    message.SentAt,
+		})
+	}
+	return map[string]any{"messages": history}, nil
+}
+
+func (s *EmailService) DeleteUserData(ctx context.Context, subject core.DataSubject) error {
+	return s.queue.DeleteByRecipient(ctx, subject.Email)
+}
@@ -68,7 +68,7 @@ func RegisterModule(container *core.Container) error {
 
 	e := do.MustInvoke[*echo.Echo](container)
 	healthController := do.MustInvoke[*healthcontroller.HealthController](container)
-	healthController.RegisterRoutes(e, "/api/v1/health")
+	healthController.RegisterRoutes(e, "{{.Module.RoutePrefix}}")
 
 	healthService := do.MustInvoke[healthinterface.HealthService](container)
 	checkInterval := healthconstants.DefaultPeriodicInterval
@@ -24,4 +24,5 @@ type UserRoleRepository interface {
 	FindUserRole(ctx context.Context, userID, roleID uuid.UUID) (UserRole, error)
 	FindActiveUserRoles(ctx context.Context, userID uuid.UUID) ([]UserRole, error)
 	CleanupExpiredRoles(ctx context.Context) error
+	DeleteByUserID(ctx context.Context, userID uuid.UUID) error
 }
\ No newline at end of file
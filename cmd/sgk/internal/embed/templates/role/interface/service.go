@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"{{.Project.GoModule}}/internal/core"
 )
 
 type RoleService interface {
@@ -18,6 +20,7 @@ type RoleService interface {
 	AssignRoleToUser(ctx context.Context, userID, roleID, assignedBy uuid.UUID, expiresAt *time.Time) error
 	UnassignRoleFromUser(ctx context.Context, userID, roleID uuid.UUID) error
 	GetUserRoles(ctx context.Context, userID uuid.UUID) ([]Role, error)
+	GetUserRoleNames(ctx context.Context, userID uuid.UUID) ([]string, error)
 	GetUsersWithRole(ctx context.Context, roleID uuid.UUID) ([]UserRole, error)
 	
 	UserHasPermission(ctx context.Context, userID uuid.UUID, permission string) (bool, error)
@@ -29,4 +32,7 @@ type RoleService interface {
 	GetSystemRoles(ctx context.Context) ([]Role, error)
 	
 	CleanupExpiredRoles(ctx context.Context) error
+	
+	ExportUserData(ctx context.Context, subject core.DataSubject) (any, error)
+	DeleteUserData(ctx context.Context, subject core.DataSubject) error
 }
\ No newline at end of file
@@ -81,6 +81,10 @@ func (m *RBACMiddleware) RequirePermission(permission string) echo.MiddlewareFun
 				return m.config.ErrorHandler(c, err)
 			}
 
+			if !core.RequestAllows(c, permission) {
+				return m.config.ErrorHandler(c, echo.NewHTTPError(http.StatusForbidden, "Missing required scope: "+permission))
+			}
+
 			hasPermission, err := m.service.UserHasPermission(c.Request().Context(), userID, permission)
 			if err != nil {
 				return m.config.ErrorHandler(c, err)
@@ -107,7 +111,18 @@ func (m *RBACMiddleware) RequireAnyPermission(permissions ...string) echo.Middle
 				return m.config.ErrorHandler(c, err)
 			}
 
-			hasPermission, err := m.service.UserHasAnyPermission(c.Request().Context(), userID, permissions)
+			// Only permissions the request's scopes grant can satisfy it
+			scoped := make([]string, 0, len(permissions))
+			for _, permission := range permissions {
+				if core.RequestAllows(c, permission) {
+					scoped = append(scoped, permission)
+				}
+			}
+			if len(scoped) == 0 {
+				return m.config.ErrorHandler(c, echo.NewHTTPError(http.StatusForbidden, "Missing required scopes: "+strings.Join(permissions, ", ")))
+			}
+
+			hasPermission, err := m.service.UserHasAnyPermission(c.Request().Context(), userID, scoped)
 			if err != nil {
 				return m.config.ErrorHandler(c, err)
 			}
@@ -133,6 +148,12 @@ func (m *RBACMiddleware) RequireAllPermissions(permissions ...string) echo.Middl
 				return m.config.ErrorHandler(c, err)
 			}
 
+			for _, permission := range permissions {
+				if !core.RequestAllows(c, permission) {
+					return m.config.ErrorHandler(c, echo.NewHTTPError(http.StatusForbidden, "Missing required scope: "+permission))
+				}
+			}
+
 			hasPermission, err := m.service.UserHasAllPermissions(c.Request().Context(), userID, permissions)
 			if err != nil {
 				return m.config.ErrorHandler(c, err)
@@ -227,6 +248,16 @@ func (m *RBACMiddleware) InjectUserPermissions() echo.MiddlewareFunc {
 				return next(c)
 			}
 
+			// API keys with scopes only carry the scopes the user still holds
+			if scopes, ok := core.GetScopes(c); ok {
+				permissions = make([]string, 0, len(scopes))
+				for _, scope := range scopes {
+					if allowed, err := m.service.UserHasPermission(c.Request().Context(), userID, scope); err == nil && allowed {
+						permissions = append(permissions, scope)
+					}
+				}
+			}
+
 			c.Set(roleconstants.ContextKeyUserPermissions, permissions)
 			return next(c)
 		}
@@ -41,6 +41,10 @@ func ProvideRoleService(i *do.Injector) (roleinterface.RoleService, error) {
 	return roleService, nil
 }
 
+func ProvideRoleLookup(i *do.Injector) (core.RoleLookup, error) {
+	return do.MustInvoke[roleinterface.RoleService](i), nil
+}
+
 func ProvideRBACMiddleware(i *do.Injector) (*rolemiddleware.RBACMiddleware, error) {
 	roleService := do.MustInvoke[roleinterface.RoleService](i)
 	return rolemiddleware.NewRBACMiddleware(roleService), nil
@@ -55,6 +59,7 @@ func RegisterModule(container *core.Container) error {
 	do.Provide(container, ProvideRoleRepository)
 	do.Provide(container, ProvideUserRoleRepository)
 	do.Provide(container, ProvideRoleService)
+	do.Provide(container, ProvideRoleLookup)
 	do.Provide(container, ProvideRBACMiddleware)
 	do.Provide(container, ProvideRoleController)
 	
@@ -62,7 +67,9 @@ func RegisterModule(container *core.Container) error {
 	roleController := do.MustInvoke[*rolecontroller.RoleController](container)
 	rbacMiddleware := do.MustInvoke[*rolemiddleware.RBACMiddleware](container)
 	
-	roleController.RegisterRoutes(e, "/api/v1/roles", rbacMiddleware)
+	roleController.RegisterRoutes(e, "{{.Module.RoutePrefix}}", rbacMiddleware)
+	
+	core.RegisterUserData(container, "roles", do.MustInvoke[roleinterface.RoleService](container))
 	
 	return nil
 }
\ No newline at end of file
@@ -114,4 +114,13 @@ func (r *UserRoleRepository) CleanupExpiredRoles(ctx context.Context) error {
 	return r.db.WithContext(ctx).
 		Where("expires_at IS NOT NULL AND expires_at <= ?", time.Now()).
 		Delete(&rolemodel.DefaultUserRole{}).Error
+}
+
+// DeleteByUserID permanently removes every assignment of the user, including
+// ones already unassigned.
+func (r *UserRoleRepository) DeleteByUserID(ctx context.Context, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Unscoped().
+		Where("user_id = ?", userID).
+		Delete(&rolemodel.DefaultUserRole{}).Error
 }
\ No newline at end of file
@@ -153,6 +153,20 @@ func (s *RoleService) GetUserRoles(ctx context.Context, userID uuid.UUID) ([]rol
 	return roles, nil
 }
 
+func (s *RoleService) GetUserRoleNames(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	roles, err := s.GetUserRoles(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(roles))
+	for i, role := range roles {
+		names[i] = role.GetName()
+	}
+
+	return names, nil
+}
+
 func (s *RoleService) GetUsersWithRole(ctx context.Context, roleID uuid.UUID) ([]roleinterface.UserRole, error) {
 	return s.userRoleRepo.FindByRoleID(ctx, roleID)
 }
@@ -0,0 +1,38 @@
+package roleservice
+
+import (
+	"context"
+	"time"
+
+	"{{.Project.GoModule}}/internal/core"
+)
+
+type roleAssignmentExport struct {
+	Role       string     `json:"role"`
+	AssignedAt time.Time  `json:"assigned_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+}
+
+func (s *RoleService) ExportUserData(ctx context.Context, subject core.DataSubject) (any, error) {
+	userRoles, err := s.userRoleRepo.FindByUserID(ctx, subject.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	assignments := make([]roleAssignmentExport, 0, len(userRoles))
+	for _, userRole := range userRoles {
+		assignment := roleAssignmentExport{
+			AssignedAt: userRole.GetAssignedAt(),
+			ExpiresAt:  userRole.GetExpiresAt(),
+		}
+		if role := userRole.GetRole(); role != nil {
+			assignment.Role = role.GetName()
+		}
+		assignments = append(assignments, assignment)
+	}
+	return map[string]any{"assignments": assignments}, nil
+}
+
+func (s *RoleService) DeleteUserData(ctx context.Context, subject core.DataSubject) error {
+	return s.userRoleRepo.DeleteByUserID(ctx, subject.UserID)
+}
@@ -0,0 +1,90 @@
+package env
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/karurosux/saas-go-kit/cmd/sgk/internal/modules"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+type Issue struct {
+	Severity Severity
+	Name     string
+	Message  string
+}
+
+// Check validates parsed .env values against the schema of the installed
+// modules. Missing required variables are errors, and so are secrets and
+// placeholder values left at their example default in production; unknown
+// variables are warnings.
+func Check(groups []modules.EnvGroup, values map[string]string, keys []string, production bool) []Issue {
+	var issues []Issue
+	known := make(map[string]bool)
+
+	if env := strings.ToLower(values["ENV"]); env == "production" || env == "prod" {
+		production = true
+	}
+
+	for _, group := range groups {
+		for _, v := range group.Vars {
+			known[v.Name] = true
+			value, set := values[v.Name]
+
+			if v.Required && (!set || value == "") {
+				issues = append(issues, Issue{
+					Severity: SeverityError,
+					Name:     v.Name,
+					Message:  fmt.Sprintf("missing, required by %s", group.Module),
+				})
+				continue
+			}
+
+			if production && (v.Secret || v.ChangeInProduction) && set && v.Default != "" && value == v.Default {
+				issues = append(issues, Issue{
+					Severity: SeverityError,
+					Name:     v.Name,
+					Message:  "still set to the example default in production",
+				})
+			}
+		}
+	}
+
+	owners := make(map[string]string)
+	for _, group := range modules.GetEnvSchema(moduleNames()) {
+		for _, v := range group.Vars {
+			owners[v.Name] = group.Module
+		}
+	}
+
+	for _, key := range keys {
+		if known[key] {
+			continue
+		}
+		message := "unknown variable, not used by any installed module"
+		if owner, ok := owners[key]; ok {
+			message = fmt.Sprintf("only used by the %s module, which is not installed", owner)
+		}
+		issues = append(issues, Issue{
+			Severity: SeverityWarning,
+			Name:     key,
+			Message:  message,
+		})
+	}
+
+	return issues
+}
+
+func moduleNames() []string {
+	var names []string
+	for name := range modules.GetAvailableModules() {
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,82 @@
+package env
+
+import (
+	"testing"
+
+	"github.com/karurosux/saas-go-kit/cmd/sgk/internal/modules"
+)
+
+var testGroups = []modules.EnvGroup{{
+	Module: "core",
+	Vars: []modules.EnvVar{
+		{Name: "PORT", Default: "8080"},
+		{Name: "ENV", Default: "development"},
+		{Name: "DATABASE_URL", Required: true, Secret: true},
+		{Name: "JWT_SECRET", Default: "change-me", Required: true, Secret: true},
+		{Name: "BASE_URL", Default: "http://localhost:8080", ChangeInProduction: true},
+	},
+}}
+
+func check(t *testing.T, content string, production bool) map[string]Issue {
+	t.Helper()
+
+	values, keys, err := Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	issues := make(map[string]Issue)
+	for _, issue := range Check(testGroups, values, keys, production) {
+		issues[issue.Name] = issue
+	}
+	return issues
+}
+
+func TestCheckRequired(t *testing.T) {
+	issues := check(t, "DATABASE_URL=\nJWT_SECRET=secret\n", false)
+
+	if issues["DATABASE_URL"].Severity != SeverityError {
+		t.Fatalf("issues = %v, want DATABASE_URL missing", issues)
+	}
+	if len(issues) != 1 {
+		t.Fatalf("issues = %v, want only DATABASE_URL", issues)
+	}
+}
+
+func TestCheckUnknown(t *testing.T) {
+	issues := check(t, "DATABASE_URL=db\nJWT_SECRET=secret\nSMTP_HOST=mail\nNOT_USED=1\n", false)
+
+	if issues["NOT_USED"].Severity != SeverityWarning {
+		t.Fatalf("issues = %v, want NOT_USED reported", issues)
+	}
+	if issue := issues["SMTP_HOST"]; issue.Severity != SeverityWarning || issue.Message != "only used by the email module, which is not installed" {
+		t.Fatalf("SMTP_HOST issue = %+v, want it attributed to the email module", issue)
+	}
+}
+
+func TestCheckDefaultsInProduction(t *testing.T) {
+	content := "DATABASE_URL=db\nJWT_SECRET=change-me\nBASE_URL=http://localhost:8080\nPORT=8080\n"
+
+	if issues := check(t, content, false); len(issues) != 0 {
+		t.Fatalf("issues = %v, want none outside production", issues)
+	}
+
+	issues := check(t, content, true)
+	for _, name := range []string{"JWT_SECRET", "BASE_URL"} {
+		if issues[name].Severity != SeverityError {
+			t.Fatalf("issues = %v, want %s left at its default", issues, name)
+		}
+	}
+	if _, ok := issues["PORT"]; ok {
+		t.Fatalf("issues = %v, want PORT allowed at its default", issues)
+	}
+
+	if issues := check(t, "ENV=production\n"+content, false); len(issues) != 2 {
+		t.Fatalf("issues = %v, want ENV=production to enable the production checks", issues)
+	}
+
+	changed := "DATABASE_URL=db\nJWT_SECRET=s3cret\nBASE_URL=https://app.example.com\n"
+	if issues := check(t, changed, true); len(issues) != 0 {
+		t.Fatalf("issues = %v, want none once the defaults are replaced", issues)
+	}
+}
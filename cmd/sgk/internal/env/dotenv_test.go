@@ -0,0 +1,48 @@
+package env
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	content := []byte(`# comment
+PORT=8080
+
+export ENV=production
+  DATABASE_URL = "postgres://localhost/app"
+JWT_SECRET='a b c'
+EMPTY=
+MISMATCHED="value'
+PORT=9090
+`)
+
+	values, keys, err := Parse(content)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	wantValues := map[string]string{
+		"PORT":         "9090",
+		"ENV":          "production",
+		"DATABASE_URL": "postgres://localhost/app",
+		"JWT_SECRET":   "a b c",
+		"EMPTY":        "",
+		"MISMATCHED":   `"value'`,
+	}
+	if !reflect.DeepEqual(values, wantValues) {
+		t.Fatalf("values = %v, want %v", values, wantValues)
+	}
+
+	wantKeys := []string{"PORT", "ENV", "DATABASE_URL", "JWT_SECRET", "EMPTY", "MISMATCHED"}
+	if !reflect.DeepEqual(keys, wantKeys) {
+		t.Fatalf("keys = %v, want %v", keys, wantKeys)
+	}
+}
+
+func TestParseRejectsLinesWithoutValue(t *testing.T) {
+	_, _, err := Parse([]byte("PORT=8080\nNOT A PAIR\n"))
+	if err == nil || err.Error() != "line 2: expected KEY=VALUE" {
+		t.Fatalf("error = %v, want line 2 to be rejected", err)
+	}
+}
@@ -0,0 +1,147 @@
+package env
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/karurosux/saas-go-kit/cmd/sgk/internal/modules"
+	"github.com/karurosux/saas-go-kit/cmd/sgk/internal/project"
+)
+
+const ExampleFile = ".env.example"
+
+// Schema returns the env schema of the installed modules with defaults
+// adjusted to the project (database URL, route prefixes, email sender).
+func Schema(config *project.ProjectConfig) []modules.EnvGroup {
+	installed := make([]string, 0, len(config.Modules))
+	for name := range config.Modules {
+		installed = append(installed, name)
+	}
+	sort.Strings(installed)
+
+	overrides := projectDefaults(config)
+
+	groups := modules.GetEnvSchema(installed)
+	for i, group := range groups {
+		vars := make([]modules.EnvVar, len(group.Vars))
+		for j, v := range group.Vars {
+			if value, ok := overrides[v.Name]; ok {
+				v.Default = value
+			}
+			vars[j] = v
+		}
+		groups[i].Vars = vars
+	}
+
+	return groups
+}
+
+// Example renders .env.example for the installed modules. Variables tied to
+// a feature that is not enabled (e.g. an OAuth provider) are left out.
+func Example(config *project.ProjectConfig) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("# Generated by sgk from the installed modules.\n")
+	buf.WriteString("# Run 'sgk env sync' to regenerate and 'sgk env check' to validate your .env.\n")
+
+	writeVars(&buf, config, nil)
+	return buf.Bytes()
+}
+
+// Merge appends the variables of the installed modules that are missing
+// from an existing .env.example, keeping the lines already there as they
+// are. Without an existing file it renders the full example.
+func Merge(existing []byte, config *project.ProjectConfig) []byte {
+	if len(bytes.TrimSpace(existing)) == 0 {
+		return Example(config)
+	}
+
+	present := make(map[string]bool)
+	for _, line := range strings.Split(string(existing), "\n") {
+		line = strings.TrimPrefix(strings.TrimSpace(line), "export ")
+		if key, _, found := strings.Cut(line, "="); found && !strings.HasPrefix(line, "#") {
+			present[strings.TrimSpace(key)] = true
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.Write(existing)
+	if !bytes.HasSuffix(existing, []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+	writeVars(&buf, config, present)
+	return buf.Bytes()
+}
+
+// writeVars writes each module's variables under a header, skipping the
+// names in present and groups left with nothing to write.
+func writeVars(buf *bytes.Buffer, config *project.ProjectConfig, present map[string]bool) {
+	features := enabledFeatures(config)
+
+	for _, group := range Schema(config) {
+		var vars []modules.EnvVar
+		for _, v := range group.Vars {
+			if present[v.Name] || (v.Feature != "" && !features[v.Feature]) {
+				continue
+			}
+			vars = append(vars, v)
+		}
+		if len(vars) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(buf, "\n# --- %s ---\n", group.Module)
+		for _, v := range vars {
+			var flags []string
+			if v.Required {
+				flags = append(flags, "required")
+			}
+			if v.Secret {
+				flags = append(flags, "secret")
+			}
+
+			description := v.Description
+			if len(flags) > 0 {
+				description = fmt.Sprintf("%s (%s)", description, strings.Join(flags, ", "))
+			}
+			fmt.Fprintf(buf, "# %s\n%s=%s\n", description, v.Name, v.Default)
+		}
+	}
+}
+
+func projectDefaults(config *project.ProjectConfig) map[string]string {
+	overrides := make(map[string]string)
+
+	overrides["DATABASE_URL"], overrides["DB_PORT"] = project.DatabaseDefaults(config.Project.Database)
+
+	if auth, ok := config.Modules["auth"]; ok {
+		prefix := auth.Options["route_prefix"]
+		if prefix == "" {
+			if def, err := modules.GetModule("auth"); err == nil {
+				prefix = def.RoutePrefix
+			}
+		}
+		overrides["GOOGLE_OAUTH_REDIRECT_URI"] = fmt.Sprintf("http://localhost:8080%s/oauth/google/callback", prefix)
+		overrides["GITHUB_OAUTH_REDIRECT_URI"] = fmt.Sprintf("http://localhost:8080%s/oauth/github/callback", prefix)
+		overrides["OIDC_REDIRECT_URI"] = fmt.Sprintf("http://localhost:8080%s/oauth/oidc/callback", prefix)
+	}
+
+	if email, ok := config.Modules["email"]; ok && email.Options["email_sender"] == "mock" {
+		overrides["SMTP_HOST"] = ""
+	}
+
+	return overrides
+}
+
+func enabledFeatures(config *project.ProjectConfig) map[string]bool {
+	features := make(map[string]bool)
+	for _, info := range config.Modules {
+		for _, provider := range strings.Split(info.Options["oauth_providers"], ",") {
+			if provider = strings.TrimSpace(provider); provider != "" {
+				features[provider] = true
+			}
+		}
+	}
+	return features
+}
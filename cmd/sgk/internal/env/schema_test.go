@@ -0,0 +1,86 @@
+package env
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/karurosux/saas-go-kit/cmd/sgk/internal/project"
+)
+
+func testConfig(modules map[string]map[string]string) *project.ProjectConfig {
+	config := &project.ProjectConfig{Modules: make(map[string]project.ModuleInfo)}
+	config.Project.Database = "postgres"
+	for name, options := range modules {
+		config.Modules[name] = project.ModuleInfo{Options: options}
+	}
+	return config
+}
+
+func TestExample(t *testing.T) {
+	config := testConfig(map[string]map[string]string{
+		"auth":  {"route_prefix": "/auth", "oauth_providers": "github"},
+		"email": {"email_sender": "mock"},
+	})
+
+	example := string(Example(config))
+
+	for _, want := range []string{
+		"# --- core ---\n",
+		"# --- auth ---\n",
+		"# Secret used to sign access tokens (required, secret)\nJWT_SECRET=",
+		"GITHUB_OAUTH_REDIRECT_URI=http://localhost:8080/auth/oauth/github/callback\n",
+		"SMTP_HOST=\n",
+	} {
+		if !strings.Contains(example, want) {
+			t.Fatalf("example does not contain %q:\n%s", want, example)
+		}
+	}
+
+	for _, unwanted := range []string{"GOOGLE_OAUTH_CLIENT_ID", "OIDC_ISSUER_URL", "# --- health ---"} {
+		if strings.Contains(example, unwanted) {
+			t.Fatalf("example contains %q:\n%s", unwanted, example)
+		}
+	}
+
+	values, _, err := Parse([]byte(example))
+	if err != nil {
+		t.Fatalf("example does not parse: %v", err)
+	}
+	if issues := Check(Schema(config), values, nil, false); len(issues) != 0 {
+		t.Fatalf("example fails its own check: %v", issues)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	existing := "# my notes\nPORT=3000\nexport JWT_SECRET=kept\n# DB_HOST=commented out\nCUSTOM=1"
+	config := testConfig(map[string]map[string]string{"health": nil})
+
+	merged := string(Merge([]byte(existing), config))
+
+	if !strings.HasPrefix(merged, existing+"\n") {
+		t.Fatalf("existing lines were changed:\n%s", merged)
+	}
+	added := strings.TrimPrefix(merged, existing+"\n")
+	for _, name := range []string{"PORT=", "JWT_SECRET="} {
+		if strings.Contains(added, "\n"+name) {
+			t.Fatalf("%s was added again:\n%s", name, added)
+		}
+	}
+	for _, want := range []string{"\nDB_HOST=localhost\n", "# --- health ---\n", "\nAPP_VERSION=1.0.0\n"} {
+		if !strings.Contains(added, want) {
+			t.Fatalf("merged file does not add %q:\n%s", want, added)
+		}
+	}
+
+	if again := string(Merge([]byte(merged), config)); again != merged {
+		t.Fatalf("merging twice changed the file:\n%s", again)
+	}
+}
+
+func TestMergeWithoutFile(t *testing.T) {
+	config := testConfig(nil)
+
+	if string(Merge(nil, config)) != string(Example(config)) {
+		t.Fatal("merging into a missing file did not render the full example")
+	}
+}
@@ -13,6 +13,8 @@ type ModuleDefinition struct {
 	ContainerServices    map[string]string `json:"container_services"`
 	Files                []string          `json:"files"`
 	Database             string            `json:"database"`
+	RoutePrefix          string            `json:"route_prefix"`
+	Env                  []EnvVar          `json:"env"`
 	Options              map[string]string `json:"options"`
 }
 
@@ -38,6 +40,55 @@ var availableModules = map[string]ModuleDefinition{
 		Name:        "auth",
 		Version:     "1.0.0",
 		Description: "Complete authentication system with JWT, email verification, password reset",
+		RoutePrefix: "/api/v1/auth",
+		Env: []EnvVar{
+			{Name: "BASE_URL", Description: "Public URL used in verification and reset links", Default: "http://localhost:8080", ChangeInProduction: true},
+			{Name: "JWT_SIGNING_KEY_FILE", Description: "RSA or Ed25519 private key (PEM) used to sign tokens instead of JWT_SECRET"},
+			{Name: "JWT_VERIFICATION_KEY_FILES", Description: "Comma-separated PEM keys still accepted for verification during key rotation"},
+			{Name: "JWT_ISSUER", Description: "Issuer (iss) claim of access tokens (defaults to the project name)"},
+			{Name: "JWT_AUDIENCE", Description: "Comma-separated audience (aud) claim of access tokens"},
+			{Name: "JWT_ROLE_CLAIMS", Description: "Embed roles and permissions in access tokens when the role module is installed", Default: "true"},
+			{Name: "AUTH_STATELESS_VALIDATION", Description: "Trust signed token claims and only check the revocation list on each request", Default: "false"},
+			{Name: "MAGIC_LINK_DEVICE_BINDING", Description: "Require magic links to be opened on the device that requested them", Default: "false"},
+			{Name: "WEBAUTHN_RP_ID", Description: "Passkey relying party ID (defaults to the host of the first origin)"},
+			{Name: "WEBAUTHN_RP_NAME", Description: "Passkey relying party name shown by authenticators (defaults to the project name)"},
+			{Name: "WEBAUTHN_ORIGINS", Description: "Comma-separated origins allowed to use passkeys (defaults to BASE_URL)"},
+			{Name: "PASSWORD_HASH_ALGORITHM", Description: "Algorithm for new password hashes (argon2id or bcrypt); the other is still accepted and upgraded on login", Default: "argon2id"},
+			{Name: "BCRYPT_COST", Description: "bcrypt cost used when PASSWORD_HASH_ALGORITHM is bcrypt", Default: "12"},
+			{Name: "PASSWORD_MIN_LENGTH", Description: "Minimum password length", Default: "8"},
+			{Name: "PASSWORD_REQUIRED_CLASSES", Description: "Comma-separated character classes passwords must contain (upper, lower, digit, symbol)"},
+			{Name: "PASSWORD_HISTORY", Description: "Number of previous passwords that cannot be reused (0 disables)", Default: "0"},
+			{Name: "PASSWORD_BREACHED_LIST_FILE", Description: "Breached password list, one password or SHA-1 hash per line, or a bloom filter file"},
+			{Name: "PHONE_VERIFICATION_REQUIRED", Description: "Require a verified phone number before users can sign in", Default: "false"},
+			{Name: "SMS_PROVIDER_URL", Description: "HTTP endpoint that sends SMS messages (codes are printed to the console when unset)"},
+			{Name: "SMS_API_KEY", Description: "Bearer token for the SMS endpoint", Secret: true},
+			{Name: "SMS_FROM", Description: "Sender number or name for SMS messages"},
+			{Name: "AUTH_MAX_FAILED_LOGINS", Description: "Failed logins that temporarily lock an account (0 disables lockout)", Default: "5"},
+			{Name: "AUTH_MAX_FAILED_LOGINS_PER_IP", Description: "Failed logins from one IP address before it is blocked (0 disables)", Default: "50"},
+			{Name: "AUTH_LOCKOUT_DURATION", Description: "How long a lockout lasts, as a Go duration", Default: "15m"},
+			{Name: "AUTH_IMPERSONATION_DURATION", Description: "How long an impersonation session started by support staff lasts, as a Go duration", Default: "1h"},
+			{Name: "AUTH_NEW_DEVICE_ALERTS", Description: "Email users when their account signs in from a new device", Default: "true"},
+			{Name: "AUTH_SESSION_STORE", Description: "Where sessions, login attempts and other short-lived auth state are kept (redis, gorm or memory); gorm and memory need no Redis server, memory is for local development and tests", Default: "redis"},
+			{Name: "AUTH_COOKIE_MODE", Description: "Keep sessions in HttpOnly cookies and require a CSRF header on unsafe requests", Default: "false"},
+			{Name: "AUTH_COOKIE_DOMAIN", Description: "Domain attribute of the session cookies (defaults to the API host only)"},
+			{Name: "AUTH_COOKIE_SAMESITE", Description: "SameSite attribute of the session cookies (lax, strict, none)", Default: "lax"},
+			{Name: "AUTH_COOKIE_SECURE", Description: "Only send the session cookies over HTTPS", Default: "true"},
+			{Name: "ACCOUNT_DELETION_GRACE_PERIOD", Description: "How long a deleted account can be restored before it is purged, as a Go duration (0 purges immediately)", Default: "720h"},
+			{Name: "MFA_ISSUER", Description: "Issuer shown in authenticator apps (defaults to the project name)"},
+			{Name: "MFA_REQUIRED_ROLES", Description: "Comma-separated roles that must use two-factor authentication"},
+			{Name: "GOOGLE_OAUTH_CLIENT_ID", Description: "Google OAuth client ID", Feature: "google"},
+			{Name: "GOOGLE_OAUTH_CLIENT_SECRET", Description: "Google OAuth client secret", Secret: true, Feature: "google"},
+			{Name: "GOOGLE_OAUTH_REDIRECT_URI", Description: "Google OAuth callback URL", Default: "http://localhost:8080/api/v1/auth/oauth/google/callback", ChangeInProduction: true, Feature: "google"},
+			{Name: "GITHUB_OAUTH_CLIENT_ID", Description: "GitHub OAuth client ID", Feature: "github"},
+			{Name: "GITHUB_OAUTH_CLIENT_SECRET", Description: "GitHub OAuth client secret", Secret: true, Feature: "github"},
+			{Name: "GITHUB_OAUTH_REDIRECT_URI", Description: "GitHub OAuth callback URL", Default: "http://localhost:8080/api/v1/auth/oauth/github/callback", ChangeInProduction: true, Feature: "github"},
+			{Name: "OIDC_PROVIDER_NAME", Description: "Name the OpenID Connect provider is registered under", Default: "oidc", Feature: "oidc"},
+			{Name: "OIDC_ISSUER_URL", Description: "OpenID Connect issuer URL used for discovery", Feature: "oidc"},
+			{Name: "OIDC_CLIENT_ID", Description: "OpenID Connect client ID", Feature: "oidc"},
+			{Name: "OIDC_CLIENT_SECRET", Description: "OpenID Connect client secret", Secret: true, Feature: "oidc"},
+			{Name: "OIDC_REDIRECT_URI", Description: "OpenID Connect callback URL", Default: "http://localhost:8080/api/v1/auth/oauth/oidc/callback", ChangeInProduction: true, Feature: "oidc"},
+			{Name: "OIDC_SCOPES", Description: "Space-separated OpenID Connect scopes", Default: "openid email profile", Feature: "oidc"},
+		},
 		Dependencies: []string{
 			"github.com/golang-jwt/jwt/v5",
 			"golang.org/x/crypto",
@@ -66,6 +117,13 @@ var availableModules = map[string]ModuleDefinition{
 		Name:         "health",
 		Version:      "1.0.0",
 		Description:  "Application health monitoring with multiple check types",
+		RoutePrefix:  "/api/v1/health",
+		Env: []EnvVar{
+			{Name: "APP_VERSION", Description: "Version reported by the health endpoints", Default: "1.0.0"},
+			{Name: "HEALTH_CHECK_DISK_PATH", Description: "Path checked by the disk space checker", Default: "/"},
+			{Name: "HEALTH_CHECK_INTERVAL", Description: "Interval between periodic health checks", Default: "30s"},
+			{Name: "EXTERNAL_SERVICE_HEALTH_URL", Description: "Optional URL of an external service to check"},
+		},
 		Dependencies: []string{},
 		InternalDependencies: []string{
 			"core",
@@ -86,6 +144,7 @@ var availableModules = map[string]ModuleDefinition{
 		Name:         "role",
 		Version:      "1.0.0",
 		Description:  "Role-based access control and permissions management",
+		RoutePrefix:  "/api/v1/roles",
 		Dependencies: []string{},
 		InternalDependencies: []string{
 			"core",
@@ -109,6 +168,20 @@ var availableModules = map[string]ModuleDefinition{
 		Name:         "email",
 		Version:      "1.0.0",
 		Description:  "Email service with SMTP support, template management, and queue processing",
+		RoutePrefix:  "/api/v1/email",
+		Env: []EnvVar{
+			{Name: "SMTP_HOST", Description: "SMTP server host, leave empty to log emails with the mock sender", Default: "smtp.gmail.com"},
+			{Name: "SMTP_PORT", Description: "SMTP server port", Default: "587"},
+			{Name: "SMTP_USER", Description: "SMTP username", Default: "your-email@gmail.com", ChangeInProduction: true},
+			{Name: "SMTP_PASSWORD", Description: "SMTP password", Default: "your-app-password", Secret: true},
+			{Name: "SMTP_FROM", Description: "Sender address used by the SMTP sender", Default: "noreply@example.com", ChangeInProduction: true},
+			{Name: "SMTP_FROM_NAME", Description: "Sender display name"},
+			{Name: "SMTP_TLS", Description: "Use TLS when connecting to the SMTP server", Default: "true"},
+			{Name: "SMTP_SKIP_VERIFY", Description: "Skip TLS certificate verification", Default: "false"},
+			{Name: "EMAIL_FROM", Description: "Default from address for queued emails", Default: "noreply@example.com", ChangeInProduction: true},
+			{Name: "EMAIL_QUEUE_ENABLED", Description: "Process the email queue in the background", Default: "true"},
+			{Name: "EMAIL_QUEUE_INTERVAL", Description: "Interval between queue runs", Default: "30s"},
+		},
 		Dependencies: []string{},
 		InternalDependencies: []string{
 			"core",
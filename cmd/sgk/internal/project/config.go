@@ -6,6 +6,8 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/karurosux/saas-go-kit/cmd/sgk/internal/staging"
 )
 
 type ProjectConfig struct {
@@ -21,8 +23,9 @@ type ProjectConfig struct {
 }
 
 type ModuleInfo struct {
-	Version     string    `json:"version"`
-	InstalledAt time.Time `json:"installed_at"`
+	Version     string            `json:"version"`
+	InstalledAt time.Time         `json:"installed_at"`
+	Options     map[string]string `json:"options,omitempty"`
 }
 
 const configFileName = "sgk.json"
@@ -48,11 +51,26 @@ func InitProjectWithConfig(projectName, goModule, database string) error {
 		goModule = projectName
 	}
 
+	config := NewProjectConfig(projectName, goModule, database)
+
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	if err := os.WriteFile(configFileName, data, 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	return nil
+}
+
+func NewProjectConfig(projectName, goModule, database string) *ProjectConfig {
 	if database == "" {
 		database = "postgres"
 	}
 
-	config := ProjectConfig{
+	config := &ProjectConfig{
 		Version:    "1.0.0",
 		CliVersion: "1.0.0",
 		CreatedAt:  time.Now(),
@@ -63,16 +81,7 @@ func InitProjectWithConfig(projectName, goModule, database string) error {
 	config.Project.GoModule = goModule
 	config.Project.Database = database
 
-	data, err := json.MarshalIndent(config, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
-	}
-
-	if err := os.WriteFile(configFileName, data, 0644); err != nil {
-		return fmt.Errorf("failed to write config file: %w", err)
-	}
-
-	return nil
+	return config
 }
 
 func LoadProjectConfig() (*ProjectConfig, error) {
@@ -100,4 +109,12 @@ func SaveProjectConfig(config *ProjectConfig) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+func StageProjectConfig(tx *staging.Transaction, config *ProjectConfig) error {
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	return tx.WriteFile(configFileName, data, 0644)
+}
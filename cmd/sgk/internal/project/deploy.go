@@ -0,0 +1,100 @@
+package project
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/karurosux/saas-go-kit/cmd/sgk/internal/staging"
+)
+
+// defaultGoVersion is the go directive written to new projects and the
+// build image used when go.mod does not name a Go version.
+const defaultGoVersion = "1.26"
+
+var deployFiles = []struct {
+	path     string
+	template string
+	redis    bool
+}{
+	{path: "Dockerfile", template: "templates/project/dockerfile.tmpl"},
+	{path: ".dockerignore", template: "templates/project/dockerignore.tmpl"},
+	{path: "docker-compose.prod.yml", template: "templates/project/dockercompose.prod.tmpl"},
+	{path: "deploy/k8s/kustomization.yaml", template: "templates/project/k8s/kustomization.tmpl"},
+	{path: "deploy/k8s/configmap.yaml", template: "templates/project/k8s/configmap.tmpl"},
+	{path: "deploy/k8s/deployment.yaml", template: "templates/project/k8s/deployment.tmpl"},
+	{path: "deploy/k8s/service.yaml", template: "templates/project/k8s/service.tmpl"},
+	{path: "deploy/k8s/redis.yaml", template: "templates/project/k8s/redis.tmpl", redis: true},
+}
+
+// DeployFilePaths lists every file GenerateDeployFiles may write.
+func DeployFilePaths() []string {
+	paths := make([]string, 0, len(deployFiles))
+	for _, file := range deployFiles {
+		paths = append(paths, file.path)
+	}
+	return paths
+}
+
+// GenerateDeployFiles stages the Dockerfile, production compose file and
+// Kubernetes manifests for the modules recorded in config. Redis is included
+// when auth is installed, and probes target the health module when present.
+func GenerateDeployFiles(tx *staging.Transaction, config *ProjectConfig) error {
+	var modules []string
+	for name := range config.Modules {
+		modules = append(modules, name)
+	}
+
+	templateData := prepareTemplateData(config.Project.Name, config.Project.GoModule, config.Project.Database, modules)
+	templateData.AppName = strings.ToLower(strings.ReplaceAll(config.Project.Name, "_", "-"))
+	templateData.GoVersion = goImageVersion(tx)
+
+	if _, ok := config.Modules["auth"]; ok {
+		templateData.Redis = true
+	}
+	if health, ok := config.Modules["health"]; ok {
+		templateData.HealthRoutePrefix = health.Options["route_prefix"]
+		if templateData.HealthRoutePrefix == "" {
+			templateData.HealthRoutePrefix = "/api/v1/health"
+		}
+	}
+
+	for _, file := range deployFiles {
+		if file.redis && !templateData.Redis {
+			if tx.Exists(file.path) {
+				if err := tx.Remove(file.path); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if err := generateFromEmbeddedTemplate(tx, file.path, file.template, templateData); err != nil {
+			return fmt.Errorf("failed to generate %s: %w", file.path, err)
+		}
+	}
+
+	return nil
+}
+
+// goImageVersion returns the major.minor release from the go directive in
+// go.mod, which is the golang image tag the Dockerfile builds with. `go mod
+// tidy` raises the directive to what the dependencies need, so the image
+// follows it instead of pinning a release.
+func goImageVersion(tx *staging.Transaction) string {
+	content, err := tx.ReadFile("go.mod")
+	if err != nil {
+		return defaultGoVersion
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 || fields[0] != "go" {
+			continue
+		}
+		if parts := strings.SplitN(fields[1], ".", 3); len(parts) >= 2 {
+			return parts[0] + "." + parts[1]
+		}
+	}
+
+	return defaultGoVersion
+}
@@ -0,0 +1,80 @@
+package project
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/karurosux/saas-go-kit/cmd/sgk/internal/staging"
+)
+
+func renderDockerfile(t *testing.T, config *ProjectConfig, goMod string) string {
+	t.Helper()
+
+	tx := staging.New(t.TempDir(), false)
+	defer tx.Rollback()
+
+	if goMod != "" {
+		if err := tx.WriteFile("go.mod", []byte(goMod), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := GenerateDeployFiles(tx, config); err != nil {
+		t.Fatalf("GenerateDeployFiles: %v", err)
+	}
+
+	dockerfile, err := tx.ReadFile("Dockerfile")
+	if err != nil {
+		t.Fatalf("read Dockerfile: %v", err)
+	}
+	return string(dockerfile)
+}
+
+func TestDockerfileFollowsGoDirective(t *testing.T) {
+	config := NewProjectConfig("shop", "github.com/acme/shop", "postgres")
+
+	for goMod, want := range map[string]string{
+		"module shop\n\ngo 1.26.0\n\ntoolchain go1.27.1\n": "FROM golang:1.26-alpine AS build\n",
+		"module shop\n\ngo 1.27\n":                         "FROM golang:1.27-alpine AS build\n",
+		"module shop\n":                                    "FROM golang:" + defaultGoVersion + "-alpine AS build\n",
+		"":                                                 "FROM golang:" + defaultGoVersion + "-alpine AS build\n",
+	} {
+		dockerfile := renderDockerfile(t, config, goMod)
+		if !strings.HasPrefix(dockerfile, want) {
+			t.Fatalf("go.mod %q: Dockerfile starts with %q, want %q", goMod, strings.SplitN(dockerfile, "\n", 2)[0], want)
+		}
+	}
+}
+
+func TestDockerfileModules(t *testing.T) {
+	config := NewProjectConfig("shop", "shop", "sqlite")
+	dockerfile := renderDockerfile(t, config, "module shop\n\ngo 1.26.0\n")
+
+	for _, want := range []string{
+		"RUN apk add --no-cache build-base\n",
+		"CGO_ENABLED=1 GOOS=linux go build",
+		"-o /out/shop main.go\n",
+		`ENTRYPOINT ["/app/shop"]`,
+	} {
+		if !strings.Contains(dockerfile, want) {
+			t.Fatalf("Dockerfile does not contain %q:\n%s", want, dockerfile)
+		}
+	}
+	if strings.Contains(dockerfile, "HEALTHCHECK") {
+		t.Fatalf("Dockerfile has a health check without the health module:\n%s", dockerfile)
+	}
+
+	config = NewProjectConfig("shop", "shop", "postgres")
+	config.Modules["health"] = ModuleInfo{
+		InstalledAt: time.Now(),
+		Options:     map[string]string{"route_prefix": "/status"},
+	}
+	dockerfile = renderDockerfile(t, config, "module shop\n\ngo 1.26.0\n")
+
+	if !strings.Contains(dockerfile, "CGO_ENABLED=0") || strings.Contains(dockerfile, "build-base") {
+		t.Fatalf("Dockerfile builds with cgo for postgres:\n%s", dockerfile)
+	}
+	if !strings.Contains(dockerfile, "http://localhost:8080/status/live") {
+		t.Fatalf("Dockerfile does not probe the health module:\n%s", dockerfile)
+	}
+}
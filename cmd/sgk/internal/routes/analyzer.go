@@ -0,0 +1,491 @@
+package routes
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Route struct {
+	Module       string   `json:"module"`
+	Method       string   `json:"method"`
+	Path         string   `json:"path"`
+	Handler      string   `json:"handler"`
+	Middleware   []string `json:"middleware"`
+	AuthRequired bool     `json:"auth_required"`
+	AuthOptional bool     `json:"auth_optional"`
+	Permissions  []string `json:"permissions,omitempty"`
+	Roles        []string `json:"roles,omitempty"`
+}
+
+var httpMethods = map[string]bool{
+	"GET":     true,
+	"POST":    true,
+	"PUT":     true,
+	"PATCH":   true,
+	"DELETE":  true,
+	"HEAD":    true,
+	"OPTIONS": true,
+	"CONNECT": true,
+	"TRACE":   true,
+	"Any":     true,
+}
+
+type group struct {
+	prefix     string
+	middleware []string
+}
+
+type routeFunc struct {
+	receiver  string
+	basePath  int
+	decl      *ast.FuncDecl
+	groupVars map[string]bool
+}
+
+// Analyze parses every module under <root>/internal and returns the routes
+// registered by their RegisterRoutes methods, resolving base paths from the
+// RegisterRoutes calls in each module's module.go.
+func Analyze(root string) ([]Route, error) {
+	entries, err := os.ReadDir(filepath.Join(root, "internal"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read internal directory: %w", err)
+	}
+
+	var routes []Route
+	for _, entry := range entries {
+		if !entry.IsDir() || entry.Name() == "core" {
+			continue
+		}
+
+		moduleDir := filepath.Join(root, "internal", entry.Name())
+		if _, err := os.Stat(filepath.Join(moduleDir, "module.go")); err != nil {
+			continue
+		}
+
+		moduleRoutes, err := analyzeModule(entry.Name(), moduleDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to analyze module %s: %w", entry.Name(), err)
+		}
+		routes = append(routes, moduleRoutes...)
+	}
+
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+
+	return routes, nil
+}
+
+func analyzeModule(moduleName, moduleDir string) ([]Route, error) {
+	fset := token.NewFileSet()
+	var files []*ast.File
+
+	err := filepath.WalkDir(moduleDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		files = append(files, file)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	funcs := make(map[string]*routeFunc)
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || fn.Name.Name != "RegisterRoutes" || fn.Body == nil {
+				continue
+			}
+			if rf := newRouteFunc(fn); rf != nil {
+				funcs[rf.receiver] = rf
+			}
+		}
+	}
+
+	var routes []Route
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+			routes = append(routes, routesFromCalls(fset, moduleName, fn, funcs)...)
+		}
+	}
+
+	return routes, nil
+}
+
+func newRouteFunc(fn *ast.FuncDecl) *routeFunc {
+	receiver := typeName(fn.Recv.List[0].Type)
+	if receiver == "" {
+		return nil
+	}
+
+	rf := &routeFunc{receiver: receiver, basePath: -1, decl: fn, groupVars: make(map[string]bool)}
+
+	index := 0
+	for _, field := range fn.Type.Params.List {
+		names := field.Names
+		if len(names) == 0 {
+			index++
+			continue
+		}
+		for _, name := range names {
+			if typeName(field.Type) == "Echo" {
+				rf.groupVars[name.Name] = true
+			}
+			if ident, ok := field.Type.(*ast.Ident); ok && ident.Name == "string" && rf.basePath < 0 {
+				rf.basePath = index
+			}
+			index++
+		}
+	}
+
+	return rf
+}
+
+// routesFromCalls finds x.RegisterRoutes(...) calls inside fn, resolves the
+// controller type of x and expands the matching RegisterRoutes method.
+func routesFromCalls(fset *token.FileSet, moduleName string, fn *ast.FuncDecl, funcs map[string]*routeFunc) []Route {
+	varTypes := make(map[string]string)
+	var routes []Route
+
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.AssignStmt:
+			for i, lhs := range node.Lhs {
+				ident, ok := lhs.(*ast.Ident)
+				if !ok || i >= len(node.Rhs) {
+					continue
+				}
+				if name := invokedType(node.Rhs[i]); name != "" {
+					varTypes[ident.Name] = name
+				}
+			}
+		case *ast.CallExpr:
+			sel, ok := node.Fun.(*ast.SelectorExpr)
+			if !ok || sel.Sel.Name != "RegisterRoutes" {
+				return true
+			}
+
+			rf := resolveRouteFunc(sel.X, varTypes, funcs)
+			if rf == nil {
+				return true
+			}
+
+			base := ""
+			if rf.basePath >= 0 && rf.basePath < len(node.Args) {
+				base = stringValue(node.Args[rf.basePath], nil)
+			}
+			routes = append(routes, expandRouteFunc(fset, moduleName, rf, base)...)
+		}
+		return true
+	})
+
+	return routes
+}
+
+func resolveRouteFunc(x ast.Expr, varTypes map[string]string, funcs map[string]*routeFunc) *routeFunc {
+	if ident, ok := x.(*ast.Ident); ok {
+		if name, ok := varTypes[ident.Name]; ok {
+			return funcs[name]
+		}
+	}
+	if len(funcs) == 1 {
+		for _, rf := range funcs {
+			return rf
+		}
+	}
+	return nil
+}
+
+// expander collects the routes registered by one RegisterRoutes method.
+type expander struct {
+	fset       *token.FileSet
+	moduleName string
+	rf         *routeFunc
+	params     map[string]string
+	groups     map[string]*group
+	routes     []Route
+}
+
+func expandRouteFunc(fset *token.FileSet, moduleName string, rf *routeFunc, base string) []Route {
+	params := make(map[string]string)
+	if rf.basePath >= 0 {
+		index := 0
+		for _, field := range rf.decl.Type.Params.List {
+			for _, name := range field.Names {
+				if index == rf.basePath {
+					params[name.Name] = base
+				}
+				index++
+			}
+			if len(field.Names) == 0 {
+				index++
+			}
+		}
+	}
+
+	groups := make(map[string]*group)
+	for name := range rf.groupVars {
+		groups[name] = &group{}
+	}
+
+	x := &expander{fset: fset, moduleName: moduleName, rf: rf, params: params, groups: groups}
+	x.stmts(rf.decl.Body.List)
+	return x.routes
+}
+
+func (x *expander) stmts(stmts []ast.Stmt) {
+	for _, stmt := range stmts {
+		x.stmt(stmt)
+	}
+}
+
+// stmt records the groups and routes declared by stmt. Routes are often
+// registered conditionally or in a loop, so nested blocks are walked too.
+func (x *expander) stmt(stmt ast.Stmt) {
+	switch s := stmt.(type) {
+	case *ast.BlockStmt:
+		x.stmts(s.List)
+	case *ast.IfStmt:
+		if s.Init != nil {
+			x.stmt(s.Init)
+		}
+		x.stmts(s.Body.List)
+		if s.Else != nil {
+			x.stmt(s.Else)
+		}
+	case *ast.ForStmt:
+		x.stmts(s.Body.List)
+	case *ast.RangeStmt:
+		x.stmts(s.Body.List)
+	case *ast.SwitchStmt:
+		x.stmts(s.Body.List)
+	case *ast.TypeSwitchStmt:
+		x.stmts(s.Body.List)
+	case *ast.CaseClause:
+		x.stmts(s.Body)
+	case *ast.LabeledStmt:
+		x.stmt(s.Stmt)
+	case *ast.AssignStmt:
+		x.assign(s)
+	case *ast.ExprStmt:
+		if call, ok := s.X.(*ast.CallExpr); ok {
+			x.call(call)
+		}
+	}
+}
+
+func (x *expander) assign(s *ast.AssignStmt) {
+	for i, lhs := range s.Lhs {
+		ident, ok := lhs.(*ast.Ident)
+		if !ok || i >= len(s.Rhs) {
+			continue
+		}
+		call, ok := s.Rhs[i].(*ast.CallExpr)
+		if !ok {
+			continue
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != "Group" || len(call.Args) == 0 {
+			continue
+		}
+		parent := groupFor(sel.X, x.groups)
+		if parent == nil {
+			continue
+		}
+		x.groups[ident.Name] = &group{
+			prefix:     parent.prefix + stringValue(call.Args[0], x.params),
+			middleware: append(append([]string(nil), parent.middleware...), render(x.fset, call.Args[1:])...),
+		}
+	}
+}
+
+func (x *expander) call(call *ast.CallExpr) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return
+	}
+	g := groupFor(sel.X, x.groups)
+	if g == nil {
+		return
+	}
+
+	if sel.Sel.Name == "Use" {
+		g.middleware = append(g.middleware, render(x.fset, call.Args)...)
+		return
+	}
+	if !httpMethods[sel.Sel.Name] || len(call.Args) < 2 {
+		return
+	}
+
+	route := Route{
+		Module:     x.moduleName,
+		Method:     strings.ToUpper(sel.Sel.Name),
+		Path:       g.prefix + stringValue(call.Args[0], x.params),
+		Handler:    handlerName(x.fset, x.rf.receiver, call.Args[1]),
+		Middleware: append(append([]string{}, g.middleware...), render(x.fset, call.Args[2:])...),
+	}
+	if route.Path == "" {
+		route.Path = "/"
+	}
+	classify(&route)
+	x.routes = append(x.routes, route)
+}
+
+func groupFor(x ast.Expr, groups map[string]*group) *group {
+	ident, ok := x.(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	return groups[ident.Name]
+}
+
+// classify marks a route as requiring authentication when any middleware
+// in its chain authenticates the caller, or as optionally authenticated when
+// the chain only identifies callers that present credentials. It also
+// collects the permissions and roles demanded by RBAC middleware.
+func classify(route *Route) {
+	for _, mw := range route.Middleware {
+		name := mw
+		if i := strings.Index(name, "("); i >= 0 {
+			name = name[:i]
+		}
+		if i := strings.LastIndex(name, "."); i >= 0 {
+			name = name[i+1:]
+		}
+
+		switch {
+		case name == "RequireAuth":
+			route.AuthRequired = true
+		case name == "OptionalAuth":
+			route.AuthOptional = true
+		case strings.HasPrefix(name, "Require") && strings.Contains(name, "Permission"):
+			route.AuthRequired = true
+			route.Permissions = append(route.Permissions, quotedArgs(mw)...)
+		case strings.HasPrefix(name, "Require") && strings.Contains(name, "Role"):
+			route.AuthRequired = true
+			route.Roles = append(route.Roles, quotedArgs(mw)...)
+		}
+	}
+
+	if route.AuthRequired {
+		route.AuthOptional = false
+	}
+}
+
+func quotedArgs(call string) []string {
+	expr, err := parser.ParseExpr(call)
+	if err != nil {
+		return nil
+	}
+	callExpr, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return nil
+	}
+
+	var values []string
+	for _, arg := range callExpr.Args {
+		if lit, ok := arg.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+			if value, err := strconv.Unquote(lit.Value); err == nil {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+func handlerName(fset *token.FileSet, receiver string, expr ast.Expr) string {
+	if sel, ok := expr.(*ast.SelectorExpr); ok {
+		if _, ok := sel.X.(*ast.Ident); ok {
+			return fmt.Sprintf("%s.%s", receiver, sel.Sel.Name)
+		}
+	}
+	return renderExpr(fset, expr)
+}
+
+// stringValue returns the literal value of expr, substituting known
+// parameters. Anything else is rendered as {expr} so the path stays readable.
+func stringValue(expr ast.Expr, params map[string]string) string {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if e.Kind == token.STRING {
+			if value, err := strconv.Unquote(e.Value); err == nil {
+				return value
+			}
+		}
+	case *ast.Ident:
+		if value, ok := params[e.Name]; ok {
+			return value
+		}
+		return "{" + e.Name + "}"
+	case *ast.BinaryExpr:
+		if e.Op == token.ADD {
+			return stringValue(e.X, params) + stringValue(e.Y, params)
+		}
+	}
+	return "{?}"
+}
+
+func invokedType(expr ast.Expr) string {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return ""
+	}
+	index, ok := call.Fun.(*ast.IndexExpr)
+	if !ok {
+		return ""
+	}
+	return typeName(index.Index)
+}
+
+func typeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return typeName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+func render(fset *token.FileSet, exprs []ast.Expr) []string {
+	var result []string
+	for _, expr := range exprs {
+		result = append(result, renderExpr(fset, expr))
+	}
+	return result
+}
+
+func renderExpr(fset *token.FileSet, expr ast.Expr) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, expr); err != nil {
+		return "?"
+	}
+	return buf.String()
+}
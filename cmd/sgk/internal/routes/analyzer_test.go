@@ -0,0 +1,173 @@
+package routes
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// routeSummary is the part of a Route the fixtures pin down; middleware is
+// checked separately where it matters.
+type routeSummary struct {
+	Module       string
+	Method       string
+	Path         string
+	Handler      string
+	AuthRequired bool
+	AuthOptional bool
+	Permissions  []string
+	Roles        []string
+}
+
+func summarize(routes []Route) []routeSummary {
+	summaries := make([]routeSummary, 0, len(routes))
+	for _, route := range routes {
+		summaries = append(summaries, routeSummary{
+			Module:       route.Module,
+			Method:       route.Method,
+			Path:         route.Path,
+			Handler:      route.Handler,
+			AuthRequired: route.AuthRequired,
+			AuthOptional: route.AuthOptional,
+			Permissions:  route.Permissions,
+			Roles:        route.Roles,
+		})
+	}
+	return summaries
+}
+
+func analyzeFixture(t *testing.T) []Route {
+	t.Helper()
+
+	routes, err := Analyze("testdata/project")
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	return routes
+}
+
+func TestAnalyze(t *testing.T) {
+	routes := analyzeFixture(t)
+
+	want := []routeSummary{
+		{Module: "billing", Method: "GET", Path: "/api/v1/invoices", Handler: "InvoiceController.List"},
+		{Module: "billing", Method: "GET", Path: "/api/v1/invoices/:id", Handler: "InvoiceController.GetByID"},
+		{Module: "billing", Method: "GET", Path: "/api/v1/plans", Handler: "PlanController.List"},
+		{Module: "shop", Method: "DELETE", Path: "/api/v1/shop/admin/products/:id", Handler: "ShopController.DeleteProduct", AuthRequired: true, Permissions: []string{"products:delete"}},
+		{Module: "shop", Method: "GET", Path: "/api/v1/shop/cart", Handler: "ShopController.GetCart", AuthRequired: true},
+		{Module: "shop", Method: "GET", Path: "/api/v1/shop/legacy", Handler: "ShopController.Legacy"},
+		{Module: "shop", Method: "POST", Path: "/api/v1/shop/orders", Handler: "ShopController.CreateOrder", AuthRequired: true, Roles: []string{"buyer"}},
+		{Module: "shop", Method: "GET", Path: "/api/v1/shop/products", Handler: "ShopController.ListProducts"},
+		{Module: "shop", Method: "GET", Path: "/api/v1/shop/products/:id", Handler: "ShopController.GetProduct", AuthOptional: true},
+		{Module: "shop", Method: "GET", Path: "/api/v1/shop/products/:id/reviews", Handler: "ShopController.ListReviews"},
+		{Module: "shop", Method: "POST", Path: "/api/v1/shop/products/:id/reviews", Handler: "ShopController.CreateReview", AuthRequired: true},
+		{Module: "shop", Method: "POST", Path: "/api/v1/shop/webhooks/{provider}", Handler: "ShopController.Webhook"},
+		{Module: "shop", Method: "GET", Path: "/healthz", Handler: "ShopController.Health"},
+	}
+
+	got := summarize(routes)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Analyze returned\n%s\nwant\n%s", formatSummaries(got), formatSummaries(want))
+	}
+}
+
+func TestAnalyzeMiddleware(t *testing.T) {
+	routes := analyzeFixture(t)
+
+	want := map[string][]string{
+		"DELETE /api/v1/shop/admin/products/:id": {"authMiddleware.RequireAuth()", `authMiddleware.RequirePermission("products:delete")`},
+		"GET /api/v1/shop/cart":                  {"authMiddleware.RequireAuth()", "authMiddleware.OptionalAuth()"},
+		"GET /api/v1/shop/products":              {},
+	}
+	for _, route := range routes {
+		key := route.Method + " " + route.Path
+		middleware, ok := want[key]
+		if !ok {
+			continue
+		}
+		delete(want, key)
+		if !reflect.DeepEqual(route.Middleware, middleware) {
+			t.Errorf("%s middleware = %q, want %q", key, route.Middleware, middleware)
+		}
+	}
+	for key := range want {
+		t.Errorf("route %s not found", key)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name       string
+		middleware []string
+		required   bool
+		optional   bool
+	}{
+		{"none", nil, false, false},
+		{"unrelated", []string{"middleware.RateLimit(10)"}, false, false},
+		{"required", []string{"authMiddleware.RequireAuth()"}, true, false},
+		{"optional", []string{"authMiddleware.OptionalAuth()"}, false, true},
+		{"required group, optional route", []string{"m.RequireAuth()", "m.OptionalAuth()"}, true, false},
+		{"permission implies auth", []string{"m.OptionalAuth()", `m.RequirePermission("a")`}, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			route := Route{Middleware: tt.middleware}
+			classify(&route)
+			if route.AuthRequired != tt.required || route.AuthOptional != tt.optional {
+				t.Fatalf("required, optional = %v, %v, want %v, %v", route.AuthRequired, route.AuthOptional, tt.required, tt.optional)
+			}
+		})
+	}
+}
+
+func TestPrintTable(t *testing.T) {
+	var out bytes.Buffer
+	PrintTable(&out, analyzeFixture(t))
+
+	lines := strings.Split(out.String(), "\n")
+	auth := make(map[string]string)
+	for _, line := range lines[1:] {
+		fields := strings.Fields(line)
+		if len(fields) == 6 {
+			auth[fields[0]+" "+fields[1]] = fields[3]
+		}
+	}
+
+	for route, want := range map[string]string{
+		"GET /api/v1/shop/products/:id": "optional",
+		"GET /api/v1/shop/cart":         "required",
+		"GET /api/v1/shop/products":     "public",
+	} {
+		if auth[route] != want {
+			t.Errorf("%s auth = %q, want %q", route, auth[route], want)
+		}
+	}
+
+	if !strings.HasSuffix(out.String(), "\n13 routes, 8 public, 1 optional auth\n") {
+		t.Fatalf("summary missing from output:\n%s", out.String())
+	}
+}
+
+func formatSummaries(summaries []routeSummary) string {
+	var b strings.Builder
+	for _, s := range summaries {
+		b.WriteString("  ")
+		b.WriteString(strings.Join([]string{s.Module, s.Method, s.Path, s.Handler}, " "))
+		if s.AuthRequired {
+			b.WriteString(" required")
+		}
+		if s.AuthOptional {
+			b.WriteString(" optional")
+		}
+		if len(s.Permissions) > 0 {
+			b.WriteString(" permissions=" + strings.Join(s.Permissions, ","))
+		}
+		if len(s.Roles) > 0 {
+			b.WriteString(" roles=" + strings.Join(s.Roles, ","))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
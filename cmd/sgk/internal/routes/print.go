@@ -0,0 +1,61 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+func PrintTable(w io.Writer, routes []Route) {
+	if len(routes) == 0 {
+		fmt.Fprintln(w, "No routes found.")
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHOD\tPATH\tHANDLER\tAUTH\tPERMISSIONS\tMODULE")
+
+	public, optional := 0, 0
+	for _, route := range routes {
+		auth := "public"
+		switch {
+		case route.AuthRequired:
+			auth = "required"
+		case route.AuthOptional:
+			auth = "optional"
+			optional++
+		default:
+			public++
+		}
+
+		access := append(append([]string{}, route.Permissions...), prefixed("role:", route.Roles)...)
+		permissions := "-"
+		if len(access) > 0 {
+			permissions = strings.Join(access, ", ")
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", route.Method, route.Path, route.Handler, auth, permissions, route.Module)
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "\n%d routes, %d public, %d optional auth\n", len(routes), public, optional)
+}
+
+func PrintJSON(w io.Writer, routes []Route) error {
+	if routes == nil {
+		routes = []Route{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(routes)
+}
+
+func prefixed(prefix string, values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		result = append(result, prefix+value)
+	}
+	return result
+}
@@ -0,0 +1,11 @@
+package billingcontroller
+
+import "github.com/labstack/echo/v4"
+
+type InvoiceController struct{}
+
+func (c *InvoiceController) RegisterRoutes(e *echo.Echo, prefix string) {
+	g := e.Group(prefix)
+	g.GET("", c.List)
+	g.GET("/:id", c.GetByID)
+}
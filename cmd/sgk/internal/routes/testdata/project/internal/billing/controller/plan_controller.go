@@ -0,0 +1,10 @@
+package billingcontroller
+
+import "github.com/labstack/echo/v4"
+
+type PlanController struct{}
+
+func (c *PlanController) RegisterRoutes(e *echo.Echo, prefix string) {
+	g := e.Group(prefix)
+	g.GET("", c.List)
+}
@@ -0,0 +1,21 @@
+package billing
+
+import (
+	billingcontroller "example.com/app/internal/billing/controller"
+	"example.com/app/internal/core"
+
+	"github.com/labstack/echo/v4"
+	"github.com/samber/do"
+)
+
+func RegisterModule(container *core.Container) error {
+	e := do.MustInvoke[*echo.Echo](container)
+
+	invoices := do.MustInvoke[*billingcontroller.InvoiceController](container)
+	invoices.RegisterRoutes(e, "/api/v1/invoices")
+
+	plans := do.MustInvoke[*billingcontroller.PlanController](container)
+	plans.RegisterRoutes(e, "/api/v1/plans")
+
+	return nil
+}
@@ -0,0 +1,14 @@
+package core
+
+import "github.com/labstack/echo/v4"
+
+type Router struct{}
+
+func (r *Router) RegisterRoutes(e *echo.Echo) {
+	e.GET("/core", r.Handle)
+}
+
+func Register(e *echo.Echo) {
+	router := &Router{}
+	router.RegisterRoutes(e)
+}
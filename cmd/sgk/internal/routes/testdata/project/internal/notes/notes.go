@@ -0,0 +1,10 @@
+package notes
+
+import "github.com/labstack/echo/v4"
+
+// notes has no module.go, so it is not an installed module.
+type NotesController struct{}
+
+func (c *NotesController) RegisterRoutes(e *echo.Echo) {
+	e.GET("/notes", c.List)
+}
@@ -0,0 +1,46 @@
+package shopcontroller
+
+import (
+	authmiddleware "example.com/app/internal/auth/middleware"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ShopController struct {
+	reviews   bool
+	mode      string
+	providers []string
+}
+
+func (c *ShopController) RegisterRoutes(e *echo.Echo, basePath string, authMiddleware *authmiddleware.AuthMiddleware) {
+	e.GET("/healthz", c.Health)
+
+	group := e.Group(basePath)
+	group.GET("/products", c.ListProducts)
+	group.GET("/products/:id", c.GetProduct, authMiddleware.OptionalAuth())
+
+	if c.reviews {
+		group.POST("/products/:id/reviews", c.CreateReview, authMiddleware.RequireAuth())
+	} else if c.mode == "readonly" {
+		group.GET("/products/:id/reviews", c.ListReviews)
+	}
+
+	{
+		admin := group.Group("/admin", authMiddleware.RequireAuth())
+		admin.DELETE("/products/:id", c.DeleteProduct, authMiddleware.RequirePermission("products:delete"))
+	}
+
+	for _, provider := range c.providers {
+		group.POST("/webhooks/"+provider, c.Webhook)
+	}
+
+	switch c.mode {
+	case "legacy":
+		group.GET("/legacy", c.Legacy)
+	}
+
+	protected := group.Group("")
+	protected.Use(authMiddleware.RequireAuth())
+	protected.GET("/cart", c.GetCart, authMiddleware.OptionalAuth())
+	protected.POST("/orders", c.CreateOrder, authMiddleware.RequireRole("buyer"))
+}
@@ -0,0 +1,5 @@
+package shopcontroller
+
+func (c *ShopController) RegisterRoutes(e *echo.Echo, basePath string) {
+	e.GET("/from-test", c.Health)
+}
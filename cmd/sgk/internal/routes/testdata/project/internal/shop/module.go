@@ -0,0 +1,19 @@
+package shop
+
+import (
+	authmiddleware "example.com/app/internal/auth/middleware"
+	"example.com/app/internal/core"
+	shopcontroller "example.com/app/internal/shop/controller"
+
+	"github.com/labstack/echo/v4"
+	"github.com/samber/do"
+)
+
+func RegisterModule(container *core.Container) error {
+	e := do.MustInvoke[*echo.Echo](container)
+	authMiddleware := do.MustInvoke[*authmiddleware.AuthMiddleware](container)
+	shopController := do.MustInvoke[*shopcontroller.ShopController](container)
+	shopController.RegisterRoutes(e, "/api/v1/shop", authMiddleware)
+
+	return nil
+}
@@ -0,0 +1,343 @@
+package staging
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+type OperationKind string
+
+const (
+	OperationCreate OperationKind = "create"
+	OperationUpdate OperationKind = "update"
+	OperationDelete OperationKind = "delete"
+)
+
+type Operation struct {
+	Kind OperationKind
+	Path string
+}
+
+type stagedFile struct {
+	path      string
+	content   []byte
+	perm      os.FileMode
+	deleted   bool
+	stagePath string
+}
+
+type backup struct {
+	path    string
+	existed bool
+	content []byte
+	perm    os.FileMode
+}
+
+// Transaction collects file writes for a generation run and applies them
+// together, restoring the previous state of every touched path on failure.
+type Transaction struct {
+	root     string
+	dryRun   bool
+	stageDir string
+	files    map[string]*stagedFile
+	order    []string
+	done     bool
+}
+
+func New(root string, dryRun bool) *Transaction {
+	if root == "" {
+		root = "."
+	}
+	return &Transaction{
+		root:   root,
+		dryRun: dryRun,
+		files:  make(map[string]*stagedFile),
+	}
+}
+
+func (t *Transaction) IsDryRun() bool {
+	return t.dryRun
+}
+
+func (t *Transaction) Root() string {
+	return t.root
+}
+
+func (t *Transaction) WriteFile(path string, content []byte, perm os.FileMode) error {
+	if t.done {
+		return fmt.Errorf("transaction already finished")
+	}
+
+	path = filepath.Clean(path)
+	file := t.track(path)
+	file.content = append([]byte(nil), content...)
+	file.perm = perm
+	file.deleted = false
+
+	if t.dryRun {
+		return nil
+	}
+
+	if file.stagePath == "" {
+		if err := t.ensureStageDir(); err != nil {
+			return err
+		}
+		file.stagePath = filepath.Join(t.stageDir, fmt.Sprintf("%04d", len(t.order)))
+	}
+
+	if err := os.WriteFile(file.stagePath, content, perm); err != nil {
+		return fmt.Errorf("failed to stage %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func (t *Transaction) Remove(path string) error {
+	if t.done {
+		return fmt.Errorf("transaction already finished")
+	}
+
+	path = filepath.Clean(path)
+	file := t.track(path)
+	file.content = nil
+	file.deleted = true
+	return nil
+}
+
+func (t *Transaction) ReadFile(path string) ([]byte, error) {
+	path = filepath.Clean(path)
+	if file, ok := t.files[path]; ok {
+		if file.deleted {
+			return nil, fmt.Errorf("%s: %w", path, os.ErrNotExist)
+		}
+		return append([]byte(nil), file.content...), nil
+	}
+	return os.ReadFile(t.abs(path))
+}
+
+func (t *Transaction) Exists(path string) bool {
+	path = filepath.Clean(path)
+	if file, ok := t.files[path]; ok {
+		return !file.deleted
+	}
+	_, err := os.Stat(t.abs(path))
+	return err == nil
+}
+
+func (t *Transaction) Operations() []Operation {
+	ops := make([]Operation, 0, len(t.order))
+	for _, path := range t.order {
+		file := t.files[path]
+		_, err := os.Stat(t.abs(path))
+		existsOnDisk := err == nil
+
+		switch {
+		case file.deleted && existsOnDisk:
+			ops = append(ops, Operation{Kind: OperationDelete, Path: path})
+		case file.deleted:
+			continue
+		case existsOnDisk:
+			ops = append(ops, Operation{Kind: OperationUpdate, Path: path})
+		default:
+			ops = append(ops, Operation{Kind: OperationCreate, Path: path})
+		}
+	}
+	return ops
+}
+
+func (t *Transaction) PrintPlan(w io.Writer) {
+	ops := t.Operations()
+	if len(ops) == 0 {
+		fmt.Fprintln(w, "📝 No file changes planned.")
+		return
+	}
+
+	fmt.Fprintln(w, "📝 Planned file operations:")
+	for _, op := range ops {
+		fmt.Fprintf(w, "  %-7s %s\n", op.Kind, filepath.Join(t.root, op.Path))
+	}
+}
+
+// Commit moves every staged file into place. If any step fails, all paths
+// already written are restored and directories created by the commit are
+// removed again.
+func (t *Transaction) Commit() error {
+	if t.done {
+		return fmt.Errorf("transaction already finished")
+	}
+	if t.dryRun {
+		t.done = true
+		return nil
+	}
+
+	var backups []backup
+	var createdDirs []string
+
+	rollback := func() {
+		for i := len(backups) - 1; i >= 0; i-- {
+			b := backups[i]
+			target := t.abs(b.path)
+			if b.existed {
+				os.WriteFile(target, b.content, b.perm)
+				os.Chmod(target, b.perm)
+			} else {
+				os.Remove(target)
+			}
+		}
+		sort.Slice(createdDirs, func(i, j int) bool {
+			return len(createdDirs[i]) > len(createdDirs[j])
+		})
+		for _, dir := range createdDirs {
+			os.Remove(dir)
+		}
+	}
+
+	for _, path := range t.order {
+		file := t.files[path]
+		target := t.abs(path)
+
+		b, err := snapshot(path, target)
+		if err != nil {
+			rollback()
+			t.cleanup()
+			return err
+		}
+
+		if file.deleted {
+			if !b.existed {
+				continue
+			}
+			backups = append(backups, b)
+			if err := os.Remove(target); err != nil {
+				rollback()
+				t.cleanup()
+				return fmt.Errorf("failed to delete %s: %w", path, err)
+			}
+			continue
+		}
+
+		dirs, err := mkdirAll(filepath.Dir(target))
+		createdDirs = append(createdDirs, dirs...)
+		if err != nil {
+			rollback()
+			t.cleanup()
+			return fmt.Errorf("failed to create directory for %s: %w", path, err)
+		}
+
+		backups = append(backups, b)
+		if err := os.Rename(file.stagePath, target); err != nil {
+			rollback()
+			t.cleanup()
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+
+	t.cleanup()
+	return nil
+}
+
+// Rollback discards everything staged so far. It is safe to defer right
+// after New; once Commit has run it does nothing.
+func (t *Transaction) Rollback() {
+	if t.done {
+		return
+	}
+	t.cleanup()
+}
+
+func (t *Transaction) track(path string) *stagedFile {
+	file, ok := t.files[path]
+	if !ok {
+		file = &stagedFile{path: path}
+		t.files[path] = file
+		t.order = append(t.order, path)
+	}
+	return file
+}
+
+func (t *Transaction) ensureStageDir() error {
+	if t.stageDir != "" {
+		return nil
+	}
+
+	absRoot, err := filepath.Abs(t.root)
+	if err != nil {
+		return err
+	}
+
+	parent := absRoot
+	if _, err := os.Stat(absRoot); err != nil {
+		parent = filepath.Dir(absRoot)
+	}
+
+	dir, err := os.MkdirTemp(parent, ".sgk-staging-")
+	if err != nil {
+		return fmt.Errorf("failed to create staging directory: %w", err)
+	}
+
+	t.stageDir = dir
+	return nil
+}
+
+func (t *Transaction) cleanup() {
+	t.done = true
+	if t.stageDir != "" {
+		os.RemoveAll(t.stageDir)
+		t.stageDir = ""
+	}
+}
+
+func (t *Transaction) abs(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(t.root, path)
+}
+
+func snapshot(path, target string) (backup, error) {
+	info, err := os.Stat(target)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return backup{path: path}, nil
+		}
+		return backup{}, err
+	}
+
+	content, err := os.ReadFile(target)
+	if err != nil {
+		return backup{}, fmt.Errorf("failed to back up %s: %w", path, err)
+	}
+
+	return backup{
+		path:    path,
+		existed: true,
+		content: content,
+		perm:    info.Mode().Perm(),
+	}, nil
+}
+
+func mkdirAll(dir string) ([]string, error) {
+	var missing []string
+	for current := dir; ; current = filepath.Dir(current) {
+		if _, err := os.Stat(current); err == nil {
+			break
+		}
+		missing = append(missing, current)
+		if parent := filepath.Dir(current); parent == current {
+			break
+		}
+	}
+
+	var created []string
+	for i := len(missing) - 1; i >= 0; i-- {
+		if err := os.Mkdir(missing[i], 0755); err != nil && !os.IsExist(err) {
+			return created, err
+		}
+		created = append(created, missing[i])
+	}
+
+	return created, nil
+}
@@ -0,0 +1,304 @@
+package staging
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func assertFileContent(t *testing.T, path, want string) {
+	t.Helper()
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	if string(got) != want {
+		t.Fatalf("%s = %q, want %q", path, got, want)
+	}
+}
+
+func assertNotExist(t *testing.T, path string) {
+	t.Helper()
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("%s exists (err = %v), want it gone", path, err)
+	}
+}
+
+func assertNoStagingDir(t *testing.T, root string) {
+	t.Helper()
+
+	matches, err := filepath.Glob(filepath.Join(root, ".sgk-staging-*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 0 {
+		t.Fatalf("staging directories left behind: %v", matches)
+	}
+}
+
+func TestCommit(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "main.go"), "old main")
+	writeTestFile(t, filepath.Join(root, "stale.go"), "stale")
+
+	tx := New(root, false)
+	defer tx.Rollback()
+
+	if err := tx.WriteFile("main.go", []byte("new main"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.WriteFile("internal/auth/module.go", []byte("package auth"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Remove("stale.go"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing reaches the project before Commit.
+	assertFileContent(t, filepath.Join(root, "main.go"), "old main")
+	assertNotExist(t, filepath.Join(root, "internal"))
+
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	assertFileContent(t, filepath.Join(root, "main.go"), "new main")
+	assertFileContent(t, filepath.Join(root, "internal/auth/module.go"), "package auth")
+	assertNotExist(t, filepath.Join(root, "stale.go"))
+	assertNoStagingDir(t, root)
+
+	if err := tx.WriteFile("late.go", nil, 0644); err == nil {
+		t.Fatal("WriteFile succeeded after Commit")
+	}
+}
+
+func TestCommitFailureRestoresFiles(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "main.go"), "old main")
+	writeTestFile(t, filepath.Join(root, "sgk.json"), "old config")
+	if err := os.Chmod(filepath.Join(root, "sgk.json"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(root, "stale.go"), "stale")
+	// A non-empty directory where a file should go makes Commit fail after
+	// the earlier paths have already been written.
+	writeTestFile(t, filepath.Join(root, "blocked", "keep.txt"), "keep")
+
+	tx := New(root, false)
+	defer tx.Rollback()
+
+	writes := []struct {
+		path    string
+		content string
+	}{
+		{"main.go", "new main"},
+		{"sgk.json", "new config"},
+		{"internal/auth/handler/auth.go", "package handler"},
+		{"internal/auth/module.go", "package auth"},
+	}
+	for _, w := range writes {
+		if err := tx.WriteFile(w.path, []byte(w.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tx.Remove("stale.go"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.WriteFile("blocked", []byte("file"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tx.Commit(); err == nil {
+		t.Fatal("Commit succeeded, want a failure on the blocked path")
+	}
+
+	assertFileContent(t, filepath.Join(root, "main.go"), "old main")
+	assertFileContent(t, filepath.Join(root, "sgk.json"), "old config")
+	assertFileContent(t, filepath.Join(root, "stale.go"), "stale")
+	assertFileContent(t, filepath.Join(root, "blocked", "keep.txt"), "keep")
+
+	info, err := os.Stat(filepath.Join(root, "sgk.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Fatalf("sgk.json mode = %v, want the original 0600", perm)
+	}
+
+	// Directories created for the new files are removed again.
+	assertNotExist(t, filepath.Join(root, "internal"))
+	assertNoStagingDir(t, root)
+
+	if err := tx.Commit(); err == nil {
+		t.Fatal("a failed transaction could be committed again")
+	}
+}
+
+func TestCommitFailureKeepsExistingDirectories(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "internal", "core", "core.go"), "package core")
+	writeTestFile(t, filepath.Join(root, "blocked", "keep.txt"), "keep")
+
+	tx := New(root, false)
+	defer tx.Rollback()
+
+	if err := tx.WriteFile("internal/auth/module.go", []byte("package auth"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.WriteFile("blocked", []byte("file"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tx.Commit(); err == nil {
+		t.Fatal("Commit succeeded, want a failure on the blocked path")
+	}
+
+	assertNotExist(t, filepath.Join(root, "internal", "auth"))
+	assertFileContent(t, filepath.Join(root, "internal", "core", "core.go"), "package core")
+}
+
+func TestNewProjectCommitFailureRemovesRoot(t *testing.T) {
+	parent := t.TempDir()
+	root := filepath.Join(parent, "app")
+	writeTestFile(t, filepath.Join(parent, "app-blocker", "keep.txt"), "keep")
+
+	tx := New(root, false)
+	defer tx.Rollback()
+
+	if err := tx.WriteFile("go.mod", []byte("module app"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.WriteFile("internal/core/core.go", []byte("package core"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.WriteFile(filepath.Join(parent, "app-blocker"), []byte("file"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tx.Commit(); err == nil {
+		t.Fatal("Commit succeeded, want a failure on the blocked path")
+	}
+
+	// The project directory itself was created by the commit, so it goes too.
+	assertNotExist(t, root)
+	assertNoStagingDir(t, parent)
+}
+
+func TestRollbackDiscardsStagedFiles(t *testing.T) {
+	root := t.TempDir()
+
+	tx := New(root, false)
+	if err := tx.WriteFile("main.go", []byte("package main"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tx.Rollback()
+
+	assertNotExist(t, filepath.Join(root, "main.go"))
+	assertNoStagingDir(t, root)
+	if err := tx.Commit(); err == nil {
+		t.Fatal("Commit succeeded after Rollback")
+	}
+}
+
+func TestReadFileSeesStagedChanges(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "main.go"), "old main")
+	writeTestFile(t, filepath.Join(root, "stale.go"), "stale")
+
+	tx := New(root, false)
+	defer tx.Rollback()
+
+	if err := tx.WriteFile("main.go", []byte("new main"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Remove("stale.go"); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := tx.ReadFile("main.go")
+	if err != nil || string(content) != "new main" {
+		t.Fatalf("ReadFile(main.go) = %q, %v, want the staged content", content, err)
+	}
+	if _, err := tx.ReadFile("stale.go"); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("ReadFile(stale.go) error = %v, want not exist", err)
+	}
+	if tx.Exists("stale.go") {
+		t.Fatal("Exists(stale.go) = true after Remove")
+	}
+	if !tx.Exists("./main.go") {
+		t.Fatal("Exists(./main.go) = false")
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "main.go"), "old main")
+	writeTestFile(t, filepath.Join(root, "Dockerfile"), "FROM scratch")
+
+	tx := New(root, true)
+	defer tx.Rollback()
+
+	if err := tx.WriteFile("internal/auth/module.go", []byte("package auth"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.WriteFile("main.go", []byte("new main"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Remove("Dockerfile"); err != nil {
+		t.Fatal(err)
+	}
+	// Removing a path that was never there is not an operation.
+	if err := tx.Remove("missing.go"); err != nil {
+		t.Fatal(err)
+	}
+
+	var plan bytes.Buffer
+	tx.PrintPlan(&plan)
+
+	want := strings.Join([]string{
+		"📝 Planned file operations:",
+		"  create  " + filepath.Join(root, "internal/auth/module.go"),
+		"  update  " + filepath.Join(root, "main.go"),
+		"  delete  " + filepath.Join(root, "Dockerfile"),
+		"",
+	}, "\n")
+	if plan.String() != want {
+		t.Fatalf("plan =\n%s\nwant\n%s", plan.String(), want)
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit: %v", err)
+	}
+
+	assertFileContent(t, filepath.Join(root, "main.go"), "old main")
+	assertFileContent(t, filepath.Join(root, "Dockerfile"), "FROM scratch")
+	assertNotExist(t, filepath.Join(root, "internal"))
+	assertNoStagingDir(t, root)
+}
+
+func TestDryRunEmptyPlan(t *testing.T) {
+	tx := New(t.TempDir(), true)
+
+	var plan bytes.Buffer
+	tx.PrintPlan(&plan)
+
+	if plan.String() != "📝 No file changes planned.\n" {
+		t.Fatalf("plan = %q", plan.String())
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -11,8 +12,11 @@ import (
 	"github.com/karurosux/saas-go-kit/cmd/sgk/commands"
 	"github.com/karurosux/saas-go-kit/cmd/sgk/internal/crud"
 	"github.com/karurosux/saas-go-kit/cmd/sgk/internal/embed"
+	"github.com/karurosux/saas-go-kit/cmd/sgk/internal/env"
 	"github.com/karurosux/saas-go-kit/cmd/sgk/internal/modules"
 	"github.com/karurosux/saas-go-kit/cmd/sgk/internal/project"
+	"github.com/karurosux/saas-go-kit/cmd/sgk/internal/routes"
+	"github.com/karurosux/saas-go-kit/cmd/sgk/internal/staging"
 )
 
 func main() {
@@ -25,12 +29,15 @@ Similar to shadcn/ui, you own the code and can modify it as needed.
 Available modules: auth, subscription, team, notification, health, role, job, sse, container`,
 	}
 
-	rootCmd.AddCommand(commands.NewCmd(createNewProjectWithModules))
+	rootCmd.AddCommand(commands.NewCmd(createNewProjectWithModules, moduleCatalog))
 	rootCmd.AddCommand(commands.InitCmd(project.InitProject))
 	rootCmd.AddCommand(commands.AddCmd(addModuleWithAllDeps))
 	rootCmd.AddCommand(commands.ListCmd(modules.ListAvailableModules, listInstalledModulesFromConfig))
 	rootCmd.AddCommand(commands.UpdateCmd(modules.UpdateModule))
 	rootCmd.AddCommand(commands.CrudCmd(crud.GenerateCRUDModule))
+	rootCmd.AddCommand(commands.DeployCmd(initDeployFiles))
+	rootCmd.AddCommand(commands.EnvCmd(syncEnvExample, checkEnvFile))
+	rootCmd.AddCommand(commands.RoutesCmd(listRoutes))
 	rootCmd.AddCommand(commands.VersionCmd())
 
 	if err := rootCmd.Execute(); err != nil {
@@ -39,48 +46,223 @@ Available modules: auth, subscription, team, notification, health, role, job, ss
 	}
 }
 
-func createNewProjectWithModules(projectName string, moduleList []string, goModule, database string) error {
-	if err := project.CreateNewProject(projectName, moduleList, goModule, database); err != nil {
-		return err
-	}
+func createNewProjectWithModules(opts commands.NewProjectOptions) error {
+	tx := staging.New(opts.ProjectName, opts.DryRun)
+	defer tx.Rollback()
 
-	originalDir, err := os.Getwd()
+	config, err := project.CreateNewProject(tx, project.CreateOptions{
+		Name:     opts.ProjectName,
+		GoModule: opts.GoModule,
+		Database: opts.Database,
+		Modules:  opts.Modules,
+	})
 	if err != nil {
 		return err
 	}
-	defer os.Chdir(originalDir)
-	
-	if err := os.Chdir(projectName); err != nil {
-		return err
-	}
 
-	if err := embed.CopyCoreFromEmbed(); err != nil {
+	if err := embed.CopyCoreFromEmbed(tx); err != nil {
 		return fmt.Errorf("failed to copy core templates: %w", err)
 	}
 
-	for _, moduleName := range moduleList {
-		options := map[string]interface{}{
-			"database": database,
+	// Dependencies are installed first and in their own right, so options
+	// such as a route prefix given for them are not dropped.
+	for _, moduleName := range dependencyOrder(opts.Modules) {
+		if _, exists := config.Modules[moduleName]; exists {
+			continue
 		}
 		
-		if err := addModuleWithAllDeps(moduleName, options); err != nil {
+		options := map[string]interface{}{
+			"database":     opts.Database,
+			"route_prefix": opts.RoutePrefixes[moduleName],
+		}
+		switch moduleName {
+		case "auth":
+			options["oauth_providers"] = strings.Join(opts.OAuthProviders, ",")
+		case "email":
+			options["email_sender"] = opts.EmailSender
+		}
+		if err := installModule(tx, config, moduleName, options); err != nil {
 			return fmt.Errorf("failed to add module %s: %w", moduleName, err)
 		}
 	}
 
+	if err := project.GenerateDeployFiles(tx, config); err != nil {
+		return err
+	}
+
+	return commitGeneration(tx, config)
+}
+
+func initDeployFiles(force, dryRun bool) error {
+	config, err := project.LoadProjectConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load project config: %w", err)
+	}
+
+	tx := staging.New(".", dryRun)
+	defer tx.Rollback()
+
+	if !force {
+		for _, path := range project.DeployFilePaths() {
+			if tx.Exists(path) {
+				return fmt.Errorf("%s already exists (use --force to overwrite)", path)
+			}
+		}
+	}
+
+	if err := project.GenerateDeployFiles(tx, config); err != nil {
+		return err
+	}
+
+	if tx.IsDryRun() {
+		tx.PrintPlan(os.Stdout)
+		return nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to write deployment files (changes rolled back): %w", err)
+	}
+
 	return nil
 }
 
-func addModuleWithAllDeps(moduleName string, options map[string]interface{}) error {
-	if !modules.IsModuleAvailable(moduleName) {
-		return fmt.Errorf("unknown module '%s'. Run 'sgk list' to see available modules", moduleName)
+func syncEnvExample(dryRun bool) error {
+	config, err := project.LoadProjectConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load project config: %w", err)
+	}
+
+	tx := staging.New(".", dryRun)
+	defer tx.Rollback()
+
+	if err := tx.WriteFile(env.ExampleFile, env.Example(config), 0644); err != nil {
+		return err
+	}
+
+	if tx.IsDryRun() {
+		tx.PrintPlan(os.Stdout)
+		return nil
+	}
+
+	return tx.Commit()
+}
+
+func checkEnvFile(path string, production bool) error {
+	config, err := project.LoadProjectConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load project config: %w", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	values, keys, err := env.Parse(content)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	issues := env.Check(env.Schema(config), values, keys, production)
+	if len(issues) == 0 {
+		fmt.Printf("✅ %s matches the installed modules\n", path)
+		return nil
+	}
+
+	errorCount := 0
+	for _, issue := range issues {
+		icon := "⚠️ "
+		if issue.Severity == env.SeverityError {
+			icon = "❌"
+			errorCount++
+		}
+		fmt.Printf("%s %s: %s\n", icon, issue.Name, issue.Message)
+	}
+
+	if errorCount > 0 {
+		return fmt.Errorf("%s has %d error(s)", path, errorCount)
+	}
+	return nil
+}
+
+func listRoutes(jsonOutput bool) error {
+	if _, err := project.LoadProjectConfig(); err != nil {
+		return fmt.Errorf("failed to load project config: %w", err)
 	}
 
+	found, err := routes.Analyze(".")
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		return routes.PrintJSON(os.Stdout, found)
+	}
+
+	routes.PrintTable(os.Stdout, found)
+	return nil
+}
+
+func moduleCatalog() []commands.ModuleChoice {
+	var catalog []commands.ModuleChoice
+	for _, module := range modules.GetAvailableModules() {
+		catalog = append(catalog, commands.ModuleChoice{
+			Name:         module.Name,
+			Description:  module.Description,
+			RoutePrefix:  module.RoutePrefix,
+			Dependencies: module.InternalDependencies,
+		})
+	}
+	return catalog
+}
+
+func addModuleWithAllDeps(moduleName string, options map[string]interface{}) error {
 	config, err := project.LoadProjectConfig()
 	if err != nil {
 		return fmt.Errorf("failed to load project config: %w", err)
 	}
 
+	dryRun, _ := options["dry_run"].(bool)
+	tx := staging.New(".", dryRun)
+	defer tx.Rollback()
+
+	if err := installModule(tx, config, moduleName, options); err != nil {
+		return err
+	}
+
+	return commitGeneration(tx, config)
+}
+
+func commitGeneration(tx *staging.Transaction, config *project.ProjectConfig) error {
+	existing, err := tx.ReadFile(env.ExampleFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read %s: %w", env.ExampleFile, err)
+	}
+	if err := tx.WriteFile(env.ExampleFile, env.Merge(existing, config), 0644); err != nil {
+		return fmt.Errorf("failed to generate %s: %w", env.ExampleFile, err)
+	}
+
+	if err := project.StageProjectConfig(tx, config); err != nil {
+		return fmt.Errorf("failed to save project config: %w", err)
+	}
+
+	if tx.IsDryRun() {
+		tx.PrintPlan(os.Stdout)
+		return nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to write generated files (changes rolled back): %w", err)
+	}
+
+	return nil
+}
+
+func installModule(tx *staging.Transaction, config *project.ProjectConfig, moduleName string, options map[string]interface{}) error {
+	if !modules.IsModuleAvailable(moduleName) {
+		return fmt.Errorf("unknown module '%s'. Run 'sgk list' to see available modules", moduleName)
+	}
+
 	if config.Modules == nil {
 		config.Modules = make(map[string]project.ModuleInfo)
 	}
@@ -100,21 +282,25 @@ func addModuleWithAllDeps(moduleName string, options map[string]interface{}) err
 		
 		if _, exists := config.Modules[dep]; !exists {
 			fmt.Printf("⚠️  Module %s requires %s. Installing it first...\n", moduleName, dep)
-			if err := addModuleWithAllDeps(dep, map[string]interface{}{}); err != nil {
-				return fmt.Errorf("failed to install dependency %s: %w", dep, err)
+			depOptions := map[string]interface{}{
+				"database": getStringOption(options, "database", config.Project.Database),
 			}
-			config, err = project.LoadProjectConfig()
-			if err != nil {
-				return fmt.Errorf("failed to reload project config: %w", err)
+			if err := installModule(tx, config, dep, depOptions); err != nil {
+				return fmt.Errorf("failed to install dependency %s: %w", dep, err)
 			}
 		} else {
 			fmt.Printf("✅ Module %s dependency %s is already installed\n", moduleName, dep)
 		}
 	}
 
-	if options["route_prefix"] == "" {
-		options["route_prefix"] = fmt.Sprintf("/api/%s", moduleName)
+	routePrefix := getStringOption(options, "route_prefix", "")
+	if routePrefix == "" {
+		routePrefix = moduleDef.RoutePrefix
 	}
+	if routePrefix == "" {
+		routePrefix = fmt.Sprintf("/api/v1/%s", moduleName)
+	}
+	options["route_prefix"] = routePrefix
 
 	templateData := embed.TemplateData{
 		Project: struct {
@@ -127,13 +313,19 @@ func addModuleWithAllDeps(moduleName string, options map[string]interface{}) err
 			Database: getStringOption(options, "database", "postgres"),
 		},
 		Module: struct {
-			Name string
+			Name        string
+			RoutePrefix string
 		}{
-			Name: moduleName,
+			Name:        moduleName,
+			RoutePrefix: routePrefix,
 		},
+		Options: make(map[string]string),
+	}
+	for key, value := range options {
+		templateData.Options[key] = fmt.Sprint(value)
 	}
 
-	if err := embed.CopyModuleFromEmbed(moduleName, templateData); err != nil {
+	if err := embed.CopyModuleFromEmbed(tx, moduleName, templateData); err != nil {
 		return err
 	}
 
@@ -141,18 +333,69 @@ func addModuleWithAllDeps(moduleName string, options map[string]interface{}) err
 		Version:     moduleDef.Version,
 		InstalledAt: time.Now(),
 	}
-
-	if err := project.SaveProjectConfig(config); err != nil {
-		return fmt.Errorf("failed to save project config: %w", err)
+	if err := setModuleOptions(config, moduleName, options); err != nil {
+		return err
 	}
 
-	if err := updateMainGoWithModule(config.Project.GoModule, moduleName); err != nil {
+	if err := updateMainGoWithModule(tx, config.Project.GoModule, moduleName); err != nil {
 		return fmt.Errorf("failed to update main.go: %w", err)
 	}
 
 	return nil
 }
 
+// setModuleOptions records the generation options of an installed module in
+// sgk.json so that updates can regenerate it the same way.
+func setModuleOptions(config *project.ProjectConfig, moduleName string, options map[string]interface{}) error {
+	info, exists := config.Modules[moduleName]
+	if !exists {
+		return fmt.Errorf("module '%s' is not installed", moduleName)
+	}
+
+	if info.Options == nil {
+		info.Options = make(map[string]string)
+	}
+	for key, value := range options {
+		if key == "dry_run" {
+			continue
+		}
+		if str := fmt.Sprint(value); str != "" {
+			info.Options[key] = str
+		}
+	}
+
+	config.Modules[moduleName] = info
+	return nil
+}
+
+// dependencyOrder returns names together with their internal dependencies,
+// each module after the modules it depends on. Unknown names are kept so
+// installModule can report them.
+func dependencyOrder(names []string) []string {
+	var ordered []string
+	visited := make(map[string]bool)
+
+	var visit func(name string)
+	visit = func(name string) {
+		if name == "core" || visited[name] {
+			return
+		}
+		visited[name] = true
+
+		if moduleDef, err := modules.GetModule(name); err == nil {
+			for _, dep := range moduleDef.InternalDependencies {
+				visit(dep)
+			}
+		}
+		ordered = append(ordered, name)
+	}
+
+	for _, name := range names {
+		visit(name)
+	}
+	return ordered
+}
+
 func getStringOption(options map[string]interface{}, key, defaultValue string) string {
 	if val, ok := options[key]; ok {
 		if str, ok := val.(string); ok {
@@ -188,9 +431,9 @@ func listInstalledModulesFromConfig() error {
 	return nil
 }
 
-func updateMainGoWithModule(goModule, moduleName string) error {
+func updateMainGoWithModule(tx *staging.Transaction, goModule, moduleName string) error {
 	mainPath := "main.go"
-	content, err := os.ReadFile(mainPath)
+	content, err := tx.ReadFile(mainPath)
 	if err != nil {
 		return fmt.Errorf("failed to read main.go: %w", err)
 	}
@@ -266,7 +509,7 @@ func updateMainGoWithModule(goModule, moduleName string) error {
 	
 	mainContent = mainContent[:markerPos] + strings.Join(newLines, "\n")
 
-	if err := os.WriteFile(mainPath, []byte(mainContent), 0644); err != nil {
+	if err := tx.WriteFile(mainPath, []byte(mainContent), 0644); err != nil {
 		return fmt.Errorf("failed to write main.go: %w", err)
 	}
 